@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"registry/internal/bench"
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// runBench implements the bench subcommand, which loads a synthetic registry into each
+// named backend and reports the throughput and latency of creating, listing and searching
+func runBench(args []string) error {
+	cfg := config.NewConfig()
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	backends := fs.String("backends", "memory", "Comma-separated backends to measure: memory, mongodb")
+	mongoURL := fs.String("mongodb-url", cfg.DatabaseURL, "Connection URL of the MongoDB deployment to measure")
+	mongoName := fs.String("mongodb-database", "mcp_registry_bench", "Name of the empty MongoDB database to load")
+	entries := fs.Int("entries", bench.DefaultOptions.Entries, "Number of versions to load")
+	operations := fs.Int("operations", bench.DefaultOptions.Operations, "Number of list and search requests to measure each")
+	concurrency := fs.Int("concurrency", bench.DefaultOptions.Concurrency, "Number of requests in flight at once")
+	keep := fs.Bool("keep", false, "Keep the data loaded into MongoDB instead of dropping the database")
+	asJSON := fs.Bool("json", false, "Print the results as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *entries <= 0 || *operations <= 0 || *concurrency <= 0 {
+		return errors.New("-entries, -operations and -concurrency must be positive")
+	}
+	opts := bench.Options{Entries: *entries, Operations: *operations, Concurrency: *concurrency}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var results []bench.Result
+	for _, backend := range strings.Split(*backends, ",") {
+		backend = strings.TrimSpace(backend)
+		var (
+			db    database.Database
+			mongo *database.MongoDB
+		)
+		switch backend {
+		case "memory":
+			db = database.NewMemoryDB(map[string]*model.Server{})
+		case "mongodb":
+			var err error
+			mongo, err = connectMongoDB(*mongoURL, *mongoName, cfg.CollectionName, cfg.DatabaseConnectTimeout)
+			if err != nil {
+				return fmt.Errorf("error connecting to MongoDB: %w", err)
+			}
+			db = mongo
+		default:
+			return fmt.Errorf("unknown backend %q: expected memory or mongodb", backend)
+		}
+
+		fmt.Fprintf(os.Stderr, "Benchmarking %s with %d versions...\n", backend, opts.Entries)
+		backendResults, err := bench.Run(ctx, backend, db, opts)
+		// Drop what the run loaded, even when interrupted, so the next run starts empty. A
+		// database that was not empty to begin with was left untouched and is kept.
+		if mongo != nil && !errors.Is(err, bench.ErrNotEmpty) && !*keep {
+			dropCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			if dropErr := mongo.Drop(dropCtx); dropErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to drop %s: %v\n", *mongoName, dropErr)
+			}
+			cancel()
+		}
+		db.Close()
+		if err != nil {
+			return fmt.Errorf("error benchmarking %s: %w", backend, err)
+		}
+		results = append(results, backendResults...)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "BACKEND\tOPERATION\tCOUNT\tERRORS\tOPS/S\tP50\tP99\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.0f\t%s\t%s\t\n",
+			r.Backend, r.Operation, r.Count, r.Errors, r.Throughput, r.P50, r.P99)
+	}
+	return tw.Flush()
+}
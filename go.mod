@@ -8,6 +8,8 @@ require (
 	github.com/google/uuid v1.6.0
 	go.mongodb.org/mongo-driver v1.17.4
 	golang.org/x/net v0.41.0
+	golang.org/x/sys v0.33.0
+	golang.org/x/text v0.26.0
 )
 
 require (
@@ -20,5 +22,4 @@ require (
 	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
 	golang.org/x/crypto v0.39.0 // indirect
 	golang.org/x/sync v0.15.0 // indirect
-	golang.org/x/text v0.26.0 // indirect
 )
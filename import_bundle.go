@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"registry/internal/bundle"
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/media"
+	"registry/internal/signing"
+)
+
+// runImportBundle implements the import-bundle subcommand, which verifies a bundle written by
+// GET /v0/export?format=tar.gz against the trusted keys and then imports its versions and
+// icons, for registries that cannot mirror over the network
+func runImportBundle(args []string) error {
+	cfg := config.NewConfig()
+	fs := flag.NewFlagSet("import-bundle", flag.ExitOnError)
+	to := fs.String("to", cfg.DatabaseURL, "Connection URL of the database to import into")
+	toName := fs.String("to-database", cfg.DatabaseName, "Name of the database to import into")
+	conflict := fs.String("conflict", cfg.ReplicationConflictPolicy,
+		"How to resolve local versions with the same name and version but another ID: source-wins or local-wins")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s import-bundle [flags] file.tar.gz\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("exactly one bundle file is required")
+	}
+	policy := database.ConflictPolicy(*conflict)
+	if policy != database.ConflictSourceWins && policy != database.ConflictLocalWins {
+		return fmt.Errorf("invalid conflict policy %q; supported: %s, %s",
+			policy, database.ConflictSourceWins, database.ConflictLocalWins)
+	}
+
+	verifier, err := signing.NewVerifier(strings.Split(cfg.BundleTrustedKeys, ","))
+	if err != nil {
+		return fmt.Errorf("invalid MCP_REGISTRY_BUNDLE_TRUSTED_KEYS: %w", err)
+	}
+	if verifier.Len() == 0 {
+		return errors.New("MCP_REGISTRY_BUNDLE_TRUSTED_KEYS is empty; bundles are only imported when signed by a trusted key")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// Everything is verified before anything is written, so a tampered bundle imports nothing
+	index, versions, err := bundle.Read(file, verifier)
+	if err != nil {
+		return err
+	}
+	log.Printf("Verified bundle of %d versions at revision %d, exported %s",
+		len(versions), index.Revision, index.ExportedAt.Format(time.RFC3339))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openMigrationDB(*to, *toName, cfg)
+	if err != nil {
+		return fmt.Errorf("error opening target: %w", err)
+	}
+	defer db.Close()
+	icons, err := media.NewStore(cfg)
+	if err != nil {
+		return fmt.Errorf("error opening media store: %w", err)
+	}
+
+	imported, kept, withIcons := 0, 0, 0
+	for _, version := range versions {
+		detail := version.Detail
+		err := db.Replicate(ctx, detail, policy)
+		if errors.Is(err, database.ErrConflict) {
+			log.Printf("Kept the local version of %s %s", detail.Name, detail.VersionDetail.Version)
+			kept++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error importing %s %s: %w", detail.Name, detail.VersionDetail.Version, err)
+		}
+		imported++
+
+		if version.Icon == nil {
+			continue
+		}
+		contentType, err := media.ValidateIcon(version.Icon, version.IconType)
+		if err != nil {
+			log.Printf("Skipped the icon of %s %s: %v", detail.Name, detail.VersionDetail.Version, err)
+			continue
+		}
+		if err := icons.Put(ctx, media.IconKey(detail.ID), version.Icon, contentType); err != nil {
+			return fmt.Errorf("error storing the icon of %s: %w", detail.ID, err)
+		}
+		withIcons++
+	}
+	log.Printf("Imported %d versions and %d icons; kept %d local versions", imported, withIcons, kept)
+	return nil
+}
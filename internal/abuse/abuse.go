@@ -0,0 +1,178 @@
+// Package abuse detects clients producing sustained failures, such as publishers retrying
+// invalid requests or scanners enumerating IDs, and bans them temporarily
+package abuse
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"registry/internal/config"
+)
+
+// Kind classifies a failed request
+type Kind int
+
+const (
+	// WriteFailure is a write rejected as invalid or unauthorized
+	WriteFailure Kind = iota
+	// NotFound is a read of something that does not exist
+	NotFound
+)
+
+// Ban is a temporary ban of a client address
+type Ban struct {
+	IP       string    `json:"ip"`
+	Reason   string    `json:"reason"`
+	BannedAt time.Time `json:"banned_at"`
+	Until    time.Time `json:"until"`
+}
+
+// window counts the failures of one client in the current window
+type window struct {
+	start         time.Time
+	writeFailures int
+	notFound      int
+}
+
+// Detector tracks failures per client address in fixed windows and bans addresses that
+// exceed a threshold. Its state is kept per process.
+type Detector struct {
+	enabled          bool
+	window           time.Duration
+	maxWriteFailures int
+	maxNotFound      int
+	banDuration      time.Duration
+
+	mu        sync.Mutex
+	clients   map[string]*window
+	bans      map[string]*Ban
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// New creates a detector with the thresholds of cfg; it does nothing unless
+// MCP_REGISTRY_ABUSE_DETECTION is set
+func New(cfg *config.Config) *Detector {
+	return &Detector{
+		enabled:          cfg.AbuseDetection && cfg.AbuseWindow > 0 && cfg.AbuseBanDuration > 0,
+		window:           cfg.AbuseWindow,
+		maxWriteFailures: cfg.AbuseMaxWriteFailures,
+		maxNotFound:      cfg.AbuseMaxNotFound,
+		banDuration:      cfg.AbuseBanDuration,
+		clients:          make(map[string]*window),
+		bans:             make(map[string]*Ban),
+		now:              time.Now,
+	}
+}
+
+// Enabled reports whether the detector tracks and bans clients
+func (d *Detector) Enabled() bool {
+	return d.enabled
+}
+
+// Banned returns the ban of ip, if it is banned
+func (d *Detector) Banned(ip string) (Ban, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ban, ok := d.bans[ip]
+	if !ok {
+		return Ban{}, false
+	}
+	if !d.now().Before(ban.Until) {
+		delete(d.bans, ip)
+		return Ban{}, false
+	}
+	return *ban, true
+}
+
+// Record counts a failed request from ip, banning ip when it exceeds a threshold
+func (d *Detector) Record(ip string, kind Kind) {
+	if !d.enabled || ip == "" {
+		return
+	}
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep(now)
+
+	w, ok := d.clients[ip]
+	if !ok || now.Sub(w.start) >= d.window {
+		w = &window{start: now}
+		d.clients[ip] = w
+	}
+
+	reason := ""
+	switch kind {
+	case WriteFailure:
+		w.writeFailures++
+		if d.maxWriteFailures > 0 && w.writeFailures >= d.maxWriteFailures {
+			reason = "sustained invalid or unauthorized writes"
+		}
+	case NotFound:
+		w.notFound++
+		if d.maxNotFound > 0 && w.notFound >= d.maxNotFound {
+			reason = "ID enumeration"
+		}
+	}
+	if reason != "" {
+		d.bans[ip] = &Ban{IP: ip, Reason: reason, BannedAt: now.UTC(), Until: now.Add(d.banDuration).UTC()}
+		delete(d.clients, ip)
+	}
+}
+
+// sweep drops windows and bans that have run out, at most once per window, so addresses
+// seen once do not accumulate. Callers must hold mu.
+func (d *Detector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.window {
+		return
+	}
+	d.lastSweep = now
+	for ip, w := range d.clients {
+		if now.Sub(w.start) >= d.window {
+			delete(d.clients, ip)
+		}
+	}
+	for ip, ban := range d.bans {
+		if !now.Before(ban.Until) {
+			delete(d.bans, ip)
+		}
+	}
+}
+
+// Bans returns the active bans, soonest to expire first
+func (d *Detector) Bans() []Ban {
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	bans := []Ban{}
+	for _, ban := range d.bans {
+		if now.Before(ban.Until) {
+			bans = append(bans, *ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		if !bans[i].Until.Equal(bans[j].Until) {
+			return bans[i].Until.Before(bans[j].Until)
+		}
+		return bans[i].IP < bans[j].IP
+	})
+	return bans
+}
+
+// Lift removes the ban of ip and forgets its failures, reporting whether it was banned
+func (d *Detector) Lift(ip string) bool {
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ban, banned := d.bans[ip]
+	delete(d.bans, ip)
+	delete(d.clients, ip)
+	return banned && now.Before(ban.Until)
+}
@@ -0,0 +1,53 @@
+// Package envelope defines the standard body of API responses from version 1 onwards
+package envelope
+
+import (
+	"context"
+	"time"
+)
+
+// Envelope wraps every JSON response body. Data is null when the request failed.
+type Envelope struct {
+	Data   interface{} `json:"data"`
+	Meta   Meta        `json:"meta"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Meta describes the request a response answers
+type Meta struct {
+	RequestID string `json:"request_id"`
+	// TookMS is the time spent handling the request before the body was written
+	TookMS int64 `json:"took_ms"`
+	// Pagination holds the paging fields of list responses, such as the next cursor
+	Pagination interface{} `json:"pagination,omitempty"`
+}
+
+// Error describes why a request failed
+type Error struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// request is the per-request state stored in the context
+type request struct {
+	id    string
+	start time.Time
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx marking responses to be enveloped, with the ID and
+// start time of the request
+func NewContext(ctx context.Context, requestID string, start time.Time) context.Context {
+	return context.WithValue(ctx, contextKey{}, request{id: requestID, start: start})
+}
+
+// MetaFromContext returns the metadata of the request in ctx, and false when responses
+// to it are not enveloped
+func MetaFromContext(ctx context.Context) (Meta, bool) {
+	req, ok := ctx.Value(contextKey{}).(request)
+	if !ok {
+		return Meta{}, false
+	}
+	return Meta{RequestID: req.id, TookMS: time.Since(req.start).Milliseconds()}, true
+}
@@ -0,0 +1,47 @@
+// Package debug contains diagnostic API handlers that are only exposed to developers and admins
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"registry/internal/sampling"
+	"registry/internal/service"
+)
+
+// StoreStatsHandler returns a handler reporting statistics about the database backend
+func StoreStatsHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, err := registry.StoreStats()
+		if err != nil {
+			http.Error(w, "Failed to collect store stats: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// RequestsHandler returns a handler listing the sampled mutating requests, newest first
+func RequestsHandler(recorder *sampling.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := struct {
+			Enabled  bool              `json:"enabled"`
+			Requests []sampling.Sample `json:"requests"`
+		}{
+			Enabled:  recorder.Enabled(),
+			Requests: recorder.Samples(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
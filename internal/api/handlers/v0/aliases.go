@@ -0,0 +1,85 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// AliasRequest is the body accepted when aliasing a former server name
+type AliasRequest struct {
+	Target string `json:"target"`
+}
+
+// AliasesResponse lists the aliases of renamed servers
+type AliasesResponse struct {
+	Aliases []*model.ServerAlias `json:"aliases"`
+}
+
+func (a AliasesResponse) envelopeParts() (interface{}, interface{}) {
+	return a.Aliases, nil
+}
+
+// AliasesHandler returns a handler listing the aliases of renamed servers
+func AliasesHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		aliases, err := registry.Aliases()
+		if err != nil {
+			http.Error(w, "Error retrieving aliases", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, AliasesResponse{Aliases: aliases}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// AliasHandler returns a handler that keeps a former server name resolving to the server's
+// current name (PUT) or removes the alias (DELETE). The former name is taken from the path.
+func AliasHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		switch r.Method {
+		case http.MethodPut:
+			var req AliasRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Target == "" {
+				http.Error(w, "Invalid request payload: target is required", http.StatusBadRequest)
+				return
+			}
+			alias, err := registry.AliasServer(name, req.Target)
+			if err != nil {
+				switch {
+				case errors.Is(err, database.ErrNotFound):
+					http.Error(w, "Target server not found", http.StatusNotFound)
+				case errors.Is(err, database.ErrInvalidInput):
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				default:
+					http.Error(w, "Failed to store alias", storeErrorStatus(err))
+				}
+				return
+			}
+			if err := writeJSON(w, r, alias); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+				return
+			}
+		case http.MethodDelete:
+			if err := registry.RemoveAlias(name); err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					http.Error(w, "Alias not found", http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Failed to remove alias", storeErrorStatus(err))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// AuthorServersHandler returns a handler listing the servers published from an author's repositories
+func AuthorServersHandler(registry service.RegistryService) http.HandlerFunc {
+	// Author listings are spread over too many paths to be worth caching
+	pages := newPageCache(0)
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeServerPage(w, r, registry, map[string]interface{}{
+			"author": r.PathValue("author"),
+		}, pages)
+	}
+}
+
+// AuthorHandler returns a handler for an author's profile and publication counts
+func AuthorHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profile, err := registry.AuthorProfile(r.PathValue("author"))
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				http.Error(w, "Author not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Error retrieving author", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, profile); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/badge"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// badgeCacheControl keeps badges embedded in READMEs reasonably fresh after a publish
+const badgeCacheControl = "public, max-age=300"
+
+// maxBadgeLabel bounds the label a badge may be rendered with
+const maxBadgeLabel = 64
+
+// BadgeHandler returns a handler rendering a shields.io style SVG badge showing the latest
+// version of a server, or that it is yanked when every version is. label overrides the
+// left-hand text. The registry tracks neither downloads nor verification, so those badge
+// types are rejected.
+func BadgeHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+		query := r.URL.Query()
+		switch query.Get("type") {
+		case "", "version":
+		case "downloads", "verified":
+			http.Error(w, "Unsupported badge type: the registry does not track "+query.Get("type"), http.StatusBadRequest)
+			return
+		default:
+			http.Error(w, "Invalid badge type: expected version", http.StatusBadRequest)
+			return
+		}
+		label := query.Get("label")
+		if label == "" {
+			label = "mcp registry"
+		}
+		if len(label) > maxBadgeLabel {
+			http.Error(w, "Label is too long", http.StatusBadRequest)
+			return
+		}
+
+		serverDetail, err := registry.GetByID(id)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+		if !canView(r, authService, serverDetail) {
+			serverNotFound(w, "Server not found")
+			return
+		}
+
+		message, color := "yanked", badge.ColorRed
+		cacheControl := cacheControlFor(serverDetail, badgeCacheControl)
+		// Private versions other callers may not see must not show through the badge
+		latest, err := registry.LatestVersionWhere(id, func(version *model.ServerDetail) bool {
+			return canView(r, authService, version)
+		})
+		switch {
+		case err == nil:
+			message, color = "v"+latest.VersionDetail.Version, badge.ColorBlue
+			if latest.Visibility.Effective() == model.VisibilityPrivate {
+				cacheControl = privateCacheControl
+			}
+		case !errors.Is(err, database.ErrNotFound):
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", badge.ContentType)
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+		if _, err := w.Write(badge.Render(label, message, color)); err != nil {
+			return
+		}
+	}
+}
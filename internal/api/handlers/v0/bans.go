@@ -0,0 +1,39 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/abuse"
+)
+
+// BansResponse lists the clients banned for abusive failure patterns
+type BansResponse struct {
+	Enabled bool        `json:"enabled"`
+	Bans    []abuse.Ban `json:"bans"`
+}
+
+func (b BansResponse) envelopeParts() (interface{}, interface{}) {
+	return b.Bans, nil
+}
+
+// BansHandler returns a handler listing the active bans, soonest to expire first
+func BansHandler(detector *abuse.Detector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := writeJSON(w, r, BansResponse{Enabled: detector.Enabled(), Bans: detector.Bans()}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// BanHandler returns a handler lifting the ban of an address before it expires
+func BanHandler(detector *abuse.Detector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !detector.Lift(r.PathValue("ip")) {
+			http.Error(w, "Address is not banned", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
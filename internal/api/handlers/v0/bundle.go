@@ -0,0 +1,75 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"registry/internal/bundle"
+	"registry/internal/media"
+	"registry/internal/model"
+	"registry/internal/service"
+	"registry/internal/signing"
+)
+
+// wantsBundle reports whether an export asks for a tar.gz bundle, with format=tar.gz or
+// Accept: application/gzip
+func wantsBundle(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "tar.gz" {
+		return true
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == bundle.ContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBundle streams the export as a tar.gz bundle holding the manifest, README, icon and
+// signatures of every version
+func writeBundle(w http.ResponseWriter, r *http.Request, registry service.RegistryService, icons media.Store,
+	signer *signing.Signer, revision int64, filter map[string]interface{}, active bool) {
+	w.Header().Set("Content-Type", bundle.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="registry-%d.tar.gz"`, revision))
+
+	archive := bundle.NewWriter(w, signer, revision)
+	err := registry.Export(filter, active, func(entry *model.ServerDetail) error {
+		manifest, err := bundleManifest(registry, entry)
+		if err != nil {
+			return err
+		}
+		var icon []byte
+		var iconType string
+		object, err := icons.Get(r.Context(), media.IconKey(entry.ID))
+		switch {
+		case err == nil:
+			icon, iconType = object.Data, object.ContentType
+		case !errors.Is(err, media.ErrNotFound):
+			return fmt.Errorf("error reading icon of %s: %w", entry.ID, err)
+		}
+		return archive.Add(entry, manifest, icon, iconType)
+	})
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		// Headers are already sent, so the archive is simply cut short and fails to unpack
+		log.Printf("Export bundle aborted: %v", err)
+	}
+}
+
+// bundleManifest returns the stored manifest of entry, or computes it for versions
+// published before manifests were stored
+func bundleManifest(registry service.RegistryService, entry *model.ServerDetail) ([]byte, error) {
+	if entry.Digest != "" {
+		return registry.GetManifest(entry.Digest)
+	}
+	_, manifest, err := entry.Manifest()
+	return manifest, err
+}
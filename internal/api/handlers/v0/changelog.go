@@ -0,0 +1,60 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// ChangelogResponse is the release notes of a single server version
+type ChangelogResponse struct {
+	Version     string `json:"version"`
+	ReleaseDate string `json:"release_date"`
+	Changelog   string `json:"changelog"`
+}
+
+// ChangelogHandler returns a handler serving the changelog published with a server version
+func ChangelogHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.GetVersion(id, r.PathValue("version"))
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server version not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+		if !canView(r, authService, serverDetail) {
+			serverNotFound(w, "Server version not found")
+			return
+		}
+
+		serverDetail = contentFor(r, serverDetail)
+
+		if serverDetail.Changelog == "" {
+			http.Error(w, "Changelog not found", http.StatusNotFound)
+			return
+		}
+
+		// Published versions are immutable, so their changelog can be cached
+		w.Header().Set("Cache-Control", cacheControlFor(serverDetail, "public, max-age=3600"))
+		if err := writeJSON(w, r, ChangelogResponse{
+			Version:     serverDetail.VersionDetail.Version,
+			ReleaseDate: serverDetail.VersionDetail.ReleaseDate,
+			Changelog:   serverDetail.Changelog,
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// RevisionHeader reports the change log revision a response reflects
+const RevisionHeader = "X-Registry-Revision"
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+)
+
+// ChangesResponse is a page of the change log
+type ChangesResponse struct {
+	Changes []*model.Change `json:"changes"`
+	// NextSince is the revision to pass as ?since= to continue syncing
+	NextSince int64 `json:"next_since"`
+	// HasMore is set when further changes are available immediately
+	HasMore bool `json:"has_more"`
+}
+
+// changesPagination is the paging part of a ChangesResponse
+type changesPagination struct {
+	NextSince int64 `json:"next_since"`
+	HasMore   bool  `json:"has_more"`
+}
+
+func (c ChangesResponse) envelopeParts() (interface{}, interface{}) {
+	return c.Changes, changesPagination{NextSince: c.NextSince, HasMore: c.HasMore}
+}
+
+// ChangesHandler returns a handler listing the change log after a revision or RFC 3339 timestamp
+func ChangesHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			sinceRevision int64
+			sinceTime     time.Time
+		)
+		if since := r.URL.Query().Get("since"); since != "" {
+			revision, revErr := strconv.ParseInt(since, 10, 64)
+			timestamp, timeErr := time.Parse(time.RFC3339, since)
+			switch {
+			case revErr == nil && revision >= 0:
+				sinceRevision = revision
+			case timeErr == nil:
+				sinceTime = timestamp
+			default:
+				http.Error(w, "Invalid since parameter: expected a revision or RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+		}
+
+		limit := defaultChangesLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			parsedLimit, err := strconv.Atoi(limitStr)
+			if err != nil || parsedLimit <= 0 {
+				http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+				return
+			}
+			limit = min(parsedLimit, maxChangesLimit)
+		}
+
+		// Read the head first so that a timestamp query with no results still yields a
+		// revision from which no later change can be missed
+		head, err := registry.HeadRevision()
+		if err != nil {
+			http.Error(w, "Failed to list changes", storeErrorStatus(err))
+			return
+		}
+
+		changes, err := registry.Changes(sinceRevision, sinceTime, limit)
+		if errors.Is(err, database.ErrPruned) {
+			http.Error(w, "Changes after this revision have been pruned; bootstrap again from /v0/export", http.StatusGone)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to list changes", storeErrorStatus(err))
+			return
+		}
+
+		response := ChangesResponse{Changes: changes, NextSince: sinceRevision, HasMore: len(changes) == limit}
+		switch {
+		case len(changes) > 0:
+			response.NextSince = changes[len(changes)-1].Revision
+		case !sinceTime.IsZero():
+			response.NextSince = head
+		}
+
+		// Progress covers every change read, but only changes to public versions are served
+		response.Changes = publicChanges(changes)
+
+		w.Header().Set(RevisionHeader, strconv.FormatInt(head, 10))
+		if err := writeJSON(w, r, response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// publicChanges returns the changes to public versions
+func publicChanges(changes []*model.Change) []*model.Change {
+	public := make([]*model.Change, 0, len(changes))
+	for _, change := range changes {
+		if change.Visibility.Effective() == model.VisibilityPublic {
+			public = append(public, change)
+		}
+	}
+	return public
+}
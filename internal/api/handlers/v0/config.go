@@ -0,0 +1,37 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/config"
+)
+
+// ConfigResponse lists the effective configuration of this instance
+type ConfigResponse struct {
+	Settings []config.Setting `json:"settings"`
+}
+
+func (c ConfigResponse) envelopeParts() (interface{}, interface{}) {
+	return c.Settings, nil
+}
+
+// ConfigHandler returns a handler listing every setting with its effective value and
+// where it came from. With diff=true only the settings differing from their defaults are
+// listed.
+func ConfigHandler(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		diff := r.URL.Query().Get("diff") == "true"
+		settings := []config.Setting{}
+		for _, setting := range cfg.Settings() {
+			if diff && setting.Source == config.SourceDefault {
+				continue
+			}
+			settings = append(settings, setting)
+		}
+		if err := writeJSON(w, r, ConfigResponse{Settings: settings}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
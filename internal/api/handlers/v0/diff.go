@@ -0,0 +1,66 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// DiffHandler returns a handler comparing two versions of a server, named by the from and
+// to query parameters. Either defaults to the version {id} refers to.
+func DiffHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get("from") == "" && query.Get("to") == "" {
+			http.Error(w, "from or to is required", http.StatusBadRequest)
+			return
+		}
+
+		versions := make([]*model.ServerDetail, 2)
+		for i, param := range []string{"from", "to"} {
+			var (
+				serverDetail *model.ServerDetail
+				err          error
+			)
+			if version := query.Get(param); version != "" {
+				serverDetail, err = registry.GetVersion(id, version)
+			} else {
+				serverDetail, err = registry.GetByID(id)
+			}
+			if err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					http.Error(w, "Server version not found: "+param+"="+query.Get(param), http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+				return
+			}
+			if !canView(r, authService, serverDetail) {
+				http.Error(w, "Server version not found: "+param+"="+query.Get(param), http.StatusNotFound)
+				return
+			}
+			versions[i] = serverDetail
+		}
+
+		// Versions are immutable apart from yanks, so diffs can be cached for a while
+		cacheControl := cacheControlFor(versions[0], "public, max-age=300")
+		if versions[1].Visibility.Effective() == model.VisibilityPrivate {
+			cacheControl = privateCacheControl
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+		if err := writeJSON(w, r, model.Diff(versions[0], versions[1])); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
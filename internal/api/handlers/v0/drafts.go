@@ -0,0 +1,215 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// DraftPreviewResponse is the validation report of a draft together with the draft's content
+type DraftPreviewResponse struct {
+	model.ValidationReport
+	Server model.ServerDetail `json:"server"`
+}
+
+// DraftsResponse lists the drafts of a server
+type DraftsResponse struct {
+	Drafts []*model.Draft `json:"drafts"`
+}
+
+func (d DraftsResponse) envelopeParts() (interface{}, interface{}) {
+	return d.Drafts, nil
+}
+
+// DraftsHandler returns a handler that lists the drafts of a server (GET with ?name=) or
+// saves a new draft (POST with a publish request body). Both require the credentials
+// needed to publish the server.
+func DraftsHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			name := r.URL.Query().Get("name")
+			if name == "" {
+				http.Error(w, "name parameter is required", http.StatusBadRequest)
+				return
+			}
+			if status, msg := authenticatePublisher(r, authService, name); status != 0 {
+				http.Error(w, msg, status)
+				return
+			}
+
+			drafts, err := registry.Drafts(name)
+			if err != nil {
+				http.Error(w, "Error retrieving drafts", storeErrorStatus(err))
+				return
+			}
+			if err := writeJSON(w, r, DraftsResponse{Drafts: drafts}); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		var serverDetail model.ServerDetail
+		if err := json.NewDecoder(r.Body).Decode(&serverDetail); err != nil {
+			http.Error(w, "Invalid server detail payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if serverDetail.Name == "" {
+			http.Error(w, "Name is required", http.StatusBadRequest)
+			return
+		}
+		if status, msg := authenticatePublisher(r, authService, serverDetail.Name); status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+
+		draft, err := registry.CreateDraft(&serverDetail)
+		if err != nil {
+			if errors.Is(err, database.ErrInvalidInput) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to save draft", storeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Location", r.URL.Path+"/"+draft.ID)
+		if err := writeJSONStatus(w, r, http.StatusCreated, draft); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// DraftHandler returns a handler that reads (GET), replaces (PUT) or discards (DELETE) a draft
+func DraftHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		draft, ok := authorizedDraft(w, r, registry, authService)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			var serverDetail model.ServerDetail
+			if err := json.NewDecoder(r.Body).Decode(&serverDetail); err != nil {
+				http.Error(w, "Invalid server detail payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			updated, err := registry.UpdateDraft(draft.ID, &serverDetail)
+			if err != nil {
+				switch {
+				case errors.Is(err, database.ErrNotFound):
+					http.Error(w, "Draft not found", http.StatusNotFound)
+				case errors.Is(err, database.ErrInvalidInput):
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				default:
+					http.Error(w, "Failed to update draft", storeErrorStatus(err))
+				}
+				return
+			}
+			draft = updated
+		case http.MethodDelete:
+			if err := registry.DeleteDraft(draft.ID); err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					http.Error(w, "Draft not found", http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Failed to delete draft", storeErrorStatus(err))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if err := writeJSON(w, r, draft); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// DraftPreviewHandler returns a handler reporting every problem that would prevent a draft
+// from being published
+func DraftPreviewHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		draft, ok := authorizedDraft(w, r, registry, authService)
+		if !ok {
+			return
+		}
+
+		report, err := registry.Validate(&draft.Server)
+		if err != nil {
+			http.Error(w, "Failed to validate draft", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, DraftPreviewResponse{ValidationReport: *report, Server: draft.Server}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// DraftPublishHandler returns a handler that publishes a draft and discards it
+func DraftPublishHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		draft, ok := authorizedDraft(w, r, registry, authService)
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.PublishDraft(draft.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				http.Error(w, "Draft not found", http.StatusNotFound)
+			case errors.Is(err, database.ErrInvalidVersion) || errors.Is(err, database.ErrAlreadyExists) ||
+				errors.Is(err, database.ErrInvalidInput):
+				http.Error(w, "Failed to publish draft: "+err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, "Failed to publish draft: "+err.Error(), storeErrorStatus(err))
+			}
+			return
+		}
+
+		if err := writeJSONStatus(w, r, http.StatusCreated, PublishResponse{
+			ID:      serverDetail.ID,
+			Message: "Server publication successful",
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// authorizedDraft loads the draft named by the request path and checks the caller may
+// publish its server, writing an error response and returning false otherwise
+func authorizedDraft(
+	w http.ResponseWriter,
+	r *http.Request,
+	registry service.RegistryService,
+	authService auth.Service,
+) (*model.Draft, bool) {
+	id, ok := pathID(w, r, "draft")
+	if !ok {
+		return nil, false
+	}
+
+	draft, err := registry.Draft(id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			http.Error(w, "Draft not found", http.StatusNotFound)
+			return nil, false
+		}
+		http.Error(w, "Error retrieving draft", storeErrorStatus(err))
+		return nil, false
+	}
+
+	if status, msg := authenticatePublisher(r, authService, draft.Server.Name); status != 0 {
+		http.Error(w, msg, status)
+		return nil, false
+	}
+	return draft, true
+}
@@ -0,0 +1,96 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"registry/internal/api/middleware"
+	"registry/internal/auth"
+	"registry/internal/config"
+	"registry/internal/sampling"
+)
+
+// EchoResponse describes a request as the handlers see it
+type EchoResponse struct {
+	sampling.Sample
+	Host       string         `json:"host"`
+	Proto      string         `json:"proto"`
+	RemoteAddr string         `json:"remote_addr"`
+	Principal  EchoPrincipal  `json:"principal"`
+	Publish    *PublishResult `json:"publish,omitempty"`
+}
+
+// EchoPrincipal reports who the request authenticates as
+type EchoPrincipal struct {
+	Admin bool   `json:"admin"`
+	Login string `json:"login,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// PublishResult reports how the publish endpoint would decode the request body
+type PublishResult struct {
+	Valid   bool   `json:"valid"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EchoHandler returns a handler replying with the request it received, after middleware and
+// with secrets redacted, so publishers can see why the registry rejects their requests
+func EchoHandler(cfg *config.Config, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Keep the prefix a sample would keep and count the rest
+		body, err := io.ReadAll(io.LimitReader(r.Body, sampling.MaxBodyBytes))
+		if err != nil {
+			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			return
+		}
+		rest, err := io.Copy(io.Discard, r.Body)
+		if err != nil {
+			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			return
+		}
+		size := len(body) + int(rest)
+
+		response := EchoResponse{
+			Sample:     sampling.Snapshot(r, body, size),
+			Host:       r.Host,
+			Proto:      r.Proto,
+			RemoteAddr: r.RemoteAddr,
+			Principal:  EchoPrincipal{Admin: middleware.IsAdmin(cfg, r)},
+		}
+
+		if r.Header.Get("Authorization") != "" {
+			login, _, msg := identifyUser(r, authService)
+			response.Principal.Login = login
+			response.Principal.Error = msg
+		}
+
+		if len(body) > 0 {
+			response.Publish = checkPublish(r, body, size)
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeJSON(w, r, response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// checkPublish decodes body the way the publish endpoint does
+func checkPublish(r *http.Request, body []byte, size int) *PublishResult {
+	if size > len(body) {
+		return &PublishResult{Error: "Body exceeds the echoed size and was not decoded"}
+	}
+
+	decode := r.Clone(r.Context())
+	decode.Body = io.NopCloser(bytes.NewReader(body))
+	detail, _, msg := decodePublishRequest(decode)
+	if detail == nil {
+		return &PublishResult{Error: msg}
+	}
+	return &PublishResult{Valid: true, Name: detail.Name, Version: detail.VersionDetail.Version}
+}
@@ -0,0 +1,112 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"registry/internal/api/envelope"
+	"registry/internal/codec"
+)
+
+const (
+	jsonContentType    = "application/json"
+	yamlContentType    = "application/yaml"
+	msgpackContentType = "application/msgpack"
+)
+
+// responseFormats maps the media types accepted in an Accept header to the content type
+// of the response written for them
+var responseFormats = map[string]string{
+	jsonContentType:           jsonContentType,
+	yamlContentType:           yamlContentType,
+	"application/x-yaml":      yamlContentType,
+	"text/yaml":               yamlContentType,
+	msgpackContentType:        msgpackContentType,
+	"application/x-msgpack":   msgpackContentType,
+	"application/vnd.msgpack": msgpackContentType,
+}
+
+// negotiateFormat returns the content type preferred by the request's Accept header among
+// JSON, YAML and MessagePack. JSON is returned for wildcards, ties with JSON and when
+// nothing acceptable is supported, so existing clients keep receiving JSON.
+func negotiateFormat(r *http.Request) string {
+	best, bestQ := jsonContentType, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, ok := responseFormats[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ || (q == bestQ && format == jsonContentType) {
+			best, bestQ = format, q
+		}
+	}
+	return best
+}
+
+// listResponse is implemented by list responses. In an envelope their items become the
+// data and their paging fields, if any, move to meta.pagination.
+type listResponse interface {
+	envelopeParts() (data interface{}, pagination interface{})
+}
+
+// writeJSON writes v with status 200 in the format negotiated from the request's Accept header
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return writeJSONStatus(w, r, http.StatusOK, v)
+}
+
+// writeJSONStatus writes v with status in the format negotiated from the request's Accept
+// header, wrapped in an envelope when the request was routed through middleware.Envelope.
+// JSON is streamed to the response; other formats are converted from the JSON encoding so
+// they carry the same field names. A json.RawMessage is written to JSON responses as is.
+func writeJSONStatus(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	if meta, ok := envelope.MetaFromContext(r.Context()); ok {
+		data := v
+		if list, ok := v.(listResponse); ok {
+			data, meta.Pagination = list.envelopeParts()
+		}
+		v = envelope.Envelope{Data: data, Meta: meta}
+	}
+
+	format := negotiateFormat(r)
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", format)
+	if format == jsonContentType {
+		w.WriteHeader(status)
+		if raw, ok := v.(json.RawMessage); ok {
+			_, err := w.Write(raw)
+			return err
+		}
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case yamlContentType:
+		data, err = codec.JSONToYAML(data)
+	case msgpackContentType:
+		data, err = codec.JSONToMsgPack(data)
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,30 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// storeErrorStatus maps an unexpected database error to an HTTP status code,
+// distinguishing a temporarily unreachable database, exhausted publish quotas and
+// archived organizations from other failures
+func storeErrorStatus(err error) int {
+	if errors.Is(err, database.ErrUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, service.ErrOrgArchived) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, service.ErrBlockedByScan) {
+		return http.StatusUnprocessableEntity
+	}
+	var quotaErr *service.QuotaError
+	if errors.As(err, &quotaErr) {
+		return quotaErr.Status()
+	}
+	return http.StatusInternalServerError
+}
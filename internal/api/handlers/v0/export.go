@@ -0,0 +1,123 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"registry/internal/config"
+	"registry/internal/media"
+	"registry/internal/model"
+	"registry/internal/service"
+	"registry/internal/signing"
+)
+
+// ExportHandler returns a handler that exports every server detail in the registry,
+// either as a JSON array, with Accept: application/x-ndjson as a stream of entries, with
+// format=csv or Accept: text/csv as a spreadsheet with a row per server, or with
+// format=tar.gz or Accept: application/gzip as a bundle for air-gapped mirrors, with files
+// signed by signer when one is configured. The listing filters and the org, namespace,
+// active and updated_since parameters narrow the export to a subset.
+func ExportHandler(registry service.RegistryService, cfg *config.Config, icons media.Store,
+	signer *signing.Signer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, active, ok := exportFilter(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		// Mirrors bootstrap from the export and then follow /v0/changes from this revision.
+		// It is read first, so changes made while exporting are replayed rather than missed.
+		revision, err := registry.HeadRevision()
+		if err != nil {
+			http.Error(w, "Failed to export servers", storeErrorStatus(err))
+			return
+		}
+		w.Header().Set(RevisionHeader, strconv.FormatInt(revision, 10))
+
+		if wantsBundle(r) {
+			writeBundle(w, r, registry, icons, signer, revision, filter, active)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "csv" || wantsCSV(r) {
+			rows := catalog{}
+			if err := registry.Export(filter, active, func(entry *model.ServerDetail) error {
+				rows.add(entry)
+				return nil
+			}); err != nil {
+				http.Error(w, "Failed to export servers", storeErrorStatus(err))
+				return
+			}
+			w.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="registry-%d.csv"`, revision))
+			if err := rows.writeCSV(w); err != nil {
+				log.Printf("CSV export aborted: %v", err)
+			}
+			return
+		}
+
+		if wantsNDJSON(r) {
+			stream := newNDJSONWriter(w)
+			if err := registry.Export(filter, active, func(entry *model.ServerDetail) error {
+				return stream.Write(entry)
+			}); err != nil {
+				// Headers are already sent, so the stream is simply cut short
+				log.Printf("Export stream aborted: %v", err)
+			}
+			return
+		}
+
+		servers := []*model.ServerDetail{}
+		if err := registry.Export(filter, active, func(entry *model.ServerDetail) error {
+			servers = append(servers, entry)
+			return nil
+		}); err != nil {
+			http.Error(w, "Failed to export servers", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, servers); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// exportFilter builds the filter of an export from the listing filters and the org,
+// namespace, active and updated_since query parameters, writing a 400 response and
+// returning false when one is invalid
+func exportFilter(w http.ResponseWriter, r *http.Request, cfg *config.Config) (map[string]interface{}, bool, bool) {
+	filter, ok := listingFilter(w, r, cfg)
+	if !ok {
+		return nil, false, false
+	}
+	params := r.URL.Query()
+	if org := strings.TrimSpace(params.Get("org")); org != "" {
+		filter["author"] = org
+	}
+	if namespace := strings.TrimSpace(params.Get("namespace")); namespace != "" {
+		filter["namespace"] = namespace
+	}
+	if since := params.Get("updated_since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			http.Error(w, "Invalid updated_since parameter: expected an RFC 3339 timestamp", http.StatusBadRequest)
+			return nil, false, false
+		}
+		filter["released_since"] = t.UTC().Format(time.RFC3339)
+	}
+	active := false
+	if value := params.Get("active"); value != "" {
+		var err error
+		if active, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid active parameter: expected true or false", http.StatusBadRequest)
+			return nil, false, false
+		}
+	}
+	return filter, active, true
+}
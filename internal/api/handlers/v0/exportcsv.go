@@ -0,0 +1,120 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"registry/internal/model"
+)
+
+// catalogColumns is the header line of a CSV export
+var catalogColumns = []string{
+	"id", "name", "description", "version", "release_date", "versions", "yanked",
+	"repository_url", "repository_source", "transports", "packages", "remotes",
+}
+
+// csvListSeparator joins the values of multi-valued columns within a cell
+const csvListSeparator = "; "
+
+// catalogRow is a server of a CSV export: the version it is represented by and the
+// number of versions exported
+type catalogRow struct {
+	detail   *model.ServerDetail
+	versions int
+}
+
+// catalog collects exported versions into one row per server
+type catalog map[string]*catalogRow
+
+// add counts entry towards its server, making it the server's row if it is the latest
+// version, or the most recently released one when no version is flagged latest
+func (c catalog) add(entry *model.ServerDetail) {
+	row, ok := c[entry.Name]
+	if !ok {
+		c[entry.Name] = &catalogRow{detail: entry, versions: 1}
+		return
+	}
+	row.versions++
+	current := row.detail.VersionDetail
+	if entry.VersionDetail.IsLatest != current.IsLatest {
+		if entry.VersionDetail.IsLatest {
+			row.detail = entry
+		}
+		return
+	}
+	if entry.VersionDetail.ReleaseDate > current.ReleaseDate {
+		row.detail = entry
+	}
+}
+
+// writeCSV writes the catalog sorted by name, with a header line
+func (c catalog) writeCSV(w io.Writer) error {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(catalogColumns); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := out.Write(c[name].record()); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// record flattens the row into the cells of catalogColumns
+func (row *catalogRow) record() []string {
+	detail := row.detail
+	var transports []string
+	for _, t := range []model.TransportType{model.TransportStdio, model.TransportSSE, model.TransportStreamableHTTP} {
+		if detail.SupportsTransport(t) {
+			transports = append(transports, string(t))
+		}
+	}
+	var packages []string
+	for _, p := range detail.Packages {
+		packages = append(packages, p.RegistryName+":"+p.Name+"@"+p.Version)
+	}
+	var remotes []string
+	for _, r := range detail.Remotes {
+		remotes = append(remotes, r.URL)
+	}
+
+	record := []string{
+		detail.ID,
+		detail.Name,
+		detail.Description,
+		detail.VersionDetail.Version,
+		detail.VersionDetail.ReleaseDate,
+		strconv.Itoa(row.versions),
+		strconv.FormatBool(detail.VersionDetail.Yanked),
+		detail.Repository.URL,
+		detail.Repository.Source,
+		strings.Join(transports, csvListSeparator),
+		strings.Join(packages, csvListSeparator),
+		strings.Join(remotes, csvListSeparator),
+	}
+	for i, cell := range record {
+		record[i] = spreadsheetSafe(cell)
+	}
+	return record
+}
+
+// spreadsheetSafe keeps a published value from being evaluated as a formula when the
+// export is opened in a spreadsheet, by prefixing cells that would start one with a quote
+func spreadsheetSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
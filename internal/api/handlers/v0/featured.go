@@ -0,0 +1,102 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// FeatureRequest is the body accepted when featuring a server
+type FeatureRequest struct {
+	Weight *int `json:"weight"`
+}
+
+// FeaturedServersHandler returns a handler listing the latest version of every featured
+// server, in the order curated by the operators
+func FeaturedServersHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		servers, err := registry.FeaturedServers()
+		if err != nil {
+			http.Error(w, "Error retrieving featured servers", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, PaginatedResponse{
+			Data:     servers,
+			Metadata: Metadata{Count: len(servers)},
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// FeaturedEntriesResponse lists the curation entries of featured servers
+type FeaturedEntriesResponse struct {
+	Featured []*model.FeaturedServer `json:"featured"`
+}
+
+func (f FeaturedEntriesResponse) envelopeParts() (interface{}, interface{}) {
+	return f.Featured, nil
+}
+
+// FeaturedEntriesHandler returns a handler listing the curation entries with their weights
+func FeaturedEntriesHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		featured, err := registry.FeaturedEntries()
+		if err != nil {
+			http.Error(w, "Error retrieving featured servers", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, FeaturedEntriesResponse{Featured: featured}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// FeaturedEntryHandler returns a handler that features a server with an ordering weight
+// (PUT) or stops featuring it (DELETE). The server is identified by name.
+func FeaturedEntryHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		switch r.Method {
+		case http.MethodPut:
+			var req FeatureRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Weight == nil {
+				http.Error(w, "Invalid request payload: weight is required", http.StatusBadRequest)
+				return
+			}
+			featured, err := registry.FeatureServer(name, *req.Weight)
+			if err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					serverNotFound(w, "Server not found")
+					return
+				}
+				http.Error(w, "Failed to feature server", storeErrorStatus(err))
+				return
+			}
+			if err := writeJSON(w, r, featured); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+				return
+			}
+		case http.MethodDelete:
+			if err := registry.UnfeatureServer(name); err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					http.Error(w, "Server is not featured", http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Failed to unfeature server", storeErrorStatus(err))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
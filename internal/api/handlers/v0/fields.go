@@ -0,0 +1,108 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// fieldAliases maps short field names accepted in ?fields= to their JSON keys
+var fieldAliases = map[string]string{
+	"version": "version_detail",
+}
+
+// parseFields parses the comma separated ?fields= query parameter.
+// It returns nil when no field selection was requested.
+func parseFields(r *http.Request) []string {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil
+	}
+
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if alias, ok := fieldAliases[f]; ok {
+			f = alias
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// validateFields reports the first unknown name in fields. Listings check the selection
+// up front, so an invalid one is rejected even when no entry would be selected from.
+func validateFields(fields []string) error {
+	for _, f := range fields {
+		if !isKnownField(f) {
+			return fmt.Errorf("unknown field: %s", f)
+		}
+	}
+	return nil
+}
+
+// selectFields marshals v and keeps only the requested top-level JSON keys.
+// Unknown field names are reported as an error so clients notice typos.
+func selectFields(v interface{}, fields []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		value, ok := all[f]
+		if !ok {
+			if !isKnownField(f) {
+				return nil, fmt.Errorf("unknown field: %s", f)
+			}
+			// Known but omitted (omitempty) fields are simply left out
+			continue
+		}
+		selected[f] = value
+	}
+	return selected, nil
+}
+
+// isKnownField reports whether f is a selectable field of a server detail
+func isKnownField(f string) bool {
+	return jsonFieldNames(reflect.TypeOf(serverDetailResponse{}))[f]
+}
+
+// jsonFieldNames collects the top-level JSON keys of a struct type, descending into embedded structs
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && tag == "" && fieldType.Kind() == reflect.Struct {
+			for name := range jsonFieldNames(fieldType) {
+				names[name] = true
+			}
+			continue
+		}
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		names[tag] = true
+	}
+	return names
+}
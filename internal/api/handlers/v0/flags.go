@@ -0,0 +1,69 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"registry/internal/flags"
+)
+
+// FlagOverrideRequest is the body accepted when overriding a feature flag
+type FlagOverrideRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// FlagsResponse lists the effective state of every feature flag
+type FlagsResponse struct {
+	Flags []flags.Status `json:"flags"`
+}
+
+func (f FlagsResponse) envelopeParts() (interface{}, interface{}) {
+	return f.Flags, nil
+}
+
+// FlagsHandler returns a handler listing the effective state of every feature flag
+func FlagsHandler(featureFlags *flags.Set) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := writeJSON(w, r, FlagsResponse{Flags: featureFlags.All()}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// FlagHandler returns a handler that sets (PUT) or clears (DELETE) a runtime override for a feature flag
+func FlagHandler(featureFlags *flags.Set) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if _, ok := featureFlags.Get(name); !ok {
+			http.Error(w, "Feature flag not found", http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req FlagOverrideRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+				http.Error(w, "Invalid request payload: enabled is required", http.StatusBadRequest)
+				return
+			}
+			if err := featureFlags.Override(name, *req.Enabled); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			if err := featureFlags.ClearOverride(name); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		status, _ := featureFlags.Get(name)
+		if err := writeJSON(w, r, status); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// GCHandler returns a handler that runs a garbage collection immediately and reports what it removed
+func GCHandler(registry service.RegistryService, policy database.RetentionPolicy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, err := registry.CollectGarbage(policy)
+		if err != nil {
+			http.Error(w, "Garbage collection failed: "+err.Error(), storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, report); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
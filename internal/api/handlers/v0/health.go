@@ -2,24 +2,52 @@
 package v0
 
 import (
-	"encoding/json"
 	"net/http"
+	"time"
+
+	"registry/internal/api/middleware"
 	"registry/internal/config"
+	"registry/internal/health"
 )
 
 type HealthResponse struct {
 	Status         string `json:"status"`
 	GitHubClientID string `json:"github_client_id"`
+	*HealthDetails
+}
+
+// HealthDetails is the process history added to the health response with verbose=true
+type HealthDetails struct {
+	StartedAt     time.Time      `json:"started_at"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	RestartReason string         `json:"restart_reason,omitempty"`
+	Checks        []health.Check `json:"checks"`
 }
 
-// HealthHandler returns a handler for health check endpoint
-func HealthHandler(cfg *config.Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(HealthResponse{
+// HealthHandler returns a handler for health check endpoint. With verbose=true it adds
+// the uptime, restart reason and recent dependency checks, which are only shown in
+// development or with the admin token because check errors may name internal hosts.
+func HealthHandler(cfg *config.Config, recorder *health.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := HealthResponse{
 			Status:         "ok",
 			GitHubClientID: cfg.GithubClientID,
-		}); err != nil {
+		}
+
+		if r.URL.Query().Get("verbose") == "true" {
+			if !cfg.IsDevelopment() && !middleware.IsAdmin(cfg, r) {
+				http.Error(w, "Admin authentication required", http.StatusUnauthorized)
+				return
+			}
+			response.HealthDetails = &HealthDetails{
+				StartedAt:     recorder.StartedAt(),
+				UptimeSeconds: int64(time.Since(recorder.StartedAt()).Seconds()),
+				RestartReason: recorder.RestartReason(),
+				Checks:        recorder.Checks(),
+			}
+		}
+
+		if err := writeJSON(w, r, response); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		}
 	}
@@ -0,0 +1,110 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/media"
+	"registry/internal/service"
+)
+
+// iconCacheControl lets caches reuse icons briefly; uploads replace icons in place, so the
+// ETag is used to revalidate afterwards
+const iconCacheControl = "public, max-age=300, must-revalidate"
+
+// IconHandler returns a handler serving a server's icon (GET) and accepting a PNG or SVG
+// upload from the server's publisher (PUT)
+func IconHandler(registry service.RegistryService, authService auth.Service, store media.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.GetByID(id)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			if status, msg := authenticatePublisher(r, authService, serverDetail.Name); status != 0 {
+				http.Error(w, msg, status)
+				return
+			}
+			uploadIcon(w, r, store, id)
+			return
+		}
+
+		if !canView(r, authService, serverDetail) {
+			serverNotFound(w, "Server not found")
+			return
+		}
+		serveIcon(w, r, store, id, cacheControlFor(serverDetail, iconCacheControl))
+	}
+}
+
+// uploadIcon validates the request body and stores it as the server's icon
+func uploadIcon(w http.ResponseWriter, r *http.Request, store media.Store, id string) {
+	// Read one byte past the limit so oversized uploads are rejected rather than truncated
+	data, err := io.ReadAll(io.LimitReader(r.Body, media.MaxIconBytes+1))
+	if err != nil {
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	contentType, err := media.ValidateIcon(data, r.Header.Get("Content-Type"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if len(data) > media.MaxIconBytes {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := store.Put(r.Context(), media.IconKey(id), data, contentType); err != nil {
+		log.Printf("Failed to store icon for %s: %v", id, err)
+		http.Error(w, "Failed to store icon", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveIcon writes the stored icon with caching headers
+func serveIcon(w http.ResponseWriter, r *http.Request, store media.Store, id, cacheControl string) {
+	icon, err := store.Get(r.Context(), media.IconKey(id))
+	if err != nil {
+		if errors.Is(err, media.ErrNotFound) {
+			http.Error(w, "Icon not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to load icon for %s: %v", id, err)
+		http.Error(w, "Failed to load icon", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(icon.Data)
+	w.Header().Set("Content-Type", icon.ContentType)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// SVGs opened directly in a browser must not run script or load external resources
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+
+	http.ServeContent(w, r, "", icon.ModTime, bytes.NewReader(icon.Data))
+}
@@ -0,0 +1,19 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/model"
+)
+
+// pathID returns the canonical form of the UUID in the request's {id} path segment. When
+// the segment is not a UUID it writes a 400 response naming the kind of ID and returns false.
+func pathID(w http.ResponseWriter, r *http.Request, kind string) (string, bool) {
+	id, ok := model.CanonicalID(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Invalid "+kind+" ID format", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,100 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/install"
+	"registry/internal/service"
+)
+
+// InstallHandler returns a handler rendering a client configuration snippet for a server
+func InstallHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := install.Client(r.URL.Query().Get("client"))
+		if client == "" {
+			client = install.ClientGeneric
+		}
+
+		snippet, ok := renderSnippet(w, r, registry, authService, client)
+		if !ok {
+			return
+		}
+
+		if err := writeJSON(w, r, snippet); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ClaudeConfigHandler returns a handler emitting the claude_desktop_config.json fragment
+// launching a server, ready to merge into the file's mcpServers. With download=true it is
+// sent as an attachment named after the file.
+func ClaudeConfigHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snippet, ok := renderSnippet(w, r, registry, authService, install.ClientClaudeDesktop)
+		if !ok {
+			return
+		}
+
+		if r.URL.Query().Get("download") == "true" {
+			w.Header().Set("Content-Disposition", `attachment; filename="`+snippet.Filename+`"`)
+		}
+		if err := writeJSON(w, r, snippet.Config); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// renderSnippet renders the configuration snippet of the server in the request path for
+// client, writing an error response and returning false when it cannot be rendered
+func renderSnippet(
+	w http.ResponseWriter,
+	r *http.Request,
+	registry service.RegistryService,
+	authService auth.Service,
+	client install.Client,
+) (*install.Snippet, bool) {
+	id, ok := pathID(w, r, "server")
+	if !ok {
+		return nil, false
+	}
+
+	serverDetail, err := registry.GetByID(id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			serverNotFound(w, "Server not found")
+			return nil, false
+		}
+		http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+		return nil, false
+	}
+	if !canView(r, authService, serverDetail) {
+		serverNotFound(w, "Server not found")
+		return nil, false
+	}
+
+	snippet, err := install.Render(serverDetail, client)
+	if err != nil {
+		switch {
+		case errors.Is(err, install.ErrUnsupportedClient):
+			http.Error(w, "Invalid client parameter", http.StatusBadRequest)
+		case errors.Is(err, install.ErrNotInstallable):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, "Failed to render install snippet", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+
+	// Installing a yanked version by ID still works, but clients are told why it was withdrawn
+	if serverDetail.VersionDetail.Yanked {
+		w.Header().Set("Warning", `299 - "version yanked`+yankReasonSuffix(serverDetail.VersionDetail.YankedReason)+`"`)
+	}
+	return snippet, true
+}
@@ -0,0 +1,27 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/scheduler"
+)
+
+// JobsResponse lists the background jobs and the outcome of their last run
+type JobsResponse struct {
+	Jobs []scheduler.JobStatus `json:"jobs"`
+}
+
+func (j JobsResponse) envelopeParts() (interface{}, interface{}) {
+	return j.Jobs, nil
+}
+
+// JobsHandler returns a handler listing the background jobs by name
+func JobsHandler(jobs *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := writeJSON(w, r, JobsResponse{Jobs: jobs.Jobs()}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"registry/internal/maintenance"
+)
+
+// MaintenanceRequest is the body accepted when enabling maintenance mode
+type MaintenanceRequest struct {
+	Message string `json:"message"`
+	// AllowReads defaults to true
+	AllowReads *bool `json:"allow_reads"`
+}
+
+// MaintenanceHandler returns a handler reporting (GET), enabling (PUT) or disabling
+// (DELETE) maintenance mode
+func MaintenanceHandler(mode *maintenance.Mode) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var status maintenance.Status
+		switch r.Method {
+		case http.MethodGet:
+			status = mode.Status()
+		case http.MethodPut:
+			var req MaintenanceRequest
+			// An empty body enables maintenance with the defaults
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				http.Error(w, "Invalid request payload", http.StatusBadRequest)
+				return
+			}
+			allowReads := req.AllowReads == nil || *req.AllowReads
+			status = mode.Enable(req.Message, allowReads)
+		case http.MethodDelete:
+			status = mode.Disable()
+		}
+
+		if err := writeJSON(w, r, status); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
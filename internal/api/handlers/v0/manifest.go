@@ -0,0 +1,45 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// ManifestHandler returns a handler serving the immutable manifest stored under a digest.
+// The body is returned byte for byte as hashed, so clients can verify it against the digest.
+func ManifestHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		digest := r.PathValue("digest")
+		if !model.IsValidDigest(digest) {
+			http.Error(w, "Invalid digest format", http.StatusBadRequest)
+			return
+		}
+
+		etag := `"` + digest + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		manifest, err := registry.GetManifest(digest)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				http.Error(w, "Manifest not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Error retrieving manifest", storeErrorStatus(err))
+			return
+		}
+
+		// Content addressed data never changes
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(manifest)
+	}
+}
@@ -0,0 +1,58 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ndjsonContentType is the media type for newline delimited JSON
+const ndjsonContentType = "application/x-ndjson"
+
+// wantsNDJSON reports whether the client asked for a newline delimited JSON stream
+func wantsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ndjsonWriter writes one JSON document per line, flushing periodically so
+// clients receive entries while the rest are still being read from the store
+type ndjsonWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+	written int
+}
+
+// newNDJSONWriter sets the response headers and returns a writer for the stream
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	flusher, _ := w.(http.Flusher)
+	return &ndjsonWriter{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		flusher: flusher,
+	}
+}
+
+// ndjsonFlushEvery is the number of entries written between flushes
+const ndjsonFlushEvery = 100
+
+// Write encodes v as a single line
+func (n *ndjsonWriter) Write(v interface{}) error {
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+	n.written++
+	if n.flusher != nil && n.written%ndjsonFlushEvery == 0 {
+		n.flusher.Flush()
+	}
+	return nil
+}
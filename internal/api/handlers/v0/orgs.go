@@ -0,0 +1,80 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// ArchiveOrgRequest is the optional body accepted when archiving an organization
+type ArchiveOrgRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ArchivedOrgsResponse lists the archived organizations
+type ArchivedOrgsResponse struct {
+	Archived []*model.ArchivedOrg `json:"archived"`
+}
+
+func (a ArchivedOrgsResponse) envelopeParts() (interface{}, interface{}) {
+	return a.Archived, nil
+}
+
+// ArchivedOrgsHandler returns a handler listing the archived organizations
+func ArchivedOrgsHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		archived, err := registry.ArchivedOrgs()
+		if err != nil {
+			http.Error(w, "Error retrieving archived organizations", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, ArchivedOrgsResponse{Archived: archived}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// OrgArchiveHandler returns a handler that archives an organization (PUT) or restores it
+// (DELETE). Archived organizations keep their versions resolvable by ID, but they are
+// hidden from listings and cannot publish.
+func OrgArchiveHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org := r.PathValue("org")
+
+		switch r.Method {
+		case http.MethodPut:
+			var req ArchiveOrgRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			archived, err := registry.ArchiveOrg(org, req.Reason)
+			if err != nil {
+				http.Error(w, "Failed to archive organization", storeErrorStatus(err))
+				return
+			}
+			if err := writeJSON(w, r, archived); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+				return
+			}
+		case http.MethodDelete:
+			if err := registry.UnarchiveOrg(org); err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					http.Error(w, "Organization is not archived", http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Failed to unarchive organization", storeErrorStatus(err))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
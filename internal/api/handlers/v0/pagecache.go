@@ -0,0 +1,133 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"registry/internal/api/envelope"
+)
+
+// maxCachedPages bounds the encoded pages kept per listing handler; when it is full the
+// cache is cleared rather than tracking which pages are least used
+const maxCachedPages = 256
+
+// encodedPage is a listing page encoded as JSON. Plain responses keep the whole body, so a
+// hit is written without encoding anything; enveloped ones keep the data and pagination,
+// which are wrapped with the metadata of each request.
+type encodedPage struct {
+	version uint64
+	expires time.Time
+	links   []string
+
+	body       json.RawMessage
+	data       json.RawMessage
+	pagination json.RawMessage
+}
+
+// envelopeParts lets an enveloped page be written through writeJSON
+func (p *encodedPage) envelopeParts() (interface{}, interface{}) {
+	return p.data, p.pagination
+}
+
+// pageCache keeps encoded listing pages, so the busiest pages are neither fetched nor
+// marshaled again for every request. Pages are keyed by path and query and are dropped
+// when the registry service invalidates its listings or after ttl; a zero ttl disables
+// the cache.
+type pageCache struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	pages map[string]*encodedPage
+}
+
+func newPageCache(ttl time.Duration) *pageCache {
+	return &pageCache{ttl: ttl, pages: make(map[string]*encodedPage)}
+}
+
+// pageKey identifies the page r asks for. Query parameters are sorted, so equivalent
+// requests share a page.
+func pageKey(r *http.Request) string {
+	key := r.URL.Path + "?" + r.URL.Query().Encode()
+	if _, enveloped := envelope.MetaFromContext(r.Context()); enveloped {
+		key += "#envelope"
+	}
+	return key
+}
+
+// serve writes the cached page for r if it is still valid for the listings version
+func (c *pageCache) serve(w http.ResponseWriter, r *http.Request, version uint64) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	page, ok := c.pages[pageKey(r)]
+	c.mu.Unlock()
+	if !ok || page.version != version || time.Now().After(page.expires) {
+		return false
+	}
+
+	if err := c.writePage(w, r, page); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+	return true
+}
+
+// write encodes body, a listing page read at the given listings version, writes it with
+// the given Link headers and caches it for later requests
+func (c *pageCache) write(w http.ResponseWriter, r *http.Request, version uint64, links []string, body listResponse) error {
+	page := &encodedPage{version: version, expires: time.Now().Add(c.ttl), links: links}
+	var err error
+	if _, enveloped := envelope.MetaFromContext(r.Context()); enveloped {
+		data, pagination := body.envelopeParts()
+		if page.data, err = json.Marshal(data); err != nil {
+			return err
+		}
+		if page.pagination, err = json.Marshal(pagination); err != nil {
+			return err
+		}
+	} else {
+		if page.body, err = json.Marshal(body); err != nil {
+			return err
+		}
+		// Match the trailing newline of json.Encoder
+		page.body = append(page.body, '\n')
+	}
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		if len(c.pages) >= maxCachedPages {
+			clear(c.pages)
+		}
+		c.pages[pageKey(r)] = page
+		c.mu.Unlock()
+	}
+
+	return c.writePage(w, r, page)
+}
+
+// writePage writes page in the format negotiated for r
+func (c *pageCache) writePage(w http.ResponseWriter, r *http.Request, page *encodedPage) error {
+	for _, link := range page.links {
+		w.Header().Add("Link", link)
+	}
+	if page.body == nil {
+		return writeJSON(w, r, page)
+	}
+	return writeJSON(w, r, page.body)
+}
+
+// pageLinks returns the Link headers (RFC 8288) of a page requested with cursor whose next
+// page starts at nextCursor. Cursors only run forward, so there is no prev link.
+func pageLinks(r *http.Request, cursor, nextCursor string) []string {
+	var links []string
+	if cursor != "" {
+		links = append(links, "<"+pageURL(r, "")+`>; rel="first"`)
+	}
+	if nextCursor != "" {
+		links = append(links, "<"+pageURL(r, nextCursor)+`>; rel="next"`)
+	}
+	return links
+}
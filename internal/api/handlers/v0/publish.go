@@ -10,124 +10,238 @@ import (
 	"registry/internal/database"
 	"registry/internal/model"
 	"registry/internal/service"
+	"registry/internal/usage"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
+// PublishResponse acknowledges a published version
+type PublishResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
 // PublishHandler handles requests to publish new server details to the registry
 func PublishHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Only allow POST method
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		serverDetail, status, msg := decodePublishRequest(r)
+		if status != 0 {
+			http.Error(w, msg, status)
 			return
 		}
-
-		// Read the request body
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Error reading request body", http.StatusBadRequest)
+		if status, msg := authorizePublish(r, registry, authService, serverDetail.Name); status != 0 {
+			http.Error(w, msg, status)
 			return
 		}
-		defer r.Body.Close()
 
-		// Parse request body into PublishRequest struct
-		var publishReq model.PublishRequest
-		err = json.Unmarshal(body, &publishReq)
-		if err != nil {
-			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-
-		// Get server details from the request
-		var serverDetail model.ServerDetail
+		// The registry generates the ID; clients choosing their own use PUT /servers/{id}
+		serverDetail.ID = ""
 
-		err = json.Unmarshal(body, &serverDetail)
+		// Call the publish method on the registry service
+		err := registry.Publish(serverDetail)
 		if err != nil {
-			http.Error(w, "Invalid server detail payload: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-		// Validate required fields
-		if serverDetail.Name == "" {
-			http.Error(w, "Name is required", http.StatusBadRequest)
-			return
-		}
-
-		// Version is required
-		if serverDetail.VersionDetail.Version == "" {
-			http.Error(w, "Version is required", http.StatusBadRequest)
+			// Check for specific error types and return appropriate HTTP status codes
+			if errors.Is(err, database.ErrInvalidVersion) || errors.Is(err, database.ErrAlreadyExists) ||
+				errors.Is(err, database.ErrInvalidInput) {
+				http.Error(w, "Failed to publish server details: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to publish server details: "+err.Error(), storeErrorStatus(err))
 			return
 		}
 
-		// Get auth token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+		w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/publish")+"/servers/"+serverDetail.ID)
+		if err := writeJSONStatus(w, r, http.StatusCreated, PublishResponse{
+			ID:      serverDetail.ID,
+			Message: "Server publication successful",
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
+	}
+}
 
-		// Handle bearer token format (e.g., "Bearer xyz123")
-		token := authHeader
-		if len(authHeader) > 7 && strings.ToUpper(authHeader[:7]) == "BEARER " {
-			token = authHeader[7:]
-		}
+// putServer publishes the request body under the ID in the request path. Repeating the
+// request returns 200 with the stored version instead of creating a duplicate, and an ID
+// already used by another version is a 409 conflict.
+func putServer(w http.ResponseWriter, r *http.Request, registry service.RegistryService, authService auth.Service, id string) {
+	serverDetail, status, msg := decodePublishRequest(r)
+	if status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+	if status, msg := authorizePublish(r, registry, authService, serverDetail.Name); status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
 
-		// Determine authentication method based on server name prefix
-		var authMethod model.AuthMethod
+	stored, created, err := registry.PublishWithID(id, serverDetail)
+	if err != nil {
 		switch {
-		case strings.HasPrefix(serverDetail.Name, "io.github"):
-			authMethod = model.AuthMethodGitHub
-		// Additional cases can be added here for other prefixes
+		case errors.Is(err, database.ErrAlreadyExists):
+			http.Error(w, "Failed to publish server details: "+err.Error(), http.StatusConflict)
+		case errors.Is(err, database.ErrInvalidVersion) || errors.Is(err, database.ErrInvalidInput):
+			http.Error(w, "Failed to publish server details: "+err.Error(), http.StatusBadRequest)
 		default:
-			// Keep the default auth method as AuthMethodNone
-			authMethod = model.AuthMethodNone
+			http.Error(w, "Failed to publish server details: "+err.Error(), storeErrorStatus(err))
 		}
+		return
+	}
 
-		serverName := html.EscapeString(serverDetail.Name)
+	code := http.StatusOK
+	if created {
+		w.Header().Set("Location", r.URL.Path)
+		code = http.StatusCreated
+	}
+	if err := writeJSONStatus(w, r, code, stored); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
 
-		// Setup authentication info
-		a := model.Authentication{
-			Method:  authMethod,
-			Token:   token,
-			RepoRef: serverName,
-		}
+// decodePublishRequest reads the server detail from a publish request body, returning a
+// non-zero status and message when the body is invalid or misses required fields
+func decodePublishRequest(r *http.Request) (*model.ServerDetail, int, string) {
+	// Read the request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Error reading request body"
+	}
+	defer r.Body.Close()
 
-		valid, err := authService.ValidateAuth(r.Context(), a)
-		if err != nil {
-			if errors.Is(err, auth.ErrAuthRequired) {
-				http.Error(w, "Authentication is required for publishing", http.StatusUnauthorized)
-				return
-			}
-			http.Error(w, "Authentication failed: "+err.Error(), http.StatusUnauthorized)
-			return
-		}
+	// Parse request body into PublishRequest struct
+	var publishReq model.PublishRequest
+	if err := json.Unmarshal(body, &publishReq); err != nil {
+		return nil, http.StatusBadRequest, "Invalid request payload: " + err.Error()
+	}
 
-		if !valid {
-			http.Error(w, "Invalid authentication credentials", http.StatusUnauthorized)
-			return
-		}
+	// Get server details from the request
+	var serverDetail model.ServerDetail
+	if err := json.Unmarshal(body, &serverDetail); err != nil {
+		return nil, http.StatusBadRequest, "Invalid server detail payload: " + err.Error()
+	}
 
-		// Call the publish method on the registry service
-		err = registry.Publish(&serverDetail)
-		if err != nil {
-			// Check for specific error types and return appropriate HTTP status codes
-			if errors.Is(err, database.ErrInvalidVersion) || errors.Is(err, database.ErrAlreadyExists) {
-				http.Error(w, "Failed to publish server details: "+err.Error(), http.StatusBadRequest)
-				return
-			}
-			http.Error(w, "Failed to publish server details: "+err.Error(), http.StatusInternalServerError)
-			return
+	// Validate required fields
+	if serverDetail.Name == "" {
+		return nil, http.StatusBadRequest, "Name is required"
+	}
+	// Version is required
+	if serverDetail.VersionDetail.Version == "" {
+		return nil, http.StatusBadRequest, "Version is required"
+	}
+	return &serverDetail, 0, ""
+}
+
+// authorizePublish validates the request's credentials for publishing a new version of
+// serverName. Besides the credentials accepted by authenticatePublisher, it accepts publish
+// tokens scoped to serverName.
+func authorizePublish(r *http.Request, registry service.RegistryService, authService auth.Service, serverName string) (int, string) {
+	if reserved, status, msg := authorizeReserved(r, authService, serverName); reserved {
+		return status, msg
+	}
+
+	token := bearerToken(r)
+	if !strings.HasPrefix(token, model.PublishTokenPrefix) {
+		return authenticatePublisher(r, authService, serverName)
+	}
+
+	publishToken, err := registry.PublishToken(token)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) || errors.Is(err, auth.ErrTokenRevoked) {
+			return http.StatusUnauthorized, "Invalid authentication credentials"
 		}
+		return storeErrorStatus(err), "Failed to check publish token"
+	}
+	if publishToken.ServerName != serverName {
+		return http.StatusForbidden, "Publish token is not valid for " + serverName
+	}
+	usage.Authenticated(r.Context())
+	return 0, ""
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		if err := json.NewEncoder(w).Encode(map[string]string{
-			"message": "Server publication successful",
-			"id":      serverDetail.ID,
-		}); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
+// authenticatePublisher validates the request's credentials for publishing under serverName.
+// It returns a zero status on success, or the HTTP status and message to reply with.
+func authenticatePublisher(r *http.Request, authService auth.Service, serverName string) (int, string) {
+	if reserved, status, msg := authorizeReserved(r, authService, serverName); reserved {
+		return status, msg
+	}
+
+	// Get auth token from Authorization header
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return http.StatusUnauthorized, "Authorization header is required"
+	}
+
+	// Handle bearer token format (e.g., "Bearer xyz123")
+	token := authHeader
+	if len(authHeader) > 7 && strings.ToUpper(authHeader[:7]) == "BEARER " {
+		token = authHeader[7:]
+	}
+
+	// Publish tokens only authorize new versions, through authorizePublish
+	if strings.HasPrefix(token, model.PublishTokenPrefix) {
+		return http.StatusForbidden, "Publish tokens can only publish new versions of their server"
+	}
+
+	// Determine authentication method based on server name prefix
+	var authMethod model.AuthMethod
+	switch {
+	case strings.HasPrefix(serverName, "io.github"):
+		authMethod = model.AuthMethodGitHub
+	// Additional cases can be added here for other prefixes
+	default:
+		// Keep the default auth method as AuthMethodNone
+		authMethod = model.AuthMethodNone
+	}
+
+	// Setup authentication info
+	a := model.Authentication{
+		Method:  authMethod,
+		Token:   token,
+		RepoRef: html.EscapeString(serverName),
+	}
+
+	valid, err := authService.ValidateAuth(r.Context(), a)
+	if err != nil {
+		if errors.Is(err, auth.ErrAuthRequired) {
+			return http.StatusUnauthorized, "Authentication is required for publishing"
 		}
+		return http.StatusUnauthorized, "Authentication failed: " + err.Error()
+	}
+
+	if !valid {
+		return http.StatusUnauthorized, "Invalid authentication credentials"
+	}
+	usage.Authenticated(r.Context())
+	return 0, ""
+}
+
+// authorizeReserved applies the reserved names policy. It reports whether serverName is
+// reserved, with a zero status when the request carries the admin token or the HTTP status
+// and message to reply with otherwise.
+func authorizeReserved(r *http.Request, authService auth.Service, serverName string) (bool, int, string) {
+	reserved, err := authService.CheckReservedName(bearerToken(r), serverName)
+	if errors.Is(err, auth.ErrNameShadowsRoute) {
+		return true, http.StatusForbidden, err.Error()
+	}
+	if err != nil {
+		return true, http.StatusForbidden, "Only administrators can publish reserved names: " + err.Error()
+	}
+	return reserved, 0, ""
+}
+
+// requireSecondFactor applies the second factor policy to a destructive operation. It
+// replies with 401 and returns false when the request's token does not prove a recent
+// second factor, asking the client to step up as in RFC 9470.
+func requireSecondFactor(w http.ResponseWriter, r *http.Request, authService auth.Service) bool {
+	err := authService.RequireSecondFactor(r.Context(), bearerToken(r))
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, auth.ErrSecondFactorRequired) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_user_authentication", `+
+			`error_description="A recent second factor is required for this operation"`)
 	}
+	http.Error(w, "Second factor check failed: "+err.Error(), http.StatusUnauthorized)
+	return false
 }
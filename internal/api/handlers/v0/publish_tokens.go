@@ -0,0 +1,99 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// CreatePublishTokenRequest is the optional body of a request minting a publish token
+type CreatePublishTokenRequest struct {
+	Description string `json:"description"`
+}
+
+// PublishTokenList lists the publish tokens of a server; secrets are never included
+type PublishTokenList struct {
+	Tokens []*model.PublishToken `json:"tokens"`
+}
+
+// PublishTokensHandler returns a handler that lets a server's publisher mint (POST), list
+// (GET) and delete (DELETE /{token_id}) tokens that can only publish new versions of that
+// server, for use in its CI. The secret is only returned when the token is minted.
+func PublishTokensHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.GetByID(id)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+
+		if status, msg := authenticatePublisher(r, authService, serverDetail.Name); status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+
+		switch r.Method {
+		case http.MethodPost:
+			var req CreatePublishTokenRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			login, _ := authService.Identify(r.Context(), bearerToken(r))
+			token, err := registry.CreatePublishToken(serverDetail.Name, login, req.Description)
+			if err != nil {
+				if errors.Is(err, database.ErrInvalidInput) {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				http.Error(w, "Failed to create publish token", storeErrorStatus(err))
+				return
+			}
+			if err := writeJSONStatus(w, r, http.StatusCreated, token); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+
+		case http.MethodDelete:
+			if !requireSecondFactor(w, r, authService) {
+				return
+			}
+			err := registry.DeletePublishToken(serverDetail.Name, r.PathValue("token_id"))
+			if err != nil {
+				if errors.Is(err, database.ErrNotFound) {
+					http.Error(w, "Publish token not found", http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Failed to delete publish token", storeErrorStatus(err))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			tokens, err := registry.PublishTokens(serverDetail.Name)
+			if err != nil {
+				http.Error(w, "Failed to list publish tokens", storeErrorStatus(err))
+				return
+			}
+			if err := writeJSON(w, r, PublishTokenList{Tokens: tokens}); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+		}
+	}
+}
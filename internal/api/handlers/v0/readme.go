@@ -0,0 +1,71 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// readmeMaxAge is how long clients and shared caches may reuse a README. A version's
+// README never changes once published, but the ETag lets caches revalidate cheaply.
+const readmeMaxAge = "public, max-age=3600"
+
+// ReadmeHandler returns a handler serving the markdown README published with a server version
+func ReadmeHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.GetByID(id)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+		if !canView(r, authService, serverDetail) {
+			serverNotFound(w, "Server not found")
+			return
+		}
+
+		serverDetail = contentFor(r, serverDetail)
+
+		if serverDetail.Readme == "" {
+			http.Error(w, "README not found", http.StatusNotFound)
+			return
+		}
+
+		sum := sha256.Sum256([]byte(serverDetail.Readme))
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Cache-Control", cacheControlFor(serverDetail, readmeMaxAge))
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		// ServeContent handles If-None-Match, If-Modified-Since, HEAD and range requests
+		modified, _ := time.Parse(time.RFC3339, serverDetail.VersionDetail.ReleaseDate)
+		http.ServeContent(w, r, "README.md", modified, strings.NewReader(serverDetail.Readme))
+	}
+}
+
+// contentFor returns the version with its text fields as published when the request asks
+// for raw=true. By default the sanitized fields are served, so frontends rendering them
+// are safe from stored XSS.
+func contentFor(r *http.Request, serverDetail *model.ServerDetail) *model.ServerDetail {
+	if r.URL.Query().Get("raw") == "true" {
+		return serverDetail.Unsanitized()
+	}
+	return serverDetail
+}
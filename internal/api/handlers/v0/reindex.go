@@ -0,0 +1,43 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"errors"
+	"net/http"
+
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// ReindexHandler returns a handler that starts a background rebuild of the search indexes
+// (POST) or reports the progress of the latest rebuild (GET)
+func ReindexHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var status service.ReindexStatus
+		switch r.Method {
+		case http.MethodGet:
+			status = registry.ReindexStatus()
+		case http.MethodPost:
+			var err error
+			status, err = registry.StartReindex()
+			if errors.Is(err, database.ErrConflict) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Failed to start reindex: "+err.Error(), storeErrorStatus(err))
+				return
+			}
+			w.Header().Set("Location", r.URL.Path)
+		}
+
+		code := http.StatusOK
+		if r.Method == http.MethodPost {
+			code = http.StatusAccepted
+		}
+		if err := writeJSONStatus(w, r, code, status); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
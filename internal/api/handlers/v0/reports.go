@@ -0,0 +1,164 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+const (
+	defaultReportsLimit = 100
+	maxReportsLimit     = 1000
+)
+
+// ReportRequest is the body of an abuse report
+type ReportRequest struct {
+	Reason  model.ReportReason `json:"reason"`
+	Details string             `json:"details"`
+	Contact string             `json:"contact"`
+}
+
+// ReportResponse acknowledges an abuse report
+type ReportResponse struct {
+	ID     string             `json:"id"`
+	Status model.ReportStatus `json:"status"`
+}
+
+// UpdateReportRequest is the body of a moderation decision
+type UpdateReportRequest struct {
+	Status model.ReportStatus `json:"status"`
+	Note   string             `json:"note"`
+}
+
+// ReportsResponse lists abuse reports in the moderation queue
+type ReportsResponse struct {
+	Reports []*model.AbuseReport `json:"reports"`
+}
+
+func (rr ReportsResponse) envelopeParts() (interface{}, interface{}) {
+	return rr.Reports, nil
+}
+
+// ReportHandler returns a handler through which anyone can flag a server version as
+// malicious, spam or otherwise abusive. Reports enter the moderation queue as open.
+func ReportHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.GetByID(id)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+		if !canView(r, authService, serverDetail) {
+			serverNotFound(w, "Server not found")
+			return
+		}
+
+		var req ReportRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<10)).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		report := &model.AbuseReport{ServerID: id, Reason: req.Reason, Details: req.Details, Contact: req.Contact}
+		if err := registry.ReportServer(report); err != nil {
+			switch {
+			case errors.Is(err, database.ErrInvalidInput):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, database.ErrNotFound):
+				serverNotFound(w, "Server not found")
+			default:
+				http.Error(w, "Failed to file report", storeErrorStatus(err))
+			}
+			return
+		}
+
+		if err := writeJSONStatus(w, r, http.StatusCreated, ReportResponse{ID: report.ID, Status: report.Status}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ReportsHandler returns a handler listing the moderation queue, oldest reports first.
+// The status parameter selects reports in one status.
+func ReportsHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultReportsLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			parsedLimit, err := strconv.Atoi(limitStr)
+			if err != nil || parsedLimit <= 0 {
+				http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+				return
+			}
+			limit = min(parsedLimit, maxReportsLimit)
+		}
+
+		reports, err := registry.Reports(model.ReportStatus(r.URL.Query().Get("status")), limit)
+		if err != nil {
+			if errors.Is(err, database.ErrInvalidInput) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Error retrieving reports", storeErrorStatus(err))
+			return
+		}
+
+		if err := writeJSON(w, r, ReportsResponse{Reports: reports}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// ReportStatusHandler returns a handler returning one abuse report (GET) or recording a
+// moderation decision on it (PUT)
+func ReportStatusHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			report *model.AbuseReport
+			err    error
+		)
+		if r.Method == http.MethodPut {
+			var req UpdateReportRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			report, err = registry.UpdateReport(r.PathValue("id"), req.Status, req.Note)
+		} else {
+			report, err = registry.Report(r.PathValue("id"))
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				http.Error(w, "Report not found", http.StatusNotFound)
+			case errors.Is(err, database.ErrInvalidInput):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, "Error updating report", storeErrorStatus(err))
+			}
+			return
+		}
+
+		if err := writeJSON(w, r, report); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/semver"
+	"registry/internal/service"
+)
+
+// LockfileVersion is the format version of the lockfiles returned by POST /v0/resolve
+const LockfileVersion = 1
+
+// maxLockRequirements bounds the requirements of one lockfile
+const maxLockRequirements = 100
+
+// ResolveResponse is the best version satisfying a constraint
+type ResolveResponse struct {
+	Constraint  string `json:"constraint"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	ID          string `json:"id"`
+	Digest      string `json:"digest,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	// Candidates counts the versions satisfying the constraint
+	Candidates int `json:"candidates"`
+	// Yanked is only set when the constraint pins a yanked version exactly
+	Yanked       bool   `json:"yanked,omitempty"`
+	YankedReason string `json:"yanked_reason,omitempty"`
+
+	// private is set when the result depends on private versions the caller can see
+	private bool
+}
+
+// ResolveHandler returns a handler resolving the semver range in the constraint query
+// parameter to the highest published, non-yanked version of a server. A constraint naming
+// one exact version also resolves it when it is yanked. {id} is the ID of any version of
+// the server.
+func ResolveHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		resolved, status, err := resolve(r, registry, authService, id, r.URL.Query().Get("constraint"))
+		if errors.Is(err, errServerNotFound) {
+			serverNotFound(w, err.Error())
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		// New versions change the result, so it is only cached briefly
+		cacheControl := "public, max-age=60"
+		if resolved.private {
+			cacheControl = privateCacheControl
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+		if err := writeJSON(w, r, resolved); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// LockRequirement asks for the best version of a server satisfying a constraint
+type LockRequirement struct {
+	// ID is the ID of any version of the server
+	ID         string `json:"id"`
+	Constraint string `json:"constraint"`
+}
+
+// LockRequest lists the servers to pin
+type LockRequest struct {
+	Servers []LockRequirement `json:"servers"`
+}
+
+// LockEntry pins one requirement to an exact version and manifest digest
+type LockEntry struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+	Version    string `json:"version"`
+	ID         string `json:"id"`
+	Digest     string `json:"digest"`
+}
+
+// LockError reports a requirement that could not be pinned
+type LockError struct {
+	ID         string `json:"id"`
+	Constraint string `json:"constraint"`
+	Status     int    `json:"status"`
+	Error      string `json:"error"`
+}
+
+// Lockfile pins a set of servers so clients can reproduce and verify an MCP setup
+type Lockfile struct {
+	LockfileVersion int         `json:"lockfile_version"`
+	GeneratedAt     string      `json:"generated_at"`
+	Servers         []LockEntry `json:"servers"`
+	Errors          []LockError `json:"errors,omitempty"`
+}
+
+// LockHandler returns a handler pinning every requirement in the body to the best version
+// satisfying its constraint. The lockfile is only returned with 200 when every
+// requirement resolved; otherwise it is returned with 422 and the failures in errors.
+func LockHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req LockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(req.Servers) == 0 {
+			http.Error(w, "servers is required", http.StatusBadRequest)
+			return
+		}
+		if len(req.Servers) > maxLockRequirements {
+			http.Error(w, fmt.Sprintf("At most %d servers can be locked at once", maxLockRequirements), http.StatusBadRequest)
+			return
+		}
+
+		lockfile := Lockfile{
+			LockfileVersion: LockfileVersion,
+			GeneratedAt:     time.Now().UTC().Format(time.RFC3339),
+			Servers:         make([]LockEntry, 0, len(req.Servers)),
+		}
+		for _, requirement := range req.Servers {
+			failed := func(status int, msg string) {
+				lockfile.Errors = append(lockfile.Errors, LockError{
+					ID: requirement.ID, Constraint: requirement.Constraint, Status: status, Error: msg,
+				})
+			}
+			id, ok := model.CanonicalID(requirement.ID)
+			if !ok {
+				failed(http.StatusBadRequest, "Invalid server ID format")
+				continue
+			}
+			resolved, status, err := resolve(r, registry, authService, id, requirement.Constraint)
+			if err != nil {
+				failed(status, err.Error())
+				continue
+			}
+			lockfile.Servers = append(lockfile.Servers, LockEntry{
+				Name:       resolved.Name,
+				Constraint: resolved.Constraint,
+				Version:    resolved.Version,
+				ID:         resolved.ID,
+				Digest:     resolved.Digest,
+			})
+		}
+
+		status := http.StatusOK
+		if len(lockfile.Errors) > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeJSONStatus(w, r, status, lockfile); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// errServerNotFound is returned by resolve when no version has the requested ID
+var errServerNotFound = errors.New("Server not found")
+
+// resolve finds the best version of the server with version ID id that satisfies the raw
+// constraint and is visible to the caller. An empty constraint matches every release. On
+// failure it returns the HTTP status and an error whose message is fit for the client.
+func resolve(r *http.Request, registry service.RegistryService, authService auth.Service, id, raw string) (*ResolveResponse, int, error) {
+	if raw == "" {
+		raw = "*"
+	}
+	constraint, err := semver.ParseConstraint(raw)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("Invalid constraint parameter: " + err.Error())
+	}
+
+	candidates, err := registry.ResolveVersions(id, constraint)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, http.StatusNotFound, errServerNotFound
+		}
+		return nil, storeErrorStatus(err), errors.New("Error resolving version")
+	}
+
+	// Private versions only resolve for callers allowed to see them
+	visible := candidates[:0]
+	private := false
+	for _, candidate := range candidates {
+		if canView(r, authService, candidate) {
+			visible = append(visible, candidate)
+			private = private || candidate.Visibility.Effective() == model.VisibilityPrivate
+		}
+	}
+	if len(visible) == 0 {
+		return nil, http.StatusNotFound, errors.New("No published version satisfies " + constraint.String())
+	}
+
+	best := visible[0]
+	return &ResolveResponse{
+		Constraint:   constraint.String(),
+		Name:         best.Name,
+		Version:      best.VersionDetail.Version,
+		ID:           best.ID,
+		Digest:       best.Digest,
+		ReleaseDate:  best.VersionDetail.ReleaseDate,
+		Candidates:   len(visible),
+		Yanked:       best.VersionDetail.Yanked,
+		YankedReason: best.VersionDetail.YankedReason,
+		private:      private,
+	}, http.StatusOK, nil
+}
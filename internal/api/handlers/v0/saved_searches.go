@@ -0,0 +1,248 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"registry/internal/auth"
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/notify"
+	"registry/internal/query"
+	"registry/internal/service"
+	"registry/internal/usage"
+)
+
+// SavedSearchRequest is the body of a request creating a saved search
+type SavedSearchRequest struct {
+	Query      string `json:"query"`
+	Match      string `json:"match,omitempty"`
+	WebhookURL string `json:"webhook_url,omitempty"`
+	Email      string `json:"email,omitempty"`
+}
+
+// SavedSearchesHandler returns a handler that lists the caller's saved searches (GET) or
+// saves a new one (POST). Callers are identified by their GitHub token.
+func SavedSearchesHandler(registry service.RegistryService, authService auth.Service, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, status, msg := identifyUser(r, authService)
+		if status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			searches, err := registry.SavedSearches(owner)
+			if err != nil {
+				http.Error(w, "Error retrieving saved searches", storeErrorStatus(err))
+				return
+			}
+			if err := writeJSON(w, r, searches); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		var req SavedSearchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msg := validateSavedSearch(r.Context(), &req, cfg); msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+
+		search := &model.SavedSearch{
+			Owner:      owner,
+			Query:      strings.TrimSpace(req.Query),
+			Match:      req.Match,
+			WebhookURL: req.WebhookURL,
+			Email:      req.Email,
+		}
+		if err := registry.SaveSearch(search); err != nil {
+			if errors.Is(err, database.ErrInvalidInput) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to save search", storeErrorStatus(err))
+			return
+		}
+
+		if search.EmailToken != "" {
+			if err := notify.SendConfirmation(cfg, search); err != nil {
+				log.Printf("Confirmation email for saved search %s failed: %v", search.ID, err)
+			}
+			search.EmailToken = ""
+		}
+
+		// The secret is returned once so the subscriber can verify webhook signatures
+		w.Header().Set("Location", r.URL.Path+"/"+search.ID)
+		if err := writeJSONStatus(w, r, http.StatusCreated, search); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
+
+// SavedSearchHandler returns a handler that deletes one of the caller's saved searches
+func SavedSearchHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "saved search")
+		if !ok {
+			return
+		}
+
+		owner, status, msg := identifyUser(r, authService)
+		if status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+
+		if err := registry.DeleteSavedSearch(owner, id); err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				http.Error(w, "Saved search not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to delete saved search", storeErrorStatus(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ConfirmSavedSearchRequest is the body of a request confirming the email address of a
+// saved search
+type ConfirmSavedSearchRequest struct {
+	Token string `json:"token"`
+}
+
+// ConfirmSavedSearchHandler returns a handler confirming the email address of one of the
+// caller's saved searches with the token mailed to it
+func ConfirmSavedSearchHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "saved search")
+		if !ok {
+			return
+		}
+
+		owner, status, msg := identifyUser(r, authService)
+		if status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+
+		var req ConfirmSavedSearchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+			http.Error(w, "Invalid request payload: token is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := registry.ConfirmSavedSearchEmail(owner, id, req.Token); err != nil {
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				http.Error(w, "Saved search not found", http.StatusNotFound)
+			case errors.Is(err, database.ErrInvalidInput):
+				http.Error(w, "Invalid confirmation token", http.StatusBadRequest)
+			default:
+				http.Error(w, "Failed to confirm saved search", storeErrorStatus(err))
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// checkWebhookHost resolves the host of a webhook URL, returning a message when it cannot be
+// resolved or resolves to an address that is not public. Deliveries check the address they
+// connect to again, so a name re-pointed after this check is still refused.
+func checkWebhookHost(ctx context.Context, host string, cfg *config.Config) string {
+	if cfg.IsDevelopment() {
+		return ""
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return "webhook_url host could not be resolved"
+	}
+	for _, addr := range addrs {
+		if !notify.IsPublicAddress(addr) {
+			return "webhook_url must resolve to a public address"
+		}
+	}
+	return ""
+}
+
+// identifyUser resolves the bearer token of a request to a GitHub login, returning a
+// non-zero status and message when the caller cannot be identified
+func identifyUser(r *http.Request, authService auth.Service) (string, int, string) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", http.StatusUnauthorized, "Authorization header is required"
+	}
+
+	token := authHeader
+	if len(authHeader) > 7 && strings.ToUpper(authHeader[:7]) == "BEARER " {
+		token = authHeader[7:]
+	}
+
+	login, err := authService.Identify(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, auth.ErrAuthRequired) {
+			return "", http.StatusUnauthorized, "Authentication is required"
+		}
+		return "", http.StatusUnauthorized, "Authentication failed: " + err.Error()
+	}
+	usage.Authenticated(r.Context())
+	return login, 0, ""
+}
+
+// validateSavedSearch checks the query and delivery targets of a saved search request,
+// returning a message describing the first problem found
+func validateSavedSearch(ctx context.Context, req *SavedSearchRequest, cfg *config.Config) string {
+	if strings.TrimSpace(req.Query) == "" {
+		return "query is required"
+	}
+
+	match := database.MatchMode(req.Match)
+	if match == "" {
+		match = database.MatchSubstring
+	}
+	if !match.IsValid() {
+		return "Invalid match: expected exact, prefix or substring"
+	}
+	if _, err := query.Parse(req.Query, query.Options{Match: match, FoldAccents: cfg.SearchFoldAccents}); err != nil {
+		return err.Error()
+	}
+
+	if req.WebhookURL == "" && req.Email == "" {
+		return "webhook_url or email is required"
+	}
+	if req.WebhookURL != "" {
+		u, err := url.Parse(req.WebhookURL)
+		if err != nil || u.Host == "" || (u.Scheme != "https" && !(u.Scheme == "http" && cfg.IsDevelopment())) {
+			return "webhook_url must be an absolute https URL"
+		}
+		if msg := checkWebhookHost(ctx, u.Hostname(), cfg); msg != "" {
+			return msg
+		}
+	}
+	if req.Email != "" {
+		if cfg.SMTPAddr == "" {
+			return "Email notifications are not configured on this registry"
+		}
+		if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+			return "Invalid email address"
+		}
+	}
+	return ""
+}
@@ -3,13 +3,19 @@ package v0
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"registry/internal/auth"
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/enrichment"
 	"registry/internal/model"
+	"registry/internal/query"
 	"registry/internal/service"
-
-	"github.com/google/uuid"
 )
 
 // Response is a paginated API response
@@ -21,113 +27,365 @@ type PaginatedResponse struct {
 // Metadata contains pagination metadata
 type Metadata struct {
 	NextCursor string `json:"next_cursor,omitempty"`
-	Count      int    `json:"count,omitempty"`
-	Total      int    `json:"total,omitempty"`
+	// Count is the number of entries on this page
+	Count int `json:"count,omitempty"`
+	// Total is the number of entries across all pages
+	Total int `json:"total,omitempty"`
+	// Limit is the page size the listing was served with
+	Limit int `json:"limit,omitempty"`
+}
+
+// sparsePaginatedResponse is a PaginatedResponse whose entries are trimmed to the fields requested via ?fields=
+type sparsePaginatedResponse struct {
+	Data     []map[string]json.RawMessage `json:"servers"`
+	Metadata Metadata                     `json:"metadata,omitempty"`
+}
+
+func (p PaginatedResponse) envelopeParts() (interface{}, interface{}) {
+	return p.Data, p.Metadata
+}
+
+func (p sparsePaginatedResponse) envelopeParts() (interface{}, interface{}) {
+	return p.Data, p.Metadata
+}
+
+// CountResponse is the number of versions a server listing would return across all pages
+type CountResponse struct {
+	Count int `json:"count"`
 }
 
 // ServersHandler returns a handler for listing registry items
-func ServersHandler(registry service.RegistryService) http.HandlerFunc {
+func ServersHandler(registry service.RegistryService, cfg *config.Config) http.HandlerFunc {
+	pages := newPageCache(cfg.ListCacheTTL)
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		filter, ok := listingFilter(w, r, cfg)
+		if !ok {
 			return
 		}
 
-		// Parse cursor and limit from query parameters
-		cursor := r.URL.Query().Get("cursor")
-		if cursor != "" {
-			_, err := uuid.Parse(cursor)
-			if err != nil {
-				http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
-				return
-			}
+		// NDJSON clients receive the full listing as a stream instead of a page
+		if wantsNDJSON(r) {
+			streamServers(w, r, registry, filter)
+			return
 		}
-		limitStr := r.URL.Query().Get("limit")
 
-		// Default limit if not specified
-		limit := 30
+		writeServerPage(w, r, registry, filter, pages)
+	}
+}
 
-		// Try to parse limit from query param
-		if limitStr != "" {
-			parsedLimit, err := strconv.Atoi(limitStr)
-			if err != nil {
-				http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
-				return
-			}
+// ServersCountHandler returns a handler counting the servers a listing with the same
+// filters would return, without fetching them
+func ServersCountHandler(registry service.RegistryService, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, ok := listingFilter(w, r, cfg)
+		if !ok {
+			return
+		}
+		if r.URL.Query().Get("include_yanked") != "true" {
+			filter["yanked"] = false
+		}
 
-			// Check if limit is within reasonable bounds
-			if parsedLimit <= 0 {
-				http.Error(w, "Limit must be greater than 0", http.StatusBadRequest)
-				return
-			}
+		count, err := registry.Count(filter)
+		if err != nil {
+			http.Error(w, "Error counting servers", storeErrorStatus(err))
+			return
+		}
 
-			if parsedLimit > 100 {
-				// Cap maximum limit to prevent excessive queries
-				limit = 100
-			} else {
-				limit = parsedLimit
-			}
+		if err := writeJSON(w, r, CountResponse{Count: count}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		}
+	}
+}
 
-		// Use the GetAll method to get paginated results
-		registries, nextCursor, err := registry.List(cursor, limit)
+// listingFilter builds the filter of a server listing from the q, match, transport, os and
+// arch query parameters, writing a 400 response and returning false when one is invalid
+func listingFilter(w http.ResponseWriter, r *http.Request, cfg *config.Config) (map[string]interface{}, bool) {
+	// Build the filter from the search query and transport, if any
+	filter := map[string]interface{}{}
+	var queryFilter map[string]interface{}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		match := database.MatchMode(r.URL.Query().Get("match"))
+		if match == "" {
+			match = database.MatchSubstring
+		}
+		if !match.IsValid() {
+			http.Error(w, "Invalid match parameter: expected exact, prefix or substring", http.StatusBadRequest)
+			return nil, false
+		}
+		var err error
+		queryFilter, err = query.Parse(q, query.Options{Match: match, FoldAccents: cfg.SearchFoldAccents})
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, "Invalid q parameter: "+err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	if transport := r.URL.Query().Get("transport"); transport != "" {
+		switch model.TransportType(transport) {
+		case model.TransportStdio, model.TransportSSE, model.TransportStreamableHTTP:
+			filter["transport"] = transport
+		default:
+			http.Error(w, "Invalid transport parameter", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	platform := model.Platform{OS: r.URL.Query().Get("os"), Arch: r.URL.Query().Get("arch")}
+	if platform.OS != "" && !model.IsKnownOS(platform.OS) {
+		http.Error(w, "Invalid os parameter", http.StatusBadRequest)
+		return nil, false
+	}
+	if platform.Arch != "" && !model.IsKnownArch(platform.Arch) {
+		http.Error(w, "Invalid arch parameter", http.StatusBadRequest)
+		return nil, false
+	}
+	if platform != (model.Platform{}) {
+		filter["platform"] = platform
+	}
+	query.Merge(filter, queryFilter)
+	return filter, true
+}
+
+// writeServerPage lists one page of servers matching filter, applying the cursor, limit,
+// sort and fields query parameters shared by every server listing endpoint. Pages are
+// served from and added to pages.
+func writeServerPage(
+	w http.ResponseWriter, r *http.Request, registry service.RegistryService, filter map[string]interface{}, pages *pageCache,
+) {
+	// Read the version before listing, so a page read during a write is never cached as current
+	version := registry.ListingsVersion()
+	if pages.serve(w, r, version) {
+		return
+	}
+
+	// Parse cursor and limit from query parameters
+	cursor := r.URL.Query().Get("cursor")
+	limitStr := r.URL.Query().Get("limit")
+
+	// Default limit if not specified
+	limit := 30
+
+	// Try to parse limit from query param
+	if limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+
+		// Check if limit is within reasonable bounds
+		if parsedLimit <= 0 {
+			http.Error(w, "Limit must be greater than 0", http.StatusBadRequest)
 			return
 		}
 
-		// Create paginated response
-		response := PaginatedResponse{
-			Data: registries,
+		if parsedLimit > 100 {
+			// Cap maximum limit to prevent excessive queries
+			limit = 100
+		} else {
+			limit = parsedLimit
 		}
+	}
 
-		// Add metadata if there's a next cursor
-		if nextCursor != "" {
-			response.Metadata = Metadata{
-				NextCursor: nextCursor,
-				Count:      len(registries),
+	// Yanked versions are only listed on request
+	if r.URL.Query().Get("include_yanked") != "true" {
+		filter["yanked"] = false
+	}
+
+	// Parse the requested ordering, defaulting to ID order
+	order := database.SortByID
+	switch sortParam := database.SortOrder(r.URL.Query().Get("sort")); sortParam {
+	case "", database.SortByID:
+	case database.SortByName, database.SortByCreatedAt:
+		order = sortParam
+	default:
+		http.Error(w, "Invalid sort parameter", http.StatusBadRequest)
+		return
+	}
+
+	fields := parseFields(r)
+	if err := validateFields(fields); err != nil {
+		http.Error(w, "Invalid fields parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Use the GetAll method to get paginated results
+	registries, nextCursor, err := registry.List(filter, cursor, limit, order)
+	if errors.Is(err, service.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor parameter", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorStatus(err))
+		return
+	}
+	total, err := registry.Count(filter)
+	if err != nil {
+		http.Error(w, err.Error(), storeErrorStatus(err))
+		return
+	}
+
+	// Create paginated response
+	response := PaginatedResponse{
+		Data: registries,
+		Metadata: Metadata{
+			NextCursor: nextCursor,
+			Count:      len(registries),
+			Total:      total,
+			Limit:      limit,
+		},
+	}
+
+	var body listResponse = response
+	if fields != nil {
+		sparse := sparsePaginatedResponse{
+			Data:     make([]map[string]json.RawMessage, 0, len(registries)),
+			Metadata: response.Metadata,
+		}
+		for _, server := range registries {
+			selected, err := selectFields(server, fields)
+			if err != nil {
+				http.Error(w, "Invalid fields parameter: "+err.Error(), http.StatusBadRequest)
+				return
 			}
+			sparse.Data = append(sparse.Data, selected)
 		}
+		body = sparse
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
+	// Generic clients follow the Link header instead of reading next_cursor
+	if err := pages.write(w, r, version, pageLinks(r, cursor, nextCursor), body); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// pageURL returns the path and query of the request with its cursor replaced by cursor,
+// or removed when cursor is empty
+func pageURL(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	if cursor == "" {
+		query.Del("cursor")
+	} else {
+		query.Set("cursor", cursor)
+	}
+	if len(query) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// streamServers writes the latest version of every server matching filter as newline delimited JSON
+func streamServers(w http.ResponseWriter, r *http.Request, registry service.RegistryService, filter map[string]interface{}) {
+	// Validate before the first write, while an error status can still be sent
+	fields := parseFields(r)
+	if err := validateFields(fields); err != nil {
+		http.Error(w, "Invalid fields parameter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	stream := newNDJSONWriter(w)
+	err := registry.StreamLatest(filter, func(server model.Server) error {
+		if fields == nil {
+			return stream.Write(server)
 		}
+		selected, err := selectFields(server, fields)
+		if err != nil {
+			return err
+		}
+		return stream.Write(selected)
+	})
+	if err != nil {
+		// Headers are already sent, so the stream is simply cut short
+		log.Printf("Server listing stream aborted: %v", err)
+	}
+}
+
+// serverDetailResponse is a server detail together with the repository metadata collected by the enricher
+type serverDetailResponse struct {
+	*model.ServerDetail
+	// Slug is the path segment GET /v0/servers/{slug} resolves to the latest version
+	Slug               string               `json:"slug"`
+	RepositoryMetadata *enrichment.Metadata `json:"repository_metadata,omitempty"`
+}
+
+// redirectToSlug permanently redirects a request for the server at slug to target, keeping
+// the API version prefix and query string
+func redirectToSlug(w http.ResponseWriter, r *http.Request, slug, target string) {
+	location := strings.TrimSuffix(r.URL.Path, slug) + target
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
 	}
+	http.Redirect(w, r, location, http.StatusPermanentRedirect)
 }
 
-// ServersDetailHandler returns a handler for getting details of a specific server by ID
-func ServersDetailHandler(registry service.RegistryService) http.HandlerFunc {
+// ServersDetailHandler returns a handler for getting details of a specific server by ID (GET)
+// or publishing a version under a client-chosen ID (PUT). Repository metadata is included
+// when enricher is non-nil and has data for the repository.
+func ServersDetailHandler(registry service.RegistryService, authService auth.Service, enricher *enrichment.Enricher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		if r.Method == http.MethodPut {
+			// Extract the server ID from the URL path in its canonical form
+			id, ok := pathID(w, r, "server")
+			if !ok {
+				return
+			}
+			putServer(w, r, registry, authService, id)
 			return
 		}
 
-		// Extract the server ID from the URL path
-		id := r.PathValue("id")
-
-		// Validate that the ID is a valid UUID
-		_, err := uuid.Parse(id)
-		if err != nil {
+		// Get the server details by version ID, or the latest visible version by slug
+		var serverDetail *model.ServerDetail
+		var err error
+		segment := r.PathValue("id")
+		if id, ok := model.CanonicalID(segment); ok {
+			serverDetail, err = registry.GetByID(id)
+		} else if model.IsSlug(segment) {
+			// Former names of renamed servers redirect to the current name
+			alias, aliasErr := registry.ResolveAlias(segment)
+			switch {
+			case aliasErr == nil:
+				redirectToSlug(w, r, segment, model.Slug(alias.Target))
+				return
+			case !errors.Is(aliasErr, database.ErrNotFound):
+				http.Error(w, "Error retrieving server details", storeErrorStatus(aliasErr))
+				return
+			}
+			// Yanked versions and versions the caller may not see never stand for the server
+			serverDetail, err = registry.GetBySlug(segment, func(version *model.ServerDetail) bool {
+				return canView(r, authService, version)
+			})
+		} else {
 			http.Error(w, "Invalid server ID format", http.StatusBadRequest)
 			return
 		}
-
-		// Get the server details from the registry service
-		serverDetail, err := registry.GetByID(id)
 		if err != nil {
-			if err.Error() == "record not found" {
-				http.Error(w, "Server not found", http.StatusNotFound)
-				return
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				serverNotFound(w, "Server not found")
+			case errors.Is(err, service.ErrAmbiguousSlug):
+				http.Error(w, err.Error()+"; use a version ID instead", http.StatusConflict)
+			default:
+				http.Error(w, "Error retrieving server details", storeErrorStatus(err))
 			}
-			http.Error(w, "Error retrieving server details", http.StatusInternalServerError)
 			return
 		}
+		if !canView(r, authService, serverDetail) {
+			serverNotFound(w, "Server not found")
+			return
+		}
+
+		response := serverDetailResponse{ServerDetail: contentFor(r, serverDetail), Slug: model.Slug(serverDetail.Name)}
+		if enricher != nil {
+			response.RepositoryMetadata, _ = enricher.Lookup(serverDetail.Repository.URL)
+		}
+
+		var body interface{} = response
+		if fields := parseFields(r); fields != nil {
+			body, err = selectFields(response, fields)
+			if err != nil {
+				http.Error(w, "Invalid fields parameter: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(serverDetail); err != nil {
+		if err := writeJSON(w, r, body); err != nil {
 			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 			return
 		}
@@ -0,0 +1,124 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"registry/internal/config"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// sitemapMaxURLs is the most URLs the sitemap protocol allows in one sitemap
+const sitemapMaxURLs = 50000
+
+// sitemapCacheControl matches how long the service reuses a computed sitemap
+const sitemapCacheControl = "public, max-age=60"
+
+// sitemapNamespace is the XML namespace of sitemaps and sitemap indexes
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapIndex lists the pages of the sitemap
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"sitemapindex"`
+	XMLNS    string           `xml:"xmlns,attr"`
+	Sitemaps []sitemapPageRef `xml:"sitemap"`
+}
+
+type sitemapPageRef struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapURLSet is a page of the sitemap
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// SitemapHandler returns a handler for the sitemap of the server detail pages of a public
+// catalog UI at MCP_REGISTRY_SITEMAP_BASE_URL. Without page it serves a sitemap index
+// listing the pages; page=N serves the Nth page of up to MCP_REGISTRY_SITEMAP_PAGE_SIZE
+// servers. Only servers shown in listings are included. The handler responds 404 when no
+// base URL is configured.
+func SitemapHandler(registry service.RegistryService, cfg *config.Config) http.HandlerFunc {
+	baseURL := strings.TrimSuffix(cfg.SitemapBaseURL, "/")
+	pageSize := cfg.SitemapPageSize
+	if pageSize <= 0 || pageSize > sitemapMaxURLs {
+		pageSize = sitemapMaxURLs
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if baseURL == "" {
+			http.Error(w, "Sitemap is not enabled", http.StatusNotFound)
+			return
+		}
+
+		page := 0
+		if value := r.URL.Query().Get("page"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				http.Error(w, "Invalid page: must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			page = n
+		}
+
+		sitemap, err := registry.Sitemap()
+		if err != nil {
+			http.Error(w, "Error computing sitemap", storeErrorStatus(err))
+			return
+		}
+		pages := max((len(sitemap.Entries)+pageSize-1)/pageSize, 1)
+
+		var doc interface{}
+		if page == 0 {
+			index := sitemapIndex{XMLNS: sitemapNamespace}
+			for n := 1; n <= pages; n++ {
+				index.Sitemaps = append(index.Sitemaps, sitemapPageRef{
+					Loc: baseURL + "/sitemap.xml?page=" + strconv.Itoa(n),
+				})
+			}
+			doc = index
+		} else {
+			if page > pages {
+				http.Error(w, "Sitemap page not found", http.StatusNotFound)
+				return
+			}
+			entries := sitemap.Entries[min((page-1)*pageSize, len(sitemap.Entries)):min(page*pageSize, len(sitemap.Entries))]
+			doc = sitemapPage(baseURL+cfg.SitemapServerPath, entries)
+		}
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Header().Set("Cache-Control", sitemapCacheControl)
+		if _, err := w.Write([]byte(xml.Header)); err != nil {
+			return
+		}
+		if err := xml.NewEncoder(w).Encode(doc); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// sitemapPage lists entries at the detail page URLs made from serverURL, in which {slug}
+// stands for the slug of each server
+func sitemapPage(serverURL string, entries []model.SitemapEntry) sitemapURLSet {
+	set := sitemapURLSet{XMLNS: sitemapNamespace, URLs: make([]sitemapURL, 0, len(entries))}
+	for _, entry := range entries {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:     strings.ReplaceAll(serverURL, "{slug}", url.PathEscape(entry.Slug)),
+			LastMod: entry.LastModified,
+		})
+	}
+	return set
+}
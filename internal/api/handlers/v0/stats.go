@@ -0,0 +1,28 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/service"
+)
+
+// statsCacheControl matches how long the service reuses computed statistics
+const statsCacheControl = "public, max-age=60"
+
+// StatsHandler returns a handler for statistics over the registry's public versions
+func StatsHandler(registry service.RegistryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, err := registry.Stats()
+		if err != nil {
+			http.Error(w, "Error computing statistics", storeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Cache-Control", statsCacheControl)
+		if err := writeJSON(w, r, stats); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
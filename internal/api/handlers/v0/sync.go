@@ -0,0 +1,77 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// SyncRequest is the desired state of every server of an org
+type SyncRequest struct {
+	Servers []*model.ServerDetail `json:"servers"`
+}
+
+// OrgSyncHandler returns a handler bringing the servers published from an org's
+// repositories to the desired state in the body. Servers missing from it are yanked. With
+// dry_run=true, the planned steps are returned without being applied.
+func OrgSyncHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req SyncRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Servers == nil {
+			http.Error(w, "servers is required; send an empty list to remove every server", http.StatusBadRequest)
+			return
+		}
+
+		plan, err := registry.PlanSync(r.PathValue("org"), req.Servers)
+		if err != nil {
+			http.Error(w, "Failed to plan sync: "+err.Error(), storeErrorStatus(err))
+			return
+		}
+		plan.DryRun = r.URL.Query().Get("dry_run") == "true"
+
+		// The caller must be allowed to publish every server the plan touches
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+			return
+		}
+		authorized := make(map[string]bool)
+		for _, step := range plan.Steps {
+			if step.Action == model.SyncUnchanged || step.Action == model.SyncInvalid || authorized[step.Name] {
+				continue
+			}
+			if status, msg := authenticatePublisher(r, authService, step.Name); status != 0 {
+				http.Error(w, msg, status)
+				return
+			}
+			authorized[step.Name] = true
+		}
+
+		if !plan.Valid() {
+			if err := writeJSONStatus(w, r, http.StatusUnprocessableEntity, plan); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			}
+			return
+		}
+		if !plan.DryRun {
+			if plan.Deletes() && !requireSecondFactor(w, r, authService) {
+				return
+			}
+			if err := registry.ApplySync(plan); err != nil {
+				http.Error(w, "Failed to apply sync: "+err.Error(), storeErrorStatus(err))
+				return
+			}
+		}
+
+		if err := writeJSON(w, r, plan); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}
+}
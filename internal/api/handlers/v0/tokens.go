@@ -0,0 +1,126 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// RevokeRequest names the token to revoke; the bearer token of the request is revoked
+// when it is empty
+type RevokeRequest struct {
+	Token string `json:"token"`
+}
+
+// RevokeResponse confirms a revocation
+type RevokeResponse struct {
+	Revoked bool `json:"revoked"`
+	// Login is the owner of the token, when it could still be identified
+	Login string `json:"login,omitempty"`
+}
+
+// IntrospectResponse reports whether a token is accepted and who it belongs to
+type IntrospectResponse struct {
+	Active  bool   `json:"active"`
+	Login   string `json:"login,omitempty"`
+	Revoked bool   `json:"revoked,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// ServerName and TokenID are set for publish tokens, which are limited to one server
+	ServerName string `json:"server_name,omitempty"`
+	TokenID    string `json:"token_id,omitempty"`
+}
+
+// RevokeHandler returns a handler revoking a token, so a leaked token stops being accepted
+// at once. Knowing the token is the permission to revoke it. The token is revoked even
+// when its provider cannot confirm it, since a leak should never wait on an outage.
+func RevokeHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RevokeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		token := req.Token
+		if token == "" {
+			token = bearerToken(r)
+		}
+		if token == "" {
+			http.Error(w, "token is required in the body or the Authorization header", http.StatusBadRequest)
+			return
+		}
+
+		login, _ := authService.Identify(r.Context(), token)
+		if err := registry.RevokeToken(auth.TokenDigest(token), login); err != nil {
+			http.Error(w, "Failed to revoke token", storeErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeJSON(w, r, RevokeResponse{Revoked: true, Login: login}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// IntrospectHandler returns a handler reporting whether the bearer token of the request is
+// accepted, so other services can check tokens against the registry
+func IntrospectHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+			return
+		}
+
+		var response IntrospectResponse
+		var login string
+		var err error
+		if strings.HasPrefix(token, model.PublishTokenPrefix) {
+			var publishToken *model.PublishToken
+			if publishToken, err = registry.PublishToken(token); err == nil {
+				login = publishToken.CreatedBy
+				response.ServerName = publishToken.ServerName
+				response.TokenID = publishToken.ID
+			} else if errors.Is(err, database.ErrNotFound) {
+				err = auth.ErrInvalidToken
+			}
+		} else {
+			login, err = authService.Identify(r.Context(), token)
+		}
+		switch {
+		case errors.Is(err, auth.ErrTokenRevoked):
+			response.Revoked = true
+			response.Error = err.Error()
+		case err != nil:
+			response.Error = err.Error()
+		default:
+			response.Active = true
+			response.Login = login
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeJSON(w, r, response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// bearerToken returns the token of the request's Authorization header, without its
+// Bearer prefix
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.ToUpper(header[:7]) == "BEARER " {
+		return header[7:]
+	}
+	return header
+}
@@ -0,0 +1,151 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/semver"
+	"registry/internal/service"
+)
+
+// maxUpdateChecks bounds the servers checked in one request
+const maxUpdateChecks = 100
+
+// InstalledServer is a server version a client has installed
+type InstalledServer struct {
+	// ID is the ID of any version of the server
+	ID               string `json:"id"`
+	InstalledVersion string `json:"installed_version"`
+}
+
+// CheckUpdatesRequest lists the servers installed by a client
+type CheckUpdatesRequest struct {
+	Servers []InstalledServer `json:"servers"`
+}
+
+// UpdateStatus reports whether an installed server is current
+type UpdateStatus struct {
+	ID               string `json:"id"`
+	Name             string `json:"name,omitempty"`
+	InstalledVersion string `json:"installed_version"`
+	LatestVersion    string `json:"latest_version,omitempty"`
+	LatestID         string `json:"latest_id,omitempty"`
+	UpdateAvailable  bool   `json:"update_available"`
+	// Yanked is set when the installed version was withdrawn by its publisher
+	Yanked       bool   `json:"yanked,omitempty"`
+	YankedReason string `json:"yanked_reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// CheckUpdatesResponse reports the status of every installed server, in request order
+type CheckUpdatesResponse struct {
+	Servers []UpdateStatus `json:"servers"`
+}
+
+// CheckUpdatesHandler returns a handler reporting, for each installed server in the body,
+// its latest version and whether the installed version has been yanked, so clients can
+// check their whole configuration in one round trip
+func CheckUpdatesHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CheckUpdatesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(req.Servers) == 0 {
+			http.Error(w, "servers is required", http.StatusBadRequest)
+			return
+		}
+		if len(req.Servers) > maxUpdateChecks {
+			http.Error(w, fmt.Sprintf("At most %d servers can be checked at once", maxUpdateChecks), http.StatusBadRequest)
+			return
+		}
+
+		response := CheckUpdatesResponse{Servers: make([]UpdateStatus, 0, len(req.Servers))}
+		for _, installed := range req.Servers {
+			status, err := checkUpdate(r, registry, authService, installed)
+			if err != nil {
+				status.Error = err.Error()
+			}
+			response.Servers = append(response.Servers, status)
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeJSON(w, r, response); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// checkUpdate compares an installed server with its latest visible version. The returned
+// error is fit for the client.
+func checkUpdate(r *http.Request, registry service.RegistryService, authService auth.Service, installed InstalledServer) (UpdateStatus, error) {
+	status := UpdateStatus{ID: installed.ID, InstalledVersion: installed.InstalledVersion}
+	id, ok := model.CanonicalID(installed.ID)
+	if !ok {
+		return status, errors.New("Invalid server ID format")
+	}
+	if installed.InstalledVersion == "" {
+		return status, errors.New("installed_version is required")
+	}
+
+	current, err := registry.GetVersion(id, installed.InstalledVersion)
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		// The server may still exist with other versions, in which case the installed
+		// version is reported as unknown below
+		current = nil
+	case err != nil:
+		return status, errors.New("Error retrieving server details")
+	case !canView(r, authService, current):
+		return status, errors.New("Server not found")
+	}
+
+	// The latest version the caller may see, so a private version does not hide public ones
+	latest, err := registry.LatestVersionWhere(id, func(version *model.ServerDetail) bool {
+		return canView(r, authService, version)
+	})
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		latest = nil
+	case err != nil:
+		return status, errors.New("Error retrieving server details")
+	}
+	if current == nil && latest == nil {
+		return status, errors.New("Server not found")
+	}
+
+	if current != nil {
+		status.Name = current.Name
+		status.Yanked = current.VersionDetail.Yanked
+		status.YankedReason = current.VersionDetail.YankedReason
+	}
+	if latest != nil {
+		status.Name = latest.Name
+		status.LatestVersion = latest.VersionDetail.Version
+		status.LatestID = latest.ID
+		status.UpdateAvailable = isNewer(latest.VersionDetail.Version, installed.InstalledVersion)
+	}
+	if current == nil {
+		return status, errors.New("Installed version " + installed.InstalledVersion + " is not published")
+	}
+	return status, nil
+}
+
+// isNewer reports whether version a is newer than b. Versions that are not semantic
+// versions are only compared for equality.
+func isNewer(a, b string) bool {
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	if errA != nil || errB != nil {
+		return a != b
+	}
+	return va.Compare(vb) > 0
+}
@@ -0,0 +1,73 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"registry/internal/usage"
+)
+
+// csvContentType is the media type of CSV exports
+const csvContentType = "text/csv"
+
+// UsageResponse is the usage report of this instance
+type UsageResponse struct {
+	Enabled bool        `json:"enabled"`
+	Usage   []usage.Row `json:"usage"`
+}
+
+// UsageHandler returns a handler reporting requests and bytes per tenant and key per day,
+// as JSON or, with format=csv or Accept: text/csv, as a CSV download. from, to, tenant
+// and key narrow the report.
+func UsageHandler(ledger *usage.Ledger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := usage.Filter{
+			From:   query.Get("from"),
+			To:     query.Get("to"),
+			Tenant: query.Get("tenant"),
+			Key:    query.Get("key"),
+		}
+		for _, day := range []string{filter.From, filter.To} {
+			if day == "" {
+				continue
+			}
+			if _, err := time.Parse(time.DateOnly, day); err != nil {
+				http.Error(w, "Invalid date, expected YYYY-MM-DD: "+day, http.StatusBadRequest)
+				return
+			}
+		}
+
+		rows := ledger.Report(filter)
+		w.Header().Set("Cache-Control", "no-store")
+
+		if query.Get("format") == "csv" || wantsCSV(r) {
+			w.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+			if err := usage.WriteCSV(w, rows); err != nil {
+				log.Printf("Usage export aborted: %v", err)
+			}
+			return
+		}
+
+		if err := writeJSON(w, r, UsageResponse{Enabled: ledger.Enabled(), Usage: rows}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// wantsCSV reports whether the client asked for CSV
+func wantsCSV(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == csvContentType {
+			return true
+		}
+	}
+	return false
+}
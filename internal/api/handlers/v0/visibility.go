@@ -0,0 +1,40 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"net/http"
+
+	"registry/internal/api/middleware"
+	"registry/internal/auth"
+	"registry/internal/model"
+)
+
+// privateCacheControl keeps private versions out of shared caches
+const privateCacheControl = "private, no-cache"
+
+// canView reports whether the request may see a version fetched by ID. Private versions
+// are only shown to callers who could publish to the server, and are otherwise reported
+// as not found so their existence is not revealed.
+func canView(r *http.Request, authService auth.Service, serverDetail *model.ServerDetail) bool {
+	if serverDetail.Visibility.Effective() != model.VisibilityPrivate {
+		return true
+	}
+	status, _ := authenticatePublisher(r, authService, serverDetail.Name)
+	return status == 0
+}
+
+// cacheControlFor returns publicValue for versions anyone may see and privateCacheControl
+// for private ones
+func cacheControlFor(serverDetail *model.ServerDetail, publicValue string) string {
+	if serverDetail.Visibility.Effective() == model.VisibilityPrivate {
+		return privateCacheControl
+	}
+	return publicValue
+}
+
+// serverNotFound answers 404 for a server that does not exist or that the caller may not
+// see, flagging the response for abuse detection
+func serverNotFound(w http.ResponseWriter, message string) {
+	middleware.MarkUnknownServer(w)
+	http.Error(w, message, http.StatusNotFound)
+}
@@ -0,0 +1,86 @@
+// Package v0 contains API handlers for version 0 of the API
+package v0
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"registry/internal/auth"
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// YankRequest is the optional body of a yank request
+type YankRequest struct {
+	Reason string `json:"reason"`
+}
+
+// YankHandler returns a handler that lets a server's publisher yank a version (POST) or
+// restore it (DELETE). Yanked versions remain available by ID for pinned clients.
+func YankHandler(registry service.RegistryService, authService auth.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, "server")
+		if !ok {
+			return
+		}
+
+		serverDetail, err := registry.GetByID(id)
+		if err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				serverNotFound(w, "Server not found")
+				return
+			}
+			http.Error(w, "Error retrieving server details", storeErrorStatus(err))
+			return
+		}
+
+		if status, msg := authenticatePublisher(r, authService, serverDetail.Name); status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+		if r.Method == http.MethodPost && !requireSecondFactor(w, r, authService) {
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			err = registry.Unyank(id)
+		} else {
+			var req YankRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			err = registry.Yank(id, req.Reason)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrNotFound):
+				serverNotFound(w, "Server not found")
+			case errors.Is(err, database.ErrInvalidInput):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, "Failed to update server version", storeErrorStatus(err))
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// yankReasonSuffix formats a yank reason for inclusion in a Warning header quoted-string
+func yankReasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	reason = strings.Map(func(r rune) rune {
+		if r == '"' || r == '\\' || r < ' ' || r > '~' {
+			return ' '
+		}
+		return r
+	}, reason)
+	return ": " + reason
+}
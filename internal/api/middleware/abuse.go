@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"registry/internal/abuse"
+	"registry/internal/config"
+)
+
+// DetectAbuse returns a middleware rejecting banned clients with 403 and reporting failed
+// requests to detector: writes rejected as invalid or unauthorized, and reads of unknown
+// servers flagged with MarkUnknownServer. Other 404s, such as mistyped paths, are not
+// counted. Admin requests are never counted or rejected, so operators can lift bans from
+// any address.
+func DetectAbuse(cfg *config.Config, detector *abuse.Detector, next http.Handler) http.Handler {
+	if !detector.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsAdmin(cfg, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := ClientIP(cfg, r)
+		if ban, ok := detector.Banned(ip); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(ban.Until).Seconds())+1))
+			http.Error(w, "Too many failed requests from this address; try again later", http.StatusForbidden)
+			return
+		}
+
+		sw := &abuseWriter{statusWriter: statusWriter{ResponseWriter: w, status: http.StatusOK}}
+		next.ServeHTTP(sw, r)
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			if sw.status == http.StatusNotFound && sw.unknownServer {
+				detector.Record(ip, abuse.NotFound)
+			}
+		case http.MethodOptions:
+		default:
+			switch sw.status {
+			case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+				detector.Record(ip, abuse.WriteFailure)
+			}
+		}
+	})
+}
+
+// abuseWriter remembers the response status and whether the handler flagged the
+// response as the lookup of an unknown server
+type abuseWriter struct {
+	statusWriter
+	unknownServer bool
+}
+
+// markUnknownServer flags the response
+func (aw *abuseWriter) markUnknownServer() {
+	aw.unknownServer = true
+}
+
+// MarkUnknownServer flags the response written to w as the lookup of a server ID or name
+// that does not exist, which DetectAbuse counts as a not-found failure. Writers wrapping
+// the one of DetectAbuse are unwrapped, and without it the call does nothing.
+func MarkUnknownServer(w http.ResponseWriter) {
+	for {
+		switch writer := w.(type) {
+		case interface{ markUnknownServer() }:
+			writer.markUnknownServer()
+			return
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return
+		}
+	}
+}
+
+// ClientIP returns the address of the client making r. Behind a proxy, the address is
+// taken from the MCP_REGISTRY_CLIENT_IP_HEADER header; when the header lists several
+// addresses, the last one, added by the nearest proxy, is used.
+func ClientIP(cfg *config.Config, r *http.Request) string {
+	if cfg.ClientIPHeader != "" {
+		if value := r.Header.Get(cfg.ClientIPHeader); value != "" {
+			addresses := strings.Split(value, ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"registry/internal/config"
+)
+
+// IsAdmin reports whether the request carries the configured admin bearer token.
+// Admin access is disabled entirely when no token is configured. The token is only read
+// from the Authorization header, which browsers never attach to cross-site requests on
+// their own, so admin endpoints need no CSRF protection. A cookie-based admin UI would
+// need SameSite cookies, CSRF tokens and origin checks before it could accept cookies here.
+func IsAdmin(cfg *config.Config, r *http.Request) bool {
+	if cfg.AdminToken == "" {
+		return false
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(cfg.AdminToken)) == 1
+}
+
+// RequireAdmin returns a middleware that rejects requests without the admin token
+func RequireAdmin(cfg *config.Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsAdmin(cfg, r) {
+			http.Error(w, "Admin authentication required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireDevelopmentOrAdmin returns a middleware that allows every request in development
+// environments and otherwise requires the admin token
+func RequireDevelopmentOrAdmin(cfg *config.Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.IsDevelopment() && !IsAdmin(cfg, r) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
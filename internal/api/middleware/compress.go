@@ -0,0 +1,141 @@
+// Package middleware contains HTTP middleware shared by the API routers
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Supported content encodings in order of preference
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// Compress returns a middleware that compresses responses with gzip or deflate,
+// negotiated via the Accept-Encoding request header
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the preferred supported encoding from an Accept-Encoding header value
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		// Encodings explicitly listed with q=0 are not acceptable
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		accepted[name] = quality > 0
+	}
+
+	for _, encoding := range []string{encodingGzip, encodingDeflate} {
+		if accepted[encoding] || (accepted["*"] && !isRejected(accepted, encoding)) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// isRejected reports whether an encoding was explicitly listed with q=0
+func isRejected(accepted map[string]bool, encoding string) bool {
+	ok, listed := accepted[encoding]
+	return listed && !ok
+}
+
+// compressWriter is a http.ResponseWriter that compresses the body once the handler starts writing it
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	writer      io.WriteCloser
+	wroteHeader bool
+}
+
+// WriteHeader sets the compression headers before delegating to the wrapped writer
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	// Skip bodies that are empty by definition, already encoded by the handler or gzipped
+	// archives, and short error messages, which gain nothing from compression
+	if status == http.StatusNoContent || status == http.StatusNotModified || status >= http.StatusBadRequest ||
+		h.Get("Content-Encoding") != "" || h.Get("Content-Type") == "application/gzip" {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+
+	switch cw.encoding {
+	case encodingGzip:
+		cw.writer = gzip.NewWriter(cw.ResponseWriter)
+	case encodingDeflate:
+		// flate.NewWriter only fails for invalid compression levels
+		cw.writer, _ = flate.NewWriter(cw.ResponseWriter, flate.DefaultCompression)
+	}
+
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+// Write compresses p into the underlying response
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.writer == nil {
+		return cw.ResponseWriter.Write(p)
+	}
+	return cw.writer.Write(p)
+}
+
+// Flush flushes buffered compressed data to the client, allowing streamed responses
+func (cw *compressWriter) Flush() {
+	if f, ok := cw.writer.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finalizes the compressed stream
+func (cw *compressWriter) Close() {
+	if cw.writer != nil {
+		_ = cw.writer.Close()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
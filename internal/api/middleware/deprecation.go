@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Deprecated returns a middleware announcing that the routes under prefix are deprecated in
+// favour of the same routes under successor. Responses carry a Deprecation header, a Sunset
+// header (RFC 8594) giving the date the routes stop working, and a Link to the successor route.
+func Deprecated(sunset time.Time, prefix, successor string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		if rest, ok := strings.CutPrefix(r.URL.Path, prefix); ok {
+			w.Header().Add("Link", "<"+successor+rest+`>; rel="successor-version"`)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
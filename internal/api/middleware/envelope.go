@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"registry/internal/api/envelope"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the client when well formed
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// Envelope returns a middleware marking JSON responses to be wrapped in envelope.Envelope.
+// Each request gets an ID, echoed in the X-Request-ID response header. Plain text error
+// responses, as written by http.Error, are converted to envelopes listing the error.
+func Envelope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ew := &envelopeWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r.WithContext(envelope.NewContext(r.Context(), id, start)))
+		if ew.errStatus == 0 {
+			return
+		}
+
+		body, _ := json.Marshal(envelope.Envelope{
+			Meta:   envelope.Meta{RequestID: id, TookMS: time.Since(start).Milliseconds()},
+			Errors: []envelope.Error{{Status: ew.errStatus, Message: strings.TrimSpace(ew.errBody.String())}},
+		})
+		h := w.Header()
+		h.Set("Content-Type", "application/json")
+		// A signature computed over the plain text body would not verify
+		h.Del(SignatureTrailer)
+		w.WriteHeader(ew.errStatus)
+		_, _ = w.Write(append(body, '\n'))
+	})
+}
+
+// validRequestID reports whether a client supplied request ID is safe to echo and log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// envelopeWriter holds back plain text error responses so they can be rewritten as envelopes
+type envelopeWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	errStatus   int
+	errBody     bytes.Buffer
+}
+
+// WriteHeader captures plain text error statuses and passes every other status through
+func (ew *envelopeWriter) WriteHeader(status int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+
+	h := ew.Header()
+	if status >= http.StatusBadRequest && strings.HasPrefix(h.Get("Content-Type"), "text/plain") && h.Get("Content-Encoding") == "" {
+		ew.errStatus = status
+		return
+	}
+	ew.ResponseWriter.WriteHeader(status)
+}
+
+// Write buffers the body of a captured error and passes other bodies through
+func (ew *envelopeWriter) Write(p []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.errStatus != 0 {
+		return ew.errBody.Write(p)
+	}
+	return ew.ResponseWriter.Write(p)
+}
+
+// Flush passes flushes through so streamed responses keep streaming
+func (ew *envelopeWriter) Flush() {
+	if ew.errStatus != 0 {
+		return
+	}
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (ew *envelopeWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// AllowMethods returns a middleware passing only the given methods to next. Other methods
+// are rejected with 405 and an Allow header listing the supported methods; OPTIONS is
+// answered with 204 and the same header.
+func AllowMethods(methods []string, next http.Handler) http.Handler {
+	allow := strings.Join(append(slices.Clone(methods), http.MethodOptions), ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(methods, r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", allow)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	})
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import "net/http"
+
+// ReadOnly returns a middleware rejecting writes with 503 when readOnly is set, as on
+// replicas that mirror another registry
+func ReadOnly(readOnly bool, next http.Handler) http.Handler {
+	if !readOnly {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+		default:
+			http.Error(w, "This registry is a read-only replica", http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"registry/internal/sampling"
+)
+
+// SampleRequests returns a middleware offering mutating requests to recorder, which keeps
+// a redacted copy of the sampled ones for /debug/requests. Safe methods are never sampled.
+func SampleRequests(recorder *sampling.Recorder, next http.Handler) http.Handler {
+	if !recorder.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !recorder.Sampled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Keep a prefix of the body and replay it, so the handler still sees all of it
+		prefix, _ := io.ReadAll(io.LimitReader(r.Body, sampling.MaxBodyBytes))
+		counter := &countingReader{Reader: io.MultiReader(bytes.NewReader(prefix), r.Body)}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{counter, r.Body}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		size := max(counter.n, len(prefix))
+		recorder.Record(r, prefix, size, sw.status)
+	})
+}
+
+// countingReader counts the bytes the handler consumed
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.Reader.Read(p)
+	cr.n += n
+	return n, err
+}
+
+// statusWriter remembers the response status
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status and passes it on
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Flush passes flushes through so streamed responses keep streaming
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"registry/internal/loadshed"
+)
+
+// CountInFlight returns a middleware counting every request in flight in shedder
+func CountInFlight(shedder *loadshed.Shedder, next http.Handler) http.Handler {
+	if !shedder.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer shedder.Begin()()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ShedLoad returns a middleware rejecting requests with 503 while shedder reports the
+// instance overloaded. It wraps low-priority routes only, so health checks and detail
+// lookups keep being served.
+func ShedLoad(shedder *loadshed.Shedder, next http.Handler) http.Handler {
+	if !shedder.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reason, overloaded := shedder.Overloaded(); overloaded {
+			shedder.Shed(reason)
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Server is overloaded; try again later", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
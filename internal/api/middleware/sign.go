@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"hash"
+	"net/http"
+
+	"registry/internal/signing"
+)
+
+// SignatureTrailer carries the detached signature of a signed response body
+const SignatureTrailer = "Registry-Signature"
+
+// Sign returns a middleware that signs the response body with signer and sends the
+// signature as an HTTP trailer, so streamed responses are signed without buffering.
+// The signature covers the uncompressed body; wrap Sign inside Compress. A nil signer
+// disables signing.
+func Sign(signer *signing.Signer, next http.Handler) http.Handler {
+	if signer == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Trailer", SignatureTrailer)
+
+		sw := &signWriter{ResponseWriter: w, hash: sha256.New()}
+		next.ServeHTTP(sw, r)
+
+		// Values set on a declared trailer after the body is written are sent as trailers
+		w.Header().Set(SignatureTrailer, signer.SignatureValue(sw.hash.Sum(nil)))
+	})
+}
+
+// signWriter hashes the response body as it is written
+type signWriter struct {
+	http.ResponseWriter
+	hash hash.Hash
+}
+
+// Write hashes p and passes it on
+func (sw *signWriter) Write(p []byte) (int, error) {
+	sw.hash.Write(p)
+	return sw.ResponseWriter.Write(p)
+}
+
+// Flush passes flushes through so streamed responses keep streaming
+func (sw *signWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (sw *signWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Deadline returns a middleware replacing the server-wide read and write deadlines with d
+// for the wrapped routes, so slow endpoints such as exports can outlive the global
+// timeouts. The request context is bounded by the same deadline. A non-positive d lifts
+// the deadlines entirely.
+func Deadline(d time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		if d > 0 {
+			deadline = time.Now().Add(d)
+			ctx, cancel := context.WithDeadline(r.Context(), deadline)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+
+		rc := http.NewResponseController(w)
+		if err := rc.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Failed to set read deadline: %v", err)
+		}
+		if err := rc.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Failed to set write deadline: %v", err)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"registry/internal/config"
+	"registry/internal/usage"
+)
+
+// AccountUsage returns a middleware recording every request in ledger under its tenant,
+// taken from the MCP_REGISTRY_USAGE_TENANT_HEADER request header, and credential. Only
+// credentials the handler accepted, by calling usage.Authenticated, are told apart; other
+// requests are recorded as usage.Anonymous.
+func AccountUsage(cfg *config.Config, ledger *usage.Ledger, next http.Handler) http.Handler {
+	if !ledger.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx, authenticated := usage.WithPrincipal(r.Context())
+		r = r.WithContext(ctx)
+		tenant := ""
+		if cfg.UsageTenantHeader != "" {
+			tenant = r.Header.Get(cfg.UsageTenantHeader)
+		}
+
+		counter := &countingReader{Reader: r.Body}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{counter, r.Body}
+
+		uw := &usageWriter{ResponseWriter: w}
+		next.ServeHTTP(uw, r)
+
+		key := usage.Anonymous
+		switch {
+		case IsAdmin(cfg, r):
+			key = usage.Admin
+		case *authenticated:
+			key = usage.KeyOf(r)
+		}
+		ledger.Record(start, tenant, key, int64(counter.n), uw.n)
+	})
+}
+
+// usageWriter counts the response bytes written
+type usageWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+// Write counts p and passes it on
+func (uw *usageWriter) Write(p []byte) (int, error) {
+	n, err := uw.ResponseWriter.Write(p)
+	uw.n += int64(n)
+	return n, err
+}
+
+// Flush passes flushes through so streamed responses keep streaming
+func (uw *usageWriter) Flush() {
+	if f, ok := uw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (uw *usageWriter) Unwrap() http.ResponseWriter {
+	return uw.ResponseWriter
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"registry/internal/warmup"
+)
+
+// TrackQueries returns a middleware counting the queries of successful listing requests
+// in warmer, which replays the most requested ones to keep them cached
+func TrackQueries(warmer *warmup.Warmer, next http.Handler) http.Handler {
+	if warmer == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if warmup.IsReplay(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		if sw.status == http.StatusOK {
+			warmer.Record(r.URL)
+		}
+	})
+}
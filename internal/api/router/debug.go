@@ -0,0 +1,31 @@
+package router
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"registry/internal/api/handlers/debug"
+	"registry/internal/api/middleware"
+	"registry/internal/config"
+	"registry/internal/sampling"
+	"registry/internal/service"
+)
+
+// RegisterDebugRoutes registers runtime diagnostics, available in development or with the admin token
+func RegisterDebugRoutes(mux *http.ServeMux, cfg *config.Config, registry service.RegistryService, recorder *sampling.Recorder) {
+	guard := func(h http.Handler) http.Handler {
+		return middleware.Deadline(cfg.RouteTimeout(RouteGroupDebug), middleware.RequireDevelopmentOrAdmin(cfg, h))
+	}
+
+	mount(mux, "/debug", []route{
+		{"/pprof/", get, guard(http.HandlerFunc(pprof.Index))},
+		{"/pprof/cmdline", get, guard(http.HandlerFunc(pprof.Cmdline))},
+		{"/pprof/profile", get, guard(http.HandlerFunc(pprof.Profile))},
+		// Symbol lookups take the addresses in a POST body
+		{"/pprof/symbol", methods(http.MethodGet, http.MethodPost), guard(http.HandlerFunc(pprof.Symbol))},
+		{"/pprof/trace", get, guard(http.HandlerFunc(pprof.Trace))},
+		{"/vars", get, guard(expvar.Handler())},
+		{"/store-stats", get, guard(debug.StoreStatsHandler(registry))},
+		{"/requests", get, guard(debug.RequestsHandler(recorder))},
+	}, nil)
+}
@@ -1,17 +1,99 @@
 package router
 
 import (
+	"log"
 	"net/http"
+	"registry/internal/abuse"
+	"registry/internal/api/handlers/v0"
+	"registry/internal/api/middleware"
 	"registry/internal/auth"
 	"registry/internal/config"
+	"registry/internal/enrichment"
+	"registry/internal/flags"
+	"registry/internal/lifecycle"
+	"registry/internal/loadshed"
+	"registry/internal/maintenance"
+	"registry/internal/mcpserver"
+	"registry/internal/media"
+	"registry/internal/metrics"
+	"registry/internal/sampling"
+	"registry/internal/scheduler"
 	"registry/internal/service"
+	"registry/internal/signing"
+	"registry/internal/usage"
+	"registry/internal/warmup"
 )
 
-func New(cfg *config.Config, registry service.RegistryService, authService auth.Service) *http.ServeMux {
+// Route groups whose timeouts can be overridden through MCP_REGISTRY_ROUTE_TIMEOUTS
+const (
+	RouteGroupExport  = "export"
+	RouteGroupPublish = "publish"
+	RouteGroupAdmin   = "admin"
+	RouteGroupDebug   = "debug"
+)
+
+var routeGroups = map[string]bool{
+	RouteGroupExport:  true,
+	RouteGroupPublish: true,
+	RouteGroupAdmin:   true,
+	RouteGroupDebug:   true,
+}
+
+func New(
+	cfg *config.Config,
+	registry service.RegistryService,
+	authService auth.Service,
+	state *lifecycle.State,
+	featureFlags *flags.Set,
+	enricher *enrichment.Enricher,
+	icons media.Store,
+	signer *signing.Signer,
+	jobs *scheduler.Scheduler,
+	warmer *warmup.Warmer,
+) http.Handler {
+	for group := range cfg.RouteTimeouts {
+		if !routeGroups[group] {
+			log.Printf("Ignoring timeout for unknown route group %q", group)
+		}
+	}
+
 	mux := http.NewServeMux()
 
+	// Register unversioned lifecycle probes
+	probe := methods(http.MethodGet, http.MethodHead)
+	mount(mux, "", []route{
+		{"/livez", probe, state.LivezHandler()},
+		{"/readyz", probe, state.ReadyzHandler()},
+		{"/startupz", probe, state.StartupzHandler()},
+
+		// Publish the key mirrors use to verify signed responses
+		{"/.well-known/mcp-registry-signing-key", get, signing.PublicKeyHandler(signer)},
+
+		// Let search engines index the detail pages of a public catalog UI
+		{"/sitemap.xml", get, v0.SitemapHandler(registry, cfg)},
+	}, nil)
+
 	// Register routes for all API versions
-	RegisterV0Routes(mux, cfg, registry, authService)
+	mode := maintenance.New(cfg)
+	ledger := usage.NewLedger(cfg.UsageRetentionDays, cfg.UsageMaxTenants)
+	detector := abuse.New(cfg)
+	shedder := loadshed.New(cfg)
+	RegisterV0Routes(mux, cfg, registry, authService, featureFlags, enricher, icons, signer, mode, ledger, detector, shedder, jobs, warmer)
+	RegisterV1Routes(mux, cfg, registry, authService, featureFlags, enricher, icons, signer, mode, ledger, detector, shedder, jobs, warmer)
+	if warmer != nil {
+		// Replays go through the versioned routes, so they fill the pages clients read
+		warmer.SetHandler(mux, "/v0/servers", "/v1/servers")
+	}
+	recorder := sampling.NewRecorder(cfg.RequestSampleRate, cfg.RequestSampleSize)
+	RegisterDebugRoutes(mux, cfg, registry, recorder)
+
+	// Agents query the catalog over MCP's streamable HTTP transport
+	mux.Handle("/mcp", middleware.AllowMethods(post, mcpserver.New(registry, cfg)))
+
+	// Metrics reveal traffic and data volumes, so scrapers send the admin token
+	mux.Handle("/metrics", middleware.AllowMethods(get,
+		middleware.RequireDevelopmentOrAdmin(cfg, featureFlags.Gate(flags.Metrics, metrics.Default.Handler()))))
 
-	return mux
+	return middleware.CountInFlight(shedder,
+		middleware.AccountUsage(cfg, ledger, middleware.DetectAbuse(cfg, detector, middleware.SampleRequests(recorder, mux))))
 }
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"net/http"
+	"registry/internal/abuse"
+	v0 "registry/internal/api/handlers/v0"
+	"registry/internal/api/middleware"
+	"registry/internal/auth"
+	"registry/internal/config"
+	"registry/internal/enrichment"
+	"registry/internal/flags"
+	"registry/internal/gc"
+	"registry/internal/health"
+	"registry/internal/loadshed"
+	"registry/internal/maintenance"
+	"registry/internal/media"
+	"registry/internal/scheduler"
+	"registry/internal/service"
+	"registry/internal/signing"
+	"registry/internal/usage"
+	"registry/internal/warmup"
+	"strings"
+)
+
+// route is an API endpoint; its pattern is relative to the version prefix it is mounted under.
+// Requests with a method outside methods are answered by middleware.AllowMethods.
+type route struct {
+	pattern string
+	methods []string
+	handler http.Handler
+}
+
+// methods lists the HTTP methods a route accepts
+func methods(m ...string) []string {
+	return m
+}
+
+var (
+	get  = methods(http.MethodGet)
+	post = methods(http.MethodPost)
+)
+
+// ServerRouteNames returns the literal path segments of the endpoints under /servers/ in
+// apiRoutes. A server whose slug is one of them could not be reached by its slug, so the
+// auth service refuses such names.
+func ServerRouteNames() []string {
+	return []string{"featured", "count", "check-updates"}
+}
+
+// apiRoutes returns the endpoints served by every API version. A version that changes an
+// endpoint's contract replaces its entry before mounting the routes.
+func apiRoutes(
+	cfg *config.Config,
+	registry service.RegistryService,
+	authService auth.Service,
+	featureFlags *flags.Set,
+	enricher *enrichment.Enricher,
+	icons media.Store,
+	signer *signing.Signer,
+	mode *maintenance.Mode,
+	ledger *usage.Ledger,
+	detector *abuse.Detector,
+	shedder *loadshed.Shedder,
+	jobs *scheduler.Scheduler,
+	warmer *warmup.Warmer,
+) []route {
+	publish := func(h http.Handler) http.Handler {
+		return middleware.Deadline(cfg.RouteTimeout(RouteGroupPublish), middleware.ReadOnly(cfg.IsReplica(), h))
+	}
+	// publishWrites sends the writes to a route that also serves reads through the publish
+	// group, leaving its reads as fast as any other lookup
+	publishWrites := func(h http.Handler) http.Handler {
+		published := publish(h)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				h.ServeHTTP(w, r)
+				return
+			}
+			published.ServeHTTP(w, r)
+		})
+	}
+	admin := func(h http.Handler) http.Handler {
+		return middleware.Deadline(cfg.RouteTimeout(RouteGroupAdmin), middleware.RequireAdmin(cfg, h))
+	}
+	// Searches and exports are the first to go when the instance is overloaded
+	lowPriority := func(h http.Handler) http.Handler {
+		return middleware.ShedLoad(shedder, h)
+	}
+
+	routes := []route{
+		{"/health", get, v0.HealthHandler(cfg, health.Default)},
+		{"/servers", get, lowPriority(middleware.Compress(middleware.Sign(signer, middleware.TrackQueries(warmer, v0.ServersHandler(registry, cfg)))))},
+		{"/servers/featured", get, v0.FeaturedServersHandler(registry)},
+		{"/servers/count", get, lowPriority(v0.ServersCountHandler(registry, cfg))},
+		{"/servers/{id}", methods(http.MethodGet, http.MethodPut),
+			publishWrites(v0.ServersDetailHandler(registry, authService, enricher))},
+		{"/servers/{id}/install", get, v0.InstallHandler(registry, authService)},
+		{"/servers/{id}/claude-config", get, v0.ClaudeConfigHandler(registry, authService)},
+		{"/servers/{id}/resolve", get, v0.ResolveHandler(registry, authService)},
+		{"/servers/{id}/diff", get, v0.DiffHandler(registry, authService)},
+		{"/servers/check-updates", post, v0.CheckUpdatesHandler(registry, authService)},
+		{"/resolve", post, v0.LockHandler(registry, authService)},
+		{"/servers/{id}/readme", methods(http.MethodGet, http.MethodHead), v0.ReadmeHandler(registry, authService)},
+		{"/servers/{id}/badge.svg", methods(http.MethodGet, http.MethodHead), v0.BadgeHandler(registry, authService)},
+		{"/servers/{id}/icon", methods(http.MethodGet, http.MethodHead, http.MethodPut),
+			publish(v0.IconHandler(registry, authService, icons))},
+		{"/servers/{id}/versions/{version}/changelog", get, v0.ChangelogHandler(registry, authService)},
+		{"/servers/{id}/yank", methods(http.MethodPost, http.MethodDelete), publish(v0.YankHandler(registry, authService))},
+		{"/servers/{id}/tokens", methods(http.MethodGet, http.MethodPost), publish(v0.PublishTokensHandler(registry, authService))},
+		{"/servers/{id}/tokens/{token_id}", methods(http.MethodDelete), publish(v0.PublishTokensHandler(registry, authService))},
+		{"/servers/{id}/report", post, middleware.ReadOnly(cfg.IsReplica(), v0.ReportHandler(registry, authService))},
+		{"/manifests/{digest}", get, v0.ManifestHandler(registry)},
+		{"/authors/{author}", get, v0.AuthorHandler(registry)},
+		{"/authors/{author}/servers", get, v0.AuthorServersHandler(registry)},
+		{"/changes", get, v0.ChangesHandler(registry)},
+		{"/stats", get, v0.StatsHandler(registry)},
+		{"/saved-searches", methods(http.MethodGet, http.MethodPost),
+			middleware.ReadOnly(cfg.IsReplica(), v0.SavedSearchesHandler(registry, authService, cfg))},
+		{"/saved-searches/{id}", methods(http.MethodDelete), middleware.ReadOnly(cfg.IsReplica(), v0.SavedSearchHandler(registry, authService))},
+		{"/saved-searches/{id}/confirm", post,
+			middleware.ReadOnly(cfg.IsReplica(), v0.ConfirmSavedSearchHandler(registry, authService))},
+		{"/ping", get, v0.PingHandler(cfg)},
+		{"/auth/revoke", post, middleware.ReadOnly(cfg.IsReplica(), v0.RevokeHandler(registry, authService))},
+		{"/auth/introspect", get, v0.IntrospectHandler(registry, authService)},
+		{"/publish", post, publish(v0.PublishHandler(registry, authService))},
+		{"/orgs/{org}/servers:sync", methods(http.MethodPut), publish(v0.OrgSyncHandler(registry, authService))},
+		{"/drafts", methods(http.MethodGet, http.MethodPost), publish(v0.DraftsHandler(registry, authService))},
+		{"/drafts/{id}", methods(http.MethodGet, http.MethodPut, http.MethodDelete), publish(v0.DraftHandler(registry, authService))},
+		{"/drafts/{id}/preview", get, publish(v0.DraftPreviewHandler(registry, authService))},
+		{"/drafts/{id}/publish", post, publish(v0.DraftPublishHandler(registry, authService))},
+		{"/debug/echo", methods(http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete),
+			middleware.Deadline(cfg.RouteTimeout(RouteGroupDebug), middleware.RequireDevelopmentOrAdmin(cfg, v0.EchoHandler(cfg, authService)))},
+		{"/export", get, lowPriority(middleware.Deadline(cfg.RouteTimeout(RouteGroupExport),
+			featureFlags.Gate(flags.Export, middleware.Compress(middleware.Sign(signer, v0.ExportHandler(registry, cfg, icons, signer))))))},
+
+		// Admin endpoints
+		{"/admin/flags", get, admin(v0.FlagsHandler(featureFlags))},
+		{"/admin/flags/{name}", methods(http.MethodGet, http.MethodPut, http.MethodDelete), admin(v0.FlagHandler(featureFlags))},
+		{"/admin/reindex", methods(http.MethodGet, http.MethodPost), admin(v0.ReindexHandler(registry))},
+		{"/admin/gc", post, admin(v0.GCHandler(registry, gc.Policy(cfg)))},
+		{"/admin/featured", get, admin(v0.FeaturedEntriesHandler(registry))},
+		{"/admin/featured/{name...}", methods(http.MethodPut, http.MethodDelete), admin(v0.FeaturedEntryHandler(registry))},
+		{"/admin/aliases", get, admin(v0.AliasesHandler(registry))},
+		{"/admin/aliases/{name...}", methods(http.MethodPut, http.MethodDelete), admin(v0.AliasHandler(registry))},
+		{"/admin/orgs/archived", get, admin(v0.ArchivedOrgsHandler(registry))},
+		{"/admin/orgs/{org}/archive", methods(http.MethodPut, http.MethodDelete), admin(v0.OrgArchiveHandler(registry))},
+		{"/admin/reports", get, admin(v0.ReportsHandler(registry))},
+		{"/admin/reports/{id}", methods(http.MethodGet, http.MethodPut), admin(v0.ReportStatusHandler(registry))},
+		{"/admin/maintenance", methods(http.MethodGet, http.MethodPut, http.MethodDelete), admin(v0.MaintenanceHandler(mode))},
+		{"/admin/usage", get, admin(v0.UsageHandler(ledger))},
+		{"/admin/bans", get, admin(v0.BansHandler(detector))},
+		{"/admin/bans/{ip}", methods(http.MethodDelete), admin(v0.BanHandler(detector))},
+		{"/admin/jobs", get, admin(v0.JobsHandler(jobs))},
+		{"/admin/config", get, admin(v0.ConfigHandler(cfg))},
+	}
+
+	// Health and admin endpoints stay available during maintenance so operators can end it
+	for i, r := range routes {
+		if r.pattern != "/health" && !strings.HasPrefix(r.pattern, "/admin/") {
+			routes[i].handler = mode.Guard(r.handler)
+		}
+	}
+	return routes
+}
+
+// mount registers routes under prefix, passing each handler through wrap when it is non-nil
+func mount(mux *http.ServeMux, prefix string, routes []route, wrap func(http.Handler) http.Handler) {
+	for _, r := range routes {
+		h := middleware.AllowMethods(r.methods, r.handler)
+		if wrap != nil {
+			h = wrap(h)
+		}
+		mux.Handle(prefix+r.pattern, h)
+	}
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"registry/internal/config"
+	"registry/internal/loadshed"
+)
+
+func TestServerRouteNames(t *testing.T) {
+	routes := apiRoutes(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, &loadshed.Shedder{}, nil, nil)
+	names := ServerRouteNames()
+	for _, r := range routes {
+		rest, ok := strings.CutPrefix(r.pattern, "/servers/")
+		if !ok {
+			continue
+		}
+		segment, _, _ := strings.Cut(rest, "/")
+		if strings.HasPrefix(segment, "{") {
+			continue
+		}
+		if !slices.Contains(names, segment) {
+			t.Errorf("route %s: ServerRouteNames() is missing %q, so a server slug could shadow it", r.pattern, segment)
+		}
+	}
+}
@@ -1,20 +1,58 @@
 package router
 
 import (
+	"log"
 	"net/http"
-	v0 "registry/internal/api/handlers/v0"
+	"time"
+
+	"registry/internal/abuse"
+	"registry/internal/api/middleware"
 	"registry/internal/auth"
 	"registry/internal/config"
+	"registry/internal/enrichment"
+	"registry/internal/flags"
+	"registry/internal/loadshed"
+	"registry/internal/maintenance"
+	"registry/internal/media"
+	"registry/internal/scheduler"
 	"registry/internal/service"
+	"registry/internal/signing"
+	"registry/internal/usage"
+	"registry/internal/warmup"
 )
 
-func RegisterV0Routes(mux *http.ServeMux, cfg *config.Config, registry service.RegistryService, authService auth.Service) {
-	// Register v0 endpoints
-	mux.HandleFunc("/v0/health", v0.HealthHandler(cfg))
-	mux.HandleFunc("/v0/servers", v0.ServersHandler(registry))
-	mux.HandleFunc("/v0/servers/{id}", v0.ServersDetailHandler(registry))
-	mux.HandleFunc("/v0/ping", v0.PingHandler(cfg))
-	mux.HandleFunc("/v0/publish", v0.PublishHandler(registry, authService))
+// RegisterV0Routes registers version 0 of the API. Its contracts are frozen; changes go
+// into a newer version. Once MCP_REGISTRY_API_V0_SUNSET is set, every response announces
+// the sunset date and the /v1 successor of the route.
+func RegisterV0Routes(
+	mux *http.ServeMux,
+	cfg *config.Config,
+	registry service.RegistryService,
+	authService auth.Service,
+	featureFlags *flags.Set,
+	enricher *enrichment.Enricher,
+	icons media.Store,
+	signer *signing.Signer,
+	mode *maintenance.Mode,
+	ledger *usage.Ledger,
+	detector *abuse.Detector,
+	shedder *loadshed.Shedder,
+	jobs *scheduler.Scheduler,
+	warmer *warmup.Warmer,
+) {
+	var deprecate func(http.Handler) http.Handler
+	if cfg.APIV0Sunset != "" {
+		sunset, err := time.Parse(time.DateOnly, cfg.APIV0Sunset)
+		if err != nil {
+			log.Printf("Ignoring invalid API v0 sunset date %q: expected YYYY-MM-DD", cfg.APIV0Sunset)
+		} else {
+			deprecate = func(h http.Handler) http.Handler {
+				return middleware.Deprecated(sunset, "/v0", "/v1", h)
+			}
+		}
+	}
+
+	mount(mux, "/v0", apiRoutes(cfg, registry, authService, featureFlags, enricher, icons, signer, mode, ledger, detector, shedder, jobs, warmer), deprecate)
 
 	// // Register Swagger UI routes
 	// mux.HandleFunc("/v0/swagger/", v0.SwaggerHandler())
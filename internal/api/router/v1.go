@@ -0,0 +1,42 @@
+package router
+
+import (
+	"net/http"
+
+	"registry/internal/abuse"
+	"registry/internal/api/middleware"
+	"registry/internal/auth"
+	"registry/internal/config"
+	"registry/internal/enrichment"
+	"registry/internal/flags"
+	"registry/internal/loadshed"
+	"registry/internal/maintenance"
+	"registry/internal/media"
+	"registry/internal/scheduler"
+	"registry/internal/service"
+	"registry/internal/signing"
+	"registry/internal/usage"
+	"registry/internal/warmup"
+)
+
+// RegisterV1Routes registers version 1 of the API, the current version. It serves the
+// paginated and filtered contracts of every v0 endpoint; contract changes land here while
+// /v0 stays frozen. JSON bodies, including errors, are wrapped in envelope.Envelope.
+func RegisterV1Routes(
+	mux *http.ServeMux,
+	cfg *config.Config,
+	registry service.RegistryService,
+	authService auth.Service,
+	featureFlags *flags.Set,
+	enricher *enrichment.Enricher,
+	icons media.Store,
+	signer *signing.Signer,
+	mode *maintenance.Mode,
+	ledger *usage.Ledger,
+	detector *abuse.Detector,
+	shedder *loadshed.Shedder,
+	jobs *scheduler.Scheduler,
+	warmer *warmup.Warmer,
+) {
+	mount(mux, "/v1", apiRoutes(cfg, registry, authService, featureFlags, enricher, icons, signer, mode, ledger, detector, shedder, jobs, warmer), middleware.Envelope)
+}
@@ -3,11 +3,20 @@ package api
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"registry/internal/api/router"
 	"registry/internal/auth"
 	"registry/internal/config"
+	"registry/internal/daemon"
+	"registry/internal/enrichment"
+	"registry/internal/flags"
+	"registry/internal/lifecycle"
+	"registry/internal/media"
+	"registry/internal/scheduler"
 	"registry/internal/service"
+	"registry/internal/signing"
+	"registry/internal/warmup"
 	"time"
 )
 
@@ -16,14 +25,26 @@ type Server struct {
 	config   *config.Config
 	registry service.RegistryService
 	// authService auth.Service
-	router *http.ServeMux
-	server *http.Server
+	router    http.Handler
+	server    *http.Server
+	lifecycle *lifecycle.State
 }
 
 // NewServer creates a new HTTP server
 // func NewServer(cfg *config.Config, registryService service.RegistryService, authService auth.Service) *Server {
-func NewServer(cfg *config.Config, registryService service.RegistryService, authService auth.Service) *Server {
-	mux := router.New(cfg, registryService, authService)
+func NewServer(
+	cfg *config.Config,
+	registryService service.RegistryService,
+	authService auth.Service,
+	featureFlags *flags.Set,
+	enricher *enrichment.Enricher,
+	icons media.Store,
+	signer *signing.Signer,
+	jobs *scheduler.Scheduler,
+	warmer *warmup.Warmer,
+) *Server {
+	state := lifecycle.New()
+	mux := router.New(cfg, registryService, authService, state, featureFlags, enricher, icons, signer, jobs, warmer)
 
 	server := &Server{
 		config:   cfg,
@@ -33,8 +54,12 @@ func NewServer(cfg *config.Config, registryService service.RegistryService, auth
 		server: &http.Server{
 			Addr:              cfg.ServerAddress,
 			Handler:           mux,
-			ReadHeaderTimeout: 10 * time.Second,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			ReadTimeout:       cfg.HTTPReadTimeout,
+			WriteTimeout:      cfg.HTTPWriteTimeout,
+			IdleTimeout:       cfg.HTTPIdleTimeout,
 		},
+		lifecycle: state,
 	}
 
 	return server
@@ -43,10 +68,34 @@ func NewServer(cfg *config.Config, registryService service.RegistryService, auth
 // Start begins listening for incoming HTTP requests
 func (s *Server) Start() error {
 	log.Printf("HTTP server starting on %s", s.config.ServerAddress)
-	return s.server.ListenAndServe()
+	addr := s.server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.lifecycle.MarkStarted()
+	// Only report readiness to the service manager once the port is bound
+	daemon.Ready()
+	return s.server.Serve(listener)
 }
 
-// Shutdown gracefully shuts down the server
+// Shutdown gracefully shuts down the server. Readiness is reported as failing first and
+// connections are only closed after the configured delay, giving load balancers time to
+// stop routing new requests to this instance.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.lifecycle.MarkDraining()
+
+	if delay := s.config.ShutdownDelay; delay > 0 {
+		log.Printf("Waiting %s before closing connections", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
 	return s.server.Shutdown(ctx)
 }
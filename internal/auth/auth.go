@@ -23,4 +23,15 @@ type Service interface {
 
 	// ValidateAuth validates the authentication credentials
 	ValidateAuth(ctx context.Context, auth model.Authentication) (bool, error)
+
+	// Identify returns the login of the user a bearer token was issued to
+	Identify(ctx context.Context, token string) (string, error)
+
+	// RequireSecondFactor returns ErrSecondFactorRequired when the second factor policy is
+	// enabled and the token does not prove a recent second factor
+	RequireSecondFactor(ctx context.Context, token string) error
+
+	// CheckReservedName reports whether serverName is reserved, returning ErrNameReserved
+	// unless token is the admin token
+	CheckReservedName(token, serverName string) (bool, error)
 }
@@ -77,6 +77,52 @@ func (g *GitHubDeviceAuth) ValidateToken(ctx context.Context, token string, requ
 		return false, fmt.Errorf("repository reference is required for token validation")
 	}
 
+	if err := g.verifyAppToken(ctx, token); err != nil {
+		return false, err
+	}
+
+	login, err := g.authenticatedUser(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	// Extract owner from the required repo
+	owner, _, err := g.ExtractGitHubRepoFromName(requiredRepo)
+	if err != nil {
+		return false, err
+	}
+
+	// Verify that the authenticated user matches the owner
+	if login != owner {
+		// Check if the user is a member of the organization
+		isMember, err := g.checkOrgMembership(ctx, token, login, owner)
+		if err != nil {
+			return false, fmt.Errorf("failed to check org membership: %s", owner)
+		}
+
+		if !isMember {
+			return false, fmt.Errorf(
+				"token belongs to user %s, but repository is owned by %s and user is not a member of the organization",
+				login, owner)
+		}
+	}
+
+	// If we've reached this point, the token has access the repo and the user matches
+	// the owner or is a member of the owner org
+	return true, nil
+}
+
+// Login returns the GitHub login of the user a token was issued to, after verifying the
+// token belongs to this application
+func (g *GitHubDeviceAuth) Login(ctx context.Context, token string) (string, error) {
+	if err := g.verifyAppToken(ctx, token); err != nil {
+		return "", err
+	}
+	return g.authenticatedUser(ctx, token)
+}
+
+// verifyAppToken checks that the token was created for the configured ClientID
+func (g *GitHubDeviceAuth) verifyAppToken(ctx context.Context, token string) error {
 	// First, validate that the token is associated with our ClientID
 	tokenReq, err := http.NewRequestWithContext(
 		ctx,
@@ -85,7 +131,7 @@ func (g *GitHubDeviceAuth) ValidateToken(ctx context.Context, token string, requ
 		nil,
 	)
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	// The applications endpoint requires basic auth with client ID and secret
@@ -99,14 +145,14 @@ func (g *GitHubDeviceAuth) ValidateToken(ctx context.Context, token string, requ
 
 	checkBody, err := json.Marshal(tokenCheck{AccessToken: token})
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	// POST instead of GET for security reasons per GitHub API
 	tokenURL := "https://api.github.com/applications/" + g.config.ClientID + "/token"
 	tokenReq, err = http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, io.NopCloser(bytes.NewReader(checkBody)))
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	tokenReq.SetBasicAuth(g.config.ClientID, g.config.ClientSecret)
@@ -116,48 +162,53 @@ func (g *GitHubDeviceAuth) ValidateToken(ctx context.Context, token string, requ
 	client := &http.Client{}
 	tokenResp, err := client.Do(tokenReq)
 	if err != nil {
-		return false, err
+		return err
 	}
 	defer tokenResp.Body.Close()
 
 	// Check response - 200 means token is valid and associated with our app
 	// 404 means token is not associated with our app
 	if tokenResp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("token is not associated with this application (status: %d)", tokenResp.StatusCode)
+		return fmt.Errorf("token is not associated with this application (status: %d)", tokenResp.StatusCode)
 	}
 
 	var tokenInfo TokenValidationResponse
 	tokenRespBody, err := io.ReadAll(tokenResp.Body)
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	if err := json.Unmarshal(tokenRespBody, &tokenInfo); err != nil {
-		return false, err
+		return err
 	}
 
 	// Check if there's an error in the response
 	if tokenInfo.Error != "" {
-		return false, fmt.Errorf("token validation error: %s", tokenInfo.Error)
+		return fmt.Errorf("token validation error: %s", tokenInfo.Error)
 	}
 
+	return nil
+}
+
+// authenticatedUser returns the login of the user owning the token
+func (g *GitHubDeviceAuth) authenticatedUser(ctx context.Context, token string) (string, error) {
 	// Get the authenticated user
 	userReq, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/user", nil)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
 	userReq.Header.Set("Accept", "application/vnd.github+json")
 	userReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	client = &http.Client{}
+	client := &http.Client{}
 	userResp, err := client.Do(userReq)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 	defer userResp.Body.Close()
 
 	if userResp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("failed to get user info: status %d", userResp.StatusCode)
+		return "", fmt.Errorf("failed to get user info: status %d", userResp.StatusCode)
 	}
 
 	var userInfo struct {
@@ -166,37 +217,14 @@ func (g *GitHubDeviceAuth) ValidateToken(ctx context.Context, token string, requ
 
 	userBody, err := io.ReadAll(userResp.Body)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
 	if err := json.Unmarshal(userBody, &userInfo); err != nil {
-		return false, err
+		return "", err
 	}
 
-	// Extract owner from the required repo
-	owner, _, err := g.ExtractGitHubRepoFromName(requiredRepo)
-	if err != nil {
-		return false, err
-	}
-
-	// Verify that the authenticated user matches the owner
-	if userInfo.Login != owner {
-		// Check if the user is a member of the organization
-		isMember, err := g.checkOrgMembership(ctx, token, userInfo.Login, owner)
-		if err != nil {
-			return false, fmt.Errorf("failed to check org membership: %s", owner)
-		}
-
-		if !isMember {
-			return false, fmt.Errorf(
-				"token belongs to user %s, but repository is owned by %s and user is not a member of the organization",
-				userInfo.Login, owner)
-		}
-	}
-
-	// If we've reached this point, the token has access the repo and the user matches
-	// the owner or is a member of the owner org
-	return true, nil
+	return userInfo.Login, nil
 }
 
 func (g *GitHubDeviceAuth) ExtractGitHubRepoFromName(n string) (owner, repo string, err error) {
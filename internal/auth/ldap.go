@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"registry/internal/config"
+)
+
+// ldapTimeout bounds a bind, from dialing to reading the response
+const ldapTimeout = 10 * time.Second
+
+// LDAP result code of a successful operation
+const ldapSuccess = 0
+
+// ldapProvider authenticates credentials with a simple bind against an LDAP directory over
+// TLS. Publishers send base64("user:password") as their bearer token, or HTTP Basic
+// credentials.
+type ldapProvider struct {
+	address string
+	// bindDN is a DN template whose %s is replaced by the escaped user name
+	bindDN string
+}
+
+func newLDAPProvider(cfg *config.Config) (*ldapProvider, error) {
+	if cfg.AuthLDAPURL == "" || !strings.Contains(cfg.AuthLDAPBindDN, "%s") {
+		return nil, errors.New("ldap auth provider requires MCP_REGISTRY_AUTH_LDAP_URL and a MCP_REGISTRY_AUTH_LDAP_BIND_DN containing %s")
+	}
+	u, err := url.Parse(cfg.AuthLDAPURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	// A simple bind sends the password as is, so plain ldap:// would expose it on the wire
+	if u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("invalid LDAP URL scheme %q: expected ldaps", u.Scheme)
+	}
+	provider := &ldapProvider{address: u.Host, bindDN: cfg.AuthLDAPBindDN}
+	if u.Port() == "" {
+		provider.address = net.JoinHostPort(u.Hostname(), "636")
+	}
+	return provider, nil
+}
+
+// Authenticate binds as the user named in token and returns the user name as login
+func (p *ldapProvider) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	token = strings.TrimSpace(token)
+	if len(token) > 6 && strings.EqualFold(token[:6], "basic ") {
+		token = token[6:]
+	}
+	decoded, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, password, ok := strings.Cut(string(decoded), ":")
+	// An empty password would perform an unauthenticated bind, which always succeeds
+	if !ok || user == "" || password == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if err := p.bind(ctx, fmt.Sprintf(p.bindDN, escapeDN(user)), password); err != nil {
+		return nil, err
+	}
+	return &Principal{Login: user}, nil
+}
+
+// bind performs an LDAPv3 simple bind and unbinds again
+func (p *ldapProvider) bind(ctx context.Context, dn, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, ldapTimeout)
+	defer cancel()
+
+	host, _, _ := net.SplitHostPort(p.address)
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return fmt.Errorf("%w: error connecting to LDAP server: %v", ErrAuthFailed, err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	// BindRequest ::= [APPLICATION 0] SEQUENCE { version, name, simple [0] password }
+	request := berTLV(0x60, berInt(3), berTLV(0x04, []byte(dn)), berTLV(0x80, []byte(password)))
+	if _, err := conn.Write(berTLV(0x30, berInt(1), request)); err != nil {
+		return fmt.Errorf("%w: error sending LDAP bind: %v", ErrAuthFailed, err)
+	}
+
+	code, err := readBindResult(bufio.NewReader(conn))
+	if err != nil {
+		return fmt.Errorf("%w: invalid LDAP response: %v", ErrAuthFailed, err)
+	}
+	// UnbindRequest ::= [APPLICATION 2] NULL
+	_, _ = conn.Write(berTLV(0x30, berInt(2), []byte{0x42, 0x00}))
+	if code != ldapSuccess {
+		return fmt.Errorf("%w: LDAP bind failed with result code %d", ErrInvalidToken, code)
+	}
+	return nil
+}
+
+// readBindResult reads an LDAPMessage holding a BindResponse and returns its result code
+func readBindResult(r *bufio.Reader) (int, error) {
+	tag, message, err := readTLV(r)
+	if err != nil {
+		return 0, err
+	}
+	if tag != 0x30 {
+		return 0, errors.New("expected an LDAP message")
+	}
+	body := bufio.NewReader(strings.NewReader(string(message)))
+	if tag, _, err = readTLV(body); err != nil || tag != 0x02 {
+		return 0, errors.New("expected a message ID")
+	}
+	tag, response, err := readTLV(body)
+	if err != nil || tag != 0x61 {
+		return 0, errors.New("expected a bind response")
+	}
+	tag, code, err := readTLV(bufio.NewReader(strings.NewReader(string(response))))
+	if err != nil || tag != 0x0a || len(code) == 0 {
+		return 0, errors.New("expected a result code")
+	}
+	result := 0
+	for _, b := range code {
+		result = result<<8 | int(b)
+	}
+	return result, nil
+}
+
+// readTLV reads one BER element with a definite length
+func readTLV(r *bufio.Reader) (byte, []byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := int(first)
+	if first&0x80 != 0 {
+		n := int(first & 0x7f)
+		if n == 0 || n > 3 {
+			return 0, nil, errors.New("unsupported length encoding")
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return 0, nil, err
+	}
+	return tag, value, nil
+}
+
+// berTLV encodes an element from its tag and the concatenated encodings of its content
+func berTLV(tag byte, content ...[]byte) []byte {
+	var value []byte
+	for _, c := range content {
+		value = append(value, c...)
+	}
+	out := []byte{tag}
+	switch n := len(value); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	default:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	}
+	return append(out, value...)
+}
+
+// berInt encodes a small non-negative INTEGER
+func berInt(n byte) []byte {
+	return []byte{0x02, 0x01, n}
+}
+
+// escapeDN escapes a user name for use as an attribute value in a DN, per RFC 4514
+func escapeDN(value string) string {
+	var b strings.Builder
+	for i, c := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, c),
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(value)-1 && c == ' ':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c == 0:
+			b.WriteString(`\00`)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"testing"
+
+	"registry/internal/config"
+)
+
+func TestEscapeDN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"alice", "alice"},
+		{"doe, john", `doe\, john`},
+		{`a+b"c\d<e>f;g=h`, `a\+b\"c\\d\<e\>f\;g\=h`},
+		{"#admin", `\#admin`},
+		{"a#b", "a#b"},
+		{" alice ", `\ alice\ `},
+		{"a b", "a b"},
+		{"a\x00b", `a\00b`},
+		{"jürgen", "jürgen"},
+	}
+	for _, tt := range tests {
+		if got := escapeDN(tt.in); got != tt.want {
+			t.Errorf("escapeDN(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewLDAPProvider(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"ldaps://ldap.example.com", "ldap.example.com:636", false},
+		{"ldaps://ldap.example.com:1636", "ldap.example.com:1636", false},
+		{"ldap://ldap.example.com", "", true},
+		{"https://ldap.example.com", "", true},
+	}
+	for _, tt := range tests {
+		p, err := newLDAPProvider(&config.Config{AuthLDAPURL: tt.url, AuthLDAPBindDN: "uid=%s,ou=people,dc=example,dc=com"})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("newLDAPProvider(%s) succeeded, want an error", tt.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("newLDAPProvider(%s) error = %v", tt.url, err)
+		}
+		if p.address != tt.want {
+			t.Errorf("newLDAPProvider(%s).address = %s, want %s", tt.url, p.address, tt.want)
+		}
+	}
+}
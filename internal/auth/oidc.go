@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"registry/internal/config"
+)
+
+const (
+	// jwksMaxAge is how long signing keys are used before they are fetched again
+	jwksMaxAge = time.Hour
+	// jwksMinRefresh bounds how often an unknown key ID triggers a refetch
+	jwksMinRefresh = time.Minute
+	// jwksRetryBackoff is how long a failed fetch of the key set is reported again rather
+	// than retried, so an unreachable provider is not asked once per request
+	jwksRetryBackoff = 10 * time.Second
+	// oidcMaxResponse bounds the discovery document and key set read from the provider
+	oidcMaxResponse = 1 << 20
+	// clockSkew is the leeway allowed on token expiry and not-before times
+	clockSkew = time.Minute
+)
+
+// oidcProvider authenticates ID or access tokens issued as JWTs by an OpenID Connect
+// provider. Signing keys are discovered from the issuer and cached.
+type oidcProvider struct {
+	issuer        string
+	audience      string
+	usernameClaim string
+	groupsClaim   string
+	client        *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+	// fetchErr is the error of the last failed fetch, made at failedAt
+	fetchErr error
+	failedAt time.Time
+}
+
+func newOIDCProvider(cfg *config.Config) (*oidcProvider, error) {
+	if cfg.AuthOIDCIssuer == "" || cfg.AuthOIDCAudience == "" {
+		return nil, errors.New("oidc auth provider requires MCP_REGISTRY_AUTH_OIDC_ISSUER and MCP_REGISTRY_AUTH_OIDC_AUDIENCE")
+	}
+	return &oidcProvider{
+		issuer:        strings.TrimSuffix(cfg.AuthOIDCIssuer, "/"),
+		audience:      cfg.AuthOIDCAudience,
+		usernameClaim: cfg.AuthOIDCUsernameClaim,
+		groupsClaim:   cfg.AuthOIDCGroupsClaim,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Authenticate verifies the signature and claims of a JWT and returns its subject
+func (p *oidcProvider) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := p.checkClaims(claims, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	principal := &Principal{}
+	principal.Login, _ = claims[p.usernameClaim].(string)
+	if principal.Login == "" {
+		principal.Login, _ = claims["sub"].(string)
+	}
+	if principal.Login == "" {
+		return nil, fmt.Errorf("%w: token has no %s or sub claim", ErrInvalidToken, p.usernameClaim)
+	}
+	if groups, ok := claims[p.groupsClaim].([]interface{}); ok {
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				principal.Groups = append(principal.Groups, name)
+			}
+		}
+	}
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		principal.AuthTime = time.Unix(int64(authTime), 0)
+	}
+	if methods, ok := claims["amr"].([]interface{}); ok {
+		for _, method := range methods {
+			if name, ok := method.(string); ok {
+				principal.Methods = append(principal.Methods, name)
+			}
+		}
+	}
+	return principal, nil
+}
+
+// checkClaims verifies the issuer, audience and validity period of a token
+func (p *oidcProvider) checkClaims(claims map[string]interface{}, now time.Time) error {
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != p.issuer {
+		return fmt.Errorf("issued by %q", iss)
+	}
+
+	audienceOK := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		audienceOK = aud == p.audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == p.audience {
+				audienceOK = true
+			}
+		}
+	}
+	if !audienceOK {
+		return errors.New("issued for another audience")
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if now.Add(-clockSkew).After(time.Unix(int64(exp), 0)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not yet valid")
+	}
+	return nil
+}
+
+// key returns the signing key with the given ID, refreshing the key set when it is stale
+// or does not know the ID
+func (p *oidcProvider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.keys[kid]
+	age := time.Since(p.fetched)
+	if ok && age < jwksMaxAge {
+		return key, nil
+	}
+	if !ok && p.keys != nil && age < jwksMinRefresh {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+
+	if ok && time.Since(p.failedAt) < jwksRetryBackoff {
+		return key, nil
+	}
+	if p.fetchErr != nil && time.Since(p.failedAt) < jwksRetryBackoff {
+		return nil, fmt.Errorf("%w: %v", ErrAuthFailed, p.fetchErr)
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		p.fetchErr, p.failedAt = err, time.Now()
+		if ok {
+			// Keep using a known key while the provider is unreachable
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrAuthFailed, err)
+	}
+	p.keys, p.fetched, p.fetchErr = keys, time.Now(), nil
+
+	if key, ok = p.keys[kid]; !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// fetchKeys discovers the JWKS URI of the issuer and loads its RSA and EC keys
+func (p *oidcProvider) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("error discovering OIDC configuration: %w", err)
+	}
+	// OpenID Connect Discovery requires the document to name the issuer it was fetched for
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("OIDC configuration names issuer %q", discovery.Issuer)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC configuration has no jwks_uri")
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("error fetching signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil || len(e) > 4 {
+				continue
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[jwk.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
+
+// getJSON fetches url and decodes up to oidcMaxResponse bytes of its JSON body into v
+func (p *oidcProvider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, oidcMaxResponse)).Decode(v)
+}
+
+// verifySignature checks a JWS signature over signed. Only asymmetric algorithms are
+// accepted, so a token cannot be signed with a public key as HMAC secret.
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	digest := digestOf(hash, []byte(signed))
+
+	switch alg[0] {
+	case 'R', 'P':
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		if alg[0] == 'P' {
+			return rsa.VerifyPSS(rsaKey, hash, digest, signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	default:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key type does not match algorithm")
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("malformed signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	}
+}
+
+func digestOf(hash crypto.Hash, data []byte) []byte {
+	switch hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384(data)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"registry/internal/config"
+)
+
+const testAudience = "registry"
+
+// testIssuer serves a discovery document and a key set holding an RSA key "rsa" and an
+// ECDSA key "ec", counting how often the key set is fetched
+type testIssuer struct {
+	server     *httptest.Server
+	rsaKey     *rsa.PrivateKey
+	ecKey      *ecdsa.PrivateKey
+	issuer     string
+	keyFetches atomic.Int32
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": ti.issuer, "jwks_uri": ti.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		ti.keyFetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		}})
+	})
+	ti.server = httptest.NewServer(mux)
+	ti.issuer = ti.server.URL
+	t.Cleanup(ti.server.Close)
+	return ti
+}
+
+func (ti *testIssuer) provider(t *testing.T) *oidcProvider {
+	t.Helper()
+	p, err := newOIDCProvider(&config.Config{
+		AuthOIDCIssuer:        ti.server.URL,
+		AuthOIDCAudience:      testAudience,
+		AuthOIDCUsernameClaim: "sub",
+		AuthOIDCGroupsClaim:   "groups",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// claims returns valid claims for the test issuer with overrides applied; a nil override
+// removes the claim
+func (ti *testIssuer) claims(overrides map[string]interface{}) map[string]interface{} {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": ti.issuer,
+		"aud": testAudience,
+		"sub": "alice",
+		"exp": now.Add(time.Hour).Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+// sign builds a JWT with the given header algorithm and key ID. The signature is made with
+// the key matching alg: RS256 and ES256 with the issuer keys, HS256 with the RSA modulus as
+// secret, and none without a signature.
+func (ti *testIssuer) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(map[string]string{"alg": alg, "kid": kid}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, ti.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, ti.ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "HS256":
+		mac := hmac.New(sha256.New, ti.rsaKey.N.Bytes())
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCAuthenticate(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := ti.provider(t)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		alg     string
+		kid     string
+		claims  map[string]interface{}
+		wantErr bool
+	}{
+		{"rsa", "RS256", "rsa", nil, false},
+		{"ecdsa", "ES256", "ec", nil, false},
+		{"audience list", "RS256", "rsa", map[string]interface{}{"aud": []string{"other", testAudience}}, false},
+		{"within clock skew", "RS256", "rsa", map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}, false},
+		{"alg none", "none", "rsa", nil, true},
+		{"hmac with public key", "HS256", "rsa", nil, true},
+		{"rsa algorithm with ec key", "RS256", "ec", nil, true},
+		{"ec algorithm with rsa key", "ES256", "rsa", nil, true},
+		{"expired", "RS256", "rsa", map[string]interface{}{"exp": now.Add(-time.Hour).Unix()}, true},
+		{"no expiry", "RS256", "rsa", map[string]interface{}{"exp": nil}, true},
+		{"not yet valid", "RS256", "rsa", map[string]interface{}{"nbf": now.Add(time.Hour).Unix()}, true},
+		{"other audience", "RS256", "rsa", map[string]interface{}{"aud": "other"}, true},
+		{"no audience", "RS256", "rsa", map[string]interface{}{"aud": nil}, true},
+		{"other issuer", "RS256", "rsa", map[string]interface{}{"iss": "https://evil.example"}, true},
+		{"no subject", "RS256", "rsa", map[string]interface{}{"sub": nil}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := ti.sign(t, tt.alg, tt.kid, ti.claims(tt.claims))
+			principal, err := p.Authenticate(context.Background(), token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Authenticate() error = %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Login != "alice" {
+				t.Errorf("Login = %q, want alice", principal.Login)
+			}
+		})
+	}
+
+	t.Run("tampered claims", func(t *testing.T) {
+		token := ti.sign(t, "RS256", "rsa", ti.claims(nil))
+		parts := strings.Split(token, ".")
+		forged, _ := json.Marshal(ti.claims(map[string]interface{}{"sub": "mallory"}))
+		parts[1] = base64.RawURLEncoding.EncodeToString(forged)
+		if _, err := p.Authenticate(context.Background(), strings.Join(parts, ".")); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate() error = %v, want %v", err, ErrInvalidToken)
+		}
+	})
+}
+
+func TestOIDCUnknownKeyRefetch(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := ti.provider(t)
+	ctx := context.Background()
+
+	if _, err := p.Authenticate(ctx, ti.sign(t, "RS256", "rsa", ti.claims(nil))); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := p.Authenticate(ctx, ti.sign(t, "RS256", "unknown", ti.claims(nil))); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Authenticate() with unknown key error = %v, want %v", err, ErrInvalidToken)
+		}
+	}
+	if got := ti.keyFetches.Load(); got != 1 {
+		t.Fatalf("key set fetched %d times within jwksMinRefresh, want 1", got)
+	}
+
+	p.mu.Lock()
+	p.fetched = time.Now().Add(-jwksMinRefresh)
+	p.mu.Unlock()
+	for i := 0; i < 3; i++ {
+		_, _ = p.Authenticate(ctx, ti.sign(t, "RS256", "unknown", ti.claims(nil)))
+	}
+	if got := ti.keyFetches.Load(); got != 2 {
+		t.Errorf("key set fetched %d times after jwksMinRefresh, want 2", got)
+	}
+}
+
+func TestOIDCIssuerMismatch(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := ti.provider(t)
+	ti.issuer = "https://evil.example"
+
+	_, err := p.Authenticate(context.Background(), ti.sign(t, "RS256", "rsa", ti.claims(map[string]interface{}{"iss": ti.server.URL})))
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrAuthFailed)
+	}
+	if got := ti.keyFetches.Load(); got != 0 {
+		t.Errorf("key set fetched %d times, want 0", got)
+	}
+}
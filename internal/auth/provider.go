@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"registry/internal/config"
+)
+
+// Provider names selected by MCP_REGISTRY_AUTH_PROVIDER
+const (
+	ProviderGitHub = "github"
+	ProviderOIDC   = "oidc"
+	ProviderLDAP   = "ldap"
+	ProviderStatic = "static"
+)
+
+// Principal is the identity a token authenticates
+type Principal struct {
+	Login string
+	// Groups are the groups the provider reports the identity to be a member of
+	Groups []string
+	// AuthTime is when the user last authenticated, and Methods how, as OIDC amr values.
+	// Providers that do not report them leave them empty.
+	AuthTime time.Time
+	Methods  []string
+}
+
+// Provider authenticates bearer tokens against an identity system other than GitHub, so
+// enterprises can publish with the accounts of their own identity provider
+type Provider interface {
+	// Authenticate returns the identity a token was issued to
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// newProvider returns the provider selected by cfg, or nil for GitHub
+//
+//nolint:ireturn // Factory function intentionally returns interface for dependency injection
+func newProvider(cfg *config.Config) (Provider, error) {
+	switch strings.ToLower(cfg.AuthProvider) {
+	case "", ProviderGitHub:
+		return nil, nil
+	case ProviderOIDC:
+		return newOIDCProvider(cfg)
+	case ProviderLDAP:
+		return newLDAPProvider(cfg)
+	case ProviderStatic:
+		return newStaticProvider(cfg.AuthStaticTokensFile)
+	default:
+		return nil, fmt.Errorf("unknown auth provider %q: expected github, oidc, ldap or static", cfg.AuthProvider)
+	}
+}
+
+// namespaceOf returns the namespace of a server name, the part before the /
+func namespaceOf(serverName string) string {
+	namespace, _, _ := strings.Cut(serverName, "/")
+	return namespace
+}
+
+// mayPublish reports whether principal may publish serverName. The login or one of the
+// groups must equal the namespace, or be granted the namespace in grants. io.github
+// namespaces belong to GitHub accounts, which other providers cannot vouch for, so they
+// are only published through grants.
+func mayPublish(principal *Principal, grants map[string][]string, serverName string) bool {
+	namespace := namespaceOf(serverName)
+	if namespace == "" {
+		return false
+	}
+	github := strings.HasPrefix(strings.ToLower(namespace), "io.github.")
+	for _, subject := range append([]string{principal.Login}, principal.Groups...) {
+		if !github && strings.EqualFold(subject, namespace) {
+			return true
+		}
+		for _, granted := range grants[strings.ToLower(subject)] {
+			if strings.EqualFold(granted, namespace) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseGrants maps lower case logins and groups to the namespaces granted to them, given
+// as |-separated lists
+func parseGrants(raw map[string]string) map[string][]string {
+	grants := make(map[string][]string, len(raw))
+	for subject, namespaces := range raw {
+		for _, namespace := range strings.Split(namespaces, "|") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				key := strings.ToLower(strings.TrimSpace(subject))
+				grants[key] = append(grants[key], namespace)
+			}
+		}
+	}
+	return grants
+}
+
+// validateWithProvider authenticates a publish request against the configured provider.
+// Server names outside io.github are accepted, since enterprise namespaces are not tied
+// to GitHub; io.github names need a grant.
+func (s *ServiceImpl) validateWithProvider(ctx context.Context, token, repoRef string) (bool, error) {
+	if token == "" {
+		return false, ErrAuthRequired
+	}
+	principal, err := s.provider.Authenticate(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	serverName := html.UnescapeString(repoRef)
+	if !mayPublish(principal, s.grants, serverName) {
+		return false, fmt.Errorf("%s is not allowed to publish under %s", principal.Login, namespaceOf(serverName))
+	}
+	return true, nil
+}
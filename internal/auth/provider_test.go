@@ -0,0 +1,33 @@
+package auth
+
+import "testing"
+
+func TestMayPublish(t *testing.T) {
+	grants := parseGrants(map[string]string{
+		"Alice":         "com.acme | org.example",
+		"platform-team": "io.github.acme",
+	})
+
+	tests := []struct {
+		principal  Principal
+		serverName string
+		want       bool
+	}{
+		{Principal{Login: "com.acme"}, "com.acme/server", true},
+		{Principal{Login: "alice"}, "com.acme/server", true},
+		{Principal{Login: "ALICE"}, "org.example/server", true},
+		{Principal{Login: "alice"}, "com.other/server", false},
+		{Principal{Login: "bob", Groups: []string{"platform-team"}}, "io.github.acme/server", true},
+		{Principal{Login: "bob", Groups: []string{"com.acme"}}, "com.acme/server", true},
+		// Other providers cannot vouch for GitHub accounts, so a matching login is not enough
+		{Principal{Login: "alice"}, "io.github.alice/server", false},
+		{Principal{Login: "io.github.alice"}, "io.github.alice/server", false},
+		{Principal{Login: "bob", Groups: []string{"acme"}}, "io.github.acme/server", false},
+		{Principal{Login: "alice"}, "/server", false},
+	}
+	for _, tt := range tests {
+		if got := mayPublish(&tt.principal, grants, tt.serverName); got != tt.want {
+			t.Errorf("mayPublish(%+v, %s) = %v, want %v", tt.principal, tt.serverName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"registry/internal/model"
+)
+
+// ErrNameReserved is returned when a server name matching MCP_REGISTRY_RESERVED_NAMES is
+// published without the admin token
+var ErrNameReserved = errors.New("server name is reserved")
+
+// ErrNameShadowsRoute is returned when a server name's slug is the path of another
+// endpoint under /v0/servers, which would leave the server unreachable by its slug
+var ErrNameShadowsRoute = errors.New("server name is the path of a registry endpoint")
+
+// reservedName is one pattern of MCP_REGISTRY_RESERVED_NAMES
+type reservedName struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// parseReservedNames compiles a comma-separated list of patterns in which * matches any
+// run of characters. Patterns are matched case-insensitively against the whole server
+// name. Patterns without a / also match the name after the namespace, so "official-*"
+// blocks "io.github.someone/official-filesystem" as well as "official-filesystem".
+func parseReservedNames(raw string) []reservedName {
+	var reserved []reservedName
+	for _, pattern := range strings.Split(raw, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		reserved = append(reserved, reservedName{
+			pattern: pattern,
+			re:      regexp.MustCompile("(?i)^" + expr + "$"),
+		})
+	}
+	return reserved
+}
+
+// matchReservedName returns the first pattern matching serverName as submitted or in the
+// normalized form it may be stored under
+func matchReservedName(reserved []reservedName, serverName string) (string, bool) {
+	for _, name := range []string{serverName, model.NormalizeName(serverName)} {
+		_, shortName, hasNamespace := strings.Cut(name, "/")
+		for _, r := range reserved {
+			if r.re.MatchString(name) {
+				return r.pattern, true
+			}
+			if hasNamespace && !strings.Contains(r.pattern, "/") && r.re.MatchString(shortName) {
+				return r.pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// CheckReservedName reports whether serverName is reserved. Only the admin token may
+// publish reserved names, so for any other token it returns ErrNameReserved; a reserved
+// name published with the admin token needs no other credentials. Names whose slug is
+// the path of an endpoint under /v0/servers are refused with ErrNameShadowsRoute whatever
+// the token.
+func (s *ServiceImpl) CheckReservedName(token, serverName string) (bool, error) {
+	if slug := model.Slug(serverName); slices.Contains(s.routeNames, slug) {
+		return true, fmt.Errorf("%w: %s has the slug %s", ErrNameShadowsRoute, serverName, slug)
+	}
+	pattern, reserved := matchReservedName(s.reserved, serverName)
+	if !reserved {
+		return false, nil
+	}
+	admin := s.config.AdminToken
+	if admin == "" || subtle.ConstantTimeCompare([]byte(token), []byte(admin)) != 1 {
+		return true, fmt.Errorf("%w: %s matches %s", ErrNameReserved, serverName, pattern)
+	}
+	return true, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+)
+
+// ErrTokenRevoked is returned for tokens that were revoked
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// RevocationChecker reports whether the token with a digest, see TokenDigest, was revoked
+type RevocationChecker interface {
+	TokenRevoked(digest string) (bool, error)
+}
+
+// revokingService rejects revoked tokens before they reach the wrapped service
+type revokingService struct {
+	Service
+	checker RevocationChecker
+}
+
+// WithRevocations returns a Service rejecting the tokens checker reports as revoked
+//
+//nolint:ireturn // Decorator intentionally returns interface for dependency injection
+func WithRevocations(service Service, checker RevocationChecker) Service {
+	return &revokingService{Service: service, checker: checker}
+}
+
+// ValidateAuth validates credentials that were not revoked
+func (s *revokingService) ValidateAuth(ctx context.Context, auth model.Authentication) (bool, error) {
+	if err := s.check(auth.Token); err != nil {
+		return false, err
+	}
+	return s.Service.ValidateAuth(ctx, auth)
+}
+
+// Identify resolves tokens that were not revoked
+func (s *revokingService) Identify(ctx context.Context, token string) (string, error) {
+	if err := s.check(token); err != nil {
+		return "", err
+	}
+	return s.Service.Identify(ctx, token)
+}
+
+func (s *revokingService) check(token string) error {
+	if token == "" {
+		return nil
+	}
+	revoked, err := s.checker.TokenRevoked(TokenDigest(token))
+	if err != nil {
+		return fmt.Errorf("%w: error checking token revocation: %v", ErrAuthFailed, err)
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSecondFactorRequired is returned for destructive operations attempted with a token
+// that does not prove a recent second factor
+var ErrSecondFactorRequired = errors.New("a recent second factor is required")
+
+// secondFactorMethods are the RFC 8176 amr values that show a second factor was used
+var secondFactorMethods = map[string]bool{
+	"mfa": true,
+	"mca": true,
+	"otp": true,
+	"hwk": true,
+	"sc":  true,
+	"sms": true,
+}
+
+// RequireSecondFactor enforces MCP_REGISTRY_AUTH_2FA_REQUIRED on a token the caller has
+// already authorized. Only OIDC tokens report how and when the user authenticated, so
+// tokens of other providers never satisfy the policy. The admin token is exempt, as it
+// belongs to the operators rather than a user who could step up.
+func (s *ServiceImpl) RequireSecondFactor(ctx context.Context, token string) error {
+	if !s.config.Auth2FARequired {
+		return nil
+	}
+	if token == "" {
+		return ErrAuthRequired
+	}
+	if admin := s.config.AdminToken; admin != "" && subtle.ConstantTimeCompare([]byte(token), []byte(admin)) == 1 {
+		return nil
+	}
+	if s.provider == nil {
+		return fmt.Errorf("%w: GitHub tokens do not report a second factor", ErrSecondFactorRequired)
+	}
+	principal, err := s.provider.Authenticate(ctx, token)
+	if err != nil {
+		return err
+	}
+	return checkSecondFactor(principal, s.config.Auth2FAMaxAge, time.Now())
+}
+
+// checkSecondFactor verifies that principal authenticated with a second factor within maxAge
+func checkSecondFactor(principal *Principal, maxAge time.Duration, now time.Time) error {
+	usedSecondFactor := false
+	for _, method := range principal.Methods {
+		usedSecondFactor = usedSecondFactor || secondFactorMethods[method]
+	}
+	switch {
+	case !usedSecondFactor:
+		return fmt.Errorf("%w: token has no second factor amr claim", ErrSecondFactorRequired)
+	case principal.AuthTime.IsZero():
+		return fmt.Errorf("%w: token has no auth_time claim", ErrSecondFactorRequired)
+	case now.Sub(principal.AuthTime) > maxAge+clockSkew:
+		return fmt.Errorf("%w: last authenticated %s ago, re-authenticate", ErrSecondFactorRequired,
+			now.Sub(principal.AuthTime).Truncate(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"registry/internal/config"
+)
+
+func TestCheckSecondFactor(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	maxAge := 15 * time.Minute
+
+	tests := []struct {
+		name      string
+		principal Principal
+		wantErr   bool
+	}{
+		{"recent second factor", Principal{Methods: []string{"pwd", "otp"}, AuthTime: now.Add(-5 * time.Minute)}, false},
+		{"within clock skew", Principal{Methods: []string{"hwk"}, AuthTime: now.Add(-maxAge - clockSkew/2)}, false},
+		{"no amr", Principal{AuthTime: now}, true},
+		{"password only", Principal{Methods: []string{"pwd"}, AuthTime: now}, true},
+		{"no auth_time", Principal{Methods: []string{"mfa"}}, true},
+		{"stale auth_time", Principal{Methods: []string{"mfa"}, AuthTime: now.Add(-time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSecondFactor(&tt.principal, maxAge, now)
+			if tt.wantErr && !errors.Is(err, ErrSecondFactorRequired) {
+				t.Errorf("checkSecondFactor() error = %v, want %v", err, ErrSecondFactorRequired)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("checkSecondFactor() error = %v", err)
+			}
+		})
+	}
+}
+
+// staticPrincipal authenticates every token as the same principal
+type staticPrincipal Principal
+
+func (p *staticPrincipal) Authenticate(context.Context, string) (*Principal, error) {
+	principal := Principal(*p)
+	return &principal, nil
+}
+
+func TestRequireSecondFactor(t *testing.T) {
+	cfg := &config.Config{AdminToken: "admin-secret", Auth2FARequired: true, Auth2FAMaxAge: 15 * time.Minute}
+	ctx := context.Background()
+
+	oidc := &ServiceImpl{config: cfg, provider: &staticPrincipal{Login: "alice", Methods: []string{"pwd"}, AuthTime: time.Now()}}
+	github := &ServiceImpl{config: cfg}
+
+	tests := []struct {
+		name    string
+		service *ServiceImpl
+		token   string
+		want    error
+	}{
+		{"admin token", oidc, "admin-secret", nil},
+		{"admin token without provider", github, "admin-secret", nil},
+		{"no token", oidc, "", ErrAuthRequired},
+		{"no second factor", oidc, "user-token", ErrSecondFactorRequired},
+		{"github token", github, "gho_token", ErrSecondFactorRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.service.RequireSecondFactor(ctx, tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("RequireSecondFactor() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	disabled := &ServiceImpl{config: &config.Config{}}
+	if err := disabled.RequireSecondFactor(ctx, "gho_token"); err != nil {
+		t.Errorf("RequireSecondFactor() with the policy disabled error = %v", err)
+	}
+}
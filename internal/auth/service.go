@@ -11,21 +11,38 @@ import (
 type ServiceImpl struct {
 	config     *config.Config
 	githubAuth *GitHubDeviceAuth
+	// provider replaces GitHub when another identity provider is configured
+	provider Provider
+	grants   map[string][]string
+	// reserved are the names only the admin token may publish
+	reserved []reservedName
+	// routeNames are the paths under /v0/servers that belong to endpoints rather than servers
+	routeNames []string
 }
 
-// NewAuthService creates a new authentication service
+// NewAuthService creates a new authentication service using the provider selected by
+// MCP_REGISTRY_AUTH_PROVIDER. Server names whose slug is one of routeNames are refused.
 //
 //nolint:ireturn // Factory function intentionally returns interface for dependency injection
-func NewAuthService(cfg *config.Config) Service {
+func NewAuthService(cfg *config.Config, routeNames []string) (Service, error) {
 	githubConfig := GitHubOAuthConfig{
 		ClientID:     cfg.GithubClientID,
 		ClientSecret: cfg.GithubClientSecret,
 	}
 
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ServiceImpl{
 		config:     cfg,
 		githubAuth: NewGitHubDeviceAuth(githubConfig),
-	}
+		provider:   provider,
+		grants:     parseGrants(cfg.AuthGrants),
+		reserved:   parseReservedNames(cfg.ReservedNames),
+		routeNames: routeNames,
+	}, nil
 }
 
 func (s *ServiceImpl) StartAuthFlow(_ context.Context, _ model.AuthMethod,
@@ -41,6 +58,10 @@ func (s *ServiceImpl) CheckAuthStatus(_ context.Context, _ string) (string, erro
 
 // ValidateAuth validates authentication credentials
 func (s *ServiceImpl) ValidateAuth(ctx context.Context, auth model.Authentication) (bool, error) {
+	if s.provider != nil {
+		return s.validateWithProvider(ctx, auth.Token, auth.RepoRef)
+	}
+
 	// If authentication is required but not provided
 	if auth.Method == "" || auth.Method == model.AuthMethodNone {
 		return false, ErrAuthRequired
@@ -56,3 +77,18 @@ func (s *ServiceImpl) ValidateAuth(ctx context.Context, auth model.Authenticatio
 		return false, ErrUnsupportedAuthMethod
 	}
 }
+
+// Identify resolves a token to the login of its owner
+func (s *ServiceImpl) Identify(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", ErrAuthRequired
+	}
+	if s.provider != nil {
+		principal, err := s.provider.Authenticate(ctx, token)
+		if err != nil {
+			return "", err
+		}
+		return principal.Login, nil
+	}
+	return s.githubAuth.Login(ctx, token)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// staticProvider authenticates tokens listed in a file, for small deployments and CI
+// systems without an identity provider
+type staticProvider struct {
+	// principals maps the hex sha256 of each token to its identity
+	principals map[string]*Principal
+}
+
+// newStaticProvider loads a token file. Each non-empty line that is not a # comment holds
+// a token, the login it authenticates and, optionally, a comma separated list of groups:
+//
+//	sha256:4f2a... ci-bot acme,platform
+//
+// Tokens are either given as is or, to keep them out of the file, as sha256: and the hex
+// digest of the token.
+func newStaticProvider(path string) (*staticProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("static auth provider requires MCP_REGISTRY_AUTH_STATIC_TOKENS_FILE")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening static tokens file: %w", err)
+	}
+	defer file.Close()
+
+	provider := &staticProvider{principals: make(map[string]*Principal)}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("static tokens file line %d: expected a token, a login and optional groups", line)
+		}
+
+		digest, hashed := strings.CutPrefix(fields[0], "sha256:")
+		if hashed {
+			digest = strings.ToLower(digest)
+			if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("static tokens file line %d: invalid sha256 digest", line)
+			}
+		} else {
+			digest = TokenDigest(fields[0])
+		}
+
+		principal := &Principal{Login: fields[1]}
+		if len(fields) == 3 {
+			for _, group := range strings.Split(fields[2], ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					principal.Groups = append(principal.Groups, group)
+				}
+			}
+		}
+		provider.principals[digest] = principal
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading static tokens file: %w", err)
+	}
+	return provider, nil
+}
+
+// Authenticate looks up the identity of token
+func (p *staticProvider) Authenticate(_ context.Context, token string) (*Principal, error) {
+	principal, ok := p.principals[TokenDigest(token)]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return principal, nil
+}
+
+// TokenDigest returns the hex sha256 digest identifying a token without revealing it
+func TokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTokensFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStaticProvider(t *testing.T) {
+	path := writeTokensFile(t, `
+# CI tokens
+plain-token   ci-bot
+sha256:`+TokenDigest("hashed-token")+` deployer com.acme,platform,
+`)
+	p, err := newStaticProvider(path)
+	if err != nil {
+		t.Fatalf("newStaticProvider() error = %v", err)
+	}
+
+	tests := []struct {
+		token string
+		want  *Principal
+	}{
+		{"plain-token", &Principal{Login: "ci-bot"}},
+		{"hashed-token", &Principal{Login: "deployer", Groups: []string{"com.acme", "platform"}}},
+		{"sha256:" + TokenDigest("hashed-token"), nil},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		got, err := p.Authenticate(context.Background(), tt.token)
+		if tt.want == nil {
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Authenticate(%s) error = %v, want %v", tt.token, err, ErrInvalidToken)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Authenticate(%s) error = %v", tt.token, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Authenticate(%s) = %+v, want %+v", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestStaticProviderInvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing login", "token\n"},
+		{"too many fields", "token login groups extra\n"},
+		{"short digest", "sha256:abcd login\n"},
+		{"invalid digest", "sha256:" + TokenDigest("x")[:62] + "zz login\n"},
+	}
+	for _, tt := range tests {
+		if _, err := newStaticProvider(writeTokensFile(t, tt.content)); err == nil {
+			t.Errorf("%s: newStaticProvider() succeeded, want an error", tt.name)
+		}
+	}
+	if _, err := newStaticProvider(""); err == nil {
+		t.Error("newStaticProvider() without a path succeeded, want an error")
+	}
+}
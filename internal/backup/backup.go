@@ -0,0 +1,105 @@
+// Package backup writes snapshots of the registry to local files
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+const (
+	filePrefix = "registry-"
+	fileSuffix = ".json"
+)
+
+// Write exports every stored version into a new file in dir and then deletes all but the
+// newest retain backups; retain zero keeps every backup. The file is a JSON array of server
+// details as served by GET /v0/export, so it can be restored as a registry seed file.
+func Write(registry service.RegistryService, dir string, retain int) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+	path := filepath.Join(dir, filePrefix+time.Now().UTC().Format("20060102T150405Z")+fileSuffix)
+
+	// Write to a temporary file first so a failed backup never looks complete
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return fmt.Errorf("error creating backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	count, err := export(registry, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+	log.Printf("Backup: wrote %d versions to %s", count, path)
+
+	if retain > 0 {
+		prune(dir, retain)
+	}
+	return nil
+}
+
+// export streams every version into f as a JSON array
+func export(registry service.RegistryService, f *os.File) (int, error) {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	count := 0
+	if _, err := w.WriteString("["); err != nil {
+		return 0, err
+	}
+	err := registry.Export(nil, false, func(entry *model.ServerDetail) error {
+		if count > 0 {
+			if _, err := w.WriteString(","); err != nil {
+				return err
+			}
+		}
+		count++
+		return enc.Encode(entry)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.WriteString("]\n"); err != nil {
+		return 0, err
+	}
+	return count, w.Flush()
+}
+
+// prune deletes the oldest backups in dir beyond the newest retain
+func prune(dir string, retain int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("Backup: failed to list %s: %v", dir, err)
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			names = append(names, name)
+		}
+	}
+	// Names embed the UTC time, so they sort chronologically
+	sort.Strings(names)
+	for len(names) > retain {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			log.Printf("Backup: failed to delete %s: %v", names[0], err)
+		}
+		names = names[1:]
+	}
+}
@@ -0,0 +1,65 @@
+// Package badge renders status badges as SVG in the flat style of shields.io, so they sit
+// alongside other shields in a project's README
+package badge
+
+import (
+	"fmt"
+	"html"
+	"math"
+)
+
+// ContentType is the media type of a rendered badge
+const ContentType = "image/svg+xml"
+
+// Colors of the message half of a badge, from the shields.io palette
+const (
+	ColorBlue = "#007ec6"
+	ColorRed  = "#e05d44"
+)
+
+// labelColor is the background of the label half of a badge
+const labelColor = "#555"
+
+// padding is the horizontal space around the text of each half
+const padding = 10
+
+// Render returns a badge reading label on the left and message on the right, over color
+func Render(label, message, color string) []byte {
+	labelWidth := textWidth(label) + padding
+	messageWidth := textWidth(message) + padding
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[3]s: %[4]s">`+
+		`<title>%[3]s: %[4]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="%[8]s"/><rect x="%[2]d" width="%[5]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%.1[7]f" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%.1[7]f" y="14">%[3]s</text>`+
+		`<text x="%.1[9]f" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%.1[9]f" y="14">%[4]s</text>`+
+		`</g></svg>`,
+		width, labelWidth, label, message, messageWidth, html.EscapeString(color),
+		float64(labelWidth)/2, labelColor, float64(labelWidth)+float64(messageWidth)/2))
+}
+
+// textWidth estimates the rendered width of s in 11px Verdana, which badges are laid out
+// for. Browsers substitute a similar font where Verdana is missing.
+func textWidth(s string) int {
+	var width float64
+	for _, r := range s {
+		switch {
+		case r == 'i' || r == 'l' || r == 'j' || r == '.' || r == ',' || r == ':' || r == ';' || r == '\'' || r == '|' || r == '!':
+			width += 3.5
+		case r == ' ' || r == 'f' || r == 't' || r == 'r' || r == 'I' || r == '-' || r == '(' || r == ')' || r == '/':
+			width += 4.8
+		case r == 'm' || r == 'w' || r == 'M' || r == 'W' || r == '@' || r == '%':
+			width += 10.5
+		case r >= 'A' && r <= 'Z':
+			width += 7.8
+		default:
+			width += 7
+		}
+	}
+	return int(math.Ceil(width))
+}
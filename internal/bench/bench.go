@@ -0,0 +1,211 @@
+// Package bench measures how a database backend performs on a synthetic registry, so
+// operators can compare backends on the operations the API issues most
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/query"
+)
+
+// Options size a benchmark run
+type Options struct {
+	// Entries is the number of versions published before reads are measured
+	Entries int
+	// Operations is the number of list and search requests measured each
+	Operations int
+	// Concurrency is the number of requests in flight at once
+	Concurrency int
+}
+
+// DefaultOptions load a registry of the size the benchmark is meant to compare backends at
+var DefaultOptions = Options{Entries: 50000, Operations: 2000, Concurrency: 8}
+
+// Result summarizes the latencies of one operation
+type Result struct {
+	Backend    string        `json:"backend"`
+	Operation  string        `json:"operation"`
+	Count      int           `json:"count"`
+	Errors     int           `json:"errors"`
+	Elapsed    time.Duration `json:"elapsed"`
+	Throughput float64       `json:"throughput"`
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+}
+
+// ErrNotEmpty is returned by Run when the database already holds versions
+var ErrNotEmpty = errors.New("benchmarks need an empty database")
+
+// pageSize is the default page size of the listing API
+const pageSize = 30
+
+// words make up the synthetic descriptions and the search terms
+var words = []string{
+	"filesystem", "github", "weather", "postgres", "slack", "browser", "calendar", "search",
+	"memory", "docker", "kubernetes", "notion", "jira", "email", "maps", "translate",
+	"spreadsheet", "git", "sqlite", "redis", "stripe", "figma", "linear", "sentry",
+}
+
+// Dataset returns n synthetic versions, each of a distinct server spread over a few hundred
+// namespaces. The same n always yields the same names and descriptions.
+func Dataset(n int) []*model.ServerDetail {
+	rng := rand.New(rand.NewPCG(uint64(n), 1))
+	entries := make([]*model.ServerDetail, n)
+	for i := range entries {
+		org := fmt.Sprintf("bench-org-%d", i%500)
+		description := make([]string, 6)
+		for j := range description {
+			description[j] = words[rng.IntN(len(words))]
+		}
+		entries[i] = &model.ServerDetail{
+			Server: model.Server{
+				Name:        fmt.Sprintf("io.github.%s/%s-%d", org, words[i%len(words)], i),
+				Description: "MCP server for " + strings.Join(description, " "),
+				Repository: model.Repository{
+					URL:    fmt.Sprintf("https://github.com/%s/server-%d", org, i),
+					Source: "github",
+					ID:     fmt.Sprintf("%d", i),
+				},
+				VersionDetail: model.VersionDetail{Version: "1.0.0"},
+			},
+		}
+	}
+	return entries
+}
+
+// Run publishes the dataset into db, which must be empty, then measures listing pages in
+// ID order and searching by description term. It returns a result per operation, labelled
+// with backend.
+func Run(ctx context.Context, backend string, db database.Database, opts Options) ([]Result, error) {
+	count, err := db.Count(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("error counting existing versions: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("%w: it holds %d versions", ErrNotEmpty, count)
+	}
+
+	entries := Dataset(opts.Entries)
+	create := measure(ctx, len(entries), opts.Concurrency, func(i int) error {
+		return db.Publish(ctx, entries[i])
+	})
+
+	// Listing walks the registry a page at a time, as mirrors do, starting over at the end
+	var (
+		mu    sync.Mutex
+		after *database.Position
+	)
+	list := measure(ctx, opts.Operations, opts.Concurrency, func(int) error {
+		mu.Lock()
+		from := after
+		mu.Unlock()
+		_, next, err := db.List(ctx, map[string]interface{}{}, database.SortByID, from, pageSize)
+		mu.Lock()
+		after = next
+		mu.Unlock()
+		return err
+	})
+
+	filters := make([]map[string]interface{}, len(words))
+	for i, word := range words {
+		if filters[i], err = query.Parse(word, query.Options{Match: database.MatchSubstring}); err != nil {
+			return nil, err
+		}
+	}
+	search := measure(ctx, opts.Operations, opts.Concurrency, func(i int) error {
+		_, _, err := db.List(ctx, filters[i%len(filters)], database.SortByID, nil, pageSize)
+		return err
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := []Result{create.result("create"), list.result("list"), search.result("search")}
+	for i := range results {
+		results[i].Backend = backend
+	}
+	return results, nil
+}
+
+// timings are the latencies of one operation's successful runs
+type timings struct {
+	latencies []time.Duration
+	errors    int
+	elapsed   time.Duration
+}
+
+// measure runs op n times, numbered 0 to n-1, with concurrency runs in flight
+func measure(ctx context.Context, n, concurrency int, op func(i int) error) *timings {
+	concurrency = max(concurrency, 1)
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := 0; i < n && ctx.Err() == nil; i++ {
+			indexes <- i
+		}
+	}()
+
+	t := &timings{}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	start := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				began := time.Now()
+				err := op(i)
+				took := time.Since(began)
+				mu.Lock()
+				if err != nil {
+					t.errors++
+				} else {
+					t.latencies = append(t.latencies, took)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	t.elapsed = time.Since(start)
+	return t
+}
+
+// result summarizes the timings of operation
+func (t *timings) result(operation string) Result {
+	sort.Slice(t.latencies, func(i, j int) bool { return t.latencies[i] < t.latencies[j] })
+	r := Result{
+		Operation: operation,
+		Count:     len(t.latencies),
+		Errors:    t.errors,
+		Elapsed:   t.elapsed,
+		P50:       percentile(t.latencies, 0.50),
+		P99:       percentile(t.latencies, 0.99),
+	}
+	if t.elapsed > 0 {
+		r.Throughput = float64(r.Count) / t.elapsed.Seconds()
+	}
+	return r
+}
+
+// percentile returns the latency at or below which fraction p of sorted falls
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(float64(len(sorted))*p)) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
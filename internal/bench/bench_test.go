@@ -0,0 +1,68 @@
+package bench
+
+import (
+	"context"
+	"testing"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/query"
+)
+
+// benchEntries is the size of the registry reads are measured on
+const benchEntries = 5000
+
+// loaded returns a memory database holding the first n versions of the dataset
+func loaded(b *testing.B, n int) database.Database {
+	b.Helper()
+	db := database.NewMemoryDB(map[string]*model.Server{})
+	for _, entry := range Dataset(n) {
+		if err := db.Publish(context.Background(), entry); err != nil {
+			b.Fatalf("publishing %s: %v", entry.Name, err)
+		}
+	}
+	return db
+}
+
+func BenchmarkMemoryDBPublish(b *testing.B) {
+	entries := Dataset(b.N)
+	db := database.NewMemoryDB(map[string]*model.Server{})
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.Publish(ctx, entries[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMemoryDBList(b *testing.B) {
+	db := loaded(b, benchEntries)
+	ctx := context.Background()
+	var after *database.Position
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if _, after, err = db.List(ctx, map[string]interface{}{}, database.SortByID, after, pageSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMemoryDBSearch(b *testing.B) {
+	db := loaded(b, benchEntries)
+	ctx := context.Background()
+	filters := make([]map[string]interface{}, len(words))
+	for i, word := range words {
+		var err error
+		if filters[i], err = query.Parse(word, query.Options{Match: database.MatchSubstring}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := db.List(ctx, filters[i%len(filters)], database.SortByID, nil, pageSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
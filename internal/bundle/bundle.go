@@ -0,0 +1,222 @@
+// Package bundle writes registry exports as gzipped tarballs for air-gapped transfer. Each
+// version is a directory holding its manifest, README, icon and signatures; index.json at
+// the root lists every version with the registry-managed state its manifest leaves out.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"registry/internal/model"
+	"registry/internal/signing"
+)
+
+// ContentType is the media type of a bundle
+const ContentType = "application/gzip"
+
+// File names within a bundle
+const (
+	IndexFile      = "index.json"
+	ManifestFile   = "manifest.json"
+	ReadmeFile     = "README.md"
+	SignaturesFile = "signatures.json"
+)
+
+// iconFiles names the icon file of a version by its content type
+var iconFiles = map[string]string{
+	"image/png":     "icon.png",
+	"image/svg+xml": "icon.svg",
+}
+
+// Index is the root document of a bundle
+type Index struct {
+	Revision   int64     `json:"revision"`
+	ExportedAt time.Time `json:"exported_at"`
+	// KeyID identifies the key the bundle is signed with; it is empty for unsigned bundles
+	KeyID   string  `json:"key_id,omitempty"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry locates a version in a bundle. The manifest at Path/manifest.json hashes to Digest.
+type Entry struct {
+	Path         string `json:"path"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Digest       string `json:"digest"`
+	IsLatest     bool   `json:"is_latest,omitempty"`
+	Yanked       bool   `json:"yanked,omitempty"`
+	YankedReason string `json:"yanked_reason,omitempty"`
+}
+
+// Signatures maps the files of a directory to detached signatures over their SHA-256
+// digests, in the Registry-Signature format
+type Signatures map[string]string
+
+// file is a file of a version directory
+type file struct {
+	name string
+	data []byte
+}
+
+// Writer streams a bundle. Versions are added one at a time; Close writes the index.
+type Writer struct {
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	signer  *signing.Signer
+	modTime time.Time
+	index   Index
+	dirs    map[string]bool
+}
+
+// NewWriter starts a bundle on w reflecting the registry at revision. Files are signed
+// with signer unless it is nil.
+func NewWriter(w io.Writer, signer *signing.Signer, revision int64) *Writer {
+	gz := gzip.NewWriter(w)
+	now := time.Now().UTC().Truncate(time.Second)
+	b := &Writer{
+		gz:      gz,
+		tw:      tar.NewWriter(gz),
+		signer:  signer,
+		modTime: now,
+		index:   Index{Revision: revision, ExportedAt: now, Entries: []Entry{}},
+		dirs:    make(map[string]bool),
+	}
+	if signer != nil {
+		b.index.KeyID = signer.KeyID()
+	}
+	return b
+}
+
+// Add writes a version: manifest is its stored manifest, and icon its icon, if any
+func (b *Writer) Add(entry *model.ServerDetail, manifest []byte, icon []byte, iconType string) error {
+	dir := b.dir(entry)
+	files := []file{{ManifestFile, manifest}}
+	if entry.Readme != "" {
+		files = append(files, file{ReadmeFile, []byte(entry.Readme)})
+	}
+	if name, ok := iconFiles[iconType]; ok && len(icon) > 0 {
+		files = append(files, file{name, icon})
+	}
+
+	signatures := Signatures{}
+	for _, f := range files {
+		if err := b.writeFile(path.Join(dir, f.name), f.data); err != nil {
+			return err
+		}
+		b.sign(signatures, f.name, f.data)
+	}
+	if err := b.writeSignatures(dir, signatures); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(manifest)
+	b.index.Entries = append(b.index.Entries, Entry{
+		Path:         dir,
+		ID:           entry.ID,
+		Name:         entry.Name,
+		Version:      entry.VersionDetail.Version,
+		Digest:       model.DigestPrefix + hex.EncodeToString(sum[:]),
+		IsLatest:     entry.VersionDetail.IsLatest,
+		Yanked:       entry.VersionDetail.Yanked,
+		YankedReason: entry.VersionDetail.YankedReason,
+	})
+	return nil
+}
+
+// Close writes the index and its signature and finishes the archive
+func (b *Writer) Close() error {
+	data, err := json.MarshalIndent(b.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := b.writeFile(IndexFile, data); err != nil {
+		return err
+	}
+	signatures := Signatures{}
+	b.sign(signatures, IndexFile, data)
+	if err := b.writeSignatures("", signatures); err != nil {
+		return err
+	}
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	return b.gz.Close()
+}
+
+// dir returns the directory of entry, <slug>/<version>. Versions that would share a
+// directory, such as those of names with the same slug, are told apart by their ID.
+func (b *Writer) dir(entry *model.ServerDetail) string {
+	slug := model.Slug(entry.Name)
+	if slug == "" {
+		slug = "server"
+	}
+	dir := slug + "/" + pathSegment(entry.VersionDetail.Version)
+	if b.dirs[dir] {
+		dir += "~" + entry.ID
+	}
+	b.dirs[dir] = true
+	return dir
+}
+
+// pathSegment returns version with characters that are unsafe in a path replaced
+func pathSegment(version string) string {
+	segment := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '+', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, version)
+	if segment == "" || strings.Trim(segment, ".") == "" {
+		return "_" + segment
+	}
+	return segment
+}
+
+// sign records the signature of a file when the bundle is signed
+func (b *Writer) sign(signatures Signatures, name string, data []byte) {
+	if b.signer == nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	signatures[name] = b.signer.SignatureValue(sum[:])
+}
+
+// writeSignatures writes the signatures of the files in dir, if the bundle is signed
+func (b *Writer) writeSignatures(dir string, signatures Signatures) error {
+	if b.signer == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(signatures, "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.writeFile(path.Join(dir, SignaturesFile), data)
+}
+
+// writeFile adds a regular file to the archive
+func (b *Writer) writeFile(name string, data []byte) error {
+	if err := b.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  b.modTime,
+	}); err != nil {
+		return fmt.Errorf("error writing %s: %w", name, err)
+	}
+	if _, err := b.tw.Write(data); err != nil {
+		return fmt.Errorf("error writing %s: %w", name, err)
+	}
+	return nil
+}
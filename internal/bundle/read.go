@@ -0,0 +1,199 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"registry/internal/model"
+	"registry/internal/signing"
+)
+
+// maxFileBytes bounds each file read from a bundle, well above the largest manifest, README
+// or icon the registry accepts
+const maxFileBytes = 4 << 20
+
+// ErrInvalid is returned for bundles that are malformed or fail verification
+var ErrInvalid = errors.New("invalid bundle")
+
+// Version is a verified version of a bundle, ready to import
+type Version struct {
+	// Detail carries the manifest of the version and the state recorded in the index
+	Detail   *model.ServerDetail
+	Icon     []byte
+	IconType string
+}
+
+// Read reads a bundle from r and verifies every file in it against verifier before returning
+// its index and versions. Any file without a signature by a trusted key, and any mismatch
+// between the index and the manifests, fails the whole bundle.
+func Read(r io.Reader, verifier *signing.Verifier) (*Index, []Version, error) {
+	files, err := readFiles(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Group the files by directory; every directory must be signed as a whole
+	dirs := make(map[string][]string)
+	for name := range files {
+		dir := path.Dir(name)
+		if dir == "." {
+			dir = ""
+		}
+		dirs[dir] = append(dirs[dir], path.Base(name))
+	}
+	if err := verifyDir(files, "", dirs[""], verifier); err != nil {
+		return nil, nil, err
+	}
+
+	var index Index
+	if err := json.Unmarshal(files[IndexFile], &index); err != nil {
+		return nil, nil, fmt.Errorf("%w: decoding %s: %v", ErrInvalid, IndexFile, err)
+	}
+
+	indexed := map[string]bool{"": true}
+	versions := make([]Version, 0, len(index.Entries))
+	for _, entry := range index.Entries {
+		if indexed[entry.Path] {
+			return nil, nil, fmt.Errorf("%w: directory %q is listed twice", ErrInvalid, entry.Path)
+		}
+		indexed[entry.Path] = true
+		if err := verifyDir(files, entry.Path, dirs[entry.Path], verifier); err != nil {
+			return nil, nil, err
+		}
+		version, err := readVersion(files, entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		versions = append(versions, version)
+	}
+	for dir := range dirs {
+		if !indexed[dir] {
+			return nil, nil, fmt.Errorf("%w: directory %q is not in the index", ErrInvalid, dir)
+		}
+	}
+	return &index, versions, nil
+}
+
+// readFiles reads the regular files of the archive, keyed by their cleaned path
+func readFiles(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("%w: %s is not a regular file", ErrInvalid, header.Name)
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("%w: %s is outside the bundle", ErrInvalid, header.Name)
+		}
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("%w: %s appears twice", ErrInvalid, name)
+		}
+		if header.Size > maxFileBytes {
+			return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrInvalid, name, maxFileBytes)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxFileBytes))
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading %s: %v", ErrInvalid, name, err)
+		}
+		files[name] = data
+	}
+}
+
+// verifyDir checks that the signatures file of dir signs exactly the other files in it
+func verifyDir(files map[string][]byte, dir string, names []string, verifier *signing.Verifier) error {
+	data, ok := files[path.Join(dir, SignaturesFile)]
+	if !ok {
+		return fmt.Errorf("%w: %s is missing", ErrInvalid, path.Join(dir, SignaturesFile))
+	}
+	var signatures Signatures
+	if err := json.Unmarshal(data, &signatures); err != nil {
+		return fmt.Errorf("%w: decoding %s: %v", ErrInvalid, path.Join(dir, SignaturesFile), err)
+	}
+
+	sort.Strings(names)
+	signed := 0
+	for _, name := range names {
+		if name == SignaturesFile {
+			continue
+		}
+		value, ok := signatures[name]
+		if !ok {
+			return fmt.Errorf("%w: %s is not signed", ErrInvalid, path.Join(dir, name))
+		}
+		sum := sha256.Sum256(files[path.Join(dir, name)])
+		if err := verifier.Verify(value, sum[:]); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalid, path.Join(dir, name), err)
+		}
+		signed++
+	}
+	if signed != len(signatures) {
+		return fmt.Errorf("%w: %s signs files missing from the bundle", ErrInvalid, path.Join(dir, SignaturesFile))
+	}
+	return nil
+}
+
+// readVersion decodes the manifest of entry and checks it against the index
+func readVersion(files map[string][]byte, entry Entry) (Version, error) {
+	manifest, ok := files[path.Join(entry.Path, ManifestFile)]
+	if !ok {
+		return Version{}, fmt.Errorf("%w: %s is missing", ErrInvalid, path.Join(entry.Path, ManifestFile))
+	}
+	detail := &model.ServerDetail{}
+	if err := json.Unmarshal(manifest, detail); err != nil {
+		return Version{}, fmt.Errorf("%w: decoding %s: %v", ErrInvalid, path.Join(entry.Path, ManifestFile), err)
+	}
+	sum := sha256.Sum256(manifest)
+	if model.DigestPrefix+hex.EncodeToString(sum[:]) != entry.Digest || detail.ID != entry.ID || detail.Name != entry.Name ||
+		detail.VersionDetail.Version != entry.Version {
+		return Version{}, fmt.Errorf("%w: %s does not match its index entry", ErrInvalid, path.Join(entry.Path, ManifestFile))
+	}
+	if readme, ok := files[path.Join(entry.Path, ReadmeFile)]; ok && string(readme) != detail.Readme {
+		return Version{}, fmt.Errorf("%w: %s does not match the manifest", ErrInvalid, path.Join(entry.Path, ReadmeFile))
+	}
+
+	detail.Digest = entry.Digest
+	detail.VersionDetail.IsLatest = entry.IsLatest
+	detail.VersionDetail.Yanked = entry.Yanked
+	detail.VersionDetail.YankedReason = entry.YankedReason
+
+	version := Version{Detail: detail}
+	for iconType, name := range iconFiles {
+		icon, ok := files[path.Join(entry.Path, name)]
+		if !ok {
+			continue
+		}
+		if version.Icon != nil {
+			return Version{}, fmt.Errorf("%w: %s has more than one icon", ErrInvalid, entry.Path)
+		}
+		version.Icon, version.IconType = icon, iconType
+	}
+	return version, nil
+}
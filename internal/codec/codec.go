@@ -0,0 +1,77 @@
+// Package codec converts JSON documents to the other formats the API can respond with.
+// Converting from JSON keeps the field names and order defined by the json struct tags.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// member is a name and value of a JSON object, kept in document order
+type member struct {
+	name  string
+	value interface{}
+}
+
+// object is a decoded JSON object
+type object []member
+
+// decode parses a JSON document into objects, []interface{}, string, json.Number, bool and nil
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after JSON document")
+	}
+	return v, nil
+}
+
+// decodeValue reads the next value from dec
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{name: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+
+	switch tok.(type) {
+	case string, json.Number, bool, nil:
+		return tok, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JSONToYAML converts a JSON document to an equivalent YAML 1.2 document in block style.
+// Strings that YAML would read as another type or that need escaping are double quoted.
+func JSONToYAML(data []byte) ([]byte, error) {
+	v, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if isBlock(v) {
+		writeYAMLBlock(&buf, v, 0, "")
+	} else {
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// isBlock reports whether v is written as an indented block rather than on one line
+func isBlock(v interface{}) bool {
+	switch v := v.(type) {
+	case object:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// writeYAMLBlock writes a non-empty object or array at indent. The first line starts with
+// first instead of the indentation, so that objects can follow a list item's "- ".
+func writeYAMLBlock(buf *bytes.Buffer, v interface{}, indent int, first string) {
+	pad := strings.Repeat("  ", indent)
+	prefix := func(i int) string {
+		if i == 0 {
+			return first
+		}
+		return pad
+	}
+
+	switch v := v.(type) {
+	case object:
+		for i, m := range v {
+			buf.WriteString(prefix(i))
+			buf.WriteString(yamlString(m.name))
+			buf.WriteByte(':')
+			if isBlock(m.value) {
+				buf.WriteByte('\n')
+				writeYAMLBlock(buf, m.value, indent+1, pad+"  ")
+				continue
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(yamlScalar(m.value))
+			buf.WriteByte('\n')
+		}
+	case []interface{}:
+		for i, item := range v {
+			if isBlock(item) {
+				writeYAMLBlock(buf, item, indent+1, prefix(i)+"- ")
+				continue
+			}
+			buf.WriteString(prefix(i))
+			buf.WriteString("- ")
+			buf.WriteString(yamlScalar(item))
+			buf.WriteByte('\n')
+		}
+	}
+}
+
+// yamlScalar formats a value that is written on a single line
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	case object:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return "null"
+}
+
+// yamlString returns s plain when YAML reads it back as the same string, and double quoted otherwise
+func yamlString(s string) string {
+	if needsQuotes(s) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(s)
+		// JSON string escapes are valid in YAML double quoted scalars
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+	return s
+}
+
+// needsQuotes reports whether a plain scalar would be misread or is not allowed
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == 0xfeff {
+			return true
+		}
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n", ".nan", ".inf", "-.inf", "+.inf":
+		return true
+	}
+	// Anything starting like a number could be read as one by some YAML parser
+	return strings.ContainsAny(s[:1], "0123456789+.")
+}
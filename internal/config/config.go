@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	env "github.com/caarlos0/env/v11"
 )
 
@@ -11,29 +13,172 @@ const (
 	DatabaseTypeMemory  DatabaseType = "memory"
 )
 
+// EnvPrefix is prepended to the name of every configuration variable
+const EnvPrefix = "MCP_REGISTRY_"
+
 // Config holds the application configuration
 type Config struct {
-	ServerAddress      string       `env:"SERVER_ADDRESS" envDefault:":8080"`
-	DatabaseType       DatabaseType `env:"DATABASE_TYPE" envDefault:"mongodb"`
-	DatabaseURL        string       `env:"DATABASE_URL" envDefault:"mongodb://localhost:27017"`
-	DatabaseName       string       `env:"DATABASE_NAME" envDefault:"mcp-registry"`
-	CollectionName     string       `env:"COLLECTION_NAME" envDefault:"servers_v2"`
-	LogLevel           string       `env:"LOG_LEVEL" envDefault:"info"`
-	SeedFilePath       string       `env:"SEED_FILE_PATH" envDefault:"data/seed_2025_05_16.json"`
-	SeedImport         bool         `env:"SEED_IMPORT" envDefault:"true"`
-	Version            string       `env:"VERSION" envDefault:"dev"`
-	GithubClientID     string       `env:"GITHUB_CLIENT_ID" envDefault:""`
-	GithubClientSecret string       `env:"GITHUB_CLIENT_SECRET" envDefault:""`
+	ServerAddress             string                   `env:"SERVER_ADDRESS" envDefault:":8080"`
+	Environment               string                   `env:"ENVIRONMENT" envDefault:"production"`
+	ShutdownDelay             time.Duration            `env:"SHUTDOWN_DELAY" envDefault:"0s"`
+	HTTPReadHeaderTimeout     time.Duration            `env:"HTTP_READ_HEADER_TIMEOUT" envDefault:"10s"`
+	HTTPReadTimeout           time.Duration            `env:"HTTP_READ_TIMEOUT" envDefault:"30s"`
+	HTTPWriteTimeout          time.Duration            `env:"HTTP_WRITE_TIMEOUT" envDefault:"30s"`
+	HTTPIdleTimeout           time.Duration            `env:"HTTP_IDLE_TIMEOUT" envDefault:"2m"`
+	RouteTimeouts             map[string]time.Duration `env:"ROUTE_TIMEOUTS" envDefault:"export=10m,debug=2m" envKeyValSeparator:"="`
+	APIV0Sunset               string                   `env:"API_V0_SUNSET" envDefault:""`
+	DatabaseType              DatabaseType             `env:"DATABASE_TYPE" envDefault:"mongodb"`
+	DatabaseURL               string                   `env:"DATABASE_URL" envDefault:"mongodb://localhost:27017" redact:"url"`
+	DatabaseName              string                   `env:"DATABASE_NAME" envDefault:"mcp-registry"`
+	CollectionName            string                   `env:"COLLECTION_NAME" envDefault:"servers_v2"`
+	DualWriteURL              string                   `env:"DUAL_WRITE_URL" envDefault:"" redact:"url"`
+	DualWriteDatabaseName     string                   `env:"DUAL_WRITE_DATABASE_NAME" envDefault:""`
+	SearchFoldAccents         bool                     `env:"SEARCH_FOLD_ACCENTS" envDefault:"true"`
+	HealthCheckInterval       time.Duration            `env:"DATABASE_HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	DatabaseConnectTimeout    time.Duration            `env:"DATABASE_CONNECT_TIMEOUT" envDefault:"1m"`
+	DatabaseTimeout           time.Duration            `env:"DATABASE_TIMEOUT" envDefault:"5s"`
+	DatabaseReadRetries       int                      `env:"DATABASE_READ_RETRIES" envDefault:"2"`
+	DatabaseReadRetryBackoff  time.Duration            `env:"DATABASE_READ_RETRY_BACKOFF" envDefault:"50ms"`
+	StreamTimeout             time.Duration            `env:"STREAM_TIMEOUT" envDefault:"5m"`
+	SlowQueryThreshold        time.Duration            `env:"SLOW_QUERY_THRESHOLD" envDefault:"500ms"`
+	PublishCoalesceWindow     time.Duration            `env:"PUBLISH_COALESCE_WINDOW" envDefault:"0"`
+	PublishCoalesceMaxBatch   int                      `env:"PUBLISH_COALESCE_MAX_BATCH" envDefault:"100"`
+	IDFormat                  string                   `env:"ID_FORMAT" envDefault:"uuidv4"`
+	NameUniqueness            string                   `env:"NAME_UNIQUENESS" envDefault:"case-insensitive"`
+	LogLevel                  string                   `env:"LOG_LEVEL" envDefault:"info"`
+	SeedFilePath              string                   `env:"SEED_FILE_PATH" envDefault:"data/seed_2025_05_16.json"`
+	SeedFormat                string                   `env:"SEED_FORMAT" envDefault:"auto"`
+	SeedConflictPolicy        string                   `env:"SEED_CONFLICT_POLICY" envDefault:"overwrite"`
+	SeedDownloadTimeout       time.Duration            `env:"SEED_DOWNLOAD_TIMEOUT" envDefault:"1m"`
+	SeedImport                bool                     `env:"SEED_IMPORT" envDefault:"true"`
+	SeedSHA256                string                   `env:"SEED_SHA256" envDefault:""`
+	Version                   string                   `env:"VERSION" envDefault:"dev"`
+	GithubClientID            string                   `env:"GITHUB_CLIENT_ID" envDefault:""`
+	GithubClientSecret        string                   `env:"GITHUB_CLIENT_SECRET" envDefault:"" redact:"value"`
+	GithubToken               string                   `env:"GITHUB_TOKEN" envDefault:"" redact:"value"`
+	AuthProvider              string                   `env:"AUTH_PROVIDER" envDefault:"github"`
+	AuthGrants                map[string]string        `env:"AUTH_GRANTS" envDefault:"" envKeyValSeparator:"="`
+	AuthStaticTokensFile      string                   `env:"AUTH_STATIC_TOKENS_FILE" envDefault:""`
+	AuthOIDCIssuer            string                   `env:"AUTH_OIDC_ISSUER" envDefault:""`
+	AuthOIDCAudience          string                   `env:"AUTH_OIDC_AUDIENCE" envDefault:""`
+	AuthOIDCUsernameClaim     string                   `env:"AUTH_OIDC_USERNAME_CLAIM" envDefault:"sub"`
+	AuthOIDCGroupsClaim       string                   `env:"AUTH_OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	AuthLDAPURL               string                   `env:"AUTH_LDAP_URL" envDefault:""`
+	AuthLDAPBindDN            string                   `env:"AUTH_LDAP_BIND_DN" envDefault:""`
+	Auth2FARequired           bool                     `env:"AUTH_2FA_REQUIRED" envDefault:"false"`
+	Auth2FAMaxAge             time.Duration            `env:"AUTH_2FA_MAX_AGE" envDefault:"15m"`
+	ReservedNames             string                   `env:"RESERVED_NAMES" envDefault:""`
+	EnrichmentInterval        time.Duration            `env:"ENRICHMENT_INTERVAL" envDefault:"6h"`
+	EnrichmentSchedule        string                   `env:"ENRICHMENT_SCHEDULE" envDefault:""`
+	EnrichmentEnabled         bool                     `env:"ENRICHMENT_ENABLED" envDefault:"true"`
+	EnrichmentTTL             time.Duration            `env:"ENRICHMENT_TTL" envDefault:"5h"`
+	GCInterval                time.Duration            `env:"GC_INTERVAL" envDefault:"24h"`
+	GCSchedule                string                   `env:"GC_SCHEDULE" envDefault:""`
+	GCEnabled                 bool                     `env:"GC_ENABLED" envDefault:"true"`
+	GCChangeRetention         time.Duration            `env:"GC_CHANGE_RETENTION" envDefault:"0s"`
+	GCLeaseRetention          time.Duration            `env:"GC_LEASE_RETENTION" envDefault:"24h"`
+	GCManifestGrace           time.Duration            `env:"GC_MANIFEST_GRACE" envDefault:"1h"`
+	BackupEnabled             bool                     `env:"BACKUP_ENABLED" envDefault:"false"`
+	BackupSchedule            string                   `env:"BACKUP_SCHEDULE" envDefault:"0 3 * * *"`
+	BackupDir                 string                   `env:"BACKUP_DIR" envDefault:"backups"`
+	BackupRetain              int                      `env:"BACKUP_RETAIN" envDefault:"7"`
+	LivenessEnabled           bool                     `env:"LIVENESS_ENABLED" envDefault:"false"`
+	LivenessSchedule          string                   `env:"LIVENESS_SCHEDULE" envDefault:"@hourly"`
+	LivenessTimeout           time.Duration            `env:"LIVENESS_TIMEOUT" envDefault:"10s"`
+	SchedulerJitter           time.Duration            `env:"SCHEDULER_JITTER" envDefault:"0s"`
+	ListCacheTTL              time.Duration            `env:"LIST_CACHE_TTL" envDefault:"30s"`
+	CacheWarmInterval         time.Duration            `env:"CACHE_WARM_INTERVAL" envDefault:"10s"`
+	CacheWarmQueries          int                      `env:"CACHE_WARM_QUERIES" envDefault:"20"`
+	SavedSearchInterval       time.Duration            `env:"SAVED_SEARCH_INTERVAL" envDefault:"1m"`
+	SavedSearchSchedule       string                   `env:"SAVED_SEARCH_SCHEDULE" envDefault:""`
+	SavedSearchEnabled        bool                     `env:"SAVED_SEARCH_ENABLED" envDefault:"true"`
+	SMTPAddr                  string                   `env:"SMTP_ADDR" envDefault:""`
+	SMTPFrom                  string                   `env:"SMTP_FROM" envDefault:""`
+	SMTPUsername              string                   `env:"SMTP_USERNAME" envDefault:""`
+	SMTPPassword              string                   `env:"SMTP_PASSWORD" envDefault:"" redact:"value"`
+	NotifySlackWebhookURL     string                   `env:"NOTIFY_SLACK_WEBHOOK_URL" envDefault:"" redact:"value"`
+	NotifyDiscordWebhookURL   string                   `env:"NOTIFY_DISCORD_WEBHOOK_URL" envDefault:"" redact:"value"`
+	ScanMode                  string                   `env:"SCAN_MODE" envDefault:"flag"`
+	ScanTimeout               time.Duration            `env:"SCAN_TIMEOUT" envDefault:"10s"`
+	ScanSafeBrowsingAPIKey    string                   `env:"SCAN_SAFE_BROWSING_API_KEY" envDefault:"" redact:"value"`
+	ScanVirusTotalAPIKey      string                   `env:"SCAN_VIRUSTOTAL_API_KEY" envDefault:"" redact:"value"`
+	ReplicationSource         string                   `env:"REPLICATION_SOURCE" envDefault:"" redact:"url"`
+	ReplicationInterval       time.Duration            `env:"REPLICATION_INTERVAL" envDefault:"30s"`
+	ReplicationSchedule       string                   `env:"REPLICATION_SCHEDULE" envDefault:""`
+	ReplicationConflictPolicy string                   `env:"REPLICATION_CONFLICT_POLICY" envDefault:"source-wins"`
+	LeaderElection            bool                     `env:"LEADER_ELECTION" envDefault:"false"`
+	LeaderLeaseTTL            time.Duration            `env:"LEADER_LEASE_TTL" envDefault:"15s"`
+	AdminToken                string                   `env:"ADMIN_TOKEN" envDefault:"" redact:"value"`
+	SigningKey                string                   `env:"SIGNING_KEY" envDefault:"" redact:"value"`
+	BundleTrustedKeys         string                   `env:"BUNDLE_TRUSTED_KEYS" envDefault:""`
+	SitemapBaseURL            string                   `env:"SITEMAP_BASE_URL" envDefault:""`
+	SitemapServerPath         string                   `env:"SITEMAP_SERVER_PATH" envDefault:"/servers/{slug}"`
+	SitemapPageSize           int                      `env:"SITEMAP_PAGE_SIZE" envDefault:"50000"`
+	CursorSecret              string                   `env:"CURSOR_SECRET" envDefault:"" redact:"value"`
+	EnableMetrics             bool                     `env:"ENABLE_METRICS" envDefault:"true"`
+	FeatureFlags              string                   `env:"FEATURE_FLAGS" envDefault:""`
+	MaintenanceMode           bool                     `env:"MAINTENANCE_MODE" envDefault:"false"`
+	MaintenanceMessage        string                   `env:"MAINTENANCE_MESSAGE" envDefault:""`
+	MaintenanceAllowReads     bool                     `env:"MAINTENANCE_ALLOW_READS" envDefault:"true"`
+	RequestSampleRate         float64                  `env:"REQUEST_SAMPLE_RATE" envDefault:"0"`
+	RequestSampleSize         int                      `env:"REQUEST_SAMPLE_SIZE" envDefault:"100"`
+	UsageRetentionDays        int                      `env:"USAGE_RETENTION_DAYS" envDefault:"35"`
+	UsageTenantHeader         string                   `env:"USAGE_TENANT_HEADER" envDefault:""`
+	UsageMaxTenants           int                      `env:"USAGE_MAX_TENANTS" envDefault:"100"`
+	ClientIPHeader            string                   `env:"CLIENT_IP_HEADER" envDefault:""`
+	AbuseDetection            bool                     `env:"ABUSE_DETECTION" envDefault:"false"`
+	AbuseWindow               time.Duration            `env:"ABUSE_WINDOW" envDefault:"10m"`
+	AbuseMaxWriteFailures     int                      `env:"ABUSE_MAX_WRITE_FAILURES" envDefault:"30"`
+	AbuseMaxNotFound          int                      `env:"ABUSE_MAX_NOT_FOUND" envDefault:"300"`
+	AbuseBanDuration          time.Duration            `env:"ABUSE_BAN_DURATION" envDefault:"1h"`
+	ShedMaxInFlight           int                      `env:"SHED_MAX_IN_FLIGHT" envDefault:"0"`
+	ShedMaxHeapBytes          int64                    `env:"SHED_MAX_HEAP_BYTES" envDefault:"0"`
+	QuotaMaxEntries           map[string]int           `env:"QUOTA_MAX_ENTRIES" envDefault:"" envKeyValSeparator:"="`
+	QuotaMaxVersionsPerDay    map[string]int           `env:"QUOTA_MAX_VERSIONS_PER_DAY" envDefault:"" envKeyValSeparator:"="`
+	QuotaMaxBytesPerDay       map[string]int64         `env:"QUOTA_MAX_BYTES_PER_DAY" envDefault:"" envKeyValSeparator:"="`
+	QuotaRoles                map[string]string        `env:"QUOTA_ROLES" envDefault:"" envKeyValSeparator:"="`
+	MediaStorage              string                   `env:"MEDIA_STORAGE" envDefault:"disk"`
+	MediaDir                  string                   `env:"MEDIA_DIR" envDefault:"data/media"`
+	MediaS3Endpoint           string                   `env:"MEDIA_S3_ENDPOINT" envDefault:""`
+	MediaS3Region             string                   `env:"MEDIA_S3_REGION" envDefault:""`
+	MediaS3Bucket             string                   `env:"MEDIA_S3_BUCKET" envDefault:""`
+	MediaS3AccessKeyID        string                   `env:"MEDIA_S3_ACCESS_KEY_ID" envDefault:""`
+	MediaS3SecretAccessKey    string                   `env:"MEDIA_S3_SECRET_ACCESS_KEY" envDefault:"" redact:"value"`
+
+	// defaulted records, by variable name, whether each setting fell back to its default
+	defaulted map[string]bool
 }
 
 // NewConfig creates a new configuration with default values
 func NewConfig() *Config {
-	var cfg Config
+	cfg := Config{defaulted: map[string]bool{}}
 	err := env.ParseWithOptions(&cfg, env.Options{
-		Prefix: "MCP_REGISTRY_",
+		Prefix: EnvPrefix,
+		OnSet: func(key string, _ interface{}, isDefault bool) {
+			cfg.defaulted[key] = isDefault
+		},
 	})
 	if err != nil {
 		panic(err)
 	}
 	return &cfg
 }
+
+// IsReplica reports whether the registry mirrors another instance and rejects local writes
+func (c *Config) IsReplica() bool {
+	return c.ReplicationSource != ""
+}
+
+// IsDevelopment reports whether the registry runs in a development environment
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == "development" || c.Environment == "dev"
+}
+
+// RouteTimeout returns the deadline for requests in the named route group, falling back
+// to the global HTTP write timeout when the group has no override
+func (c *Config) RouteTimeout(group string) time.Duration {
+	if d, ok := c.RouteTimeouts[group]; ok {
+		return d
+	}
+	return c.HTTPWriteTimeout
+}
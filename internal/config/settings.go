@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Where the effective value of a setting came from
+const (
+	SourceEnv     = "env"
+	SourceDefault = "default"
+)
+
+// redactedValue replaces the value of secret settings
+const redactedValue = "[redacted]"
+
+// Setting is the effective value of a configuration variable. Secret values are
+// redacted, so a setting can be shown to operators as is.
+type Setting struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Default string `json:"default"`
+	Source  string `json:"source"`
+	Secret  bool   `json:"secret,omitempty"`
+}
+
+// Settings lists every configuration variable in declaration order
+func (c *Config) Settings() []Setting {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		name := EnvPrefix + key
+		setting := Setting{
+			Name:    name,
+			Value:   formatValue(v.Field(i)),
+			Default: field.Tag.Get("envDefault"),
+			Source:  c.source(name),
+		}
+		switch field.Tag.Get("redact") {
+		case "value":
+			setting.Secret = true
+			if setting.Value != "" {
+				setting.Value = redactedValue
+			}
+		case "url":
+			// Keep the address but hide credentials in it
+			setting.Value = redactURL(setting.Value)
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// source reports whether a variable was set in the environment or fell back to its default.
+// An empty variable counts as unset, as when the configuration is parsed.
+func (c *Config) source(name string) string {
+	defaulted, ok := c.defaulted[name]
+	if !ok {
+		value, set := os.LookupEnv(name)
+		defaulted = !set || value == ""
+	}
+	if defaulted {
+		return SourceDefault
+	}
+	return SourceEnv
+}
+
+// formatValue renders a value the way it is written in the environment
+func formatValue(v reflect.Value) string {
+	if v.Kind() != reflect.Map {
+		return fmt.Sprint(v.Interface())
+	}
+	pairs := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", key.Interface(), v.MapIndex(key).Interface()))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,68 @@
+// Package daemon integrates the registry with native service managers: the systemd
+// notify protocol on Linux and the service control manager on Windows
+package daemon
+
+import (
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var watchdogOnce sync.Once
+
+// Ready tells the service manager that the registry is accepting requests. Under systemd
+// with WatchdogSec set, it also starts sending watchdog keepalives.
+func Ready() {
+	notify("READY=1")
+	setServiceState(stateRunning)
+	watchdogOnce.Do(startWatchdog)
+}
+
+// Stopping tells the service manager that the registry is shutting down gracefully
+func Stopping() {
+	notify("STOPPING=1")
+	setServiceState(stateStopping)
+}
+
+// notify sends a state to the socket in NOTIFY_SOCKET, as sd_notify(3) does. It does
+// nothing when the process was not started by systemd with Type=notify.
+func notify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	// A leading @ names a socket in the abstract namespace
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		log.Printf("Failed to notify service manager: %v", err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		log.Printf("Failed to notify service manager: %v", err)
+	}
+}
+
+// startWatchdog sends WATCHDOG=1 at half the interval systemd expects, when the unit sets
+// WatchdogSec and the watchdog is meant for this process
+func startWatchdog() {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return
+	}
+	interval := time.Duration(usec) * time.Microsecond / 2
+	go func() {
+		for range time.Tick(interval) {
+			notify("WATCHDOG=1")
+		}
+	}()
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"errors"
+	"os"
+)
+
+const (
+	stateRunning = iota
+	stateStopping
+)
+
+// RunWindowsService is only supported on Windows
+func RunWindowsService(name string, stop chan<- os.Signal) error {
+	return errors.New("running as a Windows service is only supported on Windows")
+}
+
+// Stopped is a no-op outside Windows
+func Stopped() {}
+
+func setServiceState(int) {}
@@ -0,0 +1,134 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+const (
+	stateRunning  = svc.Running
+	stateStopping = svc.StopPending
+)
+
+// pendingWaitHint is how long the service control manager waits between status updates
+// while the registry starts or stops, in milliseconds. Startup includes connecting to the
+// database, which is retried.
+const pendingWaitHint = 60000
+
+var service struct {
+	mu sync.Mutex
+	// changes reports status to the service control manager while the handler runs
+	changes chan<- svc.Status
+	status  svc.Status
+	stop    chan<- os.Signal
+	// done is closed by Stopped to end the handler, and exited once svc.Run returns
+	done   chan struct{}
+	exited chan struct{}
+}
+
+// handler receives requests from the service control manager
+type handler struct {
+	registered chan<- struct{}
+}
+
+// RunWindowsService connects the process to the Windows service control manager. It
+// returns once the service is registered; stop and shutdown requests are then delivered
+// to stop as SIGTERM. Call Stopped before the process exits.
+func RunWindowsService(name string, stop chan<- os.Signal) error {
+	service.stop = stop
+	service.done = make(chan struct{})
+	service.exited = make(chan struct{})
+
+	registered := make(chan struct{})
+	runErr := make(chan error, 1)
+	go func() {
+		defer close(service.exited)
+		runErr <- svc.Run(name, &handler{registered: registered})
+	}()
+
+	select {
+	case err := <-runErr:
+		if err == nil {
+			err = errors.New("service stopped before it started")
+		}
+		return fmt.Errorf("error connecting to the service control manager: %w", err)
+	case <-registered:
+		return nil
+	}
+}
+
+// Stopped ends the handler, which reports the service as stopped, and waits for the
+// dispatcher to return
+func Stopped() {
+	service.mu.Lock()
+	running := service.changes != nil
+	service.mu.Unlock()
+	if !running {
+		return
+	}
+	close(service.done)
+	<-service.exited
+}
+
+// Execute runs until Stopped is called, forwarding stop and shutdown requests
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	service.mu.Lock()
+	service.changes = changes
+	service.mu.Unlock()
+	setServiceState(svc.StartPending)
+	close(h.registered)
+
+	for {
+		select {
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				changes <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				setServiceState(svc.StopPending)
+				select {
+				case service.stop <- syscall.SIGTERM:
+				default:
+				}
+			}
+		case <-service.done:
+			service.mu.Lock()
+			service.changes = nil
+			service.mu.Unlock()
+			return false, 0
+		}
+	}
+}
+
+// setServiceState reports a new state to the service control manager. It does nothing
+// when not running as a service.
+func setServiceState(state svc.State) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if service.changes == nil {
+		return
+	}
+
+	status := &service.status
+	if state == status.State {
+		status.CheckPoint++
+	} else {
+		status.CheckPoint = 0
+	}
+	status.State = state
+	if state == svc.Running {
+		status.Accepts = svc.AcceptStop | svc.AcceptShutdown
+		status.WaitHint = 0
+	} else {
+		status.Accepts = 0
+		status.WaitHint = pendingWaitHint
+	}
+	service.changes <- *status
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"registry/internal/metrics"
+	"registry/internal/model"
+)
+
+// coalescedBatchSize records the number of versions written by each coalesced batch
+var coalescedBatchSize = metrics.NewHistogramVec(
+	"mcp_registry_coalesced_publish_batch_size",
+	"Number of versions written by each batch of coalesced publishes.",
+	[]float64{1, 2, 5, 10, 20, 50, 100, 200, 500},
+)
+
+// BatchPublisher is implemented by stores that publish several versions more cheaply at
+// once than one by one
+type BatchPublisher interface {
+	// PublishBatch publishes versions in order, as successive calls to Publish would, and
+	// returns the error of each
+	PublishBatch(ctx context.Context, serverDetails []*model.ServerDetail) []error
+}
+
+// coalescedBatchTimeout bounds the write of one batch, which outlives the requests of the
+// publishers waiting for it
+const coalescedBatchTimeout = time.Minute
+
+// pendingPublish is a publish waiting in a CoalescingDB for its batch to be written
+type pendingPublish struct {
+	serverDetail *model.ServerDetail
+	done         chan error
+}
+
+// CoalescingDB gathers the publishes arriving within a short window and writes them as one
+// batch, so bursts such as seed imports through the API take the store's write lock, or
+// reach MongoDB, once per batch instead of once per version. Every other operation goes
+// straight to the wrapped database. Stores that are not BatchPublishers get the batch one
+// version at a time from a single writer.
+type CoalescingDB struct {
+	Database
+	window   time.Duration
+	maxBatch int
+
+	queue     chan *pendingPublish
+	closeOnce sync.Once
+	stopped   chan struct{}
+	finished  chan struct{}
+}
+
+// NewCoalescingDB batches the publishes on db that arrive within window of the first one
+// of a batch, writing at most maxBatch versions at once
+func NewCoalescingDB(db Database, window time.Duration, maxBatch int) *CoalescingDB {
+	c := &CoalescingDB{
+		Database: db,
+		window:   window,
+		maxBatch: max(maxBatch, 1),
+		queue:    make(chan *pendingPublish),
+		stopped:  make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// Publish queues the version for the next batch and waits until the batch is written. The
+// version is published even if ctx ends while it waits.
+func (c *CoalescingDB) Publish(ctx context.Context, serverDetail *model.ServerDetail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	pending := &pendingPublish{serverDetail: serverDetail, done: make(chan error, 1)}
+	select {
+	case c.queue <- pending:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.stopped:
+		return ErrUnavailable
+	}
+	return <-pending.done
+}
+
+// Close writes the batch being gathered, then closes the wrapped database
+func (c *CoalescingDB) Close() error {
+	c.closeOnce.Do(func() { close(c.stopped) })
+	<-c.finished
+	return c.Database.Close()
+}
+
+// run gathers batches until the database is closed
+func (c *CoalescingDB) run() {
+	defer close(c.finished)
+	for {
+		var first *pendingPublish
+		select {
+		case first = <-c.queue:
+		case <-c.stopped:
+			return
+		}
+
+		batch := []*pendingPublish{first}
+		timer := time.NewTimer(c.window)
+	gather:
+		for len(batch) < c.maxBatch {
+			select {
+			case pending := <-c.queue:
+				batch = append(batch, pending)
+			case <-timer.C:
+				break gather
+			case <-c.stopped:
+				break gather
+			}
+		}
+		timer.Stop()
+		c.write(batch)
+	}
+}
+
+// write publishes a batch and hands each publisher its result
+func (c *CoalescingDB) write(batch []*pendingPublish) {
+	ctx, cancel := context.WithTimeout(context.Background(), coalescedBatchTimeout)
+	defer cancel()
+
+	serverDetails := make([]*model.ServerDetail, len(batch))
+	for i, pending := range batch {
+		serverDetails[i] = pending.serverDetail
+	}
+
+	var errs []error
+	if publisher, ok := c.Database.(BatchPublisher); ok {
+		errs = publisher.PublishBatch(ctx, serverDetails)
+	} else {
+		errs = make([]error, len(batch))
+		for i, serverDetail := range serverDetails {
+			errs[i] = c.Database.Publish(ctx, serverDetail)
+		}
+	}
+	coalescedBatchSize.Observe(float64(len(batch)))
+
+	for i, pending := range batch {
+		pending.done <- errs[i]
+	}
+}
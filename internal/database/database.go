@@ -3,7 +3,12 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
 	"registry/internal/model"
+	"registry/internal/textnorm"
+	"strings"
+	"time"
 )
 
 // Common database errors
@@ -13,18 +18,379 @@ var (
 	ErrInvalidInput   = errors.New("invalid input")
 	ErrDatabase       = errors.New("database error")
 	ErrInvalidVersion = errors.New("invalid version: cannot publish older version after newer version")
+	ErrUnavailable    = errors.New("database unavailable")
+	ErrConflict       = errors.New("conflicting local version")
+	ErrPruned         = errors.New("changes pruned from the log")
+)
+
+// MaxSavedSearches is the number of saved searches a user may keep
+const MaxSavedSearches = 20
+
+// MaxDrafts is the number of drafts that may be kept for one server name
+const MaxDrafts = 20
+
+// MaxPublishTokens is the number of publish tokens that may exist for one server name
+const MaxPublishTokens = 20
+
+// RetentionPolicy bounds how long garbage collection keeps operational data
+type RetentionPolicy struct {
+	// Changes is how long change log entries are kept; zero keeps them forever
+	Changes time.Duration
+	// Leases is how long expired leases are kept before they are deleted
+	Leases time.Duration
+	// ManifestGrace protects newly stored manifests, whose version may still be being
+	// written, from being collected as orphans
+	ManifestGrace time.Duration
+}
+
+// GCReport counts the records removed by a garbage collection
+type GCReport struct {
+	Changes   int64 `json:"changes"`
+	Leases    int64 `json:"leases"`
+	Manifests int64 `json:"manifests"`
+	// PrunedRevision is the newest revision no longer in the change log
+	PrunedRevision int64 `json:"pruned_revision"`
+}
+
+// SortOrder selects the ordering of List results
+type SortOrder string
+
+const (
+	// SortByID orders entries by ID, the default
+	SortByID SortOrder = "id"
+	// SortByName orders entries by name, then ID
+	SortByName SortOrder = "name"
+	// SortByCreatedAt orders entries by release date, newest first, then ID
+	SortByCreatedAt SortOrder = "created_at"
+)
+
+// Position is a keyset pagination position: the sort key of the last entry of a page. List
+// resumes with the entries sorting after it, whether or not that entry still exists, so
+// deep pages cost the same as the first one.
+type Position struct {
+	ID string `json:"id"`
+	// Name is only set when listing by name
+	Name string `json:"name,omitempty"`
+	// ReleaseDate is only set when listing by creation date
+	ReleaseDate string `json:"release_date,omitempty"`
+}
+
+// PositionOf returns the position of server in a listing sorted by order
+func PositionOf(order SortOrder, server *model.Server) *Position {
+	position := &Position{ID: server.ID}
+	switch order {
+	case SortByName:
+		position.Name = server.Name
+	case SortByCreatedAt:
+		position.ReleaseDate = server.VersionDetail.ReleaseDate
+	}
+	return position
+}
+
+// MatchMode selects how a search query is compared with server names
+type MatchMode string
+
+const (
+	// MatchSubstring matches names containing the query, the default
+	MatchSubstring MatchMode = "substring"
+	// MatchPrefix matches names starting with the query
+	MatchPrefix MatchMode = "prefix"
+	// MatchExact matches names equal to the query
+	MatchExact MatchMode = "exact"
+)
+
+// IsValid reports whether m is a known match mode
+func (m MatchMode) IsValid() bool {
+	switch m {
+	case MatchSubstring, MatchPrefix, MatchExact:
+		return true
+	}
+	return false
+}
+
+// Search is the value of the "search" filter key: a case-insensitive name search whose
+// query is matched literally, with no wildcard characters. With FoldAccents set, names
+// and query are compared with accents removed.
+type Search struct {
+	Query       string
+	Match       MatchMode
+	FoldAccents bool
+}
+
+// Matches reports whether the entry's name satisfies the search
+func (s Search) Matches(entry *model.Server) bool {
+	name, query := strings.ToLower(textnorm.NFC(entry.Name)), strings.ToLower(textnorm.NFC(s.Query))
+	if s.FoldAccents {
+		name, query = entry.SearchName, textnorm.Fold(s.Query)
+		if name == "" {
+			name = textnorm.Fold(entry.Name)
+		}
+	}
+	switch s.Match {
+	case MatchExact:
+		return name == query
+	case MatchPrefix:
+		return strings.HasPrefix(name, query)
+	default:
+		return strings.Contains(name, query)
+	}
+}
+
+// Pattern returns a case-insensitive regular expression implementing the search, with
+// every metacharacter of the query escaped
+func (s Search) Pattern() string {
+	query := textnorm.NFC(s.Query)
+	if s.FoldAccents {
+		query = textnorm.Fold(s.Query)
+	}
+	quoted := regexp.QuoteMeta(query)
+	switch s.Match {
+	case MatchExact:
+		return "^" + quoted + "$"
+	case MatchPrefix:
+		return "^" + quoted
+	default:
+		return quoted
+	}
+}
+
+// checkNewVersion checks a version about to be published against the stored versions of
+// its server: it must be new, and not older than the latest of them
+func checkNewVersion(stored []string, version string) error {
+	latest := ""
+	for _, existing := range stored {
+		if existing == version {
+			return ErrAlreadyExists
+		}
+		if latest == "" || CompareSemanticVersions(existing, latest) > 0 {
+			latest = existing
+		}
+	}
+	if latest != "" && CompareSemanticVersions(version, latest) < 0 {
+		return ErrInvalidVersion
+	}
+	return nil
+}
+
+// setDerivedNames stores the folded name that accent-insensitive searches match against,
+// the slug that pretty URLs resolve and the deprecated flat repository URL
+func setDerivedNames(serverDetail *model.ServerDetail) {
+	serverDetail.SearchName = textnorm.Fold(serverDetail.Name)
+	serverDetail.Slug = model.Slug(serverDetail.Name)
+	serverDetail.RepositoryURL = serverDetail.Repository.URL
+}
+
+// NameUniqueness decides which spellings of an existing name Publish accepts
+type NameUniqueness string
+
+const (
+	// NamesExact treats names differing only in letter case, such as "Filesystem-Server"
+	// and "filesystem-server", as different servers
+	NamesExact NameUniqueness = "exact"
+	// NamesCaseInsensitive rejects a name differing only in letter case from one already
+	// registered. Spellings registered before the policy was enabled keep publishing.
+	NamesCaseInsensitive NameUniqueness = "case-insensitive"
+)
+
+// IsValid reports whether u is a supported uniqueness policy
+func (u NameUniqueness) IsValid() bool {
+	return u == NamesExact || u == NamesCaseInsensitive
+}
+
+// nameKey returns the key that spellings of the same name share under case-insensitive
+// uniqueness. Unlike the search name it keeps accents, so "café" and "cafe" stay distinct.
+func nameKey(name string) string {
+	return strings.ToLower(textnorm.NFC(name))
+}
+
+// SavedSearchRevisionKey is the state key holding the revision of the last change processed
+// for saved searches. It is a position in this database's own change log, so it is never
+// copied to another database.
+const SavedSearchRevisionKey = "saved-searches:revision"
+
+// ConflictPolicy decides how Replicate resolves a local version that has the same name and
+// version as a replicated one but a different ID
+type ConflictPolicy string
+
+const (
+	// ConflictSourceWins replaces the local version with the replicated one
+	ConflictSourceWins ConflictPolicy = "source-wins"
+	// ConflictLocalWins keeps the local version and rejects the replicated one with ErrConflict
+	ConflictLocalWins ConflictPolicy = "local-wins"
 )
 
 // Database defines the interface for database operations on MCPRegistry entries
 type Database interface {
-	// List retrieves all MCPRegistry entries with optional filtering
-	List(ctx context.Context, filter map[string]interface{}, cursor string, limit int) ([]*model.Server, string, error)
+	// List retrieves up to limit MCPRegistry entries matching the filter that sort after
+	// the position, or from the start when after is nil. The returned position is nil on
+	// the last page.
+	List(
+		ctx context.Context,
+		filter map[string]interface{},
+		order SortOrder,
+		after *Position,
+		limit int,
+	) ([]*model.Server, *Position, error)
 	// GetByID retrieves a single ServerDetail by it's ID
 	GetByID(ctx context.Context, id string) (*model.ServerDetail, error)
+	// Iterate calls fn for every ServerDetail matching the filter, in ID order, without
+	// materializing the full result set. Iteration stops at the first error returned by fn.
+	Iterate(ctx context.Context, filter map[string]interface{}, fn func(*model.ServerDetail) error) error
+	// Count returns the number of ServerDetails matching the filter, which takes the same
+	// keys as List
+	Count(ctx context.Context, filter map[string]interface{}) (int, error)
 	// Publish adds a new ServerDetail to the database
 	Publish(ctx context.Context, serverDetail *model.ServerDetail) error
-	// ImportSeed imports initial data from a seed file
-	ImportSeed(ctx context.Context, seedFilePath string) error
+	// GetManifest retrieves the immutable manifest stored under a digest
+	GetManifest(ctx context.Context, digest string) ([]byte, error)
+	// SetYanked marks the version with the given ID as yanked, or restores it, and moves the
+	// latest flag to the highest version of the server that is not yanked
+	SetYanked(ctx context.Context, id string, yanked bool, reason string) error
+	// Replicate stores a ServerDetail copied from another registry as is, keeping its ID,
+	// release date and yanked state, after verifying it against its manifest digest
+	Replicate(ctx context.Context, serverDetail *model.ServerDetail, policy ConflictPolicy) error
+	// LoadState reads a value from a small key-value store for operational state, returning
+	// ErrNotFound for unknown keys
+	LoadState(ctx context.Context, key string) (string, error)
+	// SaveState writes a value to the key-value state store
+	SaveState(ctx context.Context, key, value string) error
+	// ListState returns every key and value of the state store
+	ListState(ctx context.Context) (map[string]string, error)
+	// AcquireLease takes or renews the named lease for holder until ttl from now. It returns
+	// false when another holder owns an unexpired lease.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up the named lease if holder owns it
+	ReleaseLease(ctx context.Context, name, holder string) error
+	// ListChanges returns up to limit change log entries in revision order, starting after
+	// sinceRevision or, when sinceTime is non-zero, after that time. It returns ErrPruned
+	// when changes after sinceRevision have already been garbage collected.
+	ListChanges(ctx context.Context, sinceRevision int64, sinceTime time.Time, limit int) ([]*model.Change, error)
+	// HeadRevision returns the revision of the most recent change, or 0 when none was recorded
+	HeadRevision(ctx context.Context) (int64, error)
+	// ImportSeed stores seed entries, resolving entries whose ID exists according to policy.
+	// Entries must be normalized by the importer package; imports are not recorded in the
+	// change log.
+	ImportSeed(ctx context.Context, servers []model.ServerDetail, policy ImportPolicy) (*ImportReport, error)
+	// CreateSavedSearch stores a saved search, failing with ErrInvalidInput once the owner
+	// has MaxSavedSearches of them
+	CreateSavedSearch(ctx context.Context, search *model.SavedSearch) error
+	// ListSavedSearches returns the owner's saved searches, or every saved search when owner is empty
+	ListSavedSearches(ctx context.Context, owner string) ([]*model.SavedSearch, error)
+	// DeleteSavedSearch removes one of the owner's saved searches
+	DeleteSavedSearch(ctx context.Context, owner, id string) error
+	// ConfirmSavedSearchEmail marks the email address of one of the owner's saved searches
+	// as confirmed if tokenHash matches, failing with ErrInvalidInput otherwise
+	ConfirmSavedSearchEmail(ctx context.Context, owner, id, tokenHash string) error
+	// CreateDraft stores a new draft, failing with ErrInvalidInput once its server name has
+	// MaxDrafts of them
+	CreateDraft(ctx context.Context, draft *model.Draft) error
+	// UpdateDraft replaces a stored draft
+	UpdateDraft(ctx context.Context, draft *model.Draft) error
+	// GetDraft retrieves a draft by ID
+	GetDraft(ctx context.Context, id string) (*model.Draft, error)
+	// ListDrafts returns the drafts for a server name, or every draft when name is empty,
+	// oldest first
+	ListDrafts(ctx context.Context, name string) ([]*model.Draft, error)
+	// DeleteDraft removes a draft
+	DeleteDraft(ctx context.Context, id string) error
+	// SetFeatured features a server, replacing its weight if it is already featured
+	SetFeatured(ctx context.Context, featured *model.FeaturedServer) error
+	// DeleteFeatured stops featuring the named server
+	DeleteFeatured(ctx context.Context, name string) error
+	// ListFeatured returns the featured servers by descending weight, then by name
+	ListFeatured(ctx context.Context) ([]*model.FeaturedServer, error)
+	// SetAlias stores an alias of a renamed server, replacing any alias with the same slug
+	SetAlias(ctx context.Context, alias *model.ServerAlias) error
+	// DeleteAlias removes the alias with the given slug
+	DeleteAlias(ctx context.Context, slug string) error
+	// GetAlias retrieves the alias with the given slug
+	GetAlias(ctx context.Context, slug string) (*model.ServerAlias, error)
+	// ListAliases returns every alias by former name
+	ListAliases(ctx context.Context) ([]*model.ServerAlias, error)
+	// ArchiveOrg archives an organization, replacing the entry if it is already archived
+	ArchiveOrg(ctx context.Context, archived *model.ArchivedOrg) error
+	// UnarchiveOrg restores an archived organization
+	UnarchiveOrg(ctx context.Context, org string) error
+	// ListArchivedOrgs returns the archived organizations by name
+	ListArchivedOrgs(ctx context.Context) ([]*model.ArchivedOrg, error)
+	// RevokeToken records a revoked token; revoking it again is not an error
+	RevokeToken(ctx context.Context, revoked *model.RevokedToken) error
+	// IsTokenRevoked reports whether the token with the given digest was revoked
+	IsTokenRevoked(ctx context.Context, digest string) (bool, error)
+	// ListRevokedTokens returns every revoked token, oldest revocation first
+	ListRevokedTokens(ctx context.Context) ([]*model.RevokedToken, error)
+	// CreatePublishToken stores a publish token, failing with ErrInvalidInput once its
+	// server has MaxPublishTokens of them
+	CreatePublishToken(ctx context.Context, token *model.PublishToken) error
+	// GetPublishToken returns the publish token with the given digest
+	GetPublishToken(ctx context.Context, digest string) (*model.PublishToken, error)
+	// ListPublishTokens returns the publish tokens of a server, or of every server when
+	// serverName is empty, oldest first
+	ListPublishTokens(ctx context.Context, serverName string) ([]*model.PublishToken, error)
+	// DeletePublishToken removes one of the publish tokens of a server
+	DeletePublishToken(ctx context.Context, serverName, id string) error
+	// CreateReport stores an abuse report
+	CreateReport(ctx context.Context, report *model.AbuseReport) error
+	// GetReport returns the abuse report with the given ID
+	GetReport(ctx context.Context, id string) (*model.AbuseReport, error)
+	// ListReports returns up to limit abuse reports, oldest first, optionally only those
+	// with the given status
+	ListReports(ctx context.Context, status model.ReportStatus, limit int) ([]*model.AbuseReport, error)
+	// UpdateReport replaces a stored abuse report
+	UpdateReport(ctx context.Context, report *model.AbuseReport) error
+	// CollectGarbage removes expired leases, change log entries past their retention and
+	// manifests that are referenced by neither a stored version nor a retained change
+	CollectGarbage(ctx context.Context, policy RetentionPolicy) (*GCReport, error)
+	// Reindex rebuilds the indexes backing search and listing, calling progress after each
+	// index with the number rebuilt so far and the total
+	Reindex(ctx context.Context, progress func(done, total int)) error
+	// Stats reports row counts, storage and connection figures for diagnostics
+	Stats(ctx context.Context) (*StoreStats, error)
 	// Close closes the database connection
 	Close() error
 }
+
+// latestUnyanked returns the highest version among versions that is not yanked, or nil
+func latestUnyanked(versions []*model.ServerDetail) *model.ServerDetail {
+	var latest *model.ServerDetail
+	for _, v := range versions {
+		if v.VersionDetail.Yanked {
+			continue
+		}
+		if latest == nil || CompareSemanticVersions(v.VersionDetail.Version, latest.VersionDetail.Version) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// replicatedManifest encodes the manifest of a replicated entry, rejecting entries whose
+// content does not match the digest reported by the source
+func replicatedManifest(serverDetail *model.ServerDetail) (string, []byte, error) {
+	if serverDetail.ID == "" || serverDetail.Name == "" {
+		return "", nil, ErrInvalidInput
+	}
+	digest, manifest, err := serverDetail.Manifest()
+	if err != nil {
+		return "", nil, fmt.Errorf("error encoding manifest: %w", err)
+	}
+	if serverDetail.Digest != "" && serverDetail.Digest != digest {
+		return "", nil, fmt.Errorf("%w: digest mismatch for %s: source reported %s, content hashes to %s",
+			ErrInvalidInput, serverDetail.ID, serverDetail.Digest, digest)
+	}
+	return digest, manifest, nil
+}
+
+// replicatedOp is the change recorded when replicating entry over existing, or "" if nothing changed
+func replicatedOp(existing, entry *model.ServerDetail) model.ChangeOp {
+	switch {
+	case existing == nil || existing.Digest != entry.Digest:
+		return model.ChangeOpPublish
+	case existing.VersionDetail.Yanked == entry.VersionDetail.Yanked:
+		return ""
+	case entry.VersionDetail.Yanked:
+		return model.ChangeOpYank
+	default:
+		return model.ChangeOpUnyank
+	}
+}
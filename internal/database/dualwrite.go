@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"registry/internal/metrics"
+	"registry/internal/model"
+)
+
+// dualWriteFailures counts writes that succeeded on the primary but failed on the secondary
+var dualWriteFailures = metrics.NewCounterVec(
+	"mcp_registry_dual_write_failures_total",
+	"Number of writes mirrored to the secondary database that failed, by operation.",
+	"operation",
+)
+
+// DualWriteDB serves every read from a primary database and mirrors each successful write to
+// a secondary one, so a new backend stays current while existing data is migrated to it.
+// Failures on the secondary are logged and counted but never fail the request; running the
+// migration again repairs them. Leases, reindexing and the saved search position in the
+// change log stay on the primary.
+type DualWriteDB struct {
+	Database
+	secondary Database
+	timeout   time.Duration
+}
+
+// NewDualWriteDB mirrors writes on primary to secondary, giving each mirrored write timeout
+func NewDualWriteDB(primary, secondary Database, timeout time.Duration) *DualWriteDB {
+	return &DualWriteDB{Database: primary, secondary: secondary, timeout: timeout}
+}
+
+// mirror runs write against the secondary once the primary write has succeeded
+func (db *DualWriteDB) mirror(operation string, primaryErr error, write func(ctx context.Context, secondary Database) error) {
+	if primaryErr != nil {
+		return
+	}
+	// The request context may already be done once the primary has answered
+	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	defer cancel()
+	// A record deleted before it was copied is already absent from the secondary
+	if err := write(ctx, db.secondary); err != nil && !errors.Is(err, ErrNotFound) {
+		dualWriteFailures.Inc(operation)
+		log.Printf("Dual write: %s failed on the secondary database: %v", operation, err)
+	}
+}
+
+// mirrorVersion copies the stored state of a version from the primary, so the secondary
+// gets the same ID, release date, yanked state and latest flags
+func (db *DualWriteDB) mirrorVersion(operation, id string, primaryErr error) {
+	db.mirror(operation, primaryErr, func(ctx context.Context, secondary Database) error {
+		stored, err := db.Database.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return secondary.Replicate(ctx, stored, ConflictSourceWins)
+	})
+}
+
+// Publish adds a version to the primary and copies it to the secondary
+func (db *DualWriteDB) Publish(ctx context.Context, serverDetail *model.ServerDetail) error {
+	err := db.Database.Publish(ctx, serverDetail)
+	db.mirrorVersion("publish", serverDetail.ID, err)
+	return err
+}
+
+// SetYanked yanks or restores a version on the primary and copies the result to the secondary
+func (db *DualWriteDB) SetYanked(ctx context.Context, id string, yanked bool, reason string) error {
+	err := db.Database.SetYanked(ctx, id, yanked, reason)
+	db.mirrorVersion("set_yanked", id, err)
+	return err
+}
+
+// Replicate stores a replicated version on the primary and copies it to the secondary
+func (db *DualWriteDB) Replicate(ctx context.Context, serverDetail *model.ServerDetail, policy ConflictPolicy) error {
+	err := db.Database.Replicate(ctx, serverDetail, policy)
+	db.mirrorVersion("replicate", serverDetail.ID, err)
+	return err
+}
+
+// ImportSeed imports seed entries into both databases
+func (db *DualWriteDB) ImportSeed(ctx context.Context, servers []model.ServerDetail, policy ImportPolicy) (*ImportReport, error) {
+	report, err := db.Database.ImportSeed(ctx, servers, policy)
+	db.mirror("import_seed", err, func(ctx context.Context, secondary Database) error {
+		_, err := secondary.ImportSeed(ctx, servers, policy)
+		return err
+	})
+	return report, err
+}
+
+// SaveState writes a state value to both databases
+func (db *DualWriteDB) SaveState(ctx context.Context, key, value string) error {
+	err := db.Database.SaveState(ctx, key, value)
+	if key == SavedSearchRevisionKey {
+		return err
+	}
+	db.mirror("save_state", err, func(ctx context.Context, secondary Database) error {
+		return secondary.SaveState(ctx, key, value)
+	})
+	return err
+}
+
+// CreateSavedSearch stores a saved search in both databases
+func (db *DualWriteDB) CreateSavedSearch(ctx context.Context, search *model.SavedSearch) error {
+	err := db.Database.CreateSavedSearch(ctx, search)
+	db.mirror("create_saved_search", err, func(ctx context.Context, secondary Database) error {
+		return secondary.CreateSavedSearch(ctx, search)
+	})
+	return err
+}
+
+// DeleteSavedSearch removes a saved search from both databases
+func (db *DualWriteDB) DeleteSavedSearch(ctx context.Context, owner, id string) error {
+	err := db.Database.DeleteSavedSearch(ctx, owner, id)
+	db.mirror("delete_saved_search", err, func(ctx context.Context, secondary Database) error {
+		return secondary.DeleteSavedSearch(ctx, owner, id)
+	})
+	return err
+}
+
+// ConfirmSavedSearchEmail confirms the email address of a saved search in both databases
+func (db *DualWriteDB) ConfirmSavedSearchEmail(ctx context.Context, owner, id, tokenHash string) error {
+	err := db.Database.ConfirmSavedSearchEmail(ctx, owner, id, tokenHash)
+	db.mirror("confirm_saved_search_email", err, func(ctx context.Context, secondary Database) error {
+		return secondary.ConfirmSavedSearchEmail(ctx, owner, id, tokenHash)
+	})
+	return err
+}
+
+// CreateDraft stores a draft in both databases
+func (db *DualWriteDB) CreateDraft(ctx context.Context, draft *model.Draft) error {
+	err := db.Database.CreateDraft(ctx, draft)
+	db.mirror("create_draft", err, func(ctx context.Context, secondary Database) error {
+		return secondary.CreateDraft(ctx, draft)
+	})
+	return err
+}
+
+// UpdateDraft replaces a draft in both databases
+func (db *DualWriteDB) UpdateDraft(ctx context.Context, draft *model.Draft) error {
+	err := db.Database.UpdateDraft(ctx, draft)
+	db.mirror("update_draft", err, func(ctx context.Context, secondary Database) error {
+		return secondary.UpdateDraft(ctx, draft)
+	})
+	return err
+}
+
+// DeleteDraft removes a draft from both databases
+func (db *DualWriteDB) DeleteDraft(ctx context.Context, id string) error {
+	err := db.Database.DeleteDraft(ctx, id)
+	db.mirror("delete_draft", err, func(ctx context.Context, secondary Database) error {
+		return secondary.DeleteDraft(ctx, id)
+	})
+	return err
+}
+
+// SetFeatured features a server in both databases
+func (db *DualWriteDB) SetFeatured(ctx context.Context, featured *model.FeaturedServer) error {
+	err := db.Database.SetFeatured(ctx, featured)
+	db.mirror("set_featured", err, func(ctx context.Context, secondary Database) error {
+		return secondary.SetFeatured(ctx, featured)
+	})
+	return err
+}
+
+// DeleteFeatured stops featuring a server in both databases
+func (db *DualWriteDB) DeleteFeatured(ctx context.Context, name string) error {
+	err := db.Database.DeleteFeatured(ctx, name)
+	db.mirror("delete_featured", err, func(ctx context.Context, secondary Database) error {
+		return secondary.DeleteFeatured(ctx, name)
+	})
+	return err
+}
+
+// SetAlias stores an alias in both databases
+func (db *DualWriteDB) SetAlias(ctx context.Context, alias *model.ServerAlias) error {
+	err := db.Database.SetAlias(ctx, alias)
+	db.mirror("set_alias", err, func(ctx context.Context, secondary Database) error {
+		return secondary.SetAlias(ctx, alias)
+	})
+	return err
+}
+
+// DeleteAlias removes an alias from both databases
+func (db *DualWriteDB) DeleteAlias(ctx context.Context, slug string) error {
+	err := db.Database.DeleteAlias(ctx, slug)
+	db.mirror("delete_alias", err, func(ctx context.Context, secondary Database) error {
+		return secondary.DeleteAlias(ctx, slug)
+	})
+	return err
+}
+
+// ArchiveOrg archives an organization in both databases
+func (db *DualWriteDB) ArchiveOrg(ctx context.Context, archived *model.ArchivedOrg) error {
+	err := db.Database.ArchiveOrg(ctx, archived)
+	db.mirror("archive_org", err, func(ctx context.Context, secondary Database) error {
+		return secondary.ArchiveOrg(ctx, archived)
+	})
+	return err
+}
+
+// UnarchiveOrg restores an organization in both databases
+func (db *DualWriteDB) UnarchiveOrg(ctx context.Context, org string) error {
+	err := db.Database.UnarchiveOrg(ctx, org)
+	db.mirror("unarchive_org", err, func(ctx context.Context, secondary Database) error {
+		return secondary.UnarchiveOrg(ctx, org)
+	})
+	return err
+}
+
+// RevokeToken records a revoked token in both databases
+func (db *DualWriteDB) RevokeToken(ctx context.Context, revoked *model.RevokedToken) error {
+	err := db.Database.RevokeToken(ctx, revoked)
+	db.mirror("revoke_token", err, func(ctx context.Context, secondary Database) error {
+		return secondary.RevokeToken(ctx, revoked)
+	})
+	return err
+}
+
+// CreatePublishToken stores a publish token in both databases
+func (db *DualWriteDB) CreatePublishToken(ctx context.Context, token *model.PublishToken) error {
+	err := db.Database.CreatePublishToken(ctx, token)
+	db.mirror("create_publish_token", err, func(ctx context.Context, secondary Database) error {
+		return secondary.CreatePublishToken(ctx, token)
+	})
+	return err
+}
+
+// DeletePublishToken removes a publish token from both databases
+func (db *DualWriteDB) DeletePublishToken(ctx context.Context, serverName, id string) error {
+	err := db.Database.DeletePublishToken(ctx, serverName, id)
+	db.mirror("delete_publish_token", err, func(ctx context.Context, secondary Database) error {
+		return secondary.DeletePublishToken(ctx, serverName, id)
+	})
+	return err
+}
+
+// CreateReport stores an abuse report in both databases
+func (db *DualWriteDB) CreateReport(ctx context.Context, report *model.AbuseReport) error {
+	err := db.Database.CreateReport(ctx, report)
+	db.mirror("create_report", err, func(ctx context.Context, secondary Database) error {
+		return secondary.CreateReport(ctx, report)
+	})
+	return err
+}
+
+// UpdateReport replaces an abuse report in both databases
+func (db *DualWriteDB) UpdateReport(ctx context.Context, report *model.AbuseReport) error {
+	err := db.Database.UpdateReport(ctx, report)
+	db.mirror("update_report", err, func(ctx context.Context, secondary Database) error {
+		return secondary.UpdateReport(ctx, report)
+	})
+	return err
+}
+
+// Close closes both databases
+func (db *DualWriteDB) Close() error {
+	return errors.Join(db.Database.Close(), db.secondary.Close())
+}
@@ -1,43 +1,85 @@
 package database
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
-	"os"
-	"path/filepath"
+
 	"registry/internal/model"
 )
 
-// ReadSeedFile reads and parses the seed.json file - exported for use by all database implementations
-func ReadSeedFile(path string) ([]model.ServerDetail, error) {
-	log.Printf("Reading seed file from %s", path)
+// ImportPolicy decides what a seed import does with an entry whose ID already exists
+type ImportPolicy string
 
-	// Set default seed file path if not provided
-	if path == "" {
-		// Try to find the seed.json in the data directory
-		path = filepath.Join("data", "seed.json")
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			return nil, fmt.Errorf("seed file not found at %s", path)
-		}
-	}
+const (
+	// ImportSkip keeps the stored entry
+	ImportSkip ImportPolicy = "skip"
+	// ImportOverwrite replaces the stored entry with the seed entry
+	ImportOverwrite ImportPolicy = "overwrite"
+	// ImportMerge updates the stored entry with the fields the seed entry sets
+	ImportMerge ImportPolicy = "merge"
+)
 
-	// Read the file content
-	fileContent, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
+// IsValid reports whether p is a supported import policy
+func (p ImportPolicy) IsValid() bool {
+	return p == ImportSkip || p == ImportOverwrite || p == ImportMerge
+}
 
-	// Parse the JSON content
-	var servers []model.ServerDetail
-	if err := json.Unmarshal(fileContent, &servers); err != nil {
-		// Try parsing as a raw JSON array and then convert to our model
-		var rawData []map[string]interface{}
-		if jsonErr := json.Unmarshal(fileContent, &rawData); jsonErr != nil {
-			return nil, fmt.Errorf("failed to parse JSON: %w (original error: %w)", jsonErr, err)
-		}
-	}
+// ImportReport counts the outcome of every entry of a seed import
+type ImportReport struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Errored   int `json:"errored"`
+}
+
+// String summarizes the report for logs
+func (r *ImportReport) String() string {
+	return fmt.Sprintf("created %d, updated %d, unchanged %d, skipped %d, errored %d",
+		r.Created, r.Updated, r.Unchanged, r.Skipped, r.Errored)
+}
+
+// Add adds the counts of other to r
+func (r *ImportReport) Add(other *ImportReport) {
+	r.Created += other.Created
+	r.Updated += other.Updated
+	r.Unchanged += other.Unchanged
+	r.Skipped += other.Skipped
+	r.Errored += other.Errored
+}
 
-	log.Printf("Found %d server entries in seed file", len(servers))
-	return servers, nil
+// mergeSeedEntry returns stored updated with the fields set in entry. Lists replace the
+// stored lists as a whole when the seed entry has any items.
+func mergeSeedEntry(stored, entry *model.ServerDetail) model.ServerDetail {
+	merged := *stored
+	if entry.Name != "" {
+		merged.Name = entry.Name
+	}
+	if entry.Description != "" {
+		merged.Description = entry.Description
+	}
+	if entry.Repository.URL != "" {
+		merged.Repository = entry.Repository
+	}
+	if entry.VersionDetail.Version != "" {
+		merged.VersionDetail = entry.VersionDetail
+	}
+	if entry.Visibility != "" {
+		merged.Visibility = entry.Visibility
+	}
+	if len(entry.Packages) > 0 {
+		merged.Packages = entry.Packages
+	}
+	if len(entry.Remotes) > 0 {
+		merged.Remotes = entry.Remotes
+	}
+	if len(entry.Transports) > 0 {
+		merged.Transports = entry.Transports
+	}
+	if entry.Readme != "" {
+		merged.Readme = entry.Readme
+	}
+	if entry.Changelog != "" {
+		merged.Changelog = entry.Changelog
+	}
+	return merged
 }
@@ -0,0 +1,566 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"registry/internal/metrics"
+	"registry/internal/model"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// storeOperationDuration records the latency of every database operation
+var storeOperationDuration = metrics.NewHistogramVec(
+	"mcp_registry_store_operation_duration_seconds",
+	"Duration of database operations by backend, operation and outcome.",
+	metrics.DefaultBuckets,
+	"backend", "operation", "status",
+)
+
+// storeSlowOperations counts operations slower than the slow query threshold
+var storeSlowOperations = metrics.NewCounterVec(
+	"mcp_registry_store_slow_operations_total",
+	"Number of database operations slower than the slow query threshold.",
+	"backend", "operation",
+)
+
+// statsCacheTTL is how long store statistics are reused for metrics
+const statsCacheTTL = 15 * time.Second
+
+// longRunningOperations walk or rewrite the whole store and are expected to be slow
+var longRunningOperations = map[string]bool{
+	"iterate":         true,
+	"import_seed":     true,
+	"reindex":         true,
+	"collect_garbage": true,
+}
+
+// InstrumentedDB wraps a Database and records per-operation durations. It holds the
+// wrapped database in a field rather than embedding it, so every method of Database must
+// be wrapped here and none goes unmeasured.
+type InstrumentedDB struct {
+	inner         Database
+	backend       string
+	slowThreshold time.Duration
+	slowQueries   atomic.Int64
+
+	statsMu sync.Mutex
+	stats   *StoreStats
+	statsAt time.Time
+}
+
+// NewInstrumentedDB wraps db so every operation is timed under the given backend label and
+// the store statistics are exposed as metrics. Operations slower than slowThreshold are
+// logged and counted; a zero threshold disables slow query tracking.
+func NewInstrumentedDB(db Database, backend string, slowThreshold time.Duration) *InstrumentedDB {
+	instrumented := &InstrumentedDB{
+		inner:         db,
+		backend:       backend,
+		slowThreshold: slowThreshold,
+	}
+	instrumented.registerStatsMetrics()
+	return instrumented
+}
+
+// observe records the duration of an operation that started at start
+func (db *InstrumentedDB) observe(operation string, start time.Time, err error) {
+	db.observeRows(operation, start, err, -1, "")
+}
+
+// observeRows records the duration of an operation returning rows. The row count and the
+// detail describing the query are included when the operation is logged as slow; a
+// negative count is omitted.
+func (db *InstrumentedDB) observeRows(operation string, start time.Time, err error, rows int, detail string) {
+	status := "ok"
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = "not_found"
+	case err != nil:
+		status = "error"
+	}
+	elapsed := time.Since(start)
+	storeOperationDuration.Observe(elapsed.Seconds(), db.backend, operation, status)
+	if db.slowThreshold <= 0 || elapsed <= db.slowThreshold || longRunningOperations[operation] {
+		return
+	}
+
+	db.slowQueries.Add(1)
+	storeSlowOperations.Inc(db.backend, operation)
+
+	msg := fmt.Sprintf("Slow store operation: backend=%s operation=%s duration=%s status=%s",
+		db.backend, operation, elapsed.Round(time.Microsecond), status)
+	if rows >= 0 {
+		msg += fmt.Sprintf(" rows=%d", rows)
+	}
+	if detail != "" {
+		msg += " " + detail
+	}
+	log.Print(msg)
+}
+
+// List retrieves entries from the wrapped database
+func (db *InstrumentedDB) List(
+	ctx context.Context,
+	filter map[string]interface{},
+	order SortOrder,
+	after *Position,
+	limit int,
+) ([]*model.Server, *Position, error) {
+	start := time.Now()
+	servers, next, err := db.inner.List(ctx, filter, order, after, limit)
+	db.observeRows("list", start, err, len(servers), fmt.Sprintf("filter=%v order=%s limit=%d", filter, order, limit))
+	return servers, next, err
+}
+
+// GetByID retrieves a single entry from the wrapped database
+func (db *InstrumentedDB) GetByID(ctx context.Context, id string) (*model.ServerDetail, error) {
+	start := time.Now()
+	serverDetail, err := db.inner.GetByID(ctx, id)
+	db.observe("get_by_id", start, err)
+	return serverDetail, err
+}
+
+// Iterate streams entries from the wrapped database
+func (db *InstrumentedDB) Iterate(
+	ctx context.Context,
+	filter map[string]interface{},
+	fn func(*model.ServerDetail) error,
+) error {
+	start := time.Now()
+	err := db.inner.Iterate(ctx, filter, fn)
+	db.observe("iterate", start, err)
+	return err
+}
+
+// Count counts entries in the wrapped database
+func (db *InstrumentedDB) Count(ctx context.Context, filter map[string]interface{}) (int, error) {
+	start := time.Now()
+	count, err := db.inner.Count(ctx, filter)
+	db.observe("count", start, err)
+	return count, err
+}
+
+// Publish adds an entry to the wrapped database
+func (db *InstrumentedDB) Publish(ctx context.Context, serverDetail *model.ServerDetail) error {
+	start := time.Now()
+	err := db.inner.Publish(ctx, serverDetail)
+	db.observe("publish", start, err)
+	return err
+}
+
+// GetManifest retrieves a manifest from the wrapped database
+func (db *InstrumentedDB) GetManifest(ctx context.Context, digest string) ([]byte, error) {
+	start := time.Now()
+	manifest, err := db.inner.GetManifest(ctx, digest)
+	db.observe("get_manifest", start, err)
+	return manifest, err
+}
+
+// SetYanked updates the yanked state of an entry in the wrapped database
+func (db *InstrumentedDB) SetYanked(ctx context.Context, id string, yanked bool, reason string) error {
+	start := time.Now()
+	err := db.inner.SetYanked(ctx, id, yanked, reason)
+	db.observe("set_yanked", start, err)
+	return err
+}
+
+// Replicate stores a replicated entry in the wrapped database
+func (db *InstrumentedDB) Replicate(ctx context.Context, serverDetail *model.ServerDetail, policy ConflictPolicy) error {
+	start := time.Now()
+	err := db.inner.Replicate(ctx, serverDetail, policy)
+	db.observe("replicate", start, err)
+	return err
+}
+
+// LoadState reads a state value from the wrapped database
+func (db *InstrumentedDB) LoadState(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	value, err := db.inner.LoadState(ctx, key)
+	db.observe("load_state", start, err)
+	return value, err
+}
+
+// ListState lists the state values in the wrapped database
+func (db *InstrumentedDB) ListState(ctx context.Context) (map[string]string, error) {
+	start := time.Now()
+	state, err := db.inner.ListState(ctx)
+	db.observeRows("list_state", start, err, len(state), "")
+	return state, err
+}
+
+// SaveState writes a state value to the wrapped database
+func (db *InstrumentedDB) SaveState(ctx context.Context, key, value string) error {
+	start := time.Now()
+	err := db.inner.SaveState(ctx, key, value)
+	db.observe("save_state", start, err)
+	return err
+}
+
+// AcquireLease takes or renews a lease in the wrapped database
+func (db *InstrumentedDB) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	start := time.Now()
+	acquired, err := db.inner.AcquireLease(ctx, name, holder, ttl)
+	db.observe("acquire_lease", start, err)
+	return acquired, err
+}
+
+// ReleaseLease gives up a lease in the wrapped database
+func (db *InstrumentedDB) ReleaseLease(ctx context.Context, name, holder string) error {
+	start := time.Now()
+	err := db.inner.ReleaseLease(ctx, name, holder)
+	db.observe("release_lease", start, err)
+	return err
+}
+
+// ListChanges reads the change log of the wrapped database
+func (db *InstrumentedDB) ListChanges(
+	ctx context.Context,
+	sinceRevision int64,
+	sinceTime time.Time,
+	limit int,
+) ([]*model.Change, error) {
+	start := time.Now()
+	changes, err := db.inner.ListChanges(ctx, sinceRevision, sinceTime, limit)
+	db.observeRows("list_changes", start, err, len(changes), fmt.Sprintf("since_revision=%d limit=%d", sinceRevision, limit))
+	return changes, err
+}
+
+// HeadRevision reads the latest change revision of the wrapped database
+func (db *InstrumentedDB) HeadRevision(ctx context.Context) (int64, error) {
+	start := time.Now()
+	revision, err := db.inner.HeadRevision(ctx)
+	db.observe("head_revision", start, err)
+	return revision, err
+}
+
+// ImportSeed imports seed data into the wrapped database
+func (db *InstrumentedDB) ImportSeed(ctx context.Context, servers []model.ServerDetail, policy ImportPolicy) (*ImportReport, error) {
+	start := time.Now()
+	report, err := db.inner.ImportSeed(ctx, servers, policy)
+	db.observe("import_seed", start, err)
+	return report, err
+}
+
+// CreateSavedSearch stores a saved search in the wrapped database
+func (db *InstrumentedDB) CreateSavedSearch(ctx context.Context, search *model.SavedSearch) error {
+	start := time.Now()
+	err := db.inner.CreateSavedSearch(ctx, search)
+	db.observe("create_saved_search", start, err)
+	return err
+}
+
+// ListSavedSearches lists saved searches from the wrapped database
+func (db *InstrumentedDB) ListSavedSearches(ctx context.Context, owner string) ([]*model.SavedSearch, error) {
+	start := time.Now()
+	searches, err := db.inner.ListSavedSearches(ctx, owner)
+	db.observeRows("list_saved_searches", start, err, len(searches), "")
+	return searches, err
+}
+
+// DeleteSavedSearch removes a saved search from the wrapped database
+func (db *InstrumentedDB) DeleteSavedSearch(ctx context.Context, owner, id string) error {
+	start := time.Now()
+	err := db.inner.DeleteSavedSearch(ctx, owner, id)
+	db.observe("delete_saved_search", start, err)
+	return err
+}
+
+// ConfirmSavedSearchEmail confirms the email address of a saved search in the wrapped database
+func (db *InstrumentedDB) ConfirmSavedSearchEmail(ctx context.Context, owner, id, tokenHash string) error {
+	start := time.Now()
+	err := db.inner.ConfirmSavedSearchEmail(ctx, owner, id, tokenHash)
+	db.observe("confirm_saved_search_email", start, err)
+	return err
+}
+
+// CreateDraft stores a draft in the wrapped database
+func (db *InstrumentedDB) CreateDraft(ctx context.Context, draft *model.Draft) error {
+	start := time.Now()
+	err := db.inner.CreateDraft(ctx, draft)
+	db.observe("create_draft", start, err)
+	return err
+}
+
+// UpdateDraft replaces a draft in the wrapped database
+func (db *InstrumentedDB) UpdateDraft(ctx context.Context, draft *model.Draft) error {
+	start := time.Now()
+	err := db.inner.UpdateDraft(ctx, draft)
+	db.observe("update_draft", start, err)
+	return err
+}
+
+// GetDraft retrieves a draft from the wrapped database
+func (db *InstrumentedDB) GetDraft(ctx context.Context, id string) (*model.Draft, error) {
+	start := time.Now()
+	draft, err := db.inner.GetDraft(ctx, id)
+	db.observe("get_draft", start, err)
+	return draft, err
+}
+
+// ListDrafts lists drafts from the wrapped database
+func (db *InstrumentedDB) ListDrafts(ctx context.Context, name string) ([]*model.Draft, error) {
+	start := time.Now()
+	drafts, err := db.inner.ListDrafts(ctx, name)
+	db.observeRows("list_drafts", start, err, len(drafts), "")
+	return drafts, err
+}
+
+// DeleteDraft removes a draft from the wrapped database
+func (db *InstrumentedDB) DeleteDraft(ctx context.Context, id string) error {
+	start := time.Now()
+	err := db.inner.DeleteDraft(ctx, id)
+	db.observe("delete_draft", start, err)
+	return err
+}
+
+// SetFeatured features a server in the wrapped database
+func (db *InstrumentedDB) SetFeatured(ctx context.Context, featured *model.FeaturedServer) error {
+	start := time.Now()
+	err := db.inner.SetFeatured(ctx, featured)
+	db.observe("set_featured", start, err)
+	return err
+}
+
+// DeleteFeatured stops featuring a server in the wrapped database
+func (db *InstrumentedDB) DeleteFeatured(ctx context.Context, name string) error {
+	start := time.Now()
+	err := db.inner.DeleteFeatured(ctx, name)
+	db.observe("delete_featured", start, err)
+	return err
+}
+
+// ListFeatured lists featured servers from the wrapped database
+func (db *InstrumentedDB) ListFeatured(ctx context.Context) ([]*model.FeaturedServer, error) {
+	start := time.Now()
+	featured, err := db.inner.ListFeatured(ctx)
+	db.observeRows("list_featured", start, err, len(featured), "")
+	return featured, err
+}
+
+// SetAlias stores an alias in the wrapped database
+func (db *InstrumentedDB) SetAlias(ctx context.Context, alias *model.ServerAlias) error {
+	start := time.Now()
+	err := db.inner.SetAlias(ctx, alias)
+	db.observe("set_alias", start, err)
+	return err
+}
+
+// DeleteAlias removes an alias from the wrapped database
+func (db *InstrumentedDB) DeleteAlias(ctx context.Context, slug string) error {
+	start := time.Now()
+	err := db.inner.DeleteAlias(ctx, slug)
+	db.observe("delete_alias", start, err)
+	return err
+}
+
+// GetAlias retrieves an alias from the wrapped database
+func (db *InstrumentedDB) GetAlias(ctx context.Context, slug string) (*model.ServerAlias, error) {
+	start := time.Now()
+	alias, err := db.inner.GetAlias(ctx, slug)
+	db.observe("get_alias", start, err)
+	return alias, err
+}
+
+// ListAliases lists aliases from the wrapped database
+func (db *InstrumentedDB) ListAliases(ctx context.Context) ([]*model.ServerAlias, error) {
+	start := time.Now()
+	aliases, err := db.inner.ListAliases(ctx)
+	db.observeRows("list_aliases", start, err, len(aliases), "")
+	return aliases, err
+}
+
+// ArchiveOrg archives an organization in the wrapped database
+func (db *InstrumentedDB) ArchiveOrg(ctx context.Context, archived *model.ArchivedOrg) error {
+	start := time.Now()
+	err := db.inner.ArchiveOrg(ctx, archived)
+	db.observe("archive_org", start, err)
+	return err
+}
+
+// UnarchiveOrg restores an organization in the wrapped database
+func (db *InstrumentedDB) UnarchiveOrg(ctx context.Context, org string) error {
+	start := time.Now()
+	err := db.inner.UnarchiveOrg(ctx, org)
+	db.observe("unarchive_org", start, err)
+	return err
+}
+
+// ListArchivedOrgs lists archived organizations from the wrapped database
+func (db *InstrumentedDB) ListArchivedOrgs(ctx context.Context) ([]*model.ArchivedOrg, error) {
+	start := time.Now()
+	archived, err := db.inner.ListArchivedOrgs(ctx)
+	db.observeRows("list_archived_orgs", start, err, len(archived), "")
+	return archived, err
+}
+
+// ListRevokedTokens lists the revoked tokens in the wrapped database
+func (db *InstrumentedDB) ListRevokedTokens(ctx context.Context) ([]*model.RevokedToken, error) {
+	start := time.Now()
+	revoked, err := db.inner.ListRevokedTokens(ctx)
+	db.observeRows("list_revoked_tokens", start, err, len(revoked), "")
+	return revoked, err
+}
+
+// RevokeToken records a revoked token in the wrapped database
+func (db *InstrumentedDB) RevokeToken(ctx context.Context, revoked *model.RevokedToken) error {
+	start := time.Now()
+	err := db.inner.RevokeToken(ctx, revoked)
+	db.observe("revoke_token", start, err)
+	return err
+}
+
+// IsTokenRevoked looks up a token revocation in the wrapped database
+func (db *InstrumentedDB) IsTokenRevoked(ctx context.Context, digest string) (bool, error) {
+	start := time.Now()
+	revoked, err := db.inner.IsTokenRevoked(ctx, digest)
+	db.observe("is_token_revoked", start, err)
+	return revoked, err
+}
+
+// CreatePublishToken stores a publish token in the wrapped database
+func (db *InstrumentedDB) CreatePublishToken(ctx context.Context, token *model.PublishToken) error {
+	start := time.Now()
+	err := db.inner.CreatePublishToken(ctx, token)
+	db.observe("create_publish_token", start, err)
+	return err
+}
+
+// GetPublishToken looks up a publish token in the wrapped database
+func (db *InstrumentedDB) GetPublishToken(ctx context.Context, digest string) (*model.PublishToken, error) {
+	start := time.Now()
+	token, err := db.inner.GetPublishToken(ctx, digest)
+	db.observe("get_publish_token", start, err)
+	return token, err
+}
+
+// ListPublishTokens lists the publish tokens of a server from the wrapped database
+func (db *InstrumentedDB) ListPublishTokens(ctx context.Context, serverName string) ([]*model.PublishToken, error) {
+	start := time.Now()
+	tokens, err := db.inner.ListPublishTokens(ctx, serverName)
+	db.observeRows("list_publish_tokens", start, err, len(tokens), "")
+	return tokens, err
+}
+
+// DeletePublishToken removes a publish token from the wrapped database
+func (db *InstrumentedDB) DeletePublishToken(ctx context.Context, serverName, id string) error {
+	start := time.Now()
+	err := db.inner.DeletePublishToken(ctx, serverName, id)
+	db.observe("delete_publish_token", start, err)
+	return err
+}
+
+// CreateReport stores an abuse report in the wrapped database
+func (db *InstrumentedDB) CreateReport(ctx context.Context, report *model.AbuseReport) error {
+	start := time.Now()
+	err := db.inner.CreateReport(ctx, report)
+	db.observe("create_report", start, err)
+	return err
+}
+
+// GetReport looks up an abuse report in the wrapped database
+func (db *InstrumentedDB) GetReport(ctx context.Context, id string) (*model.AbuseReport, error) {
+	start := time.Now()
+	report, err := db.inner.GetReport(ctx, id)
+	db.observe("get_report", start, err)
+	return report, err
+}
+
+// ListReports lists abuse reports from the wrapped database
+func (db *InstrumentedDB) ListReports(ctx context.Context, status model.ReportStatus, limit int) ([]*model.AbuseReport, error) {
+	start := time.Now()
+	reports, err := db.inner.ListReports(ctx, status, limit)
+	db.observeRows("list_reports", start, err, len(reports), "")
+	return reports, err
+}
+
+// UpdateReport replaces an abuse report in the wrapped database
+func (db *InstrumentedDB) UpdateReport(ctx context.Context, report *model.AbuseReport) error {
+	start := time.Now()
+	err := db.inner.UpdateReport(ctx, report)
+	db.observe("update_report", start, err)
+	return err
+}
+
+// CollectGarbage prunes stale records from the wrapped database
+func (db *InstrumentedDB) CollectGarbage(ctx context.Context, policy RetentionPolicy) (*GCReport, error) {
+	start := time.Now()
+	report, err := db.inner.CollectGarbage(ctx, policy)
+	db.observe("collect_garbage", start, err)
+	return report, err
+}
+
+// Reindex rebuilds the indexes of the wrapped database
+func (db *InstrumentedDB) Reindex(ctx context.Context, progress func(done, total int)) error {
+	start := time.Now()
+	err := db.inner.Reindex(ctx, progress)
+	db.observe("reindex", start, err)
+	return err
+}
+
+// Stats reports the wrapped database's statistics along with the slow operations counted here
+func (db *InstrumentedDB) Stats(ctx context.Context) (*StoreStats, error) {
+	stats, err := db.inner.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.SlowQueries = db.slowQueries.Load()
+	return stats, nil
+}
+
+// cachedStats returns statistics at most statsCacheTTL old, so that metric scrapes reading
+// several gauges query the database once. The last good value is kept on errors.
+func (db *InstrumentedDB) cachedStats() *StoreStats {
+	db.statsMu.Lock()
+	defer db.statsMu.Unlock()
+
+	if db.stats != nil && time.Since(db.statsAt) < statsCacheTTL {
+		return db.stats
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		log.Printf("Failed to collect store stats for metrics: %v", err)
+		if db.stats == nil {
+			return &StoreStats{Backend: db.backend}
+		}
+		return db.stats
+	}
+	db.stats, db.statsAt = stats, time.Now()
+	return stats
+}
+
+// registerStatsMetrics exposes the store statistics as gauges
+func (db *InstrumentedDB) registerStatsMetrics() {
+	gauges := []struct {
+		name, help string
+		value      func(*StoreStats) float64
+	}{
+		{"mcp_registry_store_entries", "Number of stored server entries, including previous versions.",
+			func(s *StoreStats) float64 { return float64(s.Entries) }},
+		{"mcp_registry_store_manifests", "Number of stored version manifests.",
+			func(s *StoreStats) float64 { return float64(s.Manifests) }},
+		{"mcp_registry_store_changes", "Number of entries retained in the change log.",
+			func(s *StoreStats) float64 { return float64(s.Changes) }},
+		{"mcp_registry_store_size_bytes", "Space the stored data and indexes take on disk.",
+			func(s *StoreStats) float64 { return float64(s.SizeBytes) }},
+		{"mcp_registry_store_open_connections", "Connections open to the database server.",
+			func(s *StoreStats) float64 { return float64(s.OpenConnections) }},
+	}
+	for _, g := range gauges {
+		value := g.value
+		metrics.NewGaugeFunc(g.name, g.help, func() float64 { return value(db.cachedStats()) })
+	}
+}
+
+// Close closes the wrapped database
+func (db *InstrumentedDB) Close() error {
+	start := time.Now()
+	err := db.inner.Close()
+	db.observe("close", start, err)
+	return err
+}
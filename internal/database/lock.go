@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// lockRetryInterval is how often RunExclusive retries a lease held by another instance
+const lockRetryInterval = time.Second
+
+// ErrLeaseLost is the cause of the cancellation of a function run by RunExclusive whose
+// lease could not be renewed
+var ErrLeaseLost = errors.New("lease lost")
+
+// NewLeaseHolder returns an identifier for this process to hold leases under
+func NewLeaseHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "registry"
+	}
+	return fmt.Sprintf("%s-%s", host, uuid.NewString()[:8])
+}
+
+// RunExclusive runs fn while holding the named lease, so that it runs on one instance at a
+// time across every instance sharing the database. It waits for other holders to finish
+// and renews the lease while fn runs, so fn may outlive ttl. When a renewal fails or
+// another instance took the lease over, the context of fn is cancelled with cause
+// ErrLeaseLost, and RunExclusive returns an error wrapping ErrLeaseLost.
+func RunExclusive(ctx context.Context, db Database, name string, ttl time.Duration, fn func(context.Context) error) error {
+	holder := NewLeaseHolder()
+	for {
+		acquired, err := db.AcquireLease(ctx, name, holder, ttl)
+		if err != nil {
+			return fmt.Errorf("acquiring %s lock: %w", name, err)
+		}
+		if acquired {
+			break
+		}
+
+		log.Printf("Waiting for another instance to finish %s", name)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %s lock: %w", name, ctx.Err())
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	fnCtx, cancelFn := context.WithCancelCause(ctx)
+	defer cancelFn(nil)
+	renewCtx, stopRenewing := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				acquired, err := db.AcquireLease(renewCtx, name, holder, ttl)
+				if renewCtx.Err() != nil {
+					return
+				}
+				// Another instance may run fn as soon as the lease lapses, so stop at once
+				switch {
+				case err != nil:
+					log.Printf("Failed to renew %s lock, stopping: %v", name, err)
+					cancelFn(fmt.Errorf("%w: %v", ErrLeaseLost, err))
+					return
+				case !acquired:
+					log.Printf("Lost %s lock to another instance, stopping", name)
+					cancelFn(ErrLeaseLost)
+					return
+				}
+			}
+		}
+	}()
+
+	err := fn(fnCtx)
+
+	stopRenewing()
+	<-renewed
+	if cause := context.Cause(fnCtx); errors.Is(cause, ErrLeaseLost) {
+		err = fmt.Errorf("running %s: %w", name, cause)
+	}
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if releaseErr := db.ReleaseLease(releaseCtx, name, holder); releaseErr != nil {
+		log.Printf("Failed to release %s lock: %v", name, releaseErr)
+	}
+
+	return err
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"log"
 	"registry/internal/model"
@@ -9,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,7 +19,69 @@ import (
 // MemoryDB is an in-memory implementation of the Database interface
 type MemoryDB struct {
 	entries map[string]*model.ServerDetail
-	mu      sync.RWMutex
+	// indexes holds the entries pre-sorted for every SortOrder, maintained on write
+	indexes map[SortOrder][]*model.ServerDetail
+	// manifests holds the immutable manifest of every published version, keyed by digest
+	manifests map[string][]byte
+	// changes is the change log; the entry at index i has revision prunedChanges+i+1
+	changes []*model.Change
+	// prunedChanges counts the entries garbage collected from the start of the log
+	prunedChanges int64
+	// state holds small values such as replication progress
+	state map[string]string
+	// leases maps lease names to their current holder
+	leases map[string]lease
+	// savedSearches maps saved search IDs to the searches
+	savedSearches map[string]*model.SavedSearch
+	// drafts maps draft IDs to unpublished server versions
+	drafts map[string]*model.Draft
+	// featured maps server names to their curation entries
+	featured map[string]*model.FeaturedServer
+	// aliases maps slugs of former server names to their aliases
+	aliases map[string]*model.ServerAlias
+	// archivedOrgs maps lower case organizations to their archive entries
+	archivedOrgs map[string]*model.ArchivedOrg
+	// revokedTokens maps token digests to their revocations
+	revokedTokens map[string]*model.RevokedToken
+	// publishTokens maps publish token IDs to the tokens
+	publishTokens map[string]*model.PublishToken
+	reports       map[string]*model.AbuseReport
+	// names maps case-folded names to every spelling stored under them
+	names map[string]map[string]bool
+	// nameUniqueness decides which spellings of an existing name Publish accepts
+	nameUniqueness NameUniqueness
+	mu             sync.RWMutex
+	// lockWait accumulates nanoseconds spent waiting for mu, reported by Stats
+	lockWait atomic.Int64
+}
+
+// sortLess defines the ordering of each SortOrder; ties are always broken by ID
+var sortLess = map[SortOrder]func(a, b *model.ServerDetail) bool{
+	SortByID: func(a, b *model.ServerDetail) bool {
+		return a.ID < b.ID
+	},
+	SortByName: func(a, b *model.ServerDetail) bool {
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	},
+	SortByCreatedAt: func(a, b *model.ServerDetail) bool {
+		if c := compareReleaseDates(a.VersionDetail.ReleaseDate, b.VersionDetail.ReleaseDate); c != 0 {
+			return c > 0
+		}
+		return a.ID < b.ID
+	},
+}
+
+// compareReleaseDates compares two RFC3339 release dates, falling back to string comparison
+func compareReleaseDates(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return ta.Compare(tb)
 }
 
 // NewMemoryDB creates a new instance of the in-memory database
@@ -29,18 +93,112 @@ func NewMemoryDB(e map[string]*model.Server) *MemoryDB {
 			Server: *v,
 		}
 	}
-	return &MemoryDB{
-		entries: serverDetails,
+	db := &MemoryDB{
+		entries:        serverDetails,
+		manifests:      make(map[string][]byte),
+		state:          make(map[string]string),
+		leases:         make(map[string]lease),
+		savedSearches:  make(map[string]*model.SavedSearch),
+		drafts:         make(map[string]*model.Draft),
+		featured:       make(map[string]*model.FeaturedServer),
+		aliases:        make(map[string]*model.ServerAlias),
+		archivedOrgs:   make(map[string]*model.ArchivedOrg),
+		revokedTokens:  make(map[string]*model.RevokedToken),
+		publishTokens:  make(map[string]*model.PublishToken),
+		reports:        make(map[string]*model.AbuseReport),
+		names:          make(map[string]map[string]bool),
+		nameUniqueness: NamesCaseInsensitive,
+	}
+	for _, entry := range serverDetails {
+		setDerivedNames(entry)
+		db.recordName(entry.Name)
+	}
+	db.rebuildIndexes()
+	return db
+}
+
+// SetNameUniqueness sets which spellings of a name Publish accepts; it must be called
+// before the database is used
+func (db *MemoryDB) SetNameUniqueness(uniqueness NameUniqueness) {
+	db.nameUniqueness = uniqueness
+}
+
+// claimName records the spelling of name before a version is published under it, failing
+// with ErrAlreadyExists when names are unique case-insensitively and the same folded name
+// is stored under other spellings only; callers must hold the write lock
+func (db *MemoryDB) claimName(name string) error {
+	spellings := db.names[nameKey(name)]
+	if db.nameUniqueness == NamesCaseInsensitive && len(spellings) > 0 && !spellings[name] {
+		registered := make([]string, 0, len(spellings))
+		for spelling := range spellings {
+			registered = append(registered, spelling)
+		}
+		sort.Strings(registered)
+		return fmt.Errorf("%w: name %s is registered as %s", ErrAlreadyExists, name, registered[0])
+	}
+	db.recordName(name)
+	return nil
+}
+
+// recordName adds the spelling of name without checking it; callers must hold the write lock
+func (db *MemoryDB) recordName(name string) {
+	key := nameKey(name)
+	if db.names[key] == nil {
+		db.names[key] = make(map[string]bool)
+	}
+	db.names[key][name] = true
+}
+
+// rlock acquires the read lock, recording the time spent waiting for it
+func (db *MemoryDB) rlock() {
+	start := time.Now()
+	db.mu.RLock()
+	db.lockWait.Add(int64(time.Since(start)))
+}
+
+// lock acquires the write lock, recording the time spent waiting for it
+func (db *MemoryDB) lock() {
+	start := time.Now()
+	db.mu.Lock()
+	db.lockWait.Add(int64(time.Since(start)))
+}
+
+// rebuildIndexes re-sorts every index from scratch; callers must hold the write lock
+func (db *MemoryDB) rebuildIndexes() {
+	db.indexes = make(map[SortOrder][]*model.ServerDetail, len(sortLess))
+	for order, less := range sortLess {
+		index := make([]*model.ServerDetail, 0, len(db.entries))
+		for _, entry := range db.entries {
+			index = append(index, entry)
+		}
+		sort.Slice(index, func(i, j int) bool {
+			return less(index[i], index[j])
+		})
+		db.indexes[order] = index
+	}
+}
+
+// insertIndexed adds a new entry to every index at its sorted position; callers must hold the write lock
+func (db *MemoryDB) insertIndexed(entry *model.ServerDetail) {
+	for order, less := range sortLess {
+		index := db.indexes[order]
+		pos := sort.Search(len(index), func(i int) bool {
+			return less(entry, index[i])
+		})
+		index = append(index, nil)
+		copy(index[pos+1:], index[pos:])
+		index[pos] = entry
+		db.indexes[order] = index
 	}
 }
 
-// compareSemanticVersions compares two semantic version strings
+// CompareSemanticVersions compares two semantic version strings
 // Returns:
 //
 //	-1 if version1 < version2
 //	 0 if version1 == version2
 //	+1 if version1 > version2
-func compareSemanticVersions(version1, version2 string) int {
+func CompareSemanticVersions(version1, version2 string) int {
 	// Simple semantic version comparison
 	// Assumes format: major.minor.patch
 
@@ -85,102 +243,157 @@ func compareSemanticVersions(version1, version2 string) int {
 	return 0
 }
 
+// MatchesFilter reports whether an entry satisfies every key of the filter. It defines the
+// filter semantics every backend implements, and evaluates filters outside of List.
+func MatchesFilter(entry *model.ServerDetail, filter map[string]interface{}) bool {
+	for key, value := range filter {
+		switch key {
+		case "name":
+			if entry.Name != value.(string) {
+				return false
+			}
+		case "slug":
+			if entry.Slug != value.(string) {
+				return false
+			}
+		case "repoUrl":
+			if entry.Repository.URL != value.(string) {
+				return false
+			}
+		case "serverDetail.id":
+			if entry.ID != value.(string) {
+				return false
+			}
+		case "version":
+			if entry.VersionDetail.Version != value.(string) {
+				return false
+			}
+		case "namespace":
+			namespace, _, ok := strings.Cut(entry.Name, "/")
+			if !ok || !strings.EqualFold(namespace, value.(string)) {
+				return false
+			}
+		case "released_since":
+			// Release dates are recorded as RFC 3339 timestamps in UTC, which sort as strings
+			if entry.VersionDetail.ReleaseDate < value.(string) {
+				return false
+			}
+		case "is_latest":
+			if entry.VersionDetail.IsLatest != value.(bool) {
+				return false
+			}
+		case "yanked":
+			if entry.VersionDetail.Yanked != value.(bool) {
+				return false
+			}
+		case "visibility":
+			if entry.Visibility.Effective() != value.(model.Visibility) {
+				return false
+			}
+		case "author":
+			if !strings.EqualFold(model.ExtractAuthorFromRepoURL(entry.Repository.URL), value.(string)) {
+				return false
+			}
+		case "exclude_authors":
+			author := model.ExtractAuthorFromRepoURL(entry.Repository.URL)
+			for _, excluded := range value.([]string) {
+				if strings.EqualFold(author, excluded) {
+					return false
+				}
+			}
+		case "transport":
+			if !entry.SupportsTransport(model.TransportType(value.(string))) {
+				return false
+			}
+		case "platform":
+			if !entry.SupportsPlatform(value.(model.Platform)) {
+				return false
+			}
+		case "search":
+			if !value.(Search).Matches(&entry.Server) {
+				return false
+			}
+		case "all":
+			for _, sub := range value.([]map[string]interface{}) {
+				if !MatchesFilter(entry, sub) {
+					return false
+				}
+			}
+		case "any":
+			matched := false
+			for _, sub := range value.([]map[string]interface{}) {
+				if MatchesFilter(entry, sub) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+			// Add more filter options as needed
+		}
+	}
+	return true
+}
+
 // List retrieves all MCPRegistry entries with optional filtering and pagination
-//
-//gocognit:ignore
 func (db *MemoryDB) List(
 	ctx context.Context,
 	filter map[string]interface{},
-	cursor string,
+	order SortOrder,
+	after *Position,
 	limit int,
-) ([]*model.Server, string, error) {
+) ([]*model.Server, *Position, error) {
 	if ctx.Err() != nil {
-		return nil, "", ctx.Err()
+		return nil, nil, ctx.Err()
 	}
 
 	if limit <= 0 {
 		limit = 10 // Default limit
 	}
 
-	db.mu.RLock()
-	defer db.mu.RUnlock()
-
-	// Convert all entries to a slice for pagination
-	var allEntries []*model.Server
-	for _, entry := range db.entries {
-		serverCopy := entry.Server
-		allEntries = append(allEntries, &serverCopy)
+	less, ok := sortLess[order]
+	if !ok {
+		less = sortLess[SortByID]
+		order = SortByID
 	}
 
-	// Simple filtering implementation
-	var filteredEntries []*model.Server
-	for _, entry := range allEntries {
-		include := true
-
-		// Apply filters if any
-		for key, value := range filter {
-			switch key {
-			case "name":
-				if entry.Name != value.(string) {
-					include = false
-				}
-			case "repoUrl":
-				if entry.Repository.URL != value.(string) {
-					include = false
-				}
-			case "serverDetail.id":
-				if entry.ID != value.(string) {
-					include = false
-				}
-			case "version":
-				if entry.VersionDetail.Version != value.(string) {
-					include = false
-				}
-				// Add more filter options as needed
-			}
-		}
+	db.rlock()
+	defer db.mu.RUnlock()
 
-		if include {
-			filteredEntries = append(filteredEntries, entry)
-		}
-	}
+	index := db.indexes[order]
 
-	// Find starting point for cursor-based pagination
+	// Find starting point for keyset pagination: the first entry sorting after the position
 	startIdx := 0
-	if cursor != "" {
-		for i, entry := range filteredEntries {
-			if entry.ID == cursor {
-				startIdx = i + 1 // Start after the cursor
-				break
-			}
-		}
-	}
-
-	// Sort filteredEntries by ID for consistent pagination
-	sort.Slice(filteredEntries, func(i, j int) bool {
-		return filteredEntries[i].ID < filteredEntries[j].ID
-	})
-
-	// Apply pagination
-	endIdx := startIdx + limit
-	if endIdx > len(filteredEntries) {
-		endIdx = len(filteredEntries)
+	if after != nil {
+		positionEntry := &model.ServerDetail{Server: model.Server{
+			ID:            after.ID,
+			Name:          after.Name,
+			VersionDetail: model.VersionDetail{ReleaseDate: after.ReleaseDate},
+		}}
+		startIdx = sort.Search(len(index), func(i int) bool {
+			return less(positionEntry, index[i])
+		})
 	}
 
-	var result []*model.Server
-	if startIdx < len(filteredEntries) {
-		result = filteredEntries[startIdx:endIdx]
-	} else {
-		result = []*model.Server{}
+	// Collect one entry beyond the page to know whether a next page exists
+	result := make([]*model.Server, 0, limit+1)
+	for i := startIdx; i < len(index) && len(result) <= limit; i++ {
+		if !MatchesFilter(index[i], filter) {
+			continue
+		}
+		serverCopy := index[i].Server
+		result = append(result, &serverCopy)
 	}
 
-	// Determine next cursor
-	nextCursor := ""
-	if endIdx < len(filteredEntries) {
-		nextCursor = filteredEntries[endIdx-1].ID
+	// Determine the next position
+	var next *Position
+	if len(result) > limit {
+		result = result[:limit]
+		next = PositionOf(order, result[limit-1])
 	}
 
-	return result, nextCursor, nil
+	return result, next, nil
 }
 
 // GetByID retrieves a single ServerDetail by its ID
@@ -189,7 +402,7 @@ func (db *MemoryDB) GetByID(ctx context.Context, id string) (*model.ServerDetail
 		return nil, ctx.Err()
 	}
 
-	db.mu.RLock()
+	db.rlock()
 	defer db.mu.RUnlock()
 
 	if entry, exists := db.entries[id]; exists {
@@ -201,15 +414,89 @@ func (db *MemoryDB) GetByID(ctx context.Context, id string) (*model.ServerDetail
 	return nil, ErrNotFound
 }
 
+// Iterate calls fn for every ServerDetail matching the filter, in ID order
+func (db *MemoryDB) Iterate(
+	ctx context.Context,
+	filter map[string]interface{},
+	fn func(*model.ServerDetail) error,
+) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// Snapshot the matching entries so fn runs without holding the lock
+	db.rlock()
+	index := db.indexes[SortByID]
+	snapshot := make([]model.ServerDetail, 0, len(index))
+	for _, entry := range index {
+		if MatchesFilter(entry, filter) {
+			snapshot = append(snapshot, *entry)
+		}
+	}
+	db.mu.RUnlock()
+
+	for i := range snapshot {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := fn(&snapshot[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count returns the number of ServerDetails matching the filter
+func (db *MemoryDB) Count(ctx context.Context, filter map[string]interface{}) (int, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	count := 0
+	for _, entry := range db.entries {
+		if MatchesFilter(entry, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // Publish adds a new ServerDetail to the database
 func (db *MemoryDB) Publish(ctx context.Context, serverDetail *model.ServerDetail) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
-	db.mu.Lock()
+	db.lock()
+	defer db.mu.Unlock()
+	return db.publishLocked(serverDetail)
+}
+
+// PublishBatch publishes versions in order under a single acquisition of the write lock,
+// returning the error of each as Publish would
+func (db *MemoryDB) PublishBatch(ctx context.Context, serverDetails []*model.ServerDetail) []error {
+	errs := make([]error, len(serverDetails))
+	if err := ctx.Err(); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	db.lock()
 	defer db.mu.Unlock()
+	for i, serverDetail := range serverDetails {
+		errs[i] = db.publishLocked(serverDetail)
+	}
+	return errs
+}
 
+// publishLocked implements Publish; callers must hold the write lock
+func (db *MemoryDB) publishLocked(serverDetail *model.ServerDetail) error {
 	// check for name
 	if serverDetail.Name == "" {
 		return ErrInvalidInput
@@ -225,14 +512,14 @@ func (db *MemoryDB) Publish(ctx context.Context, serverDetail *model.ServerDetai
 			}
 
 			// Track the latest version for this package name
-			if latestVersion == "" || compareSemanticVersions(entry.VersionDetail.Version, latestVersion) > 0 {
+			if latestVersion == "" || CompareSemanticVersions(entry.VersionDetail.Version, latestVersion) > 0 {
 				latestVersion = entry.VersionDetail.Version
 			}
 		}
 	}
 
 	// If we found existing versions, check if the new version is older than the latest
-	if latestVersion != "" && compareSemanticVersions(serverDetail.VersionDetail.Version, latestVersion) < 0 {
+	if latestVersion != "" && CompareSemanticVersions(serverDetail.VersionDetail.Version, latestVersion) < 0 {
 		return ErrInvalidVersion
 	}
 
@@ -240,56 +527,1011 @@ func (db *MemoryDB) Publish(ctx context.Context, serverDetail *model.ServerDetai
 		return ErrInvalidInput
 	}
 
-	// Generate a new ID for the server detail
-	serverDetail.ID = uuid.New().String()
+	// Generate a new ID unless the caller chose one
+	if serverDetail.ID == "" {
+		serverDetail.ID = uuid.New().String()
+	} else if _, exists := db.entries[serverDetail.ID]; exists {
+		return ErrAlreadyExists
+	}
+	if err := db.claimName(serverDetail.Name); err != nil {
+		return err
+	}
 	serverDetail.VersionDetail.IsLatest = true // Assume the new version is the latest
-	serverDetail.VersionDetail.ReleaseDate = time.Now().Format(time.RFC3339)
+	serverDetail.VersionDetail.ReleaseDate = time.Now().UTC().Format(time.RFC3339)
+	setDerivedNames(serverDetail)
+	if err := db.storeManifest(serverDetail); err != nil {
+		return err
+	}
 	// Store a copy of the entire ServerDetail
 	serverDetailCopy := *serverDetail
 	db.entries[serverDetail.ID] = &serverDetailCopy
+	db.insertIndexed(&serverDetailCopy)
+	db.updateLatest(serverDetail.Name)
+	db.recordChange(model.NewServerChange(model.ChangeOpPublish, &serverDetailCopy))
+
+	return nil
+}
+
+// storeManifest records the manifest of serverDetail and sets its digest; callers must hold the write lock
+func (db *MemoryDB) storeManifest(serverDetail *model.ServerDetail) error {
+	digest, manifest, err := serverDetail.Manifest()
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %w", err)
+	}
+	serverDetail.Digest = digest
+	db.manifests[digest] = manifest
+	return nil
+}
+
+// GetManifest retrieves the manifest stored under a digest
+func (db *MemoryDB) GetManifest(ctx context.Context, digest string) ([]byte, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	manifest, exists := db.manifests[digest]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return manifest, nil
+}
+
+// SetYanked updates the yanked state of an entry in place and recomputes the latest version of its server
+func (db *MemoryDB) SetYanked(ctx context.Context, id string, yanked bool, reason string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	entry, exists := db.entries[id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	entry.VersionDetail.Yanked = yanked
+	entry.VersionDetail.YankedReason = ""
+	if yanked {
+		entry.VersionDetail.YankedReason = reason
+	}
+
+	db.updateLatest(entry.Name)
+
+	op := model.ChangeOpUnyank
+	if yanked {
+		op = model.ChangeOpYank
+	}
+	db.recordChange(model.NewServerChange(op, entry))
 
 	return nil
 }
 
-// ImportSeed imports initial data from a seed file into memory database
-func (db *MemoryDB) ImportSeed(ctx context.Context, seedFilePath string) error {
+// updateLatest flags the highest version of a server that is not yanked as latest; callers must hold the write lock
+func (db *MemoryDB) updateLatest(name string) {
+	var versions []*model.ServerDetail
+	for _, e := range db.entries {
+		if e.Name == name {
+			versions = append(versions, e)
+		}
+	}
+	latest := latestUnyanked(versions)
+	for _, v := range versions {
+		v.VersionDetail.IsLatest = v == latest
+	}
+}
+
+// Replicate stores a version copied from another registry, keeping its ID, release date and yanked state
+func (db *MemoryDB) Replicate(ctx context.Context, serverDetail *model.ServerDetail, policy ConflictPolicy) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
-	// Read the seed file
-	seedData, err := ReadSeedFile(seedFilePath)
+	digest, manifest, err := replicatedManifest(serverDetail)
 	if err != nil {
-		return fmt.Errorf("failed to read seed file: %w", err)
+		return err
+	}
+	setDerivedNames(serverDetail)
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	// Resolve local versions that clash on name and version but not ID
+	resort := false
+	for id, e := range db.entries {
+		if id == serverDetail.ID || e.Name != serverDetail.Name ||
+			e.VersionDetail.Version != serverDetail.VersionDetail.Version {
+			continue
+		}
+		if policy == ConflictLocalWins {
+			return fmt.Errorf("%w: %s %s", ErrConflict, serverDetail.Name, serverDetail.VersionDetail.Version)
+		}
+		delete(db.entries, id)
+		resort = true
+	}
+
+	existing := db.entries[serverDetail.ID]
+	entry := *serverDetail
+	entry.Digest = digest
+	db.manifests[digest] = manifest
+	db.entries[entry.ID] = &entry
+	db.recordName(entry.Name)
+
+	// Replacing an entry may change its sort position, so re-sort rather than insert
+	if resort || existing != nil {
+		db.rebuildIndexes()
+	} else {
+		db.insertIndexed(&entry)
+	}
+	db.updateLatest(entry.Name)
+
+	if op := replicatedOp(existing, &entry); op != "" {
+		db.recordChange(model.NewServerChange(op, &entry))
+	}
+	return nil
+}
+
+// LoadState reads a value from the key-value state store
+func (db *MemoryDB) LoadState(ctx context.Context, key string) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	value, exists := db.state[key]
+	if !exists {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// SaveState writes a value to the key-value state store
+func (db *MemoryDB) SaveState(ctx context.Context, key, value string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	db.state[key] = value
+	return nil
+}
+
+// ListState returns a copy of the state store
+func (db *MemoryDB) ListState(ctx context.Context) (map[string]string, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	state := make(map[string]string, len(db.state))
+	for key, value := range db.state {
+		state[key] = value
+	}
+	return state, nil
+}
+
+// lease is a named lease held until it expires
+type lease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// AcquireLease takes or renews a lease. A memory database is private to one process, so
+// this only arbitrates between holders within that process.
+func (db *MemoryDB) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	if current, held := db.leases[name]; held && current.holder != holder && now.Before(current.expiresAt) {
+		return false, nil
+	}
+	db.leases[name] = lease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// ReleaseLease gives up a lease owned by holder
+func (db *MemoryDB) ReleaseLease(ctx context.Context, name, holder string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if db.leases[name].holder == holder {
+		delete(db.leases, name)
+	}
+	return nil
+}
+
+// recordChange appends a change to the log, assigning the next revision; callers must hold the write lock
+func (db *MemoryDB) recordChange(change *model.Change) {
+	change.Revision = db.prunedChanges + int64(len(db.changes)) + 1
+	db.changes = append(db.changes, change)
+}
+
+// ListChanges returns change log entries after a revision or time
+func (db *MemoryDB) ListChanges(
+	ctx context.Context,
+	sinceRevision int64,
+	sinceTime time.Time,
+	limit int,
+) ([]*model.Change, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	if sinceTime.IsZero() && sinceRevision < db.prunedChanges {
+		return nil, ErrPruned
+	}
+
+	start := int(min(max(sinceRevision-db.prunedChanges, 0), int64(len(db.changes))))
+	if !sinceTime.IsZero() {
+		start = sort.Search(len(db.changes), func(i int) bool {
+			return db.changes[i].Timestamp.After(sinceTime)
+		})
+	}
+	end := min(start+limit, len(db.changes))
+
+	result := make([]*model.Change, 0, end-start)
+	for _, change := range db.changes[start:end] {
+		changeCopy := *change
+		result = append(result, &changeCopy)
+	}
+	return result, nil
+}
+
+// HeadRevision returns the revision of the most recent change
+func (db *MemoryDB) HeadRevision(ctx context.Context) (int64, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+	return db.prunedChanges + int64(len(db.changes)), nil
+}
+
+// ImportSeed stores seed entries in the memory database
+func (db *MemoryDB) ImportSeed(ctx context.Context, seedData []model.ServerDetail, policy ImportPolicy) (*ImportReport, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	log.Printf("Importing %d servers into memory database", len(seedData))
 
-	db.mu.Lock()
+	db.lock()
 	defer db.mu.Unlock()
 
+	report := &ImportReport{}
 	for i, server := range seedData {
-		if server.ID == "" || server.Name == "" {
-			log.Printf("Skipping server %d: ID or Name is empty", i+1)
+		stored, exists := db.entries[server.ID]
+		switch {
+		case exists && policy == ImportSkip:
+			report.Skipped++
+			continue
+		case exists && policy == ImportMerge:
+			server = mergeSeedEntry(stored, &server)
+		}
+
+		setDerivedNames(&server)
+		if err := db.storeManifest(&server); err != nil {
+			log.Printf("Skipping server %d: %v", i+1, err)
+			report.Errored++
 			continue
 		}
 
-		// Set default version information if missing
-		if server.VersionDetail.Version == "" {
-			server.VersionDetail.Version = "0.0.1-seed"
-			server.VersionDetail.ReleaseDate = time.Now().Format(time.RFC3339)
-			server.VersionDetail.IsLatest = true
+		switch {
+		case !exists:
+			report.Created++
+		case stored.Digest == server.Digest:
+			report.Unchanged++
+		default:
+			report.Updated++
 		}
 
 		// Store a copy of the server detail
 		serverDetailCopy := server
 		db.entries[server.ID] = &serverDetailCopy
+		db.recordName(server.Name)
+	}
+
+	// Seed entries may replace existing IDs, so re-sort once rather than per entry
+	db.rebuildIndexes()
 
-		log.Printf("[%d/%d] Imported server: %s", i+1, len(seedData), server.Name)
+	log.Printf("Memory database import completed: %s", report)
+	return report, nil
+}
+
+// CreateSavedSearch stores a copy of search
+func (db *MemoryDB) CreateSavedSearch(ctx context.Context, search *model.SavedSearch) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
-	log.Println("Memory database import completed successfully")
-	return nil
+	db.lock()
+	defer db.mu.Unlock()
+
+	count := 0
+	for _, existing := range db.savedSearches {
+		if existing.Owner == search.Owner {
+			count++
+		}
+	}
+	if count >= MaxSavedSearches {
+		return fmt.Errorf("%w: at most %d saved searches are allowed", ErrInvalidInput, MaxSavedSearches)
+	}
+	if _, exists := db.savedSearches[search.ID]; exists {
+		return ErrAlreadyExists
+	}
+
+	searchCopy := *search
+	db.savedSearches[search.ID] = &searchCopy
+	return nil
+}
+
+// ListSavedSearches returns copies of the owner's saved searches, oldest first
+func (db *MemoryDB) ListSavedSearches(ctx context.Context, owner string) ([]*model.SavedSearch, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := []*model.SavedSearch{}
+	for _, search := range db.savedSearches {
+		if owner == "" || search.Owner == owner {
+			searchCopy := *search
+			result = append(result, &searchCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteSavedSearch removes one of the owner's saved searches
+func (db *MemoryDB) DeleteSavedSearch(ctx context.Context, owner, id string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	search, exists := db.savedSearches[id]
+	if !exists || search.Owner != owner {
+		return ErrNotFound
+	}
+	delete(db.savedSearches, id)
+	return nil
+}
+
+// ConfirmSavedSearchEmail marks the email address of one of the owner's saved searches as confirmed
+func (db *MemoryDB) ConfirmSavedSearchEmail(ctx context.Context, owner, id, tokenHash string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	search, exists := db.savedSearches[id]
+	if !exists || search.Owner != owner {
+		return ErrNotFound
+	}
+	if search.EmailConfirmed {
+		return nil
+	}
+	if search.EmailTokenHash == "" || subtle.ConstantTimeCompare([]byte(search.EmailTokenHash), []byte(tokenHash)) != 1 {
+		return fmt.Errorf("%w: invalid confirmation token", ErrInvalidInput)
+	}
+	search.EmailConfirmed = true
+	search.EmailTokenHash = ""
+	return nil
+}
+
+// CreateDraft stores a copy of draft
+func (db *MemoryDB) CreateDraft(ctx context.Context, draft *model.Draft) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	count := 0
+	for _, existing := range db.drafts {
+		if existing.Server.Name == draft.Server.Name {
+			count++
+		}
+	}
+	if count >= MaxDrafts {
+		return fmt.Errorf("%w: at most %d drafts are allowed per server", ErrInvalidInput, MaxDrafts)
+	}
+	if _, exists := db.drafts[draft.ID]; exists {
+		return ErrAlreadyExists
+	}
+
+	db.drafts[draft.ID] = copyDraft(draft)
+	return nil
+}
+
+// UpdateDraft replaces a stored draft with a copy of draft
+func (db *MemoryDB) UpdateDraft(ctx context.Context, draft *model.Draft) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.drafts[draft.ID]; !exists {
+		return ErrNotFound
+	}
+	db.drafts[draft.ID] = copyDraft(draft)
+	return nil
+}
+
+// GetDraft returns a copy of the draft with the given ID
+func (db *MemoryDB) GetDraft(ctx context.Context, id string) (*model.Draft, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	draft, exists := db.drafts[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return copyDraft(draft), nil
+}
+
+// ListDrafts returns copies of the drafts for a server name, oldest first
+func (db *MemoryDB) ListDrafts(ctx context.Context, name string) ([]*model.Draft, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := []*model.Draft{}
+	for _, draft := range db.drafts {
+		if name == "" || draft.Server.Name == name {
+			result = append(result, copyDraft(draft))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteDraft removes a draft
+func (db *MemoryDB) DeleteDraft(ctx context.Context, id string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.drafts[id]; !exists {
+		return ErrNotFound
+	}
+	delete(db.drafts, id)
+	return nil
+}
+
+// copyDraft copies a draft together with the slices of its server detail, so stored drafts
+// are not changed through the caller's copy
+func copyDraft(draft *model.Draft) *model.Draft {
+	draftCopy := *draft
+	draftCopy.Server.Packages = append([]model.Package(nil), draft.Server.Packages...)
+	draftCopy.Server.Remotes = append([]model.Remote(nil), draft.Server.Remotes...)
+	draftCopy.Server.Transports = append([]model.Transport(nil), draft.Server.Transports...)
+	return &draftCopy
+}
+
+// SetFeatured stores a copy of featured, replacing any entry for the same server
+func (db *MemoryDB) SetFeatured(ctx context.Context, featured *model.FeaturedServer) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	featuredCopy := *featured
+	db.featured[featured.Name] = &featuredCopy
+	return nil
+}
+
+// DeleteFeatured stops featuring the named server
+func (db *MemoryDB) DeleteFeatured(ctx context.Context, name string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.featured[name]; !exists {
+		return ErrNotFound
+	}
+	delete(db.featured, name)
+	return nil
+}
+
+// ListFeatured returns copies of the featured entries by descending weight, then by name
+func (db *MemoryDB) ListFeatured(ctx context.Context) ([]*model.FeaturedServer, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := make([]*model.FeaturedServer, 0, len(db.featured))
+	for _, featured := range db.featured {
+		featuredCopy := *featured
+		result = append(result, &featuredCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// SetAlias stores a copy of alias, replacing any alias with the same slug
+func (db *MemoryDB) SetAlias(ctx context.Context, alias *model.ServerAlias) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	aliasCopy := *alias
+	db.aliases[alias.Slug] = &aliasCopy
+	return nil
+}
+
+// DeleteAlias removes the alias with the given slug
+func (db *MemoryDB) DeleteAlias(ctx context.Context, slug string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.aliases[slug]; !exists {
+		return ErrNotFound
+	}
+	delete(db.aliases, slug)
+	return nil
+}
+
+// GetAlias returns a copy of the alias with the given slug
+func (db *MemoryDB) GetAlias(ctx context.Context, slug string) (*model.ServerAlias, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	alias, exists := db.aliases[slug]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	aliasCopy := *alias
+	return &aliasCopy, nil
+}
+
+// ListAliases returns copies of every alias by former name
+func (db *MemoryDB) ListAliases(ctx context.Context) ([]*model.ServerAlias, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := make([]*model.ServerAlias, 0, len(db.aliases))
+	for _, alias := range db.aliases {
+		aliasCopy := *alias
+		result = append(result, &aliasCopy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// ArchiveOrg stores a copy of archived, replacing any entry for the same organization
+func (db *MemoryDB) ArchiveOrg(ctx context.Context, archived *model.ArchivedOrg) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	archivedCopy := *archived
+	db.archivedOrgs[archived.Org] = &archivedCopy
+	return nil
+}
+
+// UnarchiveOrg removes the archive entry of org
+func (db *MemoryDB) UnarchiveOrg(ctx context.Context, org string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.archivedOrgs[org]; !exists {
+		return ErrNotFound
+	}
+	delete(db.archivedOrgs, org)
+	return nil
+}
+
+// ListArchivedOrgs returns copies of the archive entries by organization
+func (db *MemoryDB) ListArchivedOrgs(ctx context.Context) ([]*model.ArchivedOrg, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := make([]*model.ArchivedOrg, 0, len(db.archivedOrgs))
+	for _, archived := range db.archivedOrgs {
+		archivedCopy := *archived
+		result = append(result, &archivedCopy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Org < result[j].Org })
+	return result, nil
+}
+
+// RevokeToken stores a copy of revoked, keeping the first revocation of a token
+func (db *MemoryDB) RevokeToken(ctx context.Context, revoked *model.RevokedToken) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.revokedTokens[revoked.Digest]; !exists {
+		revokedCopy := *revoked
+		db.revokedTokens[revoked.Digest] = &revokedCopy
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token with the given digest was revoked
+func (db *MemoryDB) IsTokenRevoked(ctx context.Context, digest string) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	_, revoked := db.revokedTokens[digest]
+	return revoked, nil
+}
+
+// ListRevokedTokens returns copies of every revoked token, oldest revocation first
+func (db *MemoryDB) ListRevokedTokens(ctx context.Context) ([]*model.RevokedToken, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := make([]*model.RevokedToken, 0, len(db.revokedTokens))
+	for _, revoked := range db.revokedTokens {
+		revokedCopy := *revoked
+		result = append(result, &revokedCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].RevokedAt.Equal(result[j].RevokedAt) {
+			return result[i].RevokedAt.Before(result[j].RevokedAt)
+		}
+		return result[i].Digest < result[j].Digest
+	})
+	return result, nil
+}
+
+// CreateReport stores a copy of report
+func (db *MemoryDB) CreateReport(ctx context.Context, report *model.AbuseReport) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.reports[report.ID]; exists {
+		return ErrAlreadyExists
+	}
+	reportCopy := *report
+	db.reports[report.ID] = &reportCopy
+	return nil
+}
+
+// GetReport returns a copy of the abuse report with the given ID
+func (db *MemoryDB) GetReport(ctx context.Context, id string) (*model.AbuseReport, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	report, ok := db.reports[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	reportCopy := *report
+	return &reportCopy, nil
+}
+
+// ListReports returns copies of up to limit abuse reports, oldest first
+func (db *MemoryDB) ListReports(ctx context.Context, status model.ReportStatus, limit int) ([]*model.AbuseReport, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := []*model.AbuseReport{}
+	for _, report := range db.reports {
+		if status == "" || report.Status == status {
+			reportCopy := *report
+			result = append(result, &reportCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// UpdateReport replaces a stored abuse report with a copy of report
+func (db *MemoryDB) UpdateReport(ctx context.Context, report *model.AbuseReport) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.reports[report.ID]; !exists {
+		return ErrNotFound
+	}
+	reportCopy := *report
+	db.reports[report.ID] = &reportCopy
+	return nil
+}
+
+// CreatePublishToken stores a copy of token
+func (db *MemoryDB) CreatePublishToken(ctx context.Context, token *model.PublishToken) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	count := 0
+	for _, existing := range db.publishTokens {
+		if existing.ServerName == token.ServerName {
+			count++
+		}
+		if existing.Digest == token.Digest {
+			return ErrAlreadyExists
+		}
+	}
+	if count >= MaxPublishTokens {
+		return fmt.Errorf("%w: at most %d publish tokens are allowed per server", ErrInvalidInput, MaxPublishTokens)
+	}
+	if _, exists := db.publishTokens[token.ID]; exists {
+		return ErrAlreadyExists
+	}
+
+	tokenCopy := *token
+	tokenCopy.Token = ""
+	db.publishTokens[token.ID] = &tokenCopy
+	return nil
+}
+
+// GetPublishToken returns a copy of the publish token with the given digest
+func (db *MemoryDB) GetPublishToken(ctx context.Context, digest string) (*model.PublishToken, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	for _, token := range db.publishTokens {
+		if token.Digest == digest {
+			tokenCopy := *token
+			return &tokenCopy, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListPublishTokens returns copies of the publish tokens of a server, oldest first
+func (db *MemoryDB) ListPublishTokens(ctx context.Context, serverName string) ([]*model.PublishToken, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	defer db.mu.RUnlock()
+
+	result := []*model.PublishToken{}
+	for _, token := range db.publishTokens {
+		if serverName == "" || token.ServerName == serverName {
+			tokenCopy := *token
+			result = append(result, &tokenCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeletePublishToken removes one of the publish tokens of a server
+func (db *MemoryDB) DeletePublishToken(ctx context.Context, serverName, id string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	token, exists := db.publishTokens[id]
+	if !exists || token.ServerName != serverName {
+		return ErrNotFound
+	}
+	delete(db.publishTokens, id)
+	return nil
+}
+
+// CollectGarbage prunes expired leases, old changes and unreferenced manifests. Versions
+// and their manifests are written under one lock here, so no manifest grace is needed.
+func (db *MemoryDB) CollectGarbage(ctx context.Context, policy RetentionPolicy) (*GCReport, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.lock()
+	defer db.mu.Unlock()
+
+	report := &GCReport{}
+	now := time.Now()
+
+	for name, l := range db.leases {
+		if now.Sub(l.expiresAt) > policy.Leases {
+			delete(db.leases, name)
+			report.Leases++
+		}
+	}
+
+	if policy.Changes > 0 {
+		cutoff := now.Add(-policy.Changes)
+		pruned := sort.Search(len(db.changes), func(i int) bool {
+			return !db.changes[i].Timestamp.Before(cutoff)
+		})
+		db.changes = append([]*model.Change(nil), db.changes[pruned:]...)
+		db.prunedChanges += int64(pruned)
+		report.Changes = int64(pruned)
+	}
+	report.PrunedRevision = db.prunedChanges
+
+	referenced := make(map[string]bool, len(db.entries)+len(db.changes))
+	for _, entry := range db.entries {
+		referenced[entry.Digest] = true
+	}
+	for _, change := range db.changes {
+		referenced[change.Digest] = true
+	}
+	for digest := range db.manifests {
+		if !referenced[digest] {
+			delete(db.manifests, digest)
+			report.Manifests++
+		}
+	}
+
+	return report, nil
+}
+
+// Reindex re-sorts the in-memory indexes from scratch. The sorted indexes count as a
+// single index for progress reporting.
+func (db *MemoryDB) Reindex(ctx context.Context, progress func(done, total int)) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	db.lock()
+	db.rebuildIndexes()
+	db.mu.Unlock()
+
+	if progress != nil {
+		progress(1, 1)
+	}
+	return nil
+}
+
+// Stats reports the number of stored records and cumulative lock wait time. Nothing is
+// kept on disk, so no size is reported.
+func (db *MemoryDB) Stats(ctx context.Context) (*StoreStats, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	db.rlock()
+	stats := &StoreStats{
+		Backend:   "memory",
+		Entries:   int64(len(db.entries)),
+		Manifests: int64(len(db.manifests)),
+		Changes:   int64(len(db.changes)),
+	}
+	db.mu.RUnlock()
+
+	stats.LockWaitSeconds = time.Duration(db.lockWait.Load()).Seconds()
+	return stats, nil
 }
 
 // Close closes the database connection
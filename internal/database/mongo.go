@@ -5,27 +5,43 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"regexp"
 	"registry/internal/model"
+	"registry/internal/textnorm"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // MongoDB is an implementation of the Database interface using MongoDB
 type MongoDB struct {
-	client     *mongo.Client
-	database   *mongo.Database
-	collection *mongo.Collection
+	// mu guards the connection handles, which are replaced on reconnect
+	mu            sync.RWMutex
+	client        *mongo.Client
+	database      *mongo.Database
+	collection    *mongo.Collection
+	connectionURI string
+	breaker       *circuitBreaker
+	readRetry     RetryPolicy
+	// nameUniqueness decides which spellings of an existing name Publish accepts
+	nameUniqueness NameUniqueness
+	// connections counts the connections open across the current and replaced clients
+	connections *atomic.Int64
+	done        chan struct{}
+	closeOnce   sync.Once
 }
 
 // NewMongoDB creates a new instance of the MongoDB database
 func NewMongoDB(ctx context.Context, connectionURI, databaseName, collectionName string) (*MongoDB, error) {
 	// Set client options and connect to MongoDB
-	clientOptions := options.Client().ApplyURI(connectionURI)
-	client, err := mongo.Connect(ctx, clientOptions)
+	connections := new(atomic.Int64)
+	client, err := mongo.Connect(ctx, clientOptions(connectionURI, connections))
 	if err != nil {
 		return nil, err
 	}
@@ -39,60 +55,225 @@ func NewMongoDB(ctx context.Context, connectionURI, databaseName, collectionName
 	database := client.Database(databaseName)
 	collection := database.Collection(collectionName)
 
+	db := &MongoDB{
+		client:         client,
+		database:       database,
+		collection:     collection,
+		connectionURI:  connectionURI,
+		breaker:        &circuitBreaker{},
+		nameUniqueness: NamesCaseInsensitive,
+		connections:    connections,
+		done:           make(chan struct{}),
+	}
+
+	// Replicas starting together take turns, so schema changes run on one instance at a time
+	err = RunExclusive(ctx, db, "migrations", migrationLockTTL, func(ctx context.Context) error {
+		return migrate(ctx, database, collection)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// clientOptions configures a client for uri that tracks its open connections in connections
+func clientOptions(uri string, connections *atomic.Int64) *options.ClientOptions {
+	return options.Client().ApplyURI(uri).SetPoolMonitor(&event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				connections.Add(1)
+			case event.ConnectionClosed:
+				connections.Add(-1)
+			}
+		},
+	})
+}
+
+// migrationLockTTL bounds how long a crashed instance blocks migrations on other instances
+const migrationLockTTL = 30 * time.Second
+
+// migrate creates indexes and upgrades stored documents; every step is idempotent
+func migrate(ctx context.Context, database *mongo.Database, collection *mongo.Collection) error {
 	// Create indexes for better query performance
 	models := []mongo.IndexModel{
-		{
-			Keys: bson.D{bson.E{Key: "name", Value: 1}},
-		},
 		{
 			Keys:    bson.D{bson.E{Key: "id", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		// add an index for the combination of name and version
 		{
-			Keys:    bson.D{bson.E{Key: "name", Value: 1}, bson.E{Key: "versiondetail.version", Value: 1}},
+			Keys:    bson.D{bson.E{Key: "name", Value: 1}, bson.E{Key: "version_detail.version", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 	}
+	// Earlier releases indexed a field path documents never had, and built a collated name
+	// index that the case-insensitive regular expressions of searches could not use
+	for _, name := range []string{"name_1_versiondetail.version_1", "name_ci"} {
+		if _, err := collection.Indexes().DropOne(ctx, name); err != nil {
+			// IndexNotFound: the index was never built or is already dropped
+			var commandError mongo.CommandError
+			if !errors.As(err, &commandError) || commandError.Code != 27 {
+				return fmt.Errorf("error dropping index %s: %w", name, err)
+			}
+		}
+	}
+	models = append(models, searchIndexes()...)
 
-	_, err = collection.Indexes().CreateMany(ctx, models)
+	_, err := collection.Indexes().CreateMany(ctx, models)
 	if err != nil {
 		// Mongo will error if the index already exists, we can ignore this and continue.
 		var commandError mongo.CommandError
 		if errors.As(err, &commandError) && commandError.Code != 86 {
-			return nil, err
+			return err
 		}
 		log.Printf("Indexes already exists, skipping.")
 	}
 
-	return &MongoDB{
-		client:     client,
-		database:   database,
-		collection: collection,
-	}, nil
+	if err := migrateRepositoryObjects(ctx, collection); err != nil {
+		return err
+	}
+	if err := migrateDerivedNames(ctx, collection); err != nil {
+		return err
+	}
+	if err := migrateNames(ctx, collection, database.Collection(collection.Name()+"_names")); err != nil {
+		return err
+	}
+
+	if err := createChangeIndexes(ctx, database.Collection(collection.Name()+"_changes")); err != nil {
+		return err
+	}
+	if err := createSavedSearchIndexes(ctx, database.Collection(collection.Name()+"_saved_searches")); err != nil {
+		return err
+	}
+	if err := createDraftIndexes(ctx, database.Collection(collection.Name()+"_drafts")); err != nil {
+		return err
+	}
+	if err := createPublishTokenIndexes(ctx, database.Collection(collection.Name()+"_publish_tokens")); err != nil {
+		return err
+	}
+	return createReportIndexes(ctx, database.Collection(collection.Name()+"_reports"))
 }
 
-// List retrieves MCPRegistry entries with optional filtering and pagination
-func (db *MongoDB) List(
-	ctx context.Context,
-	filter map[string]interface{},
-	cursor string,
-	limit int,
-) ([]*model.Server, string, error) {
-	if limit <= 0 {
-		// Set default limit if not provided
-		limit = 10
+// searchIndexes are the non-unique indexes backing search and listing. Unlike the unique
+// indexes they can be dropped and rebuilt without losing any guarantees.
+func searchIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{bson.E{Key: "name", Value: 1}},
+			Options: options.Index().SetName("name_1"),
+		},
+		// index backing name search, on the case and accent folded name
+		{
+			Keys:    bson.D{bson.E{Key: "search_name", Value: 1}},
+			Options: options.Index().SetName("search_name_1"),
+		},
+		// index backing lookups by slug
+		{
+			Keys:    bson.D{bson.E{Key: "slug", Value: 1}},
+			Options: options.Index().SetName("slug_1"),
+		},
+		// index backing author lookups on the repository owner
+		{
+			Keys:    bson.D{bson.E{Key: "repository.url", Value: 1}},
+			Options: options.Index().SetName("repository.url_1"),
+		},
 	}
+}
 
-	if ctx.Err() != nil {
-		return nil, "", ctx.Err()
+// migrateDerivedNames stores the folded search name and the slug on entries written before
+// names were folded or slugged. It is idempotent and a no-op once every row has both.
+func migrateDerivedNames(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Find(ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"search_name": bson.M{"$exists": false}},
+			bson.M{"slug": bson.M{"$exists": false}},
+			bson.M{"repository_url": bson.M{"$exists": false}, "repository.url": bson.M{"$nin": bson.A{nil, ""}}},
+		}},
+		options.Find().SetProjection(bson.M{"id": 1, "name": 1, "repository": 1}))
+	if err != nil {
+		return fmt.Errorf("error finding entries without derived names: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	// Convert Go map to MongoDB filter
-	mongoFilter := bson.M{
-		"version_detail.is_latest": true,
+	var updates []mongo.WriteModel
+	for cursor.Next(ctx) {
+		var entry model.ServerDetail
+		if err := cursor.Decode(&entry); err != nil {
+			return fmt.Errorf("error decoding entry: %w", err)
+		}
+		setDerivedNames(&entry)
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": entry.ID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"search_name":    entry.SearchName,
+				"slug":           entry.Slug,
+				"repository_url": entry.RepositoryURL,
+			}}))
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("error reading entries: %w", err)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if _, err := collection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("error storing derived names: %w", err)
+	}
+	log.Printf("Stored search names, slugs and repository URLs for %d entries", len(updates))
+	return nil
+}
+
+// migrateRepositoryObjects converts entries stored with a flat repository URL string into
+// the structured repository object. It is idempotent and a no-op once every row is migrated.
+func migrateRepositoryObjects(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Find(ctx,
+		bson.M{"repository": bson.M{"$type": "string"}},
+		options.Find().SetProjection(bson.M{"_id": 1, "repository": 1}))
+	if err != nil {
+		return fmt.Errorf("error finding flat repository fields: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var updates []mongo.WriteModel
+	for cursor.Next(ctx) {
+		var entry struct {
+			ID         interface{} `bson:"_id"`
+			Repository string      `bson:"repository"`
+		}
+		if err := cursor.Decode(&entry); err != nil {
+			return fmt.Errorf("error decoding entry: %w", err)
+		}
+		// Matches what model.Repository.UnmarshalJSON infers from the legacy string form
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": entry.ID, "repository": bson.M{"$type": "string"}}).
+			SetUpdate(bson.M{"$set": bson.M{"repository": model.Repository{
+				URL:    entry.Repository,
+				Source: model.RepositorySourceFromURL(entry.Repository),
+			}}}))
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("error reading entries: %w", err)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	result, err := collection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("error migrating repository fields: %w", err)
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Migrated %d entries to structured repository objects", result.ModifiedCount)
 	}
-	// Map common filter keys to MongoDB document paths
+	return nil
+}
+
+// toMongoFilter maps common filter keys to MongoDB document paths
+func toMongoFilter(filter map[string]interface{}) bson.M {
+	mongoFilter := bson.M{}
 	for k, v := range filter {
 		// Handle nested fields with dot notation
 		switch k {
@@ -100,78 +281,236 @@ func (db *MongoDB) List(
 			mongoFilter["version_detail.version"] = v
 		case "name":
 			mongoFilter["name"] = v
+		case "slug":
+			mongoFilter["slug"] = v
+		case "is_latest":
+			mongoFilter["version_detail.is_latest"] = v
+		case "namespace":
+			mongoFilter["$and"] = append(andClauses(mongoFilter), bson.M{"name": bson.M{
+				"$regex":   "^" + regexp.QuoteMeta(v.(string)) + "/",
+				"$options": "i",
+			}})
+		case "released_since":
+			mongoFilter["version_detail.release_date"] = bson.M{"$gte": v}
+		case "yanked":
+			// Entries published before yanking existed have no yanked field
+			if v.(bool) {
+				mongoFilter["version_detail.yanked"] = true
+			} else {
+				mongoFilter["version_detail.yanked"] = bson.M{"$ne": true}
+			}
+		case "visibility":
+			// Public versions usually have no visibility field
+			if v.(model.Visibility) == model.VisibilityPublic {
+				mongoFilter["visibility"] = bson.M{"$in": bson.A{nil, "", model.VisibilityPublic}}
+			} else {
+				mongoFilter["visibility"] = v
+			}
+		case "author":
+			// Matches the owner segment of the repository URL, see model.ExtractAuthorFromRepoURL
+			mongoFilter["repository.url"] = bson.M{
+				"$regex":   "^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]+/" + regexp.QuoteMeta(v.(string)) + "(/|$)",
+				"$options": "i",
+			}
+		case "exclude_authors":
+			excluded := bson.A{}
+			for _, author := range v.([]string) {
+				excluded = append(excluded, bson.M{"repository.url": bson.M{
+					"$regex":   "^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]+/" + regexp.QuoteMeta(author) + "(/|$)",
+					"$options": "i",
+				}})
+			}
+			if len(excluded) > 0 {
+				mongoFilter["$and"] = append(andClauses(mongoFilter), bson.M{"$nor": excluded})
+			}
+		case "transport":
+			// Mirrors model.ServerDetail.SupportsTransport
+			clauses := bson.A{
+				bson.M{"transports.type": v},
+				bson.M{"remotes.transport_type": v},
+			}
+			if model.TransportType(v.(string)) == model.TransportStdio {
+				clauses = append(clauses, bson.M{"packages.0": bson.M{"$exists": true}})
+			}
+			mongoFilter["$and"] = append(andClauses(mongoFilter), bson.M{"$or": clauses})
+		case "platform":
+			// Mirrors model.ServerDetail.SupportsPlatform
+			platform := v.(model.Platform)
+			match := bson.M{}
+			if platform.OS != "" {
+				match["os"] = platform.OS
+			}
+			if platform.Arch != "" {
+				match["arch"] = bson.M{"$in": bson.A{platform.Arch, nil}}
+			}
+			mongoFilter["$and"] = append(andClauses(mongoFilter), bson.M{"$or": bson.A{
+				bson.M{"packages.0": bson.M{"$exists": false}},
+				bson.M{"packages": bson.M{"$elemMatch": bson.M{"$or": bson.A{
+					bson.M{"platforms.0": bson.M{"$exists": false}},
+					bson.M{"platforms": bson.M{"$elemMatch": match}},
+				}}}},
+			}})
+		case "search":
+			// Folded names are stored lowercased, so equality and prefix patterns on them
+			// use the index. Searches keeping accents narrow by the folded name first and
+			// then compare the name itself; substring searches scan either way.
+			search := v.(Search)
+			if search.FoldAccents {
+				mongoFilter["search_name"] = bson.M{"$regex": search.Pattern()}
+				break
+			}
+			folded := Search{Query: search.Query, Match: search.Match, FoldAccents: true}
+			clauses := bson.A{bson.M{"name": bson.M{"$regex": search.Pattern(), "$options": "i"}}}
+			switch search.Match {
+			case MatchExact:
+				clauses = append(clauses, bson.M{"search_name": textnorm.Fold(search.Query)})
+			case MatchPrefix:
+				clauses = append(clauses, bson.M{"search_name": bson.M{"$regex": folded.Pattern()}})
+			}
+			mongoFilter["$and"] = append(andClauses(mongoFilter), clauses...)
+		case "all", "any":
+			// Nested filters, every one or at least one of which must match
+			clauses := bson.A{}
+			for _, sub := range v.([]map[string]interface{}) {
+				clauses = append(clauses, toMongoFilter(sub))
+			}
+			operator := "$and"
+			if k == "any" {
+				operator = "$or"
+			}
+			mongoFilter["$and"] = append(andClauses(mongoFilter), bson.M{operator: clauses})
 		default:
 			mongoFilter[k] = v
 		}
 	}
+	return mongoFilter
+}
 
-	// Setup pagination options
-	findOptions := options.Find()
+// andClauses returns the $and clauses already present in a filter, so that several
+// filter keys needing their own $or can be combined
+func andClauses(mongoFilter bson.M) bson.A {
+	if existing, ok := mongoFilter["$and"].(bson.A); ok {
+		return existing
+	}
+	return bson.A{}
+}
 
-	// If cursor is provided, add condition to filter to only get records after the cursor
-	if cursor != "" {
-		// Validate that the cursor is a valid UUID
-		if _, err := uuid.Parse(cursor); err != nil {
-			return nil, "", fmt.Errorf("invalid cursor format: %w", err)
-		}
+// List retrieves MCPRegistry entries with optional filtering and pagination
+func (db *MongoDB) List(
+	ctx context.Context,
+	filter map[string]interface{},
+	order SortOrder,
+	after *Position,
+	limit int,
+) (_ []*model.Server, _ *Position, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, nil, err
+	}
+	defer func() { db.breaker.record(err) }()
 
-		// Fetch the document at the cursor to get its sort values
-		var cursorDoc model.Server
-		err := db.collection.FindOne(ctx, bson.M{"id": cursor}).Decode(&cursorDoc)
-		if err != nil {
-			if !errors.Is(err, mongo.ErrNoDocuments) {
-				return nil, "", err
-			}
-			// If cursor document not found, start from beginning
-		} else {
-			// Use the cursor document's ID to paginate (records with ID > cursor's ID)
-			mongoFilter["id"] = bson.M{"$gt": cursor}
-		}
+	if limit <= 0 {
+		// Set default limit if not provided
+		limit = 10
 	}
 
-	// Set sort order by ID (for consistent pagination)
-	findOptions.SetSort(bson.M{"id": 1})
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
 
-	// Set limit if provided and valid
-	if limit > 0 {
-		findOptions.SetLimit(int64(limit))
+	// Convert Go map to MongoDB filter
+	mongoFilter := toMongoFilter(filter)
+	if _, ok := mongoFilter["version_detail.is_latest"]; !ok {
+		mongoFilter["version_detail.is_latest"] = true
 	}
 
-	// Execute find operation with options
-	mongoCursor, err := db.collection.Find(ctx, mongoFilter, findOptions)
-	if err != nil {
-		return nil, "", err
+	// Setup pagination options
+	findOptions := options.Find()
+
+	// Paginate on the position's sort key, breaking ties by ID. The keyset condition uses
+	// the sort indexes, so deep pages don't scan the entries before them.
+	if after != nil {
+		mongoFilter["$and"] = append(andClauses(mongoFilter), keysetAfter(order, after))
 	}
-	defer mongoCursor.Close(ctx)
 
-	// Decode results
+	findOptions.SetSort(sortDocument(order))
+
+	// Read one entry beyond the page to know whether a next page exists
+	findOptions.SetLimit(int64(limit) + 1)
+
+	// Execute find operation with options and decode the results
 	var results []*model.Server
-	if err = mongoCursor.All(ctx, &results); err != nil {
-		return nil, "", err
+	err = db.withReadRetry(ctx, func() error {
+		mongoCursor, err := db.coll().Find(ctx, mongoFilter, findOptions)
+		if err != nil {
+			return err
+		}
+		defer mongoCursor.Close(ctx)
+
+		results = nil
+		return mongoCursor.All(ctx, &results)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Determine the next cursor
-	nextCursor := ""
-	if len(results) > 0 && limit > 0 && len(results) >= limit {
-		// Use the last item's ID as the next cursor
-		nextCursor = results[len(results)-1].ID
+	// Determine the next position
+	var next *Position
+	if len(results) > limit {
+		results = results[:limit]
+		next = PositionOf(order, results[limit-1])
 	}
 
-	return results, nextCursor, nil
+	return results, next, nil
+}
+
+// sortDocument returns the MongoDB sort specification for a SortOrder
+func sortDocument(order SortOrder) bson.D {
+	switch order {
+	case SortByName:
+		return bson.D{{Key: "name", Value: 1}, {Key: "id", Value: 1}}
+	case SortByCreatedAt:
+		return bson.D{{Key: "version_detail.release_date", Value: -1}, {Key: "id", Value: 1}}
+	default:
+		return bson.D{{Key: "id", Value: 1}}
+	}
+}
+
+// keysetAfter returns the filter matching documents that sort after the position
+func keysetAfter(order SortOrder, after *Position) bson.M {
+	switch order {
+	case SortByName:
+		return bson.M{"$or": bson.A{
+			bson.M{"name": bson.M{"$gt": after.Name}},
+			bson.M{"name": after.Name, "id": bson.M{"$gt": after.ID}},
+		}}
+	case SortByCreatedAt:
+		return bson.M{"$or": bson.A{
+			bson.M{"version_detail.release_date": bson.M{"$lt": after.ReleaseDate}},
+			bson.M{"version_detail.release_date": after.ReleaseDate, "id": bson.M{"$gt": after.ID}},
+		}}
+	default:
+		return bson.M{"id": bson.M{"$gt": after.ID}}
+	}
 }
 
 // GetByID retrieves a single ServerDetail by its ID
-func (db *MongoDB) GetByID(ctx context.Context, id string) (*model.ServerDetail, error) {
+func (db *MongoDB) GetByID(ctx context.Context, id string) (_ *model.ServerDetail, err error) {
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
 
 	// Create a filter for the ID
 	filter := bson.M{"id": id}
 
 	// Find the entry in the database
 	var entry model.ServerDetail
-	err := db.collection.FindOne(ctx, filter).Decode(&entry)
+	err = db.withReadRetry(ctx, func() error {
+		return db.coll().FindOne(ctx, filter).Decode(&entry)
+	})
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, ErrNotFound
@@ -183,34 +522,101 @@ func (db *MongoDB) GetByID(ctx context.Context, id string) (*model.ServerDetail,
 	return &entry, nil
 }
 
-// Publish adds a new ServerDetail to the database
-func (db *MongoDB) Publish(ctx context.Context, serverDetail *model.ServerDetail) error {
+// Iterate streams every ServerDetail matching the filter from a MongoDB cursor, in ID order
+func (db *MongoDB) Iterate(
+	ctx context.Context,
+	filter map[string]interface{},
+	fn func(*model.ServerDetail) error,
+) (err error) {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	// find a server detail with the same name and check that the current version is greater than the existing one
-	filter := bson.M{
-		"name":                     serverDetail.Name,
-		"version_detail.is_latest": true,
+	if err := db.breaker.allow(); err != nil {
+		return err
 	}
+	defer func() { db.breaker.record(err) }()
+
+	mongoFilter := toMongoFilter(filter)
 
-	var existingEntry model.ServerDetail
-	err := db.collection.FindOne(ctx, filter).Decode(&existingEntry)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		return fmt.Errorf("error checking existing entry: %w", err)
+	mongoCursor, err := db.coll().Find(ctx, mongoFilter, options.Find().SetSort(bson.M{"id": 1}))
+	if err != nil {
+		return err
 	}
+	defer mongoCursor.Close(ctx)
 
-	// check that the current version is greater than the existing one
-	if serverDetail.VersionDetail.Version <= existingEntry.VersionDetail.Version {
-		return fmt.Errorf("version must be greater than existing version")
+	for mongoCursor.Next(ctx) {
+		var entry model.ServerDetail
+		if err := mongoCursor.Decode(&entry); err != nil {
+			return fmt.Errorf("error decoding entry: %w", err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
 	}
 
-	serverDetail.ID = uuid.New().String()
+	return mongoCursor.Err()
+}
+
+// Count returns the number of ServerDetails matching the filter
+func (db *MongoDB) Count(ctx context.Context, filter map[string]interface{}) (count int, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return 0, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	err = db.withReadRetry(ctx, func() error {
+		n, err := db.coll().CountDocuments(ctx, toMongoFilter(filter))
+		count = int(n)
+		return err
+	})
+	return count, err
+}
+
+// Publish adds a new ServerDetail to the database
+func (db *MongoDB) Publish(ctx context.Context, serverDetail *model.ServerDetail) (err error) {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+	// Check that the new version is not older than any existing version of the server
+	cursor, err := db.coll().Find(ctx, bson.M{"name": serverDetail.Name},
+		options.Find().SetProjection(bson.M{"version_detail.version": 1}))
+	if err != nil {
+		return fmt.Errorf("error checking existing entries: %w", err)
+	}
+	var existing []model.ServerDetail
+	if err = cursor.All(ctx, &existing); err != nil {
+		return fmt.Errorf("error checking existing entries: %w", err)
+	}
+	versions := make([]string, 0, len(existing))
+	for _, entry := range existing {
+		versions = append(versions, entry.VersionDetail.Version)
+	}
+	if err := checkNewVersion(versions, serverDetail.VersionDetail.Version); err != nil {
+		return err
+	}
+
+	// Generate a new ID unless the caller chose one; the unique index rejects reused IDs
+	if serverDetail.ID == "" {
+		serverDetail.ID = uuid.New().String()
+	}
+	if err = db.claimName(ctx, serverDetail.Name); err != nil {
+		return err
+	}
 	serverDetail.VersionDetail.IsLatest = true
-	serverDetail.VersionDetail.ReleaseDate = time.Now().Format(time.RFC3339)
+	serverDetail.VersionDetail.ReleaseDate = time.Now().UTC().Format(time.RFC3339)
+	setDerivedNames(serverDetail)
+
+	// Store the immutable manifest first so the entry never points at a missing digest
+	if err = db.storeManifest(ctx, serverDetail); err != nil {
+		return err
+	}
 
 	// Insert the entry into the database
-	_, err = db.collection.InsertOne(ctx, serverDetail)
+	_, err = db.coll().InsertOne(ctx, serverDetail)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return ErrAlreadyExists
@@ -218,73 +624,605 @@ func (db *MongoDB) Publish(ctx context.Context, serverDetail *model.ServerDetail
 		return fmt.Errorf("error inserting entry: %w", err)
 	}
 
-	// update the existing entry to not be the latest version
-	if existingEntry.ID != "" {
-		_, err = db.collection.UpdateOne(
-			ctx,
-			bson.M{"id": existingEntry.ID},
-			bson.M{"$set": bson.M{"versiondetail.islatest": false}})
+	// Clear the latest flag of the versions the new one supersedes
+	if err = db.updateLatest(ctx, serverDetail.Name); err != nil {
+		return err
+	}
+
+	return db.recordChange(ctx, model.NewServerChange(model.ChangeOpPublish, serverDetail))
+}
+
+// PublishBatch publishes versions in order, as successive calls to Publish would, but
+// looks up the existing versions of every name with one query and inserts the accepted
+// versions with one write. It returns the error of each version.
+func (db *MongoDB) PublishBatch(ctx context.Context, serverDetails []*model.ServerDetail) []error {
+	errs := make([]error, len(serverDetails))
+	failRemaining := func(err error) []error {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+	if err := ctx.Err(); err != nil {
+		return failRemaining(err)
+	}
+	if err := db.breaker.allow(); err != nil {
+		return failRemaining(err)
+	}
+	var storeErr error
+	defer func() { db.breaker.record(storeErr) }()
+
+	names := make([]string, 0, len(serverDetails))
+	versions := make(map[string][]string)
+	for _, serverDetail := range serverDetails {
+		if _, ok := versions[serverDetail.Name]; !ok {
+			versions[serverDetail.Name] = nil
+			names = append(names, serverDetail.Name)
+		}
+	}
+	cursor, err := db.coll().Find(ctx, bson.M{"name": bson.M{"$in": names}},
+		options.Find().SetProjection(bson.M{"name": 1, "version_detail.version": 1}))
+	if err != nil {
+		storeErr = fmt.Errorf("error checking existing entries: %w", err)
+		return failRemaining(storeErr)
+	}
+	var existing []model.ServerDetail
+	if err = cursor.All(ctx, &existing); err != nil {
+		storeErr = fmt.Errorf("error checking existing entries: %w", err)
+		return failRemaining(storeErr)
+	}
+	for _, entry := range existing {
+		versions[entry.Name] = append(versions[entry.Name], entry.VersionDetail.Version)
+	}
+
+	// Check and prepare each version against those stored and those earlier in the batch
+	var (
+		accepted  []int
+		documents []interface{}
+	)
+	for i, serverDetail := range serverDetails {
+		if errs[i] = checkNewVersion(versions[serverDetail.Name], serverDetail.VersionDetail.Version); errs[i] != nil {
+			continue
+		}
+		if serverDetail.ID == "" {
+			serverDetail.ID = uuid.New().String()
+		}
+		if errs[i] = db.claimName(ctx, serverDetail.Name); errs[i] != nil {
+			if !errors.Is(errs[i], ErrAlreadyExists) {
+				storeErr = errs[i]
+			}
+			continue
+		}
+		serverDetail.VersionDetail.IsLatest = true
+		serverDetail.VersionDetail.ReleaseDate = time.Now().UTC().Format(time.RFC3339)
+		setDerivedNames(serverDetail)
+		if errs[i] = db.storeManifest(ctx, serverDetail); errs[i] != nil {
+			storeErr = errs[i]
+			continue
+		}
+		versions[serverDetail.Name] = append(versions[serverDetail.Name], serverDetail.VersionDetail.Version)
+		accepted = append(accepted, i)
+		documents = append(documents, serverDetail)
+	}
+	if len(documents) == 0 {
+		return errs
+	}
+
+	_, err = db.coll().InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	switch {
+	case errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil:
+		for _, writeErr := range bulkErr.WriteErrors {
+			i := accepted[writeErr.Index]
+			if mongo.IsDuplicateKeyError(writeErr.WriteError) {
+				errs[i] = ErrAlreadyExists
+			} else {
+				storeErr = fmt.Errorf("error inserting entry: %w", writeErr.WriteError)
+				errs[i] = storeErr
+			}
+		}
+	case err != nil:
+		storeErr = fmt.Errorf("error inserting entries: %w", err)
+		for _, i := range accepted {
+			errs[i] = storeErr
+		}
+		return errs
+	}
+
+	// Recompute the latest version once per name, then record the changes in order
+	updated := make(map[string]bool)
+	for _, i := range accepted {
+		if errs[i] != nil {
+			continue
+		}
+		serverDetail := serverDetails[i]
+		if !updated[serverDetail.Name] {
+			updated[serverDetail.Name] = true
+			if err := db.updateLatest(ctx, serverDetail.Name); err != nil {
+				storeErr, errs[i] = err, err
+				continue
+			}
+		}
+		if err := db.recordChange(ctx, model.NewServerChange(model.ChangeOpPublish, serverDetail)); err != nil {
+			storeErr, errs[i] = err, err
+		}
+	}
+	return errs
+}
+
+// SetYanked updates the yanked state of an entry and recomputes the latest version of its
+// server. The updates are not transactional; a concurrent publish may briefly leave two
+// versions flagged as latest, which the next yank or publish corrects.
+func (db *MongoDB) SetYanked(ctx context.Context, id string, yanked bool, reason string) (err error) {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	set := bson.M{"version_detail.yanked": yanked, "version_detail.yanked_reason": ""}
+	if yanked {
+		set["version_detail.yanked_reason"] = reason
+	}
+
+	var entry model.ServerDetail
+	err = db.coll().FindOneAndUpdate(ctx, bson.M{"id": id}, bson.M{"$set": set}).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("error updating entry: %w", err)
+	}
+
+	if err = db.updateLatest(ctx, entry.Name); err != nil {
+		return err
+	}
+
+	op := model.ChangeOpUnyank
+	if yanked {
+		op = model.ChangeOpYank
+	}
+	return db.recordChange(ctx, model.NewServerChange(op, &entry))
+}
+
+// updateLatest flags the highest version of a server that is not yanked as latest
+func (db *MongoDB) updateLatest(ctx context.Context, name string) error {
+	cursor, err := db.coll().Find(ctx, bson.M{"name": name})
+	if err != nil {
+		return fmt.Errorf("error listing versions: %w", err)
+	}
+	var versions []*model.ServerDetail
+	if err = cursor.All(ctx, &versions); err != nil {
+		return fmt.Errorf("error listing versions: %w", err)
+	}
+
+	latestID := ""
+	if latest := latestUnyanked(versions); latest != nil {
+		latestID = latest.ID
+	}
+	_, err = db.coll().UpdateMany(ctx,
+		bson.M{"name": name, "id": bson.M{"$ne": latestID}},
+		bson.M{"$set": bson.M{"version_detail.is_latest": false}})
+	if err != nil {
+		return fmt.Errorf("error updating latest version: %w", err)
+	}
+	if latestID != "" {
+		_, err = db.coll().UpdateOne(ctx,
+			bson.M{"id": latestID},
+			bson.M{"$set": bson.M{"version_detail.is_latest": true}})
 		if err != nil {
-			return fmt.Errorf("error updating existing entry: %w", err)
+			return fmt.Errorf("error updating latest version: %w", err)
 		}
 	}
+	return nil
+}
+
+// Replicate stores a version copied from another registry, keeping its ID, release date and yanked state
+func (db *MongoDB) Replicate(ctx context.Context, serverDetail *model.ServerDetail, policy ConflictPolicy) (err error) {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	if _, _, err = replicatedManifest(serverDetail); err != nil {
+		return err
+	}
+	setDerivedNames(serverDetail)
+
+	// Resolve local versions that clash on name and version but not ID
+	conflicts := bson.M{
+		"name":                   serverDetail.Name,
+		"version_detail.version": serverDetail.VersionDetail.Version,
+		"id":                     bson.M{"$ne": serverDetail.ID},
+	}
+	if policy == ConflictLocalWins {
+		count, err := db.coll().CountDocuments(ctx, conflicts)
+		if err != nil {
+			return fmt.Errorf("error checking conflicts: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %s %s", ErrConflict, serverDetail.Name, serverDetail.VersionDetail.Version)
+		}
+	} else if _, err = db.coll().DeleteMany(ctx, conflicts); err != nil {
+		return fmt.Errorf("error removing conflicting versions: %w", err)
+	}
+
+	var existing *model.ServerDetail
+	var found model.ServerDetail
+	err = db.coll().FindOne(ctx, bson.M{"id": serverDetail.ID}).Decode(&found)
+	switch {
+	case err == nil:
+		existing = &found
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("error retrieving entry: %w", err)
+	}
+
+	entry := *serverDetail
+	if err = db.storeManifest(ctx, &entry); err != nil {
+		return err
+	}
+	if err = db.recordNames(ctx, entry.Name); err != nil {
+		return err
+	}
+	_, err = db.coll().ReplaceOne(ctx, bson.M{"id": entry.ID}, &entry, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("error storing entry: %w", err)
+	}
+	if err = db.updateLatest(ctx, entry.Name); err != nil {
+		return err
+	}
+
+	if op := replicatedOp(existing, &entry); op != "" {
+		return db.recordChange(ctx, model.NewServerChange(op, &entry))
+	}
+	return nil
+}
+
+// stateDocument is a value of the key-value state store
+type stateDocument struct {
+	Key   string `bson:"_id"`
+	Value string `bson:"value"`
+}
+
+// state returns the collection backing the key-value state store
+func (db *MongoDB) state() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_state")
+}
+
+// LoadState reads a value from the key-value state store
+func (db *MongoDB) LoadState(ctx context.Context, key string) (_ string, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return "", err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var doc stateDocument
+	err = db.state().FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("error reading state: %w", err)
+	}
+	return doc.Value, nil
+}
+
+// SaveState writes a value to the key-value state store
+func (db *MongoDB) SaveState(ctx context.Context, key, value string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	_, err = db.state().ReplaceOne(ctx, bson.M{"_id": key}, stateDocument{Key: key, Value: value},
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("error writing state: %w", err)
+	}
+	return nil
+}
+
+// ListState returns every key and value of the state store
+func (db *MongoDB) ListState(ctx context.Context) (_ map[string]string, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	cursor, err := db.state().Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing state: %w", err)
+	}
+	var docs []stateDocument
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("error decoding state: %w", err)
+	}
+
+	state := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		state[doc.Key] = doc.Value
+	}
+	return state, nil
+}
+
+// manifestDocument is a stored manifest, keyed by its digest
+type manifestDocument struct {
+	Digest    string    `bson:"_id"`
+	Manifest  []byte    `bson:"manifest"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// manifests returns the collection holding immutable manifests, stored alongside the entries
+func (db *MongoDB) manifests() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_manifests")
+}
+
+// storeManifest records the manifest of serverDetail and sets its digest. Manifests are
+// immutable and content addressed, so an existing document with the same digest is kept.
+func (db *MongoDB) storeManifest(ctx context.Context, serverDetail *model.ServerDetail) error {
+	digest, manifest, err := serverDetail.Manifest()
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %w", err)
+	}
+
+	_, err = db.manifests().UpdateOne(ctx,
+		bson.M{"_id": digest},
+		bson.M{"$setOnInsert": manifestDocument{Digest: digest, Manifest: manifest, CreatedAt: time.Now()}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("error storing manifest: %w", err)
+	}
 
+	serverDetail.Digest = digest
 	return nil
 }
 
-// ImportSeed imports initial data from a seed file into MongoDB
-func (db *MongoDB) ImportSeed(ctx context.Context, seedFilePath string) error {
-	// Read the seed file
-	servers, err := ReadSeedFile(seedFilePath)
+// GetManifest retrieves the manifest stored under a digest
+func (db *MongoDB) GetManifest(ctx context.Context, digest string) (_ []byte, err error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var doc manifestDocument
+	err = db.manifests().FindOne(ctx, bson.M{"_id": digest}).Decode(&doc)
 	if err != nil {
-		return fmt.Errorf("failed to read seed file: %w", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error retrieving manifest: %w", err)
 	}
+	return doc.Manifest, nil
+}
+
+// importBatchSize is the number of seed entries upserted per bulk write
+const importBatchSize = 500
 
-	collection := db.collection
+// ImportSeed upserts seed entries into MongoDB
+func (db *MongoDB) ImportSeed(ctx context.Context, servers []model.ServerDetail, policy ImportPolicy) (*ImportReport, error) {
+	collection := db.coll()
 
 	log.Printf("Importing %d servers into collection %s", len(servers), collection.Name())
 
+	// Coalesce upserts into bulk writes so large seeds don't pay one round trip per entry
+	report := &ImportReport{}
+	batch := make([]model.ServerDetail, 0, importBatchSize)
+	for i := range servers {
+		batch = append(batch, servers[i])
+		if len(batch) == importBatchSize || i == len(servers)-1 {
+			db.writeImportBatch(ctx, batch, policy, report, i+1, len(servers))
+			batch = batch[:0]
+		}
+	}
+
+	log.Printf("MongoDB database import completed: %s", report)
+	return report, nil
+}
+
+// writeImportBatch applies a batch of seed upserts, logging rather than aborting on failures
+// so that one bad entry doesn't prevent the rest of the seed from importing
+func (db *MongoDB) writeImportBatch(ctx context.Context, servers []model.ServerDetail, policy ImportPolicy, report *ImportReport, done, total int) {
+	var stored map[string]*model.ServerDetail
+	if policy == ImportMerge {
+		var err error
+		if stored, err = db.importedEntries(ctx, servers); err != nil {
+			log.Printf("Error loading entries for batch ending at entry %d: %v", done, err)
+			report.Errored += len(servers)
+			return
+		}
+	}
+
+	batch := make([]mongo.WriteModel, 0, len(servers))
+	names := make([]string, 0, len(servers))
 	for i, server := range servers {
-		if server.ID == "" || server.Name == "" {
-			log.Printf("Skipping server %d: ID or Name is empty", i+1)
+		if existing, ok := stored[server.ID]; ok {
+			server = mergeSeedEntry(existing, &server)
+		}
+		setDerivedNames(&server)
+		if err := db.storeManifest(ctx, &server); err != nil {
+			log.Printf("Skipping server %d: %v", done-len(servers)+i+1, err)
+			report.Errored++
 			continue
 		}
 
-		if server.VersionDetail.Version == "" {
-			server.VersionDetail.Version = "0.0.1-seed"
-			server.VersionDetail.ReleaseDate = time.Now().Format(time.RFC3339)
-			server.VersionDetail.IsLatest = true
+		// Skipping only writes entries that don't exist yet; the other policies replace
+		// the stored fields with the (merged) seed entry
+		names = append(names, server.Name)
+		update := bson.M{"$set": server}
+		if policy == ImportSkip {
+			update = bson.M{"$setOnInsert": server}
 		}
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": server.ID}).
+			SetUpdate(update).
+			SetUpsert(true))
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := db.recordNames(ctx, names...); err != nil {
+		log.Printf("Error recording names for batch ending at entry %d: %v", done, err)
+	}
 
-		// Create filter based on server ID
-		filter := bson.M{"id": server.ID}
+	result, err := db.coll().BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		log.Printf("Error importing batch ending at entry %d: %v", done, err)
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) {
+			report.Errored += len(bulkErr.WriteErrors)
+		} else if result == nil {
+			report.Errored += len(batch)
+		}
+	}
+	if result == nil {
+		return
+	}
 
-		// Create update document
-		update := bson.M{"$set": server}
+	report.Created += int(result.UpsertedCount)
+	if policy == ImportSkip {
+		report.Skipped += int(result.MatchedCount)
+	} else {
+		report.Updated += int(result.ModifiedCount)
+		report.Unchanged += int(result.MatchedCount - result.ModifiedCount)
+	}
+	log.Printf("[%d/%d] Created %d, matched %d, modified %d servers",
+		done, total, result.UpsertedCount, result.MatchedCount, result.ModifiedCount)
+}
+
+// importedEntries loads the stored entries sharing an ID with the given seed entries
+func (db *MongoDB) importedEntries(ctx context.Context, servers []model.ServerDetail) (map[string]*model.ServerDetail, error) {
+	ids := make([]string, 0, len(servers))
+	for _, server := range servers {
+		ids = append(ids, server.ID)
+	}
 
-		// Use upsert to create if not exists or update if exists
-		opts := options.Update().SetUpsert(true)
-		result, err := collection.UpdateOne(ctx, filter, update, opts)
+	var entries []model.ServerDetail
+	err := db.withReadRetry(ctx, func() error {
+		cursor, err := db.coll().Find(ctx, bson.M{"id": bson.M{"$in": ids}})
 		if err != nil {
-			log.Printf("Error importing server %s: %v", server.ID, err)
-			continue
+			return err
 		}
+		entries = nil
+		return cursor.All(ctx, &entries)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		switch {
-		case result.UpsertedCount > 0:
-			log.Printf("[%d/%d] Created server: %s", i+1, len(servers), server.Name)
-		case result.ModifiedCount > 0:
-			log.Printf("[%d/%d] Updated server: %s", i+1, len(servers), server.Name)
-		default:
-			log.Printf("[%d/%d] Server already up to date: %s", i+1, len(servers), server.Name)
+	stored := make(map[string]*model.ServerDetail, len(entries))
+	for i := range entries {
+		stored[entries[i].ID] = &entries[i]
+	}
+	return stored, nil
+}
+
+// Reindex drops and recreates the search indexes one at a time, so queries keep working
+// on the remaining indexes while each is rebuilt
+func (db *MongoDB) Reindex(ctx context.Context, progress func(done, total int)) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	indexes := searchIndexes()
+	for i, index := range indexes {
+		name := *index.Options.Name
+		if _, err := db.coll().Indexes().DropOne(ctx, name); err != nil {
+			// IndexNotFound: the index was never built or another rebuild dropped it
+			var commandError mongo.CommandError
+			if !errors.As(err, &commandError) || commandError.Code != 27 {
+				return fmt.Errorf("error dropping index %s: %w", name, err)
+			}
 		}
+		if _, err := db.coll().Indexes().CreateOne(ctx, index); err != nil {
+			return fmt.Errorf("error creating index %s: %w", name, err)
+		}
+		log.Printf("Rebuilt index %s", name)
+		if progress != nil {
+			progress(i+1, len(indexes))
+		}
+	}
+	return nil
+}
+
+// Stats reports estimated document counts, the database size, open connections and
+// sessions, and the circuit breaker state
+func (db *MongoDB) Stats(ctx context.Context) (*StoreStats, error) {
+	stats := &StoreStats{
+		Backend:         "mongodb",
+		OpenConnections: db.connections.Load(),
+		CircuitOpen:     db.breaker.isOpen(),
+	}
+
+	counts := []struct {
+		collection *mongo.Collection
+		count      *int64
+	}{
+		{db.coll(), &stats.Entries},
+		{db.manifests(), &stats.Manifests},
+		{db.changes(), &stats.Changes},
+	}
+	for _, c := range counts {
+		n, err := c.collection.EstimatedDocumentCount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error counting %s: %w", c.collection.Name(), err)
+		}
+		*c.count = n
 	}
 
-	log.Println("MongoDB database import completed successfully")
+	db.mu.RLock()
+	client := db.client
+	database := db.database
+	db.mu.RUnlock()
+	stats.OpenSessions = client.NumberSessionsInProgress()
+
+	// dbStats covers the change log, manifests and other side collections too
+	var size struct {
+		StorageSize float64 `bson:"storageSize"`
+		IndexSize   float64 `bson:"indexSize"`
+	}
+	if err := database.RunCommand(ctx, bson.D{bson.E{Key: "dbStats", Value: 1}}).Decode(&size); err != nil {
+		return nil, fmt.Errorf("error reading database size: %w", err)
+	}
+	stats.SizeBytes = int64(size.StorageSize + size.IndexSize)
+
+	return stats, nil
+}
+
+// Drop deletes the whole database, with the versions and every other collection of the
+// registry. It is meant for scratch databases, such as those loaded by benchmarks.
+func (db *MongoDB) Drop(ctx context.Context) error {
+	db.mu.RLock()
+	database := db.database
+	db.mu.RUnlock()
+
+	if err := database.Drop(ctx); err != nil {
+		return fmt.Errorf("error dropping database: %w", err)
+	}
 	return nil
 }
 
-// Close closes the database connection
+// Close stops the health monitor and closes the database connection
 func (db *MongoDB) Close() error {
-	return db.client.Disconnect(context.Background())
+	db.closeOnce.Do(func() { close(db.done) })
+
+	db.mu.RLock()
+	client := db.client
+	db.mu.RUnlock()
+
+	return client.Disconnect(context.Background())
 }
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// aliases returns the collection holding the aliases of renamed servers, keyed by slug
+func (db *MongoDB) aliases() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_aliases")
+}
+
+// SetAlias stores alias, replacing any alias with the same slug
+func (db *MongoDB) SetAlias(ctx context.Context, alias *model.ServerAlias) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err = db.aliases().ReplaceOne(ctx, bson.M{"_id": alias.Slug}, alias, opts); err != nil {
+		return fmt.Errorf("error storing alias: %w", err)
+	}
+	return nil
+}
+
+// DeleteAlias removes the alias with the given slug
+func (db *MongoDB) DeleteAlias(ctx context.Context, slug string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.aliases().DeleteOne(ctx, bson.M{"_id": slug})
+	if err != nil {
+		return fmt.Errorf("error deleting alias: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetAlias retrieves the alias with the given slug
+func (db *MongoDB) GetAlias(ctx context.Context, slug string) (_ *model.ServerAlias, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var alias model.ServerAlias
+	err = db.aliases().FindOne(ctx, bson.M{"_id": slug}).Decode(&alias)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving alias: %w", err)
+	}
+	return &alias, nil
+}
+
+// ListAliases returns every alias by former name. Aliases are few, so no index backs the sort.
+func (db *MongoDB) ListAliases(ctx context.Context) (_ []*model.ServerAlias, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	cursor, err := db.aliases().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{bson.E{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("error listing aliases: %w", err)
+	}
+
+	aliases := []*model.ServerAlias{}
+	if err = cursor.All(ctx, &aliases); err != nil {
+		return nil, fmt.Errorf("error decoding aliases: %w", err)
+	}
+	return aliases, nil
+}
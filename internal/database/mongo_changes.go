@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// changeCounterID is the counter document allocating change revisions
+	changeCounterID = "changes"
+	// changeSettleDelay holds back the newest changes from readers. Revisions are allocated
+	// before the change is inserted, so concurrent writers may briefly expose revision N+1
+	// before N; a mirror that had already advanced past N would otherwise never see it.
+	changeSettleDelay = 2 * time.Second
+)
+
+// changes returns the collection holding the change log
+func (db *MongoDB) changes() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_changes")
+}
+
+// counters returns the collection holding sequence counters
+func (db *MongoDB) counters() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_counters")
+}
+
+// createChangeIndexes creates the indexes backing change log queries
+func createChangeIndexes(ctx context.Context, changes *mongo.Collection) error {
+	_, err := changes.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{bson.E{Key: "revision", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{bson.E{Key: "timestamp", Value: 1}},
+		},
+	})
+	var commandError mongo.CommandError
+	if err != nil && (!errors.As(err, &commandError) || commandError.Code != 86) {
+		return fmt.Errorf("error creating change log indexes: %w", err)
+	}
+	return nil
+}
+
+// recordChange allocates the next revision and appends change to the log
+func (db *MongoDB) recordChange(ctx context.Context, change *model.Change) error {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := db.counters().FindOneAndUpdate(ctx,
+		bson.M{"_id": changeCounterID},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return fmt.Errorf("error allocating change revision: %w", err)
+	}
+
+	change.Revision = counter.Seq
+	if _, err := db.changes().InsertOne(ctx, change); err != nil {
+		return fmt.Errorf("error recording change: %w", err)
+	}
+	return nil
+}
+
+// ListChanges returns change log entries after a revision or time
+func (db *MongoDB) ListChanges(
+	ctx context.Context,
+	sinceRevision int64,
+	sinceTime time.Time,
+	limit int,
+) (_ []*model.Change, err error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	if sinceTime.IsZero() {
+		pruned, err := db.prunedRevision(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if sinceRevision < pruned {
+			return nil, ErrPruned
+		}
+	}
+
+	timestamp := bson.M{"$lte": time.Now().Add(-changeSettleDelay)}
+	filter := bson.M{"revision": bson.M{"$gt": sinceRevision}, "timestamp": timestamp}
+	if !sinceTime.IsZero() {
+		timestamp["$gt"] = sinceTime
+		filter = bson.M{"timestamp": timestamp}
+	}
+
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "revision", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := db.changes().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing changes: %w", err)
+	}
+
+	changes := []*model.Change{}
+	if err = cursor.All(ctx, &changes); err != nil {
+		return nil, fmt.Errorf("error decoding changes: %w", err)
+	}
+	return changes, nil
+}
+
+// HeadRevision returns the revision of the most recent change
+func (db *MongoDB) HeadRevision(ctx context.Context) (_ int64, err error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return 0, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err = db.counters().FindOne(ctx, bson.M{"_id": changeCounterID}).Decode(&counter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading head revision: %w", err)
+	}
+	return counter.Seq, nil
+}
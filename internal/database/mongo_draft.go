@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// drafts returns the collection holding unpublished drafts
+func (db *MongoDB) drafts() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_drafts")
+}
+
+// createDraftIndexes creates the index backing per-server draft listings
+func createDraftIndexes(ctx context.Context, drafts *mongo.Collection) error {
+	_, err := drafts.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "server.name", Value: 1}, bson.E{Key: "created_at", Value: 1}},
+	})
+	var commandError mongo.CommandError
+	if err != nil && (!errors.As(err, &commandError) || commandError.Code != 86) {
+		return fmt.Errorf("error creating draft indexes: %w", err)
+	}
+	return nil
+}
+
+// CreateDraft stores draft. The per-server limit is checked before inserting, so concurrent
+// requests may briefly exceed it.
+func (db *MongoDB) CreateDraft(ctx context.Context, draft *model.Draft) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	count, err := db.drafts().CountDocuments(ctx, bson.M{"server.name": draft.Server.Name})
+	if err != nil {
+		return fmt.Errorf("error counting drafts: %w", err)
+	}
+	if count >= MaxDrafts {
+		return fmt.Errorf("%w: at most %d drafts are allowed per server", ErrInvalidInput, MaxDrafts)
+	}
+
+	if _, err = db.drafts().InsertOne(ctx, draft); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("error storing draft: %w", err)
+	}
+	return nil
+}
+
+// UpdateDraft replaces a stored draft
+func (db *MongoDB) UpdateDraft(ctx context.Context, draft *model.Draft) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.drafts().ReplaceOne(ctx, bson.M{"_id": draft.ID}, draft)
+	if err != nil {
+		return fmt.Errorf("error updating draft: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetDraft retrieves a draft by ID
+func (db *MongoDB) GetDraft(ctx context.Context, id string) (_ *model.Draft, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var draft model.Draft
+	if err = db.drafts().FindOne(ctx, bson.M{"_id": id}).Decode(&draft); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error retrieving draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// ListDrafts returns the drafts for a server name, or every draft when name is empty, oldest first
+func (db *MongoDB) ListDrafts(ctx context.Context, name string) (_ []*model.Draft, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}})
+	filter := bson.M{}
+	if name != "" {
+		filter["server.name"] = name
+	}
+	cursor, err := db.drafts().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing drafts: %w", err)
+	}
+
+	drafts := []*model.Draft{}
+	if err = cursor.All(ctx, &drafts); err != nil {
+		return nil, fmt.Errorf("error decoding drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+// DeleteDraft removes a draft
+func (db *MongoDB) DeleteDraft(ctx context.Context, id string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.drafts().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("error deleting draft: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// featuredServers returns the collection holding featured server entries, keyed by server name
+func (db *MongoDB) featuredServers() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_featured")
+}
+
+// SetFeatured stores featured, replacing any entry for the same server
+func (db *MongoDB) SetFeatured(ctx context.Context, featured *model.FeaturedServer) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err = db.featuredServers().ReplaceOne(ctx, bson.M{"_id": featured.Name}, featured, opts); err != nil {
+		return fmt.Errorf("error storing featured server: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeatured stops featuring the named server
+func (db *MongoDB) DeleteFeatured(ctx context.Context, name string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.featuredServers().DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("error deleting featured server: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListFeatured returns the featured entries by descending weight, then by name. Curated
+// lists are small, so no index backs the sort.
+func (db *MongoDB) ListFeatured(ctx context.Context) (_ []*model.FeaturedServer, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "weight", Value: -1}, bson.E{Key: "_id", Value: 1}})
+	cursor, err := db.featuredServers().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing featured servers: %w", err)
+	}
+
+	featured := []*model.FeaturedServer{}
+	if err = cursor.All(ctx, &featured); err != nil {
+		return nil, fmt.Errorf("error decoding featured servers: %w", err)
+	}
+	return featured, nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// prunedCounterID is the counter document holding the newest revision pruned from the log
+const prunedCounterID = "changes_pruned"
+
+// prunedRevision returns the newest revision garbage collected from the change log
+func (db *MongoDB) prunedRevision(ctx context.Context) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := db.counters().FindOne(ctx, bson.M{"_id": prunedCounterID}).Decode(&counter)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading pruned revision: %w", err)
+	}
+	return counter.Seq, nil
+}
+
+// CollectGarbage prunes expired leases, old changes and manifests no longer referenced
+func (db *MongoDB) CollectGarbage(ctx context.Context, policy RetentionPolicy) (_ *GCReport, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	report := &GCReport{}
+	now := time.Now()
+
+	leases, err := db.leases().DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lt": now.Add(-policy.Leases)}})
+	if err != nil {
+		return nil, fmt.Errorf("error deleting expired leases: %w", err)
+	}
+	report.Leases = leases.DeletedCount
+
+	if policy.Changes > 0 {
+		if report.Changes, err = db.pruneChanges(ctx, now.Add(-policy.Changes)); err != nil {
+			return nil, err
+		}
+	}
+	if report.PrunedRevision, err = db.prunedRevision(ctx); err != nil {
+		return nil, err
+	}
+
+	if report.Manifests, err = db.pruneManifests(ctx, now.Add(-policy.ManifestGrace)); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// pruneChanges deletes the change log entries recorded before cutoff. The pruned revision
+// is raised before deleting, so readers get ErrPruned rather than silently missing changes.
+func (db *MongoDB) pruneChanges(ctx context.Context, cutoff time.Time) (int64, error) {
+	var newest struct {
+		Revision int64 `bson:"revision"`
+	}
+	err := db.changes().FindOne(ctx,
+		bson.M{"timestamp": bson.M{"$lt": cutoff}},
+		options.FindOne().SetSort(bson.D{bson.E{Key: "revision", Value: -1}}),
+	).Decode(&newest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error finding changes to prune: %w", err)
+	}
+
+	_, err = db.counters().UpdateOne(ctx,
+		bson.M{"_id": prunedCounterID},
+		bson.M{"$max": bson.M{"seq": newest.Revision}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, fmt.Errorf("error recording pruned revision: %w", err)
+	}
+
+	result, err := db.changes().DeleteMany(ctx, bson.M{"revision": bson.M{"$lte": newest.Revision}})
+	if err != nil {
+		return 0, fmt.Errorf("error pruning changes: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
+// pruneManifests deletes manifests stored before cutoff that no version or retained change
+// refers to. Manifests stored before creation times were recorded are treated as old.
+func (db *MongoDB) pruneManifests(ctx context.Context, cutoff time.Time) (int64, error) {
+	referenced, err := db.coll().Distinct(ctx, "digest", bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("error listing referenced manifests: %w", err)
+	}
+	logged, err := db.changes().Distinct(ctx, "digest", bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("error listing referenced manifests: %w", err)
+	}
+	referenced = append(referenced, logged...)
+
+	result, err := db.manifests().DeleteMany(ctx, bson.M{
+		"_id": bson.M{"$nin": referenced},
+		"$or": bson.A{
+			bson.M{"created_at": bson.M{"$lt": cutoff}},
+			bson.M{"created_at": bson.M{"$exists": false}},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error pruning manifests: %w", err)
+	}
+	return result.DeletedCount, nil
+}
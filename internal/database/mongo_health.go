@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"registry/internal/health"
+)
+
+const (
+	// breakerFailureThreshold is the number of consecutive connectivity failures that opens the circuit
+	breakerFailureThreshold = 5
+	// breakerOpenDuration is how long the circuit stays open before a trial request is let through
+	breakerOpenDuration = 30 * time.Second
+	// maxReconnectBackoff caps the delay between reconnect attempts
+	maxReconnectBackoff = 2 * time.Minute
+)
+
+// circuitBreaker fails operations fast while the database is known to be unreachable
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow returns ErrUnavailable while the circuit is open. Once the open period has
+// elapsed a single trial operation is let through (half-open) to probe the database.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerFailureThreshold {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) {
+		return ErrUnavailable
+	}
+
+	// Half-open: re-arm the timer so concurrent callers keep failing fast until the trial reports back
+	b.openUntil = time.Now().Add(breakerOpenDuration)
+	return nil
+}
+
+// record updates the breaker with the outcome of an operation
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isConnectivityError(err) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures == breakerFailureThreshold {
+		log.Printf("MongoDB circuit breaker opened after %d consecutive failures", b.failures)
+	}
+	if b.failures >= breakerFailureThreshold {
+		b.openUntil = time.Now().Add(breakerOpenDuration)
+	}
+}
+
+// isOpen reports whether the breaker currently rejects operations
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= breakerFailureThreshold
+}
+
+// isConnectivityError reports whether err indicates the database could not be reached,
+// as opposed to a query level error such as a missing document
+func isConnectivityError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return mongo.IsNetworkError(err) || mongo.IsTimeout(err) || errors.Is(err, mongo.ErrClientDisconnected)
+}
+
+// StartHealthMonitor pings MongoDB every interval. Failed pings feed the circuit breaker,
+// and once it opens the client is re-created with exponential backoff until a ping succeeds.
+// The monitor stops when the database is closed.
+func (db *MongoDB) StartHealthMonitor(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
+
+		backoff := interval
+		for {
+			select {
+			case <-db.done:
+				return
+			case <-timer.C:
+			}
+
+			start := time.Now()
+			err := db.ping()
+			health.Default.Record("mongodb", time.Since(start), err)
+			db.breaker.record(err)
+
+			switch {
+			case err == nil:
+				backoff = interval
+			case db.breaker.isOpen():
+				log.Printf("MongoDB health check failed, reconnecting: %v", err)
+				if reconnectErr := db.reconnect(); reconnectErr != nil {
+					log.Printf("MongoDB reconnect failed, retrying in %s: %v", backoff, reconnectErr)
+					backoff = min(backoff*2, maxReconnectBackoff)
+				} else {
+					log.Println("MongoDB reconnected")
+					db.breaker.record(nil)
+					backoff = interval
+				}
+			default:
+				log.Printf("MongoDB health check failed: %v", err)
+			}
+
+			timer.Reset(backoff)
+		}
+	}()
+}
+
+// ping checks the current connection with a short timeout
+func (db *MongoDB) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db.mu.RLock()
+	client := db.client
+	db.mu.RUnlock()
+
+	return client.Ping(ctx, nil)
+}
+
+// reconnect replaces the client with a freshly connected one and disconnects the old client
+func (db *MongoDB) reconnect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, clientOptions(db.connectionURI, db.connections))
+	if err != nil {
+		return err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return err
+	}
+
+	db.mu.Lock()
+	old := db.client
+	db.client = client
+	db.database = client.Database(db.database.Name())
+	db.collection = db.database.Collection(db.collection.Name())
+	db.mu.Unlock()
+
+	// In-flight operations on the old client fail and are retried by callers
+	_ = old.Disconnect(context.Background())
+	return nil
+}
+
+// coll returns the current collection handle, which changes after a reconnect
+func (db *MongoDB) coll() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.collection
+}
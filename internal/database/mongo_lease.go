@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// leases returns the collection holding named leases
+func (db *MongoDB) leases() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_leases")
+}
+
+// AcquireLease takes or renews a lease document. The conditional upsert is atomic: when
+// another holder owns an unexpired lease the filter does not match, the upsert attempts to
+// insert a second document with the same _id and fails with a duplicate key error.
+func (db *MongoDB) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (_ bool, err error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err := db.breaker.allow(); err != nil {
+		return false, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	now := time.Now()
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"expires_at": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"holder": holder, "expires_at": now.Add(ttl)}}
+
+	_, err = db.leases().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error acquiring lease %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// ReleaseLease deletes the lease document if holder owns it
+func (db *MongoDB) ReleaseLease(ctx context.Context, name, holder string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	if _, err = db.leases().DeleteOne(ctx, bson.M{"_id": name, "holder": holder}); err != nil {
+		return fmt.Errorf("error releasing lease %s: %w", name, err)
+	}
+	return nil
+}
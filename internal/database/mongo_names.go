@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// nameRecord lists every spelling stored under one case-folded name. Its _id is the folded
+// name, so the collection's primary key makes two first publications race safely.
+type nameRecord struct {
+	Key       string   `bson:"_id"`
+	Spellings []string `bson:"spellings"`
+}
+
+// names returns the collection recording the spellings of every server name
+func (db *MongoDB) names() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_names")
+}
+
+// SetNameUniqueness sets which spellings of a name Publish accepts; it must be called
+// before the database is used
+func (db *MongoDB) SetNameUniqueness(uniqueness NameUniqueness) {
+	db.nameUniqueness = uniqueness
+}
+
+// claimName records the spelling of name before a version is published under it. When
+// names are unique case-insensitively, a spelling that differs from those already
+// recorded for the same folded name fails with ErrAlreadyExists.
+func (db *MongoDB) claimName(ctx context.Context, name string) error {
+	if db.nameUniqueness != NamesCaseInsensitive {
+		return db.recordNames(ctx, name)
+	}
+
+	key := nameKey(name)
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var record nameRecord
+	err := db.names().FindOneAndUpdate(ctx, bson.M{"_id": key},
+		bson.M{"$setOnInsert": bson.M{"spellings": []string{name}}}, opts).Decode(&record)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent publication inserted the record first; read the spelling it stored
+		err = db.names().FindOne(ctx, bson.M{"_id": key}).Decode(&record)
+	}
+	if err != nil {
+		return fmt.Errorf("error claiming name: %w", err)
+	}
+	if !slices.Contains(record.Spellings, name) {
+		return fmt.Errorf("%w: name %s is registered as %s", ErrAlreadyExists, name, record.Spellings[0])
+	}
+	return nil
+}
+
+// recordNames adds the given spellings to their folded names without checking them, for
+// versions the registry stores on trust such as replicated and seeded ones
+func (db *MongoDB) recordNames(ctx context.Context, names ...string) error {
+	models := make([]mongo.WriteModel, 0, len(names))
+	for _, name := range names {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": nameKey(name)}).
+			SetUpdate(bson.M{"$addToSet": bson.M{"spellings": name}}).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if _, err := db.names().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("error recording names: %w", err)
+	}
+	return nil
+}
+
+// migrateNames records the spelling of every name stored before spellings were tracked.
+// Names that already differ only in letter case all keep publishing. It is idempotent.
+func migrateNames(ctx context.Context, collection, names *mongo.Collection) error {
+	values, err := collection.Distinct(ctx, "name", bson.M{})
+	if err != nil {
+		return fmt.Errorf("error listing names: %w", err)
+	}
+
+	models := make([]mongo.WriteModel, 0, len(values))
+	spellings := make(map[string][]string)
+	for _, value := range values {
+		name, ok := value.(string)
+		if !ok {
+			continue
+		}
+		key := nameKey(name)
+		spellings[key] = append(spellings[key], name)
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": key}).
+			SetUpdate(bson.M{"$addToSet": bson.M{"spellings": name}}).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if _, err := names.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("error recording names: %w", err)
+	}
+	for _, list := range spellings {
+		if len(list) > 1 {
+			log.Printf("Names differing only in letter case are already registered: %v", list)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// archivedOrgs returns the collection holding archived organizations, keyed by organization
+func (db *MongoDB) archivedOrgs() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_archived_orgs")
+}
+
+// ArchiveOrg stores archived, replacing any entry for the same organization
+func (db *MongoDB) ArchiveOrg(ctx context.Context, archived *model.ArchivedOrg) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err = db.archivedOrgs().ReplaceOne(ctx, bson.M{"_id": archived.Org}, archived, opts); err != nil {
+		return fmt.Errorf("error archiving organization: %w", err)
+	}
+	return nil
+}
+
+// UnarchiveOrg removes the archive entry of org
+func (db *MongoDB) UnarchiveOrg(ctx context.Context, org string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.archivedOrgs().DeleteOne(ctx, bson.M{"_id": org})
+	if err != nil {
+		return fmt.Errorf("error unarchiving organization: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListArchivedOrgs returns the archive entries by organization
+func (db *MongoDB) ListArchivedOrgs(ctx context.Context) (_ []*model.ArchivedOrg, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "_id", Value: 1}})
+	cursor, err := db.archivedOrgs().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing archived organizations: %w", err)
+	}
+
+	archived := []*model.ArchivedOrg{}
+	if err = cursor.All(ctx, &archived); err != nil {
+		return nil, fmt.Errorf("error decoding archived organizations: %w", err)
+	}
+	return archived, nil
+}
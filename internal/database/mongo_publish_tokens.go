@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// publishTokens returns the collection holding publish tokens
+func (db *MongoDB) publishTokens() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_publish_tokens")
+}
+
+// createPublishTokenIndexes creates the indexes backing token lookups and per-server listings
+func createPublishTokenIndexes(ctx context.Context, tokens *mongo.Collection) error {
+	_, err := tokens.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{bson.E{Key: "digest", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{bson.E{Key: "server_name", Value: 1}, bson.E{Key: "created_at", Value: 1}}},
+	})
+	var commandError mongo.CommandError
+	if err != nil && (!errors.As(err, &commandError) || commandError.Code != 86) {
+		return fmt.Errorf("error creating publish token indexes: %w", err)
+	}
+	return nil
+}
+
+// CreatePublishToken stores token. The per-server limit is checked before inserting, so
+// concurrent requests for one server may briefly exceed it.
+func (db *MongoDB) CreatePublishToken(ctx context.Context, token *model.PublishToken) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	count, err := db.publishTokens().CountDocuments(ctx, bson.M{"server_name": token.ServerName})
+	if err != nil {
+		return fmt.Errorf("error counting publish tokens: %w", err)
+	}
+	if count >= MaxPublishTokens {
+		return fmt.Errorf("%w: at most %d publish tokens are allowed per server", ErrInvalidInput, MaxPublishTokens)
+	}
+
+	if _, err = db.publishTokens().InsertOne(ctx, token); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("error storing publish token: %w", err)
+	}
+	return nil
+}
+
+// GetPublishToken returns the publish token with the given digest
+func (db *MongoDB) GetPublishToken(ctx context.Context, digest string) (_ *model.PublishToken, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var token model.PublishToken
+	err = db.publishTokens().FindOne(ctx, bson.M{"digest": digest}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error looking up publish token: %w", err)
+	}
+	return &token, nil
+}
+
+// ListPublishTokens returns the publish tokens of a server, or of every server when serverName
+// is empty, oldest first
+func (db *MongoDB) ListPublishTokens(ctx context.Context, serverName string) (_ []*model.PublishToken, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}})
+	filter := bson.M{}
+	if serverName != "" {
+		filter["server_name"] = serverName
+	}
+	cursor, err := db.publishTokens().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing publish tokens: %w", err)
+	}
+
+	tokens := []*model.PublishToken{}
+	if err = cursor.All(ctx, &tokens); err != nil {
+		return nil, fmt.Errorf("error decoding publish tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeletePublishToken removes one of the publish tokens of a server
+func (db *MongoDB) DeletePublishToken(ctx context.Context, serverName, id string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.publishTokens().DeleteOne(ctx, bson.M{"_id": id, "server_name": serverName})
+	if err != nil {
+		return fmt.Errorf("error deleting publish token: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reports returns the collection holding abuse reports
+func (db *MongoDB) reports() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_reports")
+}
+
+// createReportIndexes creates the index backing the moderation queue
+func createReportIndexes(ctx context.Context, reports *mongo.Collection) error {
+	_, err := reports.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "status", Value: 1}, bson.E{Key: "created_at", Value: 1}},
+	})
+	var commandError mongo.CommandError
+	if err != nil && (!errors.As(err, &commandError) || commandError.Code != 86) {
+		return fmt.Errorf("error creating report indexes: %w", err)
+	}
+	return nil
+}
+
+// CreateReport stores report
+func (db *MongoDB) CreateReport(ctx context.Context, report *model.AbuseReport) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	if _, err = db.reports().InsertOne(ctx, report); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("error storing report: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns the abuse report with the given ID
+func (db *MongoDB) GetReport(ctx context.Context, id string) (_ *model.AbuseReport, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var report model.AbuseReport
+	err = db.reports().FindOne(ctx, bson.M{"_id": id}).Decode(&report)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error looking up report: %w", err)
+	}
+	return &report, nil
+}
+
+// ListReports returns up to limit abuse reports, oldest first
+func (db *MongoDB) ListReports(ctx context.Context, status model.ReportStatus, limit int) (_ []*model.AbuseReport, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := db.reports().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing reports: %w", err)
+	}
+
+	reports := []*model.AbuseReport{}
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, fmt.Errorf("error decoding reports: %w", err)
+	}
+	return reports, nil
+}
+
+// UpdateReport replaces a stored abuse report
+func (db *MongoDB) UpdateReport(ctx context.Context, report *model.AbuseReport) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.reports().ReplaceOne(ctx, bson.M{"_id": report.ID}, report)
+	if err != nil {
+		return fmt.Errorf("error updating report: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxReadRetryDelay caps the backoff between read attempts
+const maxReadRetryDelay = time.Second
+
+// RetryPolicy configures how reads are retried after transient MongoDB errors, such as
+// those returned while a replica set elects a new primary
+type RetryPolicy struct {
+	// Retries is the number of attempts after the first; zero disables retries
+	Retries int
+	// Backoff is the delay before the first retry, doubled for every further retry and
+	// jittered so concurrent readers do not retry in lockstep
+	Backoff time.Duration
+}
+
+// transientServerErrors are the server error codes of failovers and shutdowns
+var transientServerErrors = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	89,    // NetworkTimeout
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// SetReadRetryPolicy sets how List and GetByID retry transient errors; it must be called
+// before the database is used
+func (db *MongoDB) SetReadRetryPolicy(policy RetryPolicy) {
+	db.readRetry = policy
+}
+
+// isTransientReadError reports whether a read failing with err may succeed when retried
+func isTransientReadError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		if serverErr.HasErrorLabel("RetryableReadError") || serverErr.HasErrorLabel("TransientTransactionError") {
+			return true
+		}
+		for _, code := range transientServerErrors {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withReadRetry runs read until it succeeds, fails with an error that is not transient,
+// ctx is done or the retries of the policy are used up. The last error is returned.
+func (db *MongoDB) withReadRetry(ctx context.Context, read func() error) error {
+	delay := db.readRetry.Backoff
+	for attempt := 0; ; attempt++ {
+		err := read()
+		if attempt >= db.readRetry.Retries || !isTransientReadError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		// Sleep between half and the full backoff
+		wait := delay/2 + rand.N(delay/2+1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxReadRetryDelay)
+	}
+}
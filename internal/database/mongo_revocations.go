@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// revokedTokens returns the collection holding revoked tokens, keyed by token digest
+func (db *MongoDB) revokedTokens() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_revoked_tokens")
+}
+
+// RevokeToken stores revoked, keeping the first revocation of a token
+func (db *MongoDB) RevokeToken(ctx context.Context, revoked *model.RevokedToken) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Update().SetUpsert(true)
+	update := bson.M{"$setOnInsert": revoked}
+	if _, err = db.revokedTokens().UpdateOne(ctx, bson.M{"_id": revoked.Digest}, update, opts); err != nil {
+		return fmt.Errorf("error revoking token: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token with the given digest was revoked
+func (db *MongoDB) IsTokenRevoked(ctx context.Context, digest string) (_ bool, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return false, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	err = db.revokedTokens().FindOne(ctx, bson.M{"_id": digest}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error looking up token revocation: %w", err)
+	}
+	return true, nil
+}
+
+// ListRevokedTokens returns every revoked token, oldest revocation first
+func (db *MongoDB) ListRevokedTokens(ctx context.Context) (_ []*model.RevokedToken, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "revoked_at", Value: 1}, bson.E{Key: "_id", Value: 1}})
+	cursor, err := db.revokedTokens().Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing revoked tokens: %w", err)
+	}
+
+	revoked := []*model.RevokedToken{}
+	if err = cursor.All(ctx, &revoked); err != nil {
+		return nil, fmt.Errorf("error decoding revoked tokens: %w", err)
+	}
+	return revoked, nil
+}
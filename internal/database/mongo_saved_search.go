@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"registry/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// savedSearches returns the collection holding saved searches
+func (db *MongoDB) savedSearches() *mongo.Collection {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.database.Collection(db.collection.Name() + "_saved_searches")
+}
+
+// createSavedSearchIndexes creates the index backing per-owner listings
+func createSavedSearchIndexes(ctx context.Context, searches *mongo.Collection) error {
+	_, err := searches.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "owner", Value: 1}, bson.E{Key: "created_at", Value: 1}},
+	})
+	var commandError mongo.CommandError
+	if err != nil && (!errors.As(err, &commandError) || commandError.Code != 86) {
+		return fmt.Errorf("error creating saved search indexes: %w", err)
+	}
+	return nil
+}
+
+// CreateSavedSearch stores search. The per-owner limit is checked before inserting, so
+// concurrent requests by one owner may briefly exceed it.
+func (db *MongoDB) CreateSavedSearch(ctx context.Context, search *model.SavedSearch) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	count, err := db.savedSearches().CountDocuments(ctx, bson.M{"owner": search.Owner})
+	if err != nil {
+		return fmt.Errorf("error counting saved searches: %w", err)
+	}
+	if count >= MaxSavedSearches {
+		return fmt.Errorf("%w: at most %d saved searches are allowed", ErrInvalidInput, MaxSavedSearches)
+	}
+
+	if _, err = db.savedSearches().InsertOne(ctx, search); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("error storing saved search: %w", err)
+	}
+	return nil
+}
+
+// ListSavedSearches returns the owner's saved searches, oldest first
+func (db *MongoDB) ListSavedSearches(ctx context.Context, owner string) (_ []*model.SavedSearch, err error) {
+	if err := db.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	filter := bson.M{}
+	if owner != "" {
+		filter["owner"] = owner
+	}
+	opts := options.Find().SetSort(bson.D{bson.E{Key: "created_at", Value: 1}, bson.E{Key: "_id", Value: 1}})
+	cursor, err := db.savedSearches().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing saved searches: %w", err)
+	}
+
+	searches := []*model.SavedSearch{}
+	if err = cursor.All(ctx, &searches); err != nil {
+		return nil, fmt.Errorf("error decoding saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch removes one of the owner's saved searches
+func (db *MongoDB) DeleteSavedSearch(ctx context.Context, owner, id string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	result, err := db.savedSearches().DeleteOne(ctx, bson.M{"_id": id, "owner": owner})
+	if err != nil {
+		return fmt.Errorf("error deleting saved search: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ConfirmSavedSearchEmail marks the email address of one of the owner's saved searches as confirmed
+func (db *MongoDB) ConfirmSavedSearchEmail(ctx context.Context, owner, id, tokenHash string) (err error) {
+	if err := db.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { db.breaker.record(err) }()
+
+	var search model.SavedSearch
+	err = db.savedSearches().FindOne(ctx, bson.M{"_id": id, "owner": owner}).Decode(&search)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("error reading saved search: %w", err)
+	}
+	if search.EmailConfirmed {
+		return nil
+	}
+	if search.EmailTokenHash == "" || subtle.ConstantTimeCompare([]byte(search.EmailTokenHash), []byte(tokenHash)) != 1 {
+		return fmt.Errorf("%w: invalid confirmation token", ErrInvalidInput)
+	}
+	_, err = db.savedSearches().UpdateOne(ctx,
+		bson.M{"_id": id, "owner": owner, "email_token_hash": tokenHash},
+		bson.M{"$set": bson.M{"email_confirmed": true}, "$unset": bson.M{"email_token_hash": ""}})
+	if err != nil {
+		return fmt.Errorf("error confirming saved search: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build mongo
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestMongoDB connects to a fresh database on MCP_REGISTRY_TEST_DATABASE_URL, dropped
+// when the test ends, and skips the test when the variable is not set.
+//
+// Run with: MCP_REGISTRY_TEST_DATABASE_URL=mongodb://localhost:27017 go test -tags mongo ./internal/database
+func newTestMongoDB(t *testing.T) *MongoDB {
+	t.Helper()
+	url := os.Getenv("MCP_REGISTRY_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("MCP_REGISTRY_TEST_DATABASE_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	db, err := NewMongoDB(ctx, url, fmt.Sprintf("registry_test_%d", time.Now().UnixNano()), "servers")
+	if err != nil {
+		t.Fatalf("connecting to MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := db.database.Drop(context.Background()); err != nil {
+			t.Errorf("dropping test database: %v", err)
+		}
+		db.Close()
+	})
+	return db
+}
+
+func TestMongoDBNameSearch(t *testing.T) {
+	testNameSearch(t, newTestMongoDB(t))
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"registry/internal/model"
+)
+
+// publishName publishes a version of a server called name to db
+func publishName(ctx context.Context, db Database, name, version string) error {
+	return db.Publish(ctx, &model.ServerDetail{Server: model.Server{
+		Name:          name,
+		Repository:    model.Repository{URL: "https://github.com/acme/filesystem-server"},
+		VersionDetail: model.VersionDetail{Version: version},
+	}})
+}
+
+// seedNames publishes each name and version to db
+func seedNames(t *testing.T, db Database, seeds []struct{ name, version string }) {
+	t.Helper()
+	for _, seed := range seeds {
+		if err := publishName(context.Background(), db, seed.name, seed.version); err != nil {
+			t.Fatalf("publishing %s %s: %v", seed.name, seed.version, err)
+		}
+	}
+}
+
+// testNameSearch publishes mixed-case names to db and checks that searches find them
+// whatever the case of the query
+func testNameSearch(t *testing.T, db Database) {
+	t.Helper()
+	ctx := context.Background()
+	seedNames(t, db, []struct{ name, version string }{
+		{"io.github.acme/Filesystem-Server", "1.0.0"},
+		{"io.github.acme/Filesystem-Server", "1.1.0"},
+		{"io.github.acme/Filesystem-Server-Two", "1.0.0"},
+		{"io.github.acme/Other", "1.0.0"},
+	})
+
+	tests := []struct {
+		search Search
+		want   int
+	}{
+		{Search{Query: "io.github.acme/Filesystem-Server", Match: MatchExact}, 2},
+		{Search{Query: "io.github.acme/filesystem-server", Match: MatchExact}, 2},
+		{Search{Query: "IO.GITHUB.ACME/FILESYSTEM-SERVER", Match: MatchExact}, 2},
+		{Search{Query: "io.github.acme/filesystem", Match: MatchExact}, 0},
+		{Search{Query: "IO.github.Acme/File", Match: MatchPrefix}, 3},
+		{Search{Query: "filesystem", Match: MatchPrefix}, 0},
+		{Search{Query: "SYSTEM-serv", Match: MatchSubstring}, 3},
+		{Search{Query: "io.github.acme/FÍLESYSTEM-server", Match: MatchExact, FoldAccents: true}, 2},
+		{Search{Query: "io.github.acme/FÍLESYSTEM-server", Match: MatchExact}, 0},
+	}
+	for _, tt := range tests {
+		got, err := db.Count(ctx, map[string]interface{}{"search": tt.search})
+		if err != nil {
+			t.Fatalf("Count(%+v): %v", tt.search, err)
+		}
+		if got != tt.want {
+			t.Errorf("Count(%+v) = %d, want %d", tt.search, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryDBNameSearch(t *testing.T) {
+	testNameSearch(t, NewMemoryDB(map[string]*model.Server{}))
+}
@@ -0,0 +1,25 @@
+package database
+
+// StoreStats describes the state of a database backend for diagnostics. Fields a backend
+// cannot measure are left zero.
+type StoreStats struct {
+	Backend string `json:"backend"`
+	// Entries is the number of stored server entries, including previous versions
+	Entries int64 `json:"entries"`
+	// Manifests is the number of stored version manifests
+	Manifests int64 `json:"manifests"`
+	// Changes is the number of entries retained in the change log
+	Changes int64 `json:"changes"`
+	// SizeBytes is the space the data and its indexes take on disk
+	SizeBytes int64 `json:"size_bytes,omitempty"`
+	// OpenConnections is the number of connections the client holds to the database server
+	OpenConnections int64 `json:"open_connections,omitempty"`
+	// OpenSessions is the number of sessions currently checked out by the client
+	OpenSessions int `json:"open_sessions,omitempty"`
+	// SlowQueries counts operations slower than the slow query threshold since startup
+	SlowQueries int64 `json:"slow_queries"`
+	// LockWaitSeconds is the cumulative time spent waiting for store locks
+	LockWaitSeconds float64 `json:"lock_wait_seconds,omitempty"`
+	// CircuitOpen reports whether the backend is currently failing fast
+	CircuitOpen bool `json:"circuit_open,omitempty"`
+}
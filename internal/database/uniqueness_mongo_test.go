@@ -0,0 +1,9 @@
+//go:build mongo
+
+package database
+
+import "testing"
+
+func TestMongoDBNameUniqueness(t *testing.T) {
+	testNameUniqueness(t, newTestMongoDB(t))
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"registry/internal/model"
+)
+
+// testNameUniqueness publishes a mixed-case name to db and checks that other spellings of
+// it are rejected
+func testNameUniqueness(t *testing.T, db Database) {
+	t.Helper()
+	ctx := context.Background()
+	seedNames(t, db, []struct{ name, version string }{
+		{"io.github.acme/Filesystem-Server", "1.0.0"},
+		{"io.github.acme/Other", "1.0.0"},
+	})
+
+	tests := []struct {
+		name    string
+		version string
+		want    error
+	}{
+		{"io.github.acme/Filesystem-Server", "1.1.0", nil},
+		{"io.github.acme/Filesystem-Server", "1.1.0", ErrAlreadyExists},
+		{"io.github.acme/filesystem-server", "2.0.0", ErrAlreadyExists},
+		{"IO.GITHUB.ACME/FILESYSTEM-SERVER", "2.0.0", ErrAlreadyExists},
+		{"io.github.acme/Filesystem-Server-Two", "1.0.0", nil},
+	}
+	for _, tt := range tests {
+		if err := publishName(ctx, db, tt.name, tt.version); !errors.Is(err, tt.want) {
+			t.Errorf("Publish(%s, %s) = %v, want %v", tt.name, tt.version, err, tt.want)
+		}
+	}
+}
+
+func TestMemoryDBNameUniqueness(t *testing.T) {
+	testNameUniqueness(t, NewMemoryDB(map[string]*model.Server{}))
+}
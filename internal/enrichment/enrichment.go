@@ -0,0 +1,220 @@
+// Package enrichment augments registry entries with metadata fetched from their source repositories
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"registry/internal/model"
+)
+
+const (
+	// githubAPIURL is the base URL of the GitHub REST API
+	githubAPIURL = "https://api.github.com"
+	// readmeExcerptLength is the maximum number of characters kept from a README
+	readmeExcerptLength = 500
+	// requestSpacing throttles consecutive GitHub API calls during a refresh
+	requestSpacing = 250 * time.Millisecond
+)
+
+// Metadata is the repository information collected for a server
+type Metadata struct {
+	Stars         int       `json:"stars"`
+	License       string    `json:"license,omitempty"`
+	DefaultBranch string    `json:"default_branch,omitempty"`
+	LastCommitAt  string    `json:"last_commit_at,omitempty"`
+	ReadmeExcerpt string    `json:"readme_excerpt,omitempty"`
+	FetchedAt     time.Time `json:"fetched_at"`
+}
+
+// Enricher fetches and caches repository metadata from the GitHub API
+type Enricher struct {
+	token  string
+	ttl    time.Duration
+	client *http.Client
+
+	mu    sync.RWMutex
+	cache map[string]*Metadata
+}
+
+// NewEnricher creates an enricher authenticating with the given GitHub token, which may be
+// empty. Refresh fetches metadata again once it is older than ttl.
+func NewEnricher(token string, ttl time.Duration) *Enricher {
+	return &Enricher{
+		token:  token,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+		cache:  make(map[string]*Metadata),
+	}
+}
+
+// Lookup returns the cached metadata for a repository URL, if any
+func (e *Enricher) Lookup(repoURL string) (*Metadata, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	metadata, ok := e.cache[normalizeRepoURL(repoURL)]
+	return metadata, ok
+}
+
+// Refresh fetches metadata for every listed repository whose cache entry is missing or
+// stale. repos is called first to list the repository URLs to enrich.
+func (e *Enricher) Refresh(ctx context.Context, repos func() ([]string, error)) error {
+	urls, err := repos()
+	if err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	refreshed, fresh := 0, 0
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for _, repoURL := range urls {
+		if cached, ok := e.Lookup(repoURL); ok && time.Since(cached.FetchedAt) < e.ttl {
+			fresh++
+			continue
+		}
+
+		// Space out API calls, stopping at once when the job is cancelled
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		metadata, err := e.fetch(ctx, repoURL)
+		timer.Reset(requestSpacing)
+		if err != nil {
+			log.Printf("Enrichment: skipping %s: %v", repoURL, err)
+			continue
+		}
+
+		e.mu.Lock()
+		e.cache[normalizeRepoURL(repoURL)] = metadata
+		e.mu.Unlock()
+		refreshed++
+	}
+
+	log.Printf("Enrichment: refreshed metadata for %d of %d repositories, %d still fresh", refreshed, len(urls), fresh)
+	return nil
+}
+
+// fetch retrieves repository and README information for a GitHub repository URL
+func (e *Enricher) fetch(ctx context.Context, repoURL string) (*Metadata, error) {
+	owner, repo, ok := parseGitHubRepo(repoURL)
+	if !ok {
+		return nil, fmt.Errorf("not a GitHub repository")
+	}
+
+	var info struct {
+		StargazersCount int    `json:"stargazers_count"`
+		DefaultBranch   string `json:"default_branch"`
+		PushedAt        string `json:"pushed_at"`
+		License         *struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+	body, err := e.get(ctx, fmt.Sprintf("%s/repos/%s/%s", githubAPIURL, owner, repo), "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("invalid repository response: %w", err)
+	}
+
+	metadata := &Metadata{
+		Stars:         info.StargazersCount,
+		DefaultBranch: info.DefaultBranch,
+		LastCommitAt:  info.PushedAt,
+		FetchedAt:     time.Now().UTC(),
+	}
+	if info.License != nil {
+		metadata.License = info.License.SPDXID
+	}
+
+	// A missing README is not an error, the excerpt is simply left empty
+	readme, err := e.get(ctx, fmt.Sprintf("%s/repos/%s/%s/readme", githubAPIURL, owner, repo), "application/vnd.github.raw")
+	if err == nil {
+		metadata.ReadmeExcerpt = excerpt(string(readme), readmeExcerptLength)
+	}
+
+	return metadata, nil
+}
+
+// get performs an authenticated GET request against the GitHub API
+func (e *Enricher) get(ctx context.Context, endpoint, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	// Bound the amount read, README files in particular can be large
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseGitHubRepo extracts the owner and repository name from a github.com URL
+func parseGitHubRepo(repoURL string) (owner, repo string, ok bool) {
+	u, err := url.Parse(repoURL)
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(u.Hostname(), "www."), "github.com") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
+
+// normalizeRepoURL builds the cache key for a repository URL
+func normalizeRepoURL(repoURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(repoURL), "/"), ".git")
+}
+
+// excerpt truncates s to at most n runes, cutting at the last whitespace before the limit
+func excerpt(s string, n int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndexAny(cut, " \n\t"); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
+
+// RepositoryURLs lists the distinct repository URLs of the latest version of every server
+func RepositoryURLs(stream func(filter map[string]interface{}, fn func(model.Server) error) error) ([]string, error) {
+	seen := make(map[string]bool)
+	var urls []string
+	err := stream(nil, func(server model.Server) error {
+		key := normalizeRepoURL(server.Repository.URL)
+		if server.Repository.URL != "" && !seen[key] {
+			seen[key] = true
+			urls = append(urls, server.Repository.URL)
+		}
+		return nil
+	})
+	return urls, err
+}
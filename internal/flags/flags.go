@@ -0,0 +1,204 @@
+// Package flags provides feature flags with per-environment defaults, configuration
+// overrides and runtime overrides set through the admin API
+package flags
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"registry/internal/config"
+)
+
+// Known feature flags
+const (
+	// Metrics serves Prometheus metrics on /metrics
+	Metrics = "metrics"
+	// Export serves the full registry export on /v0/export
+	Export = "export"
+	// Enrichment periodically fetches repository metadata from GitHub
+	Enrichment = "enrichment"
+)
+
+// Definition describes a feature flag and its defaults
+type Definition struct {
+	Name        string
+	Description string
+	// Default is the value used when no environment default or override applies
+	Default bool
+	// Environments overrides Default for specific values of config.Environment
+	Environments map[string]bool
+}
+
+// definitions lists every known flag
+var definitions = []Definition{
+	{
+		Name:        Metrics,
+		Description: "Serve Prometheus metrics on /metrics",
+		Default:     true,
+	},
+	{
+		Name:        Export,
+		Description: "Serve the full registry export on /v0/export",
+		Default:     true,
+	},
+	{
+		Name:        Enrichment,
+		Description: "Fetch stars, license, last commit and README excerpts from GitHub",
+		Default:     false,
+	},
+}
+
+// Source identifies where the effective value of a flag comes from
+type Source string
+
+const (
+	SourceDefault     Source = "default"
+	SourceEnvironment Source = "environment"
+	SourceConfig      Source = "config"
+	SourceOverride    Source = "override"
+)
+
+// Status is the effective state of a flag
+type Status struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Source      Source `json:"source"`
+}
+
+// Set holds the effective values of all feature flags
+type Set struct {
+	mu         sync.RWMutex
+	configured map[string]Status
+	overrides  map[string]bool
+}
+
+// New builds the flag set for cfg. Values are resolved from the flag default, then the
+// environment default, then the legacy Enable* settings and finally MCP_REGISTRY_FEATURE_FLAGS.
+func New(cfg *config.Config) (*Set, error) {
+	set := &Set{
+		configured: make(map[string]Status, len(definitions)),
+		overrides:  make(map[string]bool),
+	}
+
+	for _, def := range definitions {
+		status := Status{Name: def.Name, Description: def.Description, Enabled: def.Default, Source: SourceDefault}
+		if enabled, ok := def.Environments[cfg.Environment]; ok {
+			status.Enabled, status.Source = enabled, SourceEnvironment
+		}
+		set.configured[def.Name] = status
+	}
+
+	// Honour the dedicated setting that predates the flags subsystem
+	if !cfg.EnableMetrics {
+		set.setConfigured(Metrics, false)
+	}
+
+	for _, entry := range strings.Split(cfg.FeatureFlags, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		enabled := true
+		if value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for feature flag %s: %w", name, err)
+			}
+			enabled = parsed
+		}
+		if _, ok := set.configured[name]; !ok {
+			return nil, fmt.Errorf("unknown feature flag: %s", name)
+		}
+		set.setConfigured(name, enabled)
+	}
+
+	return set, nil
+}
+
+// setConfigured records a value coming from configuration
+func (s *Set) setConfigured(name string, enabled bool) {
+	status := s.configured[name]
+	status.Enabled, status.Source = enabled, SourceConfig
+	s.configured[name] = status
+}
+
+// Enabled reports whether the named flag is on; unknown flags are off
+func (s *Set) Enabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if enabled, ok := s.overrides[name]; ok {
+		return enabled
+	}
+	return s.configured[name].Enabled
+}
+
+// Override sets a runtime value for a flag, taking precedence over configuration
+func (s *Set) Override(name string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.configured[name]; !ok {
+		return fmt.Errorf("unknown feature flag: %s", name)
+	}
+	s.overrides[name] = enabled
+	return nil
+}
+
+// ClearOverride removes a runtime override, restoring the configured value
+func (s *Set) ClearOverride(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.configured[name]; !ok {
+		return fmt.Errorf("unknown feature flag: %s", name)
+	}
+	delete(s.overrides, name)
+	return nil
+}
+
+// Get returns the effective status of a flag
+func (s *Set) Get(name string) (Status, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status, ok := s.configured[name]
+	if !ok {
+		return Status{}, false
+	}
+	if enabled, overridden := s.overrides[name]; overridden {
+		status.Enabled, status.Source = enabled, SourceOverride
+	}
+	return status, true
+}
+
+// All returns the effective status of every flag, ordered by name
+func (s *Set) All() []Status {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.configured))
+	for name := range s.configured {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(names)
+	statuses := make([]Status, 0, len(names))
+	for _, name := range names {
+		status, _ := s.Get(name)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Gate returns a handler that responds 404 while the named flag is disabled
+func (s *Set) Gate(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.Enabled(name) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,30 @@
+// Package gc removes stale operational data from the registry database
+package gc
+
+import (
+	"log"
+
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/service"
+)
+
+// Policy returns the retention windows configured for garbage collection
+func Policy(cfg *config.Config) database.RetentionPolicy {
+	return database.RetentionPolicy{
+		Changes:       cfg.GCChangeRetention,
+		Leases:        cfg.GCLeaseRetention,
+		ManifestGrace: cfg.GCManifestGrace,
+	}
+}
+
+// Collect removes stale operational data once and logs what was removed
+func Collect(registry service.RegistryService, policy database.RetentionPolicy) error {
+	report, err := registry.CollectGarbage(policy)
+	if err != nil {
+		return err
+	}
+	log.Printf("Garbage collection removed %d changes, %d leases and %d manifests",
+		report.Changes, report.Leases, report.Manifests)
+	return nil
+}
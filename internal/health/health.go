@@ -0,0 +1,92 @@
+// Package health records the process uptime, why the process last restarted and the
+// recent results of dependency checks, for operators without external monitoring
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// historySize is the number of dependency checks kept
+const historySize = 50
+
+// Check is the outcome of one dependency check
+type Check struct {
+	Dependency string    `json:"dependency"`
+	Time       time.Time `json:"time"`
+	OK         bool      `json:"ok"`
+	LatencyMS  float64   `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Recorder keeps the health history of the process
+type Recorder struct {
+	startedAt time.Time
+
+	mu            sync.Mutex
+	restartReason string
+	checks        []Check
+	next          int
+}
+
+// Default is the recorder of the running process
+var Default = NewRecorder()
+
+// NewRecorder creates a recorder for a process starting now
+func NewRecorder() *Recorder {
+	return &Recorder{startedAt: time.Now().UTC(), checks: make([]Check, 0, historySize)}
+}
+
+// StartedAt returns when the process started
+func (r *Recorder) StartedAt() time.Time {
+	return r.startedAt
+}
+
+// SetRestartReason records why the previous run of the process ended
+func (r *Recorder) SetRestartReason(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.restartReason = reason
+}
+
+// RestartReason returns why the previous run of the process ended
+func (r *Recorder) RestartReason() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.restartReason
+}
+
+// Record adds the outcome of a dependency check, dropping the oldest check once
+// historySize checks are kept
+func (r *Recorder) Record(dependency string, latency time.Duration, err error) {
+	check := Check{
+		Dependency: dependency,
+		Time:       time.Now().UTC(),
+		OK:         err == nil,
+		LatencyMS:  float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.checks) < historySize {
+		r.checks = append(r.checks, check)
+		return
+	}
+	r.checks[r.next] = check
+	r.next = (r.next + 1) % historySize
+}
+
+// Checks returns the recorded checks, newest first
+func (r *Recorder) Checks() []Check {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checks := make([]Check, 0, len(r.checks))
+	for i := len(r.checks) - 1; i >= 0; i-- {
+		checks = append(checks, r.checks[(r.next+i)%len(r.checks)])
+	}
+	return checks
+}
@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StateStore is the key-value state store of the database, where each instance records
+// whether it is running or stopped cleanly
+type StateStore interface {
+	LoadState(ctx context.Context, key string) (string, error)
+	SaveState(ctx context.Context, key, value string) error
+}
+
+// Process states recorded for an instance
+const (
+	stateRunning = "running"
+	stateStopped = "stopped"
+)
+
+// processKey is the state key of an instance
+func processKey(instance string) string {
+	return "process:" + instance
+}
+
+// TrackStart derives the restart reason from the state the instance's previous run left in
+// store, then records this run as running. A previous run still marked running ended
+// without shutting down, for example because it crashed or was killed. notFound is the
+// error store returns for missing keys.
+func (r *Recorder) TrackStart(ctx context.Context, store StateStore, instance string, notFound error) error {
+	previous, err := store.LoadState(ctx, processKey(instance))
+	switch {
+	case errors.Is(err, notFound):
+		r.SetRestartReason("first start")
+	case err != nil:
+		return err
+	default:
+		r.SetRestartReason(restartReason(previous))
+	}
+
+	return store.SaveState(ctx, processKey(instance), stateRunning+"|"+r.startedAt.Format(time.RFC3339))
+}
+
+// TrackStop records that the instance is shutting down cleanly for reason
+func (r *Recorder) TrackStop(ctx context.Context, store StateStore, instance, reason string) error {
+	value := stateStopped + "|" + time.Now().UTC().Format(time.RFC3339) + "|" + reason
+	return store.SaveState(ctx, processKey(instance), value)
+}
+
+// restartReason describes how the run recorded as previous ended
+func restartReason(previous string) string {
+	state, rest, _ := strings.Cut(previous, "|")
+	at, reason, _ := strings.Cut(rest, "|")
+	switch state {
+	case stateStopped:
+		return fmt.Sprintf("shutdown on %s at %s", reason, at)
+	case stateRunning:
+		return fmt.Sprintf("unclean exit of the run started at %s (crash, kill or host failure)", at)
+	default:
+		return "unknown"
+	}
+}
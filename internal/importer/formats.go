@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"registry/internal/model"
+)
+
+// streamRegistry decodes a JSON array of server details, or an object holding them in
+// "servers", one entry at a time
+func streamRegistry(r io.Reader, emit func(model.ServerDetail) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('['):
+		return decodeEntries(dec, emit)
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if key != "servers" {
+				var skipped json.RawMessage
+				if err := dec.Decode(&skipped); err != nil {
+					return err
+				}
+				continue
+			}
+			if tok, err := dec.Token(); err != nil {
+				return err
+			} else if tok != json.Delim('[') {
+				return errors.New("servers is not an array")
+			}
+			if err := decodeEntries(dec, emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.New("expected an array or an object")
+	}
+}
+
+// decodeEntries decodes the elements of the array dec is positioned in, consuming its end
+func decodeEntries(dec *json.Decoder, emit func(model.ServerDetail) error) error {
+	for dec.More() {
+		var server model.ServerDetail
+		if err := dec.Decode(&server); err != nil {
+			return err
+		}
+		if err := emit(server); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// npmSearchResult is the part of an npm registry search response used for seeding
+type npmSearchResult struct {
+	Objects []struct {
+		Package struct {
+			Name        string `json:"name"`
+			Version     string `json:"version"`
+			Description string `json:"description"`
+			Date        string `json:"date"`
+			Links       struct {
+				Repository string `json:"repository"`
+			} `json:"links"`
+		} `json:"package"`
+	} `json:"objects"`
+}
+
+// npmNamePrefix namespaces servers imported from npm, whose package names carry no owner
+// domain. Scoped packages keep their scope as the first path segment.
+const npmNamePrefix = "io.npmjs/"
+
+// parseNPM converts each package of an npm search dump to a server with one npm package
+func parseNPM(data []byte) ([]model.ServerDetail, error) {
+	var result npmSearchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	servers := make([]model.ServerDetail, 0, len(result.Objects))
+	for _, object := range result.Objects {
+		pkg := object.Package
+		if pkg.Name == "" {
+			continue
+		}
+
+		var server model.ServerDetail
+		server.Name = npmNamePrefix + strings.TrimPrefix(pkg.Name, "@")
+		server.Description = pkg.Description
+		server.Repository = repositoryFromURL(pkg.Links.Repository)
+		server.VersionDetail = model.VersionDetail{Version: pkg.Version, ReleaseDate: pkg.Date, IsLatest: true}
+		server.Packages = []model.Package{{RegistryName: "npm", Name: pkg.Name, Version: pkg.Version}}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// csvColumns are the columns understood in CSV seeds; only name is required
+var csvColumns = map[string]func(*model.ServerDetail, string){
+	"id":                func(s *model.ServerDetail, v string) { s.ID = v },
+	"name":              func(s *model.ServerDetail, v string) { s.Name = v },
+	"description":       func(s *model.ServerDetail, v string) { s.Description = v },
+	"version":           func(s *model.ServerDetail, v string) { s.VersionDetail.Version = v },
+	"release_date":      func(s *model.ServerDetail, v string) { s.VersionDetail.ReleaseDate = v },
+	"repository_url":    func(s *model.ServerDetail, v string) { s.Repository.URL = v },
+	"repository_source": func(s *model.ServerDetail, v string) { s.Repository.Source = v },
+	"repository_id":     func(s *model.ServerDetail, v string) { s.Repository.ID = v },
+	"package_registry":  func(s *model.ServerDetail, v string) { csvPackage(s).RegistryName = v },
+	"package_name":      func(s *model.ServerDetail, v string) { csvPackage(s).Name = v },
+	"package_version":   func(s *model.ServerDetail, v string) { csvPackage(s).Version = v },
+}
+
+// csvPackage returns the single package a CSV row can describe, creating it on first use
+func csvPackage(s *model.ServerDetail) *model.Package {
+	if len(s.Packages) == 0 {
+		s.Packages = []model.Package{{}}
+	}
+	return &s.Packages[0]
+}
+
+// streamCSV converts each row of a CSV file to a server. Unknown columns are ignored, and a
+// row's version is marked latest because a CSV seed lists one version per server.
+func streamCSV(r io.Reader, emit func(model.ServerDetail) error) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	setters := make([]func(*model.ServerDetail, string), len(header))
+	hasName := false
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		setters[i] = csvColumns[column]
+		hasName = hasName || column == "name"
+	}
+	if !hasName {
+		return errors.New("header has no name column")
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var server model.ServerDetail
+		for i, value := range record {
+			if i < len(setters) && setters[i] != nil && value != "" {
+				setters[i](&server, value)
+			}
+		}
+		if server.Repository.URL != "" && server.Repository.Source == "" {
+			server.Repository.Source = repositoryFromURL(server.Repository.URL).Source
+		}
+		server.VersionDetail.IsLatest = true
+		if err := emit(server); err != nil {
+			return err
+		}
+	}
+}
+
+// repositoryFromURL describes a repository from its URL, recognizing GitHub and GitLab hosts
+func repositoryFromURL(raw string) model.Repository {
+	raw = strings.TrimSuffix(strings.TrimPrefix(raw, "git+"), ".git")
+	repository := model.Repository{URL: raw}
+	if u, err := url.Parse(raw); err == nil {
+		switch strings.ToLower(u.Host) {
+		case "github.com":
+			repository.Source = "github"
+		case "gitlab.com":
+			repository.Source = "gitlab"
+		}
+	}
+	return repository
+}
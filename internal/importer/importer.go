@@ -0,0 +1,319 @@
+// Package importer reads seed files in the supported formats and converts their entries to
+// server details ready to be stored. Parsers only map the fields of their format; Normalize
+// applies the defaults and checks shared by every format.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// Format names a seed file format
+type Format string
+
+const (
+	// FormatAuto detects the format from the file extension and content
+	FormatAuto Format = "auto"
+	// FormatRegistry is a JSON array of server details, as exported by the registry and
+	// published as the official seed. An object with a "servers" array is also accepted.
+	FormatRegistry Format = "registry"
+	// FormatNPM is the JSON returned by the npm registry search API
+	FormatNPM Format = "npm"
+	// FormatCSV is a CSV file with a header row naming the columns
+	FormatCSV Format = "csv"
+)
+
+// DefaultPath is the seed file read when no path is configured
+var DefaultPath = filepath.Join("data", "seed.json")
+
+// Parser decodes the entries of a seed document
+type Parser func(data []byte) ([]model.ServerDetail, error)
+
+// StreamParser decodes the entries of a seed document from r, passing each to emit as soon
+// as it is read so that large seeds don't have to fit in memory. It stops at the first
+// error emit returns.
+type StreamParser func(r io.Reader, emit func(model.ServerDetail) error) error
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[Format]StreamParser{
+		FormatRegistry: streamRegistry,
+		FormatNPM:      buffered(parseNPM),
+		FormatCSV:      streamCSV,
+	}
+)
+
+// Register adds a parser for a custom format, replacing any parser registered under the same name
+func Register(format Format, parser Parser) {
+	RegisterStream(format, buffered(parser))
+}
+
+// RegisterStream adds a streaming parser for a custom format, replacing any parser
+// registered under the same name
+func RegisterStream(format Format, parser StreamParser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[format] = parser
+}
+
+// buffered adapts a parser of whole documents to the streaming interface
+func buffered(parser Parser) StreamParser {
+	return func(r io.Reader, emit func(model.ServerDetail) error) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		entries, err := parser(data)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := emit(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// idNamespace derives stable IDs for entries whose format carries none, so importing the
+// same file twice updates the entries instead of duplicating them
+var idNamespace = uuid.MustParse("6f1c1d2e-3a53-4c55-9a4e-2f6d0b7c8e91")
+
+// seedVersion is the version given to entries that do not declare one
+const seedVersion = "0.0.1-seed"
+
+// Parse decodes data in format and normalizes every entry. Entries failing normalization
+// are logged and skipped. path is only used to detect the format when format is FormatAuto.
+func Parse(data []byte, format Format, path string) ([]model.ServerDetail, error) {
+	var servers []model.ServerDetail
+	_, err := Stream(bytes.NewReader(data), format, path, func(server model.ServerDetail) error {
+		servers = append(servers, server)
+		return nil
+	})
+	return servers, err
+}
+
+// detectPrefixSize is how much of a seed file DetectFormat sees when streaming
+const detectPrefixSize = 64 << 10
+
+// Stream decodes r in format and passes every normalized entry to emit. Entries failing
+// normalization are logged and skipped, and their number is returned. path is only used to
+// detect the format when format is FormatAuto.
+func Stream(r io.Reader, format Format, path string, emit func(model.ServerDetail) error) (int, error) {
+	if format == "" || format == FormatAuto {
+		buffered := bufio.NewReaderSize(r, detectPrefixSize)
+		// Peek returns what it could read along with an error when the file is shorter
+		prefix, _ := buffered.Peek(detectPrefixSize)
+		format = DetectFormat(path, prefix)
+		r = buffered
+	}
+
+	parsersMu.RLock()
+	parser, ok := parsers[format]
+	parsersMu.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("unknown seed format %q", format)
+	}
+
+	read, rejected := 0, 0
+	err := parser(r, func(entry model.ServerDetail) error {
+		read++
+		if err := Normalize(&entry); err != nil {
+			log.Printf("Skipping seed entry %d: %v", read, err)
+			rejected++
+			return nil
+		}
+		return emit(entry)
+	})
+	if err != nil {
+		return rejected, fmt.Errorf("failed to parse %s seed: %w", format, err)
+	}
+	return rejected, nil
+}
+
+// DetectFormat guesses the format of a seed file from its extension and content. data may
+// be a prefix of the file: the top level keys of a JSON object are scanned until one
+// identifies the format or data runs out.
+func DetectFormat(path string, data []byte) Format {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return FormatRegistry
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil || key == "servers" {
+			break
+		}
+		if key == "objects" {
+			return FormatNPM
+		}
+		var skipped json.RawMessage
+		if err := dec.Decode(&skipped); err != nil {
+			break
+		}
+	}
+	return FormatRegistry
+}
+
+// Normalize checks an entry and fills in the fields a seed may leave out: a missing ID is
+// derived from the name and version, and a missing version becomes the latest seed version
+func Normalize(entry *model.ServerDetail) error {
+	entry.Name = strings.TrimSpace(entry.Name)
+	if entry.Name == "" {
+		return errors.New("name is empty")
+	}
+
+	if entry.VersionDetail.Version == "" {
+		entry.VersionDetail.Version = seedVersion
+		entry.VersionDetail.IsLatest = true
+	}
+	if entry.VersionDetail.ReleaseDate == "" {
+		entry.VersionDetail.ReleaseDate = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if entry.ID == "" {
+		entry.ID = uuid.NewSHA1(idNamespace, []byte(entry.Name+"@"+entry.VersionDetail.Version)).String()
+	}
+	id, ok := model.CanonicalID(entry.ID)
+	if !ok {
+		return fmt.Errorf("%s: id %q is not a UUID", entry.Name, entry.ID)
+	}
+	entry.ID = id
+	return nil
+}
+
+// ReadFile reads and parses the seed file at path, using DefaultPath when path is empty
+func ReadFile(path string, format Format) ([]model.ServerDetail, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+	log.Printf("Reading seed file from %s", path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+	defer file.Close()
+
+	var servers []model.ServerDetail
+	if _, err := Stream(file, format, path, func(server model.ServerDetail) error {
+		servers = append(servers, server)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	log.Printf("Found %d server entries in seed file", len(servers))
+	return servers, nil
+}
+
+// seedImportLockTTL bounds how long a crashed importer blocks other instances
+const seedImportLockTTL = time.Minute
+
+// importBatchSize is the number of entries handed to the store at a time, bounding the
+// memory an import needs regardless of the seed file's size
+const importBatchSize = 1000
+
+// Options configure ImportOnce
+type Options struct {
+	// Path is a local file or an https:// URL; DefaultPath is used when empty
+	Path   string
+	Format Format
+	// Policy resolves entries whose ID is already stored; overwrite when empty
+	Policy database.ImportPolicy
+	// SHA256 is the hex encoded digest the seed file must have, if set
+	SHA256 string
+	// DownloadTimeout bounds downloading a remote seed file
+	DownloadTimeout time.Duration
+}
+
+// ImportOnce imports the seed file unless an instance sharing the database already
+// imported the same file. Imports are serialized across instances and recorded by the
+// file's content hash, so editing the seed file triggers a new import. Entries are streamed
+// from the file and stored in batches. The returned report is nil when the file was
+// already imported.
+func ImportOnce(ctx context.Context, db database.Database, opts Options) (*database.ImportReport, error) {
+	path, format, policy := opts.Path, opts.Format, opts.Policy
+	if path == "" {
+		path = DefaultPath
+	}
+	if policy == "" {
+		policy = database.ImportOverwrite
+	}
+	if !policy.IsValid() {
+		return nil, fmt.Errorf("unknown seed conflict policy %q", policy)
+	}
+	local, digest, cleanup, err := stage(ctx, path, opts.SHA256, opts.DownloadTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	stateKey := "seed-import:" + digest
+
+	var report *database.ImportReport
+	err = database.RunExclusive(ctx, db, "seed-import", seedImportLockTTL, func(ctx context.Context) error {
+		if _, err := db.LoadState(ctx, stateKey); err == nil {
+			log.Printf("Seed file %s was already imported, skipping", path)
+			return nil
+		} else if !errors.Is(err, database.ErrNotFound) {
+			return err
+		}
+
+		file, err := os.Open(local)
+		if err != nil {
+			return fmt.Errorf("failed to read seed file: %w", err)
+		}
+		defer file.Close()
+
+		report = &database.ImportReport{}
+		batch := make([]model.ServerDetail, 0, importBatchSize)
+		flush := func() error {
+			batchReport, err := db.ImportSeed(ctx, batch, policy)
+			if err != nil {
+				return err
+			}
+			report.Add(batchReport)
+			batch = batch[:0]
+			return nil
+		}
+
+		log.Printf("Importing seed file %s", path)
+		rejected, err := Stream(file, format, path, func(server model.ServerDetail) error {
+			batch = append(batch, server)
+			if len(batch) == importBatchSize {
+				return flush()
+			}
+			return nil
+		})
+		if err == nil && len(batch) > 0 {
+			err = flush()
+		}
+		if err != nil {
+			return err
+		}
+		report.Errored += rejected
+		log.Printf("Seed import finished with policy %s: %s", policy, report)
+		return db.SaveState(ctx, stateKey, time.Now().UTC().Format(time.RFC3339))
+	})
+	return report, err
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultDownloadTimeout bounds downloading a remote seed file when no timeout is configured
+const DefaultDownloadTimeout = time.Minute
+
+// maxDownloadSize caps remote seed files so a misconfigured URL can't fill the disk
+const maxDownloadSize = 1 << 30
+
+// IsRemote reports whether path names a seed file to download rather than a local file
+func IsRemote(path string) bool {
+	return strings.HasPrefix(path, "https://")
+}
+
+// stage makes the seed file at path available as a local file and returns its hex encoded
+// sha256 digest. Remote files are downloaded to a temporary file that cleanup removes.
+// When checksum is set, the content must have that digest.
+func stage(ctx context.Context, path, checksum string, timeout time.Duration) (local, digest string, cleanup func(), err error) {
+	cleanup = func() {}
+	if IsRemote(path) {
+		local, digest, err = download(ctx, path, timeout)
+		if local != "" {
+			cleanup = func() { os.Remove(local) }
+		}
+	} else {
+		local = path
+		digest, err = fileDigest(path)
+	}
+	if err != nil {
+		cleanup()
+		return "", "", nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	if checksum != "" && !strings.EqualFold(digest, strings.TrimPrefix(checksum, "sha256:")) {
+		cleanup()
+		return "", "", nil, fmt.Errorf("seed file %s has sha256 %s, expected %s", path, digest, checksum)
+	}
+	return local, digest, cleanup, nil
+}
+
+// fileDigest returns the hex encoded sha256 digest of the file at path
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// download saves a remote seed file to a temporary file, failing on non-200 responses. The
+// temporary file's path is returned even on failure once it was created.
+func download(ctx context.Context, url string, timeout time.Duration) (string, string, error) {
+	if timeout <= 0 {
+		timeout = DefaultDownloadTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	log.Printf("Downloading seed file from %s", url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("downloading %s: unexpected status %s", url, resp.Status)
+	}
+
+	file, err := os.CreateTemp("", "registry-seed-*")
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return file.Name(), "", err
+	}
+	if n > maxDownloadSize {
+		return file.Name(), "", fmt.Errorf("downloading %s: seed file exceeds %d bytes", url, maxDownloadSize)
+	}
+	return file.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,204 @@
+// Package install renders ready-to-paste client configuration for registry entries
+package install
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"registry/internal/model"
+)
+
+// Client identifies an MCP client whose configuration format can be rendered
+type Client string
+
+const (
+	ClientClaudeDesktop Client = "claude-desktop"
+	ClientCursor        Client = "cursor"
+	ClientGeneric       Client = "generic"
+)
+
+var (
+	// ErrUnsupportedClient is returned for clients without a renderer
+	ErrUnsupportedClient = errors.New("unsupported client")
+	// ErrNotInstallable is returned when an entry has no package, transport or remote to launch
+	ErrNotInstallable = errors.New("server has no installable package or remote endpoint")
+)
+
+// Snippet is a rendered configuration fragment for a client
+type Snippet struct {
+	Client Client `json:"client"`
+	// Filename is the client configuration file the snippet belongs in
+	Filename string `json:"filename,omitempty"`
+	// Config is the JSON document to merge into the configuration file
+	Config map[string]interface{} `json:"config"`
+}
+
+// ServerConfig is the launch configuration of a single server, shared by the client formats
+type ServerConfig struct {
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Type    string            `json:"type,omitempty"`
+	URL     string            `json:"url,omitempty"`
+}
+
+// Render builds the configuration snippet of server for client
+func Render(server *model.ServerDetail, client Client) (*Snippet, error) {
+	launch, err := Launch(server)
+	if err != nil {
+		return nil, err
+	}
+	key := ServerKey(server.Name)
+
+	switch client {
+	case ClientClaudeDesktop:
+		// Claude Desktop only launches local processes, so remote servers go through mcp-remote
+		if launch.URL != "" {
+			launch = ServerConfig{Command: "npx", Args: []string{"-y", "mcp-remote", launch.URL}}
+		}
+		return &Snippet{
+			Client:   client,
+			Filename: "claude_desktop_config.json",
+			Config:   map[string]interface{}{"mcpServers": map[string]ServerConfig{key: launch}},
+		}, nil
+	case ClientCursor:
+		if launch.URL != "" {
+			launch = ServerConfig{URL: launch.URL}
+		}
+		return &Snippet{
+			Client:   client,
+			Filename: ".cursor/mcp.json",
+			Config:   map[string]interface{}{"mcpServers": map[string]ServerConfig{key: launch}},
+		}, nil
+	case ClientGeneric:
+		return &Snippet{
+			Client: client,
+			Config: map[string]interface{}{key: launch},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedClient, client)
+	}
+}
+
+// ServerKey derives the configuration key of a server from its name, e.g. io.github.owner/repo -> repo
+func ServerKey(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 && i < len(name)-1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// Launch determines how to start or connect to a server. Declared stdio transports take
+// precedence, then the first package, then remote endpoints.
+func Launch(server *model.ServerDetail) (ServerConfig, error) {
+	for _, t := range server.Transports {
+		if t.Type == model.TransportStdio {
+			return ServerConfig{Command: t.Command, Args: t.Args, Env: t.Env}, nil
+		}
+	}
+
+	if len(server.Packages) > 0 {
+		return packageLaunch(server.Packages[0]), nil
+	}
+
+	for _, t := range server.Transports {
+		if t.URL != "" {
+			return ServerConfig{Type: string(t.Type), URL: t.URL}, nil
+		}
+	}
+	for _, r := range server.Remotes {
+		if r.URL != "" {
+			return ServerConfig{Type: r.TransportType, URL: r.URL}, nil
+		}
+	}
+
+	return ServerConfig{}, ErrNotInstallable
+}
+
+// packageLaunch builds the command line running a package from its registry:
+// <command> <runtime flags> <runtime arguments> <package reference> <package arguments>
+func packageLaunch(pkg model.Package) ServerConfig {
+	var (
+		launch ServerConfig
+		ref    string
+	)
+
+	switch pkg.RegistryName {
+	case "npm":
+		launch.Command = "npx"
+		launch.Args = []string{"-y"}
+		ref = versioned(pkg.Name, "@", pkg.Version)
+	case "pypi":
+		launch.Command = "uvx"
+		ref = versioned(pkg.Name, "==", pkg.Version)
+	case "docker":
+		launch.Command = "docker"
+		launch.Args = []string{"run", "-i", "--rm"}
+		for _, env := range pkg.EnvironmentVariables {
+			launch.Args = append(launch.Args, "-e", env.Name)
+		}
+		ref = versioned(pkg.Name, ":", pkg.Version)
+	default:
+		launch.Command = pkg.Name
+	}
+	if pkg.RunTimeHint != "" {
+		launch.Command = pkg.RunTimeHint
+	}
+
+	for _, arg := range pkg.RuntimeArguments {
+		launch.Args = append(launch.Args, argumentValues(arg)...)
+	}
+	if ref != "" {
+		launch.Args = append(launch.Args, ref)
+	}
+	for _, arg := range pkg.PackageArguments {
+		launch.Args = append(launch.Args, argumentValues(arg)...)
+	}
+
+	if len(pkg.EnvironmentVariables) > 0 {
+		launch.Env = make(map[string]string, len(pkg.EnvironmentVariables))
+		for _, env := range pkg.EnvironmentVariables {
+			launch.Env[env.Name] = inputValue(env.Input, env.Name)
+		}
+	}
+
+	return launch
+}
+
+// versioned appends the version to a package name using the registry's separator
+func versioned(name, sep, version string) string {
+	if version == "" || version == "latest" {
+		return name
+	}
+	return name + sep + version
+}
+
+// argumentValues renders an argument as command line values
+func argumentValues(arg model.Argument) []string {
+	value := inputValue(arg.Input, arg.ValueHint)
+	if arg.Type == model.ArgumentTypeNamed {
+		// Some entries repeat the flag in the value, e.g. name and value both "--dir <path>"
+		if value == "" || value == arg.Name {
+			return []string{arg.Name}
+		}
+		return []string{arg.Name, value}
+	}
+	return []string{value}
+}
+
+// inputValue returns the fixed value or default of an input, or a placeholder users must fill in
+func inputValue(input model.Input, hint string) string {
+	switch {
+	case input.Value != "":
+		return input.Value
+	case input.Default != "":
+		return input.Default
+	case strings.HasPrefix(hint, "<"):
+		return hint
+	case hint != "":
+		return "<" + hint + ">"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,103 @@
+// Package leader elects a single replica to run background jobs when several registry
+// instances share one database
+package leader
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"registry/internal/database"
+	"registry/internal/metrics"
+)
+
+// leaseName is the lease contended for by every replica
+const leaseName = "background-jobs"
+
+// isLeader mirrors the elector state for the metrics gauge
+var isLeader atomic.Bool
+
+func init() {
+	metrics.NewGaugeFunc("mcp_registry_leader", "Whether this instance is the elected leader running background jobs.",
+		func() float64 {
+			if isLeader.Load() {
+				return 1
+			}
+			return 0
+		})
+}
+
+// Elector holds a lease in the database while it can renew it
+type Elector struct {
+	db     database.Database
+	holder string
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+// NewElector creates an elector identified by the host name and a random suffix
+func NewElector(db database.Database, ttl time.Duration) *Elector {
+	return &Elector{
+		db:     db,
+		holder: database.NewLeaseHolder(),
+		ttl:    ttl,
+	}
+}
+
+// IsLeader reports whether this instance currently holds the lease
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run contends for the lease until ctx is cancelled, renewing it well before it expires.
+// Leadership is given up as soon as a renewal fails, since the lease may lapse before the
+// next attempt, and the lease is released on shutdown so another replica takes over quickly.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.attempt(ctx)
+
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				e.setLeader(false)
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := e.db.ReleaseLease(releaseCtx, leaseName, e.holder); err != nil {
+					log.Printf("Failed to release leadership: %v", err)
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// attempt acquires or renews the lease once
+func (e *Elector) attempt(ctx context.Context) {
+	attemptCtx, cancel := context.WithTimeout(ctx, e.ttl/3)
+	defer cancel()
+
+	acquired, err := e.db.AcquireLease(attemptCtx, leaseName, e.holder, e.ttl)
+	if err != nil {
+		log.Printf("Leader election failed: %v", err)
+		acquired = false
+	}
+	e.setLeader(acquired)
+}
+
+// setLeader records a leadership change
+func (e *Elector) setLeader(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+	isLeader.Store(leader)
+	if leader {
+		log.Printf("Acquired leadership as %s", e.holder)
+	} else {
+		log.Printf("Lost leadership as %s", e.holder)
+	}
+}
@@ -0,0 +1,73 @@
+// Package lifecycle tracks the process lifecycle and serves Kubernetes style probe endpoints
+package lifecycle
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// State records whether the process has finished starting up and whether it is draining
+type State struct {
+	started  atomic.Bool
+	draining atomic.Bool
+}
+
+// New creates a lifecycle state for a process that is still starting
+func New() *State {
+	return &State{}
+}
+
+// MarkStarted records that initialization has completed and the server is accepting requests
+func (s *State) MarkStarted() {
+	s.started.Store(true)
+}
+
+// MarkDraining records that shutdown has begun, so load balancers should stop sending traffic
+func (s *State) MarkDraining() {
+	s.draining.Store(true)
+}
+
+// Started reports whether initialization has completed
+func (s *State) Started() bool {
+	return s.started.Load()
+}
+
+// Ready reports whether the process should receive traffic
+func (s *State) Ready() bool {
+	return s.started.Load() && !s.draining.Load()
+}
+
+// probeResponse is the body returned by the probe endpoints
+type probeResponse struct {
+	Status string `json:"status"`
+}
+
+// probeHandler returns a handler responding 200 when check passes and 503 otherwise
+func probeHandler(check func() bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, code := "ok", http.StatusOK
+		if !check() {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(probeResponse{Status: status})
+	}
+}
+
+// LivezHandler reports whether the process is alive; it only fails if the process cannot serve at all
+func (s *State) LivezHandler() http.HandlerFunc {
+	return probeHandler(func() bool { return true })
+}
+
+// ReadyzHandler reports whether the process should receive traffic; it fails while starting and draining
+func (s *State) ReadyzHandler() http.HandlerFunc {
+	return probeHandler(s.Ready)
+}
+
+// StartupzHandler reports whether initialization has completed
+func (s *State) StartupzHandler() http.HandlerFunc {
+	return probeHandler(s.Started)
+}
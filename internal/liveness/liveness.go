@@ -0,0 +1,105 @@
+// Package liveness checks that the remote endpoints of published servers respond
+package liveness
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"registry/internal/metrics"
+	"registry/internal/model"
+	"registry/internal/service"
+)
+
+// concurrency bounds the number of endpoints probed at once
+const concurrency = 8
+
+// unreachable mirrors the result of the last check for the metrics gauge
+var unreachable atomic.Int64
+
+func init() {
+	metrics.NewGaugeFunc("mcp_registry_unreachable_remotes", "Remote endpoints of latest versions that failed the last liveness check.",
+		func() float64 { return float64(unreachable.Load()) })
+}
+
+// Checker probes remote endpoints
+type Checker struct {
+	client *http.Client
+}
+
+// NewChecker creates a checker giving each endpoint timeout to respond
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{client: &http.Client{
+		Timeout: timeout,
+		// A redirect answer already shows the endpoint is up
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+// Check probes the remotes of the latest public version of every server and logs the ones
+// that do not respond. Any response below 500 counts as alive, since MCP endpoints often
+// reject plain requests without a session.
+func (c *Checker) Check(ctx context.Context, registry service.RegistryService) error {
+	var urls []string
+	err := registry.Export(nil, false, func(entry *model.ServerDetail) error {
+		if !entry.VersionDetail.IsLatest || entry.VersionDetail.Yanked || entry.Visibility.Effective() != model.VisibilityPublic {
+			return nil
+		}
+		for _, remote := range entry.Remotes {
+			urls = append(urls, remote.URL)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int64
+		slots  = make(chan struct{}, concurrency)
+	)
+	for _, url := range urls {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(url string) {
+			defer func() { <-slots; wg.Done() }()
+			if err := c.probe(ctx, url); err != nil && ctx.Err() == nil {
+				failed.Add(1)
+				log.Printf("Liveness: %s is unreachable: %v", url, err)
+			}
+		}(url)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	unreachable.Store(failed.Load())
+	log.Printf("Liveness: %d of %d remote endpoints unreachable", failed.Load(), len(urls))
+	return nil
+}
+
+func (c *Checker) probe(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "mcp-registry-liveness")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
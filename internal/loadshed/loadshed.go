@@ -0,0 +1,104 @@
+// Package loadshed detects when an instance is overloaded, by heap size or by the number of
+// requests in flight, so expensive low-priority requests can be turned away before they
+// starve health checks and detail lookups
+package loadshed
+
+import (
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"registry/internal/config"
+	regmetrics "registry/internal/metrics"
+)
+
+// Reasons an instance is overloaded
+const (
+	ReasonHeap     = "heap"
+	ReasonInFlight = "in_flight"
+)
+
+// heapMetric is the runtime metric holding the bytes of live and not yet swept heap objects
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// heapSampleInterval bounds how often the heap size is read
+const heapSampleInterval = 100 * time.Millisecond
+
+// shedRequests counts requests rejected because the instance was overloaded
+var shedRequests = regmetrics.NewCounterVec(
+	"mcp_registry_shed_requests_total",
+	"Number of low-priority requests rejected because the instance was overloaded, by reason.",
+	"reason",
+)
+
+// Shedder tracks the requests in flight and the heap size against the thresholds of
+// MCP_REGISTRY_SHED_MAX_IN_FLIGHT and MCP_REGISTRY_SHED_MAX_HEAP_BYTES. A threshold of zero
+// is never exceeded.
+type Shedder struct {
+	maxInFlight  int64
+	maxHeapBytes uint64
+
+	inFlight atomic.Int64
+
+	mu        sync.Mutex
+	heapBytes uint64
+	sampledAt time.Time
+}
+
+// New creates a shedder with the thresholds of cfg
+func New(cfg *config.Config) *Shedder {
+	return &Shedder{
+		maxInFlight:  int64(max(cfg.ShedMaxInFlight, 0)),
+		maxHeapBytes: uint64(max(cfg.ShedMaxHeapBytes, 0)),
+	}
+}
+
+// Enabled reports whether any threshold is set
+func (s *Shedder) Enabled() bool {
+	return s.maxInFlight > 0 || s.maxHeapBytes > 0
+}
+
+// Begin counts a request as in flight until the returned function is called
+func (s *Shedder) Begin() func() {
+	s.inFlight.Add(1)
+	return func() { s.inFlight.Add(-1) }
+}
+
+// InFlight returns the number of requests in flight
+func (s *Shedder) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// Overloaded reports whether a threshold is exceeded, and which one. Requests are counted
+// as in flight before they are checked, so shedding starts once more than the maximum are
+// in flight.
+func (s *Shedder) Overloaded() (string, bool) {
+	if s.maxInFlight > 0 && s.inFlight.Load() > s.maxInFlight {
+		return ReasonInFlight, true
+	}
+	if s.maxHeapBytes > 0 && s.heap() > s.maxHeapBytes {
+		return ReasonHeap, true
+	}
+	return "", false
+}
+
+// Shed records that a request was rejected for reason
+func (s *Shedder) Shed(reason string) {
+	shedRequests.Inc(reason)
+}
+
+// heap returns the heap size, read at most every heapSampleInterval
+func (s *Shedder) heap() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.sampledAt) >= heapSampleInterval {
+		sample := []metrics.Sample{{Name: heapMetric}}
+		metrics.Read(sample)
+		if sample[0].Value.Kind() == metrics.KindUint64 {
+			s.heapBytes = sample[0].Value.Uint64()
+		}
+		s.sampledAt = time.Now()
+	}
+	return s.heapBytes
+}
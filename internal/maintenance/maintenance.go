@@ -0,0 +1,106 @@
+// Package maintenance lets operators take the registry into maintenance mode through the
+// admin API, for example during migrations or restores, instead of stopping the process
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"registry/internal/api/envelope"
+	"registry/internal/config"
+)
+
+// DefaultMessage is shown when maintenance is enabled without a message
+const DefaultMessage = "The registry is undergoing maintenance"
+
+// retryAfterSeconds is the Retry-After hint sent with maintenance responses
+const retryAfterSeconds = 60
+
+// Status is the maintenance state of the registry
+type Status struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+	// AllowReads keeps GET and HEAD requests working during maintenance
+	AllowReads bool       `json:"allow_reads"`
+	Since      *time.Time `json:"since,omitempty"`
+}
+
+// Banner is the body of responses rejected during maintenance
+type Banner struct {
+	Maintenance bool       `json:"maintenance"`
+	Message     string     `json:"message"`
+	Since       *time.Time `json:"since,omitempty"`
+}
+
+// Mode holds the maintenance state of this process
+type Mode struct {
+	mu     sync.RWMutex
+	status Status
+}
+
+// New creates the maintenance state for cfg, enabled at startup when
+// MCP_REGISTRY_MAINTENANCE_MODE is set
+func New(cfg *config.Config) *Mode {
+	mode := &Mode{}
+	if cfg.MaintenanceMode {
+		mode.Enable(cfg.MaintenanceMessage, cfg.MaintenanceAllowReads)
+	}
+	return mode
+}
+
+// Enable turns maintenance on with message, optionally keeping reads available
+func (m *Mode) Enable(message string, allowReads bool) Status {
+	if message == "" {
+		message = DefaultMessage
+	}
+	now := time.Now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = Status{Enabled: true, Message: message, AllowReads: allowReads, Since: &now}
+	return m.status
+}
+
+// Disable turns maintenance off
+func (m *Mode) Disable() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = Status{}
+	return m.status
+}
+
+// Status returns the current maintenance state
+func (m *Mode) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Guard returns a handler answering 503 with a JSON banner while maintenance is on. Reads
+// pass through when the maintenance allows them.
+func (m *Mode) Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := m.Status()
+		if !status.Enabled || r.Method == http.MethodOptions ||
+			(status.AllowReads && (r.Method == http.MethodGet || r.Method == http.MethodHead)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body interface{} = Banner{Maintenance: true, Message: status.Message, Since: status.Since}
+		if meta, ok := envelope.MetaFromContext(r.Context()); ok {
+			body = envelope.Envelope{
+				Meta:   meta,
+				Errors: []envelope.Error{{Status: http.StatusServiceUnavailable, Message: status.Message}},
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
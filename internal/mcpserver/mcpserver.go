@@ -0,0 +1,354 @@
+// Package mcpserver exposes the registry itself as an MCP server, so agents can search the
+// catalog and fetch install snippets through MCP tools instead of REST calls. Messages are
+// exchanged over stdio or the streamable HTTP transport.
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/install"
+	"registry/internal/model"
+	"registry/internal/query"
+	"registry/internal/service"
+)
+
+// ProtocolVersions are the MCP revisions the server speaks, newest first
+var ProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// ServerName is reported to clients during initialization
+const ServerName = "mcp-registry"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// maxMessageBytes bounds a single message read from stdin or an HTTP body
+const maxMessageBytes = 1 << 20
+
+// request is a JSON-RPC request or notification; notifications have no ID
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers MCP requests from the registry's public catalog
+type Server struct {
+	registry service.RegistryService
+	cfg      *config.Config
+}
+
+// New creates an MCP server reading from registry
+func New(registry service.RegistryService, cfg *config.Config) *Server {
+	return &Server{registry: registry, cfg: cfg}
+}
+
+// ServeStdio answers newline delimited messages read from in on out until in is closed
+// or ctx is cancelled
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if reply := s.Handle(ctx, line); reply != nil {
+			if _, err := out.Write(append(reply, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// ServeHTTP implements the streamable HTTP transport without server initiated streams:
+// each POSTed message or batch is answered with a JSON body, and notifications with 202
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageBytes+1))
+	if err != nil {
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxMessageBytes {
+		http.Error(w, "Message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	reply := s.Handle(r.Context(), body)
+	if reply == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(reply)
+}
+
+// Handle answers one message, which may be a batch, returning nil when nothing is owed
+// to the client because the message only held notifications
+func (s *Server) Handle(ctx context.Context, message []byte) []byte {
+	message = bytes.TrimSpace(message)
+	if len(message) > 0 && message[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(message, &batch); err != nil {
+			return encode(errorResponse(nil, codeParseError, "Parse error"))
+		}
+		if len(batch) == 0 {
+			return encode(errorResponse(nil, codeInvalidRequest, "Empty batch"))
+		}
+		var replies []*response
+		for _, item := range batch {
+			if reply := s.handleOne(ctx, item); reply != nil {
+				replies = append(replies, reply)
+			}
+		}
+		if len(replies) == 0 {
+			return nil
+		}
+		return encode(replies)
+	}
+
+	if reply := s.handleOne(ctx, message); reply != nil {
+		return encode(reply)
+	}
+	return nil
+}
+
+// handleOne answers a single request, returning nil for notifications
+func (s *Server) handleOne(ctx context.Context, message []byte) *response {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return errorResponse(nil, codeParseError, "Parse error")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "Invalid request")
+	}
+
+	result, rpcErr := s.dispatch(ctx, req)
+	if req.ID == nil {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// dispatch runs the method of req
+func (s *Server) dispatch(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := ProtocolVersions[0]
+		if slices.Contains(ProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": ServerName, "version": s.cfg.Version},
+			"instructions":    "Search the MCP server registry with search_servers, then use get_server and get_install_snippet with the returned IDs.",
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "Invalid params"}
+		}
+		return s.callTool(ctx, params.Name, params.Arguments)
+	default:
+		// Notifications such as notifications/initialized need no handling
+		if strings.HasPrefix(req.Method, "notifications/") {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "Method not found: " + req.Method}
+	}
+}
+
+// callTool runs the named tool. Failures of the tool itself are reported in the result
+// with isError set, as MCP expects, so the model can see and react to them.
+func (s *Server) callTool(_ context.Context, name string, arguments json.RawMessage) (interface{}, *rpcError) {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	var (
+		value interface{}
+		err   error
+	)
+	switch name {
+	case "search_servers":
+		value, err = s.searchServers(arguments)
+	case "get_server":
+		value, err = s.getServer(arguments)
+	case "get_install_snippet":
+		value, err = s.getInstallSnippet(arguments)
+	default:
+		return nil, &rpcError{Code: codeInvalidParams, Message: "Unknown tool: " + name}
+	}
+	if err != nil {
+		return toolResult(err.Error(), true), nil
+	}
+
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: "Failed to encode result"}
+	}
+	return toolResult(string(text), false), nil
+}
+
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// searchServers lists public servers matching a query in the q language of GET /v0/servers
+func (s *Server) searchServers(arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Query     string `json:"query"`
+		Transport string `json:"transport"`
+		Limit     int    `json:"limit"`
+		Cursor    string `json:"cursor"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.Limit <= 0 || args.Limit > 100 {
+		args.Limit = 20
+	}
+
+	filter := map[string]interface{}{"yanked": false}
+	if args.Query != "" {
+		parsed, err := query.Parse(args.Query, query.Options{Match: database.MatchSubstring, FoldAccents: s.cfg.SearchFoldAccents})
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+		query.Merge(filter, parsed)
+	}
+	if args.Transport != "" {
+		switch model.TransportType(args.Transport) {
+		case model.TransportStdio, model.TransportSSE, model.TransportStreamableHTTP:
+			filter["transport"] = args.Transport
+		default:
+			return nil, fmt.Errorf("invalid transport %q: expected stdio, sse or streamable-http", args.Transport)
+		}
+	}
+
+	servers, next, err := s.registry.List(filter, args.Cursor, args.Limit, database.SortByName)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return nil, errors.New("invalid cursor: pass the next_cursor of the previous search unchanged")
+		}
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	if servers == nil {
+		servers = []model.Server{}
+	}
+	return map[string]interface{}{"servers": servers, "next_cursor": next}, nil
+}
+
+// getServer returns the full public details of a server version
+func (s *Server) getServer(arguments json.RawMessage) (interface{}, error) {
+	return s.lookup(arguments)
+}
+
+// getInstallSnippet renders the client configuration of a server version
+func (s *Server) getInstallSnippet(arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Client string `json:"client"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	serverDetail, err := s.lookup(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	client := install.Client(args.Client)
+	if client == "" {
+		client = install.ClientGeneric
+	}
+	return install.Render(serverDetail, client)
+}
+
+// lookup returns the server version named by the id argument, hiding private versions
+func (s *Server) lookup(arguments json.RawMessage) (*model.ServerDetail, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.ID == "" {
+		return nil, errors.New("id is required")
+	}
+
+	serverDetail, err := s.registry.GetByID(args.ID)
+	if errors.Is(err, database.ErrNotFound) || (err == nil && serverDetail.Visibility.Effective() == model.VisibilityPrivate) {
+		return nil, fmt.Errorf("server %s not found", args.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup failed: %w", err)
+	}
+	return serverDetail, nil
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+func encode(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(nil, codeInternalError, "Failed to encode response"))
+	}
+	return data
+}
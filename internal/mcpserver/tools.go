@@ -0,0 +1,62 @@
+package mcpserver
+
+// tool describes an MCP tool in tools/list
+type tool struct {
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations map[string]bool        `json:"annotations"`
+}
+
+// readOnly marks tools that only read the catalog
+var readOnly = map[string]bool{"readOnlyHint": true, "openWorldHint": false}
+
+// tools are the tools the registry offers
+var tools = []tool{
+	{
+		Name:  "search_servers",
+		Title: "Search MCP servers",
+		Description: "Search the registry for MCP servers. The query matches server names and supports " +
+			"qualifiers such as author:<owner>, transport:<type> and os:<os>. Results are paged; pass " +
+			"next_cursor back as cursor to get the next page.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query":     map[string]string{"type": "string", "description": "Search query, for example \"github author:modelcontextprotocol\""},
+				"transport": map[string]interface{}{"type": "string", "enum": []string{"stdio", "sse", "streamable-http"}, "description": "Only list servers usable over this transport"},
+				"limit":     map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 100, "description": "Page size, 20 by default"},
+				"cursor":    map[string]string{"type": "string", "description": "next_cursor of the previous page"},
+			},
+		},
+		Annotations: readOnly,
+	},
+	{
+		Name:        "get_server",
+		Title:       "Get MCP server details",
+		Description: "Get the full details of a server version by ID: packages, remotes, transports, README and version information.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]string{"type": "string", "description": "Server version ID from search_servers"},
+			},
+			"required": []string{"id"},
+		},
+		Annotations: readOnly,
+	},
+	{
+		Name:  "get_install_snippet",
+		Title: "Get MCP server install snippet",
+		Description: "Render the configuration that adds a server version to an MCP client, with placeholders " +
+			"for required inputs such as API keys.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id":     map[string]string{"type": "string", "description": "Server version ID from search_servers"},
+				"client": map[string]interface{}{"type": "string", "enum": []string{"generic", "claude-desktop", "cursor"}, "description": "Client to render for, generic by default"},
+			},
+			"required": []string{"id"},
+		},
+		Annotations: readOnly,
+	},
+}
@@ -0,0 +1,96 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// contentTypeSuffix is appended to an object's file name to record its content type
+const contentTypeSuffix = ".content-type"
+
+// DiskStore keeps assets as files below a directory
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore creates a store rooted at dir, creating the directory if needed
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating media directory: %w", err)
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+// path maps a key to a file below the store directory, rejecting keys that would escape it
+func (s *DiskStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) || strings.HasSuffix(key, contentTypeSuffix) {
+		return "", fmt.Errorf("%w: invalid key %q", ErrInvalidMedia, key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object atomically so readers never observe a partial file
+func (s *DiskStore) Put(_ context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path+contentTypeSuffix, []byte(contentType)); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// Get reads the object and its recorded content type
+func (s *DiskStore) Get(_ context.Context, key string) (*Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := os.ReadFile(path + contentTypeSuffix)
+	if err != nil {
+		contentType = []byte(http.DetectContentType(data))
+	}
+
+	return &Object{Data: data, ContentType: string(contentType), ModTime: info.ModTime()}, nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
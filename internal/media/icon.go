@@ -0,0 +1,138 @@
+package media
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Accepted icon content types
+const (
+	ContentTypePNG = "image/png"
+	ContentTypeSVG = "image/svg+xml"
+)
+
+const (
+	// MaxIconBytes is the largest accepted icon upload
+	MaxIconBytes = 256 << 10
+	// minIconDimension and maxIconDimension bound the pixel size of raster icons
+	minIconDimension = 16
+	maxIconDimension = 1024
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// forbiddenSVGElements can execute script or embed foreign content
+var forbiddenSVGElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+}
+
+// ValidateIcon checks an icon upload against the declared content type and returns the
+// normalized content type. PNGs must be within the dimension bounds;
+// SVGs must be well formed and free of scripts, event handlers and external references.
+func ValidateIcon(data []byte, declaredType string) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: icon is empty", ErrInvalidMedia)
+	}
+	if len(data) > MaxIconBytes {
+		return "", fmt.Errorf("%w: icon exceeds %d bytes", ErrInvalidMedia, MaxIconBytes)
+	}
+
+	mediaType, _, _ := strings.Cut(declaredType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	var err error
+	switch mediaType {
+	case ContentTypePNG:
+		err = validatePNG(data)
+	case ContentTypeSVG:
+		err = validateSVG(data)
+	default:
+		err = fmt.Errorf("%w: content type must be %s or %s", ErrInvalidMedia, ContentTypePNG, ContentTypeSVG)
+	}
+	if err != nil {
+		return "", err
+	}
+	return mediaType, nil
+}
+
+// validatePNG checks the PNG signature and image dimensions
+func validatePNG(data []byte) error {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return fmt.Errorf("%w: body is not a PNG image", ErrInvalidMedia)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: decoding PNG: %v", ErrInvalidMedia, err)
+	}
+	if cfg.Width < minIconDimension || cfg.Height < minIconDimension ||
+		cfg.Width > maxIconDimension || cfg.Height > maxIconDimension {
+		return fmt.Errorf("%w: icon is %dx%d; dimensions must be between %d and %d pixels",
+			ErrInvalidMedia, cfg.Width, cfg.Height, minIconDimension, maxIconDimension)
+	}
+	return nil
+}
+
+// validateSVG walks the document and rejects active or external content
+func validateSVG(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	sawRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: parsing SVG: %v", ErrInvalidMedia, err)
+		}
+
+		switch t := token.(type) {
+		case xml.Directive:
+			// DOCTYPE declarations can define entities
+			return fmt.Errorf("%w: SVG must not contain DOCTYPE or entity declarations", ErrInvalidMedia)
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if !sawRoot {
+				if name != "svg" {
+					return fmt.Errorf("%w: root element must be <svg>", ErrInvalidMedia)
+				}
+				sawRoot = true
+			}
+			if forbiddenSVGElements[name] {
+				return fmt.Errorf("%w: SVG must not contain <%s> elements", ErrInvalidMedia, t.Name.Local)
+			}
+			for _, attr := range t.Attr {
+				if err := checkSVGAttribute(attr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !sawRoot {
+		return fmt.Errorf("%w: body is not an SVG image", ErrInvalidMedia)
+	}
+	return nil
+}
+
+// checkSVGAttribute rejects event handlers and references to anything but fragments in the same document
+func checkSVGAttribute(attr xml.Attr) error {
+	name := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(name, "on") {
+		return fmt.Errorf("%w: SVG must not contain event handler attributes", ErrInvalidMedia)
+	}
+	if name == "href" && !strings.HasPrefix(strings.TrimSpace(attr.Value), "#") {
+		return fmt.Errorf("%w: SVG must not reference external resources", ErrInvalidMedia)
+	}
+	return nil
+}
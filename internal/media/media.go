@@ -0,0 +1,62 @@
+// Package media stores and validates binary assets, such as server icons, uploaded by publishers
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"registry/internal/config"
+)
+
+var (
+	// ErrNotFound is returned when no object is stored under a key
+	ErrNotFound = errors.New("media not found")
+	// ErrInvalidMedia is returned when an upload fails validation
+	ErrInvalidMedia = errors.New("invalid media")
+)
+
+// Storage backends selectable with MCP_REGISTRY_MEDIA_STORAGE
+const (
+	StorageDisk = "disk"
+	StorageS3   = "s3"
+)
+
+// Object is a stored asset
+type Object struct {
+	Data        []byte
+	ContentType string
+	ModTime     time.Time
+}
+
+// Store persists assets under opaque keys
+type Store interface {
+	// Put stores data under key, replacing any previous object
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the object stored under key or ErrNotFound
+	Get(ctx context.Context, key string) (*Object, error)
+}
+
+// NewStore creates the store selected by the configuration
+func NewStore(cfg *config.Config) (Store, error) {
+	switch cfg.MediaStorage {
+	case StorageDisk:
+		return NewDiskStore(cfg.MediaDir)
+	case StorageS3:
+		return NewS3Store(S3Config{
+			Endpoint:        cfg.MediaS3Endpoint,
+			Region:          cfg.MediaS3Region,
+			Bucket:          cfg.MediaS3Bucket,
+			AccessKeyID:     cfg.MediaS3AccessKeyID,
+			SecretAccessKey: cfg.MediaS3SecretAccessKey,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported media storage %q; supported: %s, %s", cfg.MediaStorage, StorageDisk, StorageS3)
+	}
+}
+
+// IconKey is the storage key of a server's icon
+func IconKey(serverID string) string {
+	return "icons/" + serverID
+}
@@ -0,0 +1,235 @@
+// Package migrate copies a registry from one database to another and verifies the copy
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// Report counts the records written to the target, by kind
+type Report struct {
+	Versions      int `json:"versions"`
+	SavedSearches int `json:"saved_searches"`
+	Drafts        int `json:"drafts"`
+	PublishTokens int `json:"publish_tokens"`
+	Reports       int `json:"reports"`
+	Featured      int `json:"featured"`
+	Aliases       int `json:"aliases"`
+	ArchivedOrgs  int `json:"archived_orgs"`
+	RevokedTokens int `json:"revoked_tokens"`
+	State         int `json:"state"`
+}
+
+// Copy writes every record of from into to. Versions keep their IDs, release dates and
+// yanked state, and replace versions of the same name and version in to. Copying is
+// idempotent, so it can be run again to catch up with writes made in the meantime. The
+// change log, leases and the saved search position in the change log are not copied: the
+// target records its own changes as versions arrive, so mirrors following the change feed
+// must bootstrap again after a cutover.
+func Copy(ctx context.Context, from, to database.Database) (*Report, error) {
+	report := &Report{}
+
+	err := from.Iterate(ctx, map[string]interface{}{}, func(entry *model.ServerDetail) error {
+		if err := to.Replicate(ctx, entry, database.ConflictSourceWins); err != nil {
+			return fmt.Errorf("error copying version %s: %w", entry.ID, err)
+		}
+		report.Versions++
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	steps := []struct {
+		name string
+		copy func() (int, error)
+	}{
+		{"saved searches", func() (int, error) { return copySavedSearches(ctx, from, to) }},
+		{"drafts", func() (int, error) { return copyDrafts(ctx, from, to) }},
+		{"publish tokens", func() (int, error) { return copyPublishTokens(ctx, from, to) }},
+		{"abuse reports", func() (int, error) { return copyReports(ctx, from, to) }},
+		{"featured servers", func() (int, error) { return copyFeatured(ctx, from, to) }},
+		{"aliases", func() (int, error) { return copyAliases(ctx, from, to) }},
+		{"archived organizations", func() (int, error) { return copyArchivedOrgs(ctx, from, to) }},
+		{"revoked tokens", func() (int, error) { return copyRevokedTokens(ctx, from, to) }},
+		{"state", func() (int, error) { return copyState(ctx, from, to) }},
+	}
+	counts := []*int{
+		&report.SavedSearches, &report.Drafts, &report.PublishTokens, &report.Reports,
+		&report.Featured, &report.Aliases, &report.ArchivedOrgs, &report.RevokedTokens, &report.State,
+	}
+	for i, step := range steps {
+		n, err := step.copy()
+		*counts[i] = n
+		if err != nil {
+			return report, fmt.Errorf("error copying %s: %w", step.name, err)
+		}
+	}
+	return report, nil
+}
+
+// Saved searches and publish tokens cannot be updated, so ones already copied are skipped
+func copySavedSearches(ctx context.Context, from, to database.Database) (int, error) {
+	searches, err := from.ListSavedSearches(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	existing, err := to.ListSavedSearches(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	copied := ids(existing, func(s *model.SavedSearch) string { return s.ID })
+	n := 0
+	for _, search := range searches {
+		if copied[search.ID] {
+			continue
+		}
+		if err := to.CreateSavedSearch(ctx, search); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func copyPublishTokens(ctx context.Context, from, to database.Database) (int, error) {
+	tokens, err := from.ListPublishTokens(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	existing, err := to.ListPublishTokens(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	copied := ids(existing, func(t *model.PublishToken) string { return t.ID })
+	n := 0
+	for _, token := range tokens {
+		if copied[token.ID] {
+			continue
+		}
+		if err := to.CreatePublishToken(ctx, token); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Drafts and reports already copied are updated, since they change after creation
+func copyDrafts(ctx context.Context, from, to database.Database) (int, error) {
+	drafts, err := from.ListDrafts(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	for i, draft := range drafts {
+		err := to.CreateDraft(ctx, draft)
+		if errors.Is(err, database.ErrAlreadyExists) {
+			err = to.UpdateDraft(ctx, draft)
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(drafts), nil
+}
+
+func copyReports(ctx context.Context, from, to database.Database) (int, error) {
+	reports, err := from.ListReports(ctx, "", math.MaxInt32)
+	if err != nil {
+		return 0, err
+	}
+	for i, report := range reports {
+		err := to.CreateReport(ctx, report)
+		if errors.Is(err, database.ErrAlreadyExists) {
+			err = to.UpdateReport(ctx, report)
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(reports), nil
+}
+
+// Featured servers, aliases, archived organizations, revocations and state are written in place
+func copyFeatured(ctx context.Context, from, to database.Database) (int, error) {
+	featured, err := from.ListFeatured(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, f := range featured {
+		if err := to.SetFeatured(ctx, f); err != nil {
+			return i, err
+		}
+	}
+	return len(featured), nil
+}
+
+func copyAliases(ctx context.Context, from, to database.Database) (int, error) {
+	aliases, err := from.ListAliases(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, alias := range aliases {
+		if err := to.SetAlias(ctx, alias); err != nil {
+			return i, err
+		}
+	}
+	return len(aliases), nil
+}
+
+func copyArchivedOrgs(ctx context.Context, from, to database.Database) (int, error) {
+	orgs, err := from.ListArchivedOrgs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, org := range orgs {
+		if err := to.ArchiveOrg(ctx, org); err != nil {
+			return i, err
+		}
+	}
+	return len(orgs), nil
+}
+
+func copyRevokedTokens(ctx context.Context, from, to database.Database) (int, error) {
+	revoked, err := from.ListRevokedTokens(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, r := range revoked {
+		if err := to.RevokeToken(ctx, r); err != nil {
+			return i, err
+		}
+	}
+	return len(revoked), nil
+}
+
+func copyState(ctx context.Context, from, to database.Database) (int, error) {
+	state, err := from.ListState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for key, value := range state {
+		if key == database.SavedSearchRevisionKey {
+			continue
+		}
+		if err := to.SaveState(ctx, key, value); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func ids[T any](records []T, id func(T) string) map[string]bool {
+	set := make(map[string]bool, len(records))
+	for _, record := range records {
+		set[id(record)] = true
+	}
+	return set
+}
@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// maxMismatches bounds the differences listed by Verify
+const maxMismatches = 100
+
+// Verification lists records of the source that are missing from the target or differ in it
+type Verification struct {
+	Checked    int      `json:"checked"`
+	Mismatches []string `json:"mismatches"`
+	// Truncated is set when more mismatches were found than are listed
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// OK reports whether the target holds every record of the source
+func (v *Verification) OK() bool {
+	return len(v.Mismatches) == 0
+}
+
+func (v *Verification) mismatch(format string, args ...interface{}) {
+	if len(v.Mismatches) == maxMismatches {
+		v.Truncated = true
+		return
+	}
+	v.Mismatches = append(v.Mismatches, fmt.Sprintf(format, args...))
+}
+
+// Verify checks that every record of from is present in to. Versions must match in
+// manifest digest, yanked state and visibility; other records must exist under the same ID.
+// Records only present in to are not reported.
+func Verify(ctx context.Context, from, to database.Database) (*Verification, error) {
+	v := &Verification{Mismatches: []string{}}
+
+	err := from.Iterate(ctx, map[string]interface{}{}, func(entry *model.ServerDetail) error {
+		v.Checked++
+		copied, err := to.GetByID(ctx, entry.ID)
+		switch {
+		case errors.Is(err, database.ErrNotFound):
+			v.mismatch("version %s (%s %s) is missing", entry.ID, entry.Name, entry.VersionDetail.Version)
+			return nil
+		case err != nil:
+			return err
+		}
+		digest, _, err := entry.Manifest()
+		if err != nil {
+			return err
+		}
+		switch {
+		case copied.Digest != digest:
+			v.mismatch("version %s has digest %s, expected %s", entry.ID, copied.Digest, digest)
+		case copied.VersionDetail.Yanked != entry.VersionDetail.Yanked:
+			v.mismatch("version %s has yanked %t, expected %t", entry.ID, copied.VersionDetail.Yanked, entry.VersionDetail.Yanked)
+		case copied.Visibility.Effective() != entry.Visibility.Effective():
+			v.mismatch("version %s is %s, expected %s", entry.ID, copied.Visibility.Effective(), entry.Visibility.Effective())
+		}
+		return nil
+	})
+	if err != nil {
+		return v, err
+	}
+
+	checks := []struct {
+		kind string
+		list func(database.Database) ([]string, error)
+	}{
+		{"saved search", func(db database.Database) ([]string, error) {
+			return list(db.ListSavedSearches(ctx, ""))(func(s *model.SavedSearch) string { return s.ID })
+		}},
+		{"draft", func(db database.Database) ([]string, error) {
+			return list(db.ListDrafts(ctx, ""))(func(d *model.Draft) string { return d.ID })
+		}},
+		{"publish token", func(db database.Database) ([]string, error) {
+			return list(db.ListPublishTokens(ctx, ""))(func(t *model.PublishToken) string { return t.ID })
+		}},
+		{"abuse report", func(db database.Database) ([]string, error) {
+			return list(db.ListReports(ctx, "", math.MaxInt32))(func(r *model.AbuseReport) string { return r.ID })
+		}},
+		{"featured server", func(db database.Database) ([]string, error) {
+			return list(db.ListFeatured(ctx))(func(f *model.FeaturedServer) string { return f.Name })
+		}},
+		{"alias", func(db database.Database) ([]string, error) {
+			return list(db.ListAliases(ctx))(func(a *model.ServerAlias) string { return a.Slug + " " + a.Target })
+		}},
+		{"archived organization", func(db database.Database) ([]string, error) {
+			return list(db.ListArchivedOrgs(ctx))(func(o *model.ArchivedOrg) string { return o.Org })
+		}},
+		{"revoked token", func(db database.Database) ([]string, error) {
+			return list(db.ListRevokedTokens(ctx))(func(r *model.RevokedToken) string { return r.Digest })
+		}},
+		{"state key", func(db database.Database) ([]string, error) {
+			state, err := db.ListState(ctx)
+			keys := make([]string, 0, len(state))
+			for key, value := range state {
+				if key == database.SavedSearchRevisionKey {
+					continue
+				}
+				keys = append(keys, key+"="+value)
+			}
+			return keys, err
+		}},
+	}
+	for _, check := range checks {
+		want, err := check.list(from)
+		if err != nil {
+			return v, err
+		}
+		got, err := check.list(to)
+		if err != nil {
+			return v, err
+		}
+		present := make(map[string]bool, len(got))
+		for _, id := range got {
+			present[id] = true
+		}
+		for _, id := range want {
+			v.Checked++
+			if !present[id] {
+				v.mismatch("%s %s is missing", check.kind, id)
+			}
+		}
+	}
+	return v, nil
+}
+
+// list adapts a listing result to return the IDs of its records
+func list[T any](records []T, err error) func(id func(T) string) ([]string, error) {
+	return func(id func(T) string) ([]string, error) {
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(records))
+		for i, record := range records {
+			ids[i] = id(record)
+		}
+		return ids, nil
+	}
+}
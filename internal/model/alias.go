@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ServerAlias keeps the former name of a renamed or moved server resolving. Looking up the
+// former name's slug redirects to the server's current name. Aliases are keyed by slug.
+type ServerAlias struct {
+	Slug      string    `json:"slug" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	Target    string    `json:"target" bson:"target"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
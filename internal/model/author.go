@@ -0,0 +1,26 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AuthorProfile summarizes the servers published from an author's repositories
+type AuthorProfile struct {
+	Author         string `json:"author"`
+	ServerCount    int    `json:"server_count"`
+	VersionCount   int    `json:"version_count"`
+	FirstPublished string `json:"first_published,omitempty"`
+	LastPublished  string `json:"last_published,omitempty"`
+}
+
+// ExtractAuthorFromRepoURL returns the owner segment of a repository URL such as
+// https://github.com/<author>/<repo>, or an empty string if the URL has no owner
+func ExtractAuthorFromRepoURL(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	owner, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	return owner
+}
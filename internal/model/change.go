@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// ChangeOp is the kind of modification recorded in the change log
+type ChangeOp string
+
+const (
+	// ChangeOpPublish records a newly published version
+	ChangeOpPublish ChangeOp = "publish"
+	// ChangeOpYank records a version being yanked
+	ChangeOpYank ChangeOp = "yank"
+	// ChangeOpUnyank records a yanked version being restored
+	ChangeOpUnyank ChangeOp = "unyank"
+)
+
+// ChangeEntityServer is the entity type of changes to server versions
+const ChangeEntityServer = "server"
+
+// Change is an entry of the ordered change log mirrors use to sync incrementally.
+// Revisions increase strictly with every change.
+type Change struct {
+	Revision  int64     `json:"revision" bson:"revision"`
+	Entity    string    `json:"entity" bson:"entity"`
+	Op        ChangeOp  `json:"op" bson:"op"`
+	ID        string    `json:"id" bson:"id"`
+	Name      string    `json:"name" bson:"name"`
+	Version   string    `json:"version" bson:"version"`
+	Digest    string    `json:"digest,omitempty" bson:"digest,omitempty"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+	// Visibility is the version's visibility when the change was recorded; only changes to
+	// public versions are served
+	Visibility Visibility `json:"-" bson:"visibility,omitempty"`
+}
+
+// NewServerChange describes op applied to a server version; the revision is assigned when it is recorded
+func NewServerChange(op ChangeOp, serverDetail *ServerDetail) *Change {
+	return &Change{
+		Entity:     ChangeEntityServer,
+		Op:         op,
+		ID:         serverDetail.ID,
+		Name:       serverDetail.Name,
+		Version:    serverDetail.VersionDetail.Version,
+		Digest:     serverDetail.Digest,
+		Timestamp:  time.Now().UTC(),
+		Visibility: serverDetail.Visibility,
+	}
+}
@@ -0,0 +1,197 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+
+	"registry/internal/semver"
+)
+
+// DiffChange is how an item differs between two versions
+type DiffChange string
+
+const (
+	DiffAdded      DiffChange = "added"
+	DiffRemoved    DiffChange = "removed"
+	DiffChanged    DiffChange = "changed"
+	DiffUpgraded   DiffChange = "upgraded"
+	DiffDowngraded DiffChange = "downgraded"
+)
+
+// FieldChange reports a top level field whose value differs. The values of the readme and
+// changelog are left out; only the fact that they changed is reported.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// PackageChange reports a package added, removed, bumped or reconfigured
+type PackageChange struct {
+	RegistryName string     `json:"registry_name"`
+	Name         string     `json:"name"`
+	Change       DiffChange `json:"change"`
+	From         string     `json:"from,omitempty"`
+	To           string     `json:"to,omitempty"`
+}
+
+// CapabilityChange reports a transport or remote endpoint added, removed or reconfigured.
+// Kind is "transport" or "remote"; Type is the transport type.
+type CapabilityChange struct {
+	Kind   string     `json:"kind"`
+	Type   string     `json:"type"`
+	URL    string     `json:"url,omitempty"`
+	Change DiffChange `json:"change"`
+}
+
+// VersionDiff lists what changed between two versions of a server
+type VersionDiff struct {
+	Name         string             `json:"name"`
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	Fields       []FieldChange      `json:"fields"`
+	Packages     []PackageChange    `json:"packages"`
+	Capabilities []CapabilityChange `json:"capabilities"`
+}
+
+// Diff compares two versions of the same server
+func Diff(from, to *ServerDetail) VersionDiff {
+	diff := VersionDiff{
+		Name:         to.Name,
+		From:         from.VersionDetail.Version,
+		To:           to.VersionDetail.Version,
+		Fields:       []FieldChange{},
+		Packages:     []PackageChange{},
+		Capabilities: []CapabilityChange{},
+	}
+
+	field := func(name string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			diff.Fields = append(diff.Fields, FieldChange{Field: name, From: a, To: b})
+		}
+	}
+	field("description", from.Description, to.Description)
+	field("repository", from.Repository, to.Repository)
+	field("visibility", from.Visibility.Effective(), to.Visibility.Effective())
+	field("yanked", from.VersionDetail.Yanked, to.VersionDetail.Yanked)
+	if from.Readme != to.Readme {
+		diff.Fields = append(diff.Fields, FieldChange{Field: "readme"})
+	}
+	if from.Changelog != to.Changelog {
+		diff.Fields = append(diff.Fields, FieldChange{Field: "changelog"})
+	}
+
+	diff.Packages = diffPackages(from.Packages, to.Packages)
+	diff.Capabilities = append(diffTransports(from.Transports, to.Transports), diffRemotes(from.Remotes, to.Remotes)...)
+	return diff
+}
+
+// diffPackages matches packages by registry and name
+func diffPackages(from, to []Package) []PackageChange {
+	type key struct{ registry, name string }
+	old := make(map[key]Package, len(from))
+	for _, p := range from {
+		old[key{p.RegistryName, p.Name}] = p
+	}
+
+	changes := []PackageChange{}
+	seen := make(map[key]bool, len(to))
+	for _, p := range to {
+		k := key{p.RegistryName, p.Name}
+		seen[k] = true
+		before, ok := old[k]
+		switch {
+		case !ok:
+			changes = append(changes, PackageChange{RegistryName: p.RegistryName, Name: p.Name, Change: DiffAdded, To: p.Version})
+		case before.Version != p.Version:
+			changes = append(changes, PackageChange{
+				RegistryName: p.RegistryName, Name: p.Name, Change: versionChange(before.Version, p.Version),
+				From: before.Version, To: p.Version,
+			})
+		case !reflect.DeepEqual(before, p):
+			changes = append(changes, PackageChange{
+				RegistryName: p.RegistryName, Name: p.Name, Change: DiffChanged, From: before.Version, To: p.Version,
+			})
+		}
+	}
+	for _, p := range from {
+		if !seen[key{p.RegistryName, p.Name}] {
+			changes = append(changes, PackageChange{RegistryName: p.RegistryName, Name: p.Name, Change: DiffRemoved, From: p.Version})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].RegistryName != changes[j].RegistryName {
+			return changes[i].RegistryName < changes[j].RegistryName
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// versionChange classifies a package version bump, falling back to changed when either
+// version is not a semantic version
+func versionChange(from, to string) DiffChange {
+	a, errA := semver.Parse(from)
+	b, errB := semver.Parse(to)
+	if errA != nil || errB != nil {
+		return DiffChanged
+	}
+	if b.Compare(a) < 0 {
+		return DiffDowngraded
+	}
+	return DiffUpgraded
+}
+
+// diffTransports matches transports by type
+func diffTransports(from, to []Transport) []CapabilityChange {
+	old := make(map[TransportType]Transport, len(from))
+	for _, t := range from {
+		old[t.Type] = t
+	}
+	changes := []CapabilityChange{}
+	seen := make(map[TransportType]bool, len(to))
+	for _, t := range to {
+		seen[t.Type] = true
+		before, ok := old[t.Type]
+		switch {
+		case !ok:
+			changes = append(changes, CapabilityChange{Kind: "transport", Type: string(t.Type), URL: t.URL, Change: DiffAdded})
+		case !reflect.DeepEqual(before, t):
+			changes = append(changes, CapabilityChange{Kind: "transport", Type: string(t.Type), URL: t.URL, Change: DiffChanged})
+		}
+	}
+	for _, t := range from {
+		if !seen[t.Type] {
+			changes = append(changes, CapabilityChange{Kind: "transport", Type: string(t.Type), URL: t.URL, Change: DiffRemoved})
+		}
+	}
+	return changes
+}
+
+// diffRemotes matches remotes by transport type and URL
+func diffRemotes(from, to []Remote) []CapabilityChange {
+	type key struct{ transport, url string }
+	old := make(map[key]Remote, len(from))
+	for _, r := range from {
+		old[key{r.TransportType, r.URL}] = r
+	}
+	changes := []CapabilityChange{}
+	seen := make(map[key]bool, len(to))
+	for _, r := range to {
+		k := key{r.TransportType, r.URL}
+		seen[k] = true
+		before, ok := old[k]
+		switch {
+		case !ok:
+			changes = append(changes, CapabilityChange{Kind: "remote", Type: r.TransportType, URL: r.URL, Change: DiffAdded})
+		case !reflect.DeepEqual(before, r):
+			changes = append(changes, CapabilityChange{Kind: "remote", Type: r.TransportType, URL: r.URL, Change: DiffChanged})
+		}
+	}
+	for _, r := range from {
+		if !seen[key{r.TransportType, r.URL}] {
+			changes = append(changes, CapabilityChange{Kind: "remote", Type: r.TransportType, URL: r.URL, Change: DiffRemoved})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Draft is a server version saved for review before it is published. Drafts never appear
+// in listings and are only visible to the server's publishers.
+type Draft struct {
+	ID        string       `json:"id" bson:"_id"`
+	Server    ServerDetail `json:"server" bson:"server"`
+	CreatedAt time.Time    `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" bson:"updated_at"`
+}
+
+// ValidationIssue is one problem that would prevent a server version from being published
+type ValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationReport lists every problem found when checking a server version for publishing
+type ValidationReport struct {
+	Valid  bool              `json:"valid"`
+	Issues []ValidationIssue `json:"issues"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// FeaturedServer marks a server as curated by the registry operators. Featured servers are
+// listed by descending weight, then by name.
+type FeaturedServer struct {
+	Name       string    `json:"name" bson:"_id"`
+	Weight     int       `json:"weight" bson:"weight"`
+	FeaturedAt time.Time `json:"featured_at" bson:"featured_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "github.com/google/uuid"
+
+// CanonicalID returns id in the lowercase hyphenated UUID form the registry stores, and
+// false when id is not a UUID in that layout. The braced, URN and unhyphenated forms that
+// uuid.Parse also accepts are rejected so every ID has a single spelling in URLs.
+func CanonicalID(id string) (string, bool) {
+	if len(id) != 36 {
+		return "", false
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
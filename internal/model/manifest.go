@@ -0,0 +1,40 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// DigestPrefix identifies the hash algorithm of a manifest digest
+const DigestPrefix = "sha256:"
+
+// Manifest returns the canonical, immutable JSON encoding of a published version and its
+// sha256 digest. Registry-managed state (the latest and yanked flags, the scan result and
+// the digest itself) is excluded, so identical publications produce the same digest on
+// every mirror.
+func (s ServerDetail) Manifest() (string, []byte, error) {
+	s.Digest = ""
+	s.VersionDetail.IsLatest = false
+	s.VersionDetail.Yanked = false
+	s.VersionDetail.YankedReason = ""
+	s.Scan = nil
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(data)
+	return DigestPrefix + hex.EncodeToString(sum[:]), data, nil
+}
+
+// IsValidDigest reports whether d is a well formed manifest digest
+func IsValidDigest(d string) bool {
+	hexPart, ok := strings.CutPrefix(d, DigestPrefix)
+	if !ok || len(hexPart) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hexPart)
+	return err == nil && strings.ToLower(hexPart) == hexPart
+}
@@ -19,9 +19,11 @@ type Authentication struct {
 
 // Repository represents a source code repository as defined in the spec
 type Repository struct {
-	URL    string `json:"url" bson:"url"`
-	Source string `json:"source" bson:"source"`
-	ID     string `json:"id" bson:"id"`
+	URL           string `json:"url" bson:"url"`
+	Source        string `json:"source" bson:"source"`
+	ID            string `json:"id" bson:"id"`
+	DefaultBranch string `json:"default_branch,omitempty" bson:"default_branch,omitempty"`
+	Stars         int    `json:"stars,omitempty" bson:"stars,omitempty"`
 }
 
 // ServerList represents the response for listing servers as defined in the spec
@@ -66,6 +68,38 @@ type VersionDetail struct {
 	Version     string `json:"version" bson:"version"`
 	ReleaseDate string `json:"release_date" bson:"release_date"`
 	IsLatest    bool   `json:"is_latest" bson:"is_latest"`
+	// Yanked versions stay resolvable by ID but are never latest and are hidden from listings
+	Yanked       bool   `json:"yanked,omitempty" bson:"yanked,omitempty"`
+	YankedReason string `json:"yanked_reason,omitempty" bson:"yanked_reason,omitempty"`
+}
+
+// Visibility controls where a server version can be seen
+type Visibility string
+
+const (
+	// VisibilityPublic versions appear in listings, searches, exports and the change feed
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted versions can only be fetched by ID
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPrivate versions can only be fetched by ID, by members of the owning organization
+	VisibilityPrivate Visibility = "private"
+)
+
+// IsValid reports whether v is a known visibility; empty means public
+func (v Visibility) IsValid() bool {
+	switch v {
+	case "", VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// Effective returns the visibility v stands for, mapping empty to public
+func (v Visibility) Effective() Visibility {
+	if v == "" {
+		return VisibilityPublic
+	}
+	return v
 }
 
 // Server represents a basic server information as defined in the spec
@@ -75,6 +109,15 @@ type Server struct {
 	Description   string        `json:"description" bson:"description"`
 	Repository    Repository    `json:"repository" bson:"repository"`
 	VersionDetail VersionDetail `json:"version_detail" bson:"version_detail"`
+	// RepositoryURL repeats Repository.URL for clients still reading the flat repository
+	// string of earlier releases. It is deprecated and derived from Repository on write.
+	RepositoryURL string `json:"repository_url,omitempty" bson:"repository_url,omitempty"`
+	// Visibility is omitted for public versions
+	Visibility Visibility `json:"visibility,omitempty" bson:"visibility,omitempty"`
+	// SearchName is the case and accent folded name matched by searches
+	SearchName string `json:"-" bson:"search_name,omitempty"`
+	// Slug is the URL-safe form of the name, resolved by GET /v0/servers/{slug}
+	Slug string `json:"-" bson:"slug,omitempty"`
 }
 
 // PublishRequest represents a request to publish a server to the registry
@@ -85,11 +128,53 @@ type PublishRequest struct {
 
 // ServerDetail represents detailed server information as defined in the spec
 type ServerDetail struct {
-	Server   `json:",inline" bson:",inline"`
-	Packages []Package `json:"packages,omitempty" bson:"packages,omitempty"`
-	Remotes  []Remote  `json:"remotes,omitempty" bson:"remotes,omitempty"`
+	Server     `json:",inline" bson:",inline"`
+	Packages   []Package   `json:"packages,omitempty" bson:"packages,omitempty"`
+	Remotes    []Remote    `json:"remotes,omitempty" bson:"remotes,omitempty"`
+	Transports []Transport `json:"transports,omitempty" bson:"transports,omitempty"`
+	Readme     string      `json:"readme,omitempty" bson:"readme,omitempty"`
+	Changelog  string      `json:"changelog,omitempty" bson:"changelog,omitempty"`
+	// Digest addresses the immutable manifest stored when this version was published
+	Digest string `json:"digest,omitempty" bson:"digest,omitempty"`
+	// Raw keeps the text fields as published when sanitization changed any of them
+	Raw *RawContent `json:"-" bson:"raw,omitempty"`
+	// Scan is the URL reputation scan run on publish, when scanners are configured
+	Scan *ScanResult `json:"scan,omitempty" bson:"scan,omitempty"`
 }
 
+// RawContent holds user supplied text fields as published, before active content was
+// stripped from them. Fields that sanitization left unchanged are empty.
+type RawContent struct {
+	Description string `bson:"description,omitempty"`
+	Readme      string `bson:"readme,omitempty"`
+	Changelog   string `bson:"changelog,omitempty"`
+}
+
+// Unsanitized returns a copy of the version with its text fields as they were published
+func (s *ServerDetail) Unsanitized() *ServerDetail {
+	if s.Raw == nil {
+		return s
+	}
+	raw := *s
+	if s.Raw.Description != "" {
+		raw.Description = s.Raw.Description
+	}
+	if s.Raw.Readme != "" {
+		raw.Readme = s.Raw.Readme
+	}
+	if s.Raw.Changelog != "" {
+		raw.Changelog = s.Raw.Changelog
+	}
+	return &raw
+}
+
+const (
+	// MaxReadmeBytes is the largest README accepted with a published version
+	MaxReadmeBytes = 64 << 10
+	// MaxChangelogBytes is the largest changelog accepted with a published version
+	MaxChangelogBytes = 16 << 10
+)
+
 // Remote represents a remote connection endpoint
 type Remote struct {
 	TransportType string  `json:"transport_type" bson:"transport_type"`
@@ -98,13 +183,15 @@ type Remote struct {
 }
 
 type Package struct {
-	RegistryName         string          `json:"registry_name" bson:"registry_name"`
-	Name                 string          `json:"name" bson:"name"`
-	Version              string          `json:"version" bson:"version"`
-	RunTimeHint          string          `json:"runtime_hint,omitempty" bson:"runtime_hint,omitempty"`
-	RuntimeArguments     []Argument      `json:"runtime_arguments,omitempty" bson:"runtime_arguments,omitempty"`
-	PackageArguments     []Argument      `json:"package_arguments,omitempty" bson:"package_arguments,omitempty"`
-	EnvironmentVariables []KeyValueInput `json:"environment_variables,omitempty" bson:"environment_variables,omitempty"`
+	RegistryName         string            `json:"registry_name" bson:"registry_name"`
+	Name                 string            `json:"name" bson:"name"`
+	Version              string            `json:"version" bson:"version"`
+	RunTimeHint          string            `json:"runtime_hint,omitempty" bson:"runtime_hint,omitempty"`
+	RuntimeArguments     []Argument        `json:"runtime_arguments,omitempty" bson:"runtime_arguments,omitempty"`
+	PackageArguments     []Argument        `json:"package_arguments,omitempty" bson:"package_arguments,omitempty"`
+	EnvironmentVariables []KeyValueInput   `json:"environment_variables,omitempty" bson:"environment_variables,omitempty"`
+	Platforms            []Platform        `json:"platforms,omitempty" bson:"platforms,omitempty"`
+	MinRuntimeVersions   map[string]string `json:"min_runtime_versions,omitempty" bson:"min_runtime_versions,omitempty"`
 }
 
 // RuntimeArgument defines a type that can be either a PositionalArgument or a NamedArgument
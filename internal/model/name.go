@@ -0,0 +1,52 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"registry/internal/textnorm"
+)
+
+// NormalizeName returns name trimmed and lowercased, with every run of whitespace and
+// underscores replaced by a single dash, so "  Filesystem_Server " becomes "filesystem-server"
+func NormalizeName(name string) string {
+	var b strings.Builder
+	separator := false
+	for _, r := range strings.TrimSpace(textnorm.NFC(name)) {
+		if unicode.IsSpace(r) || r == '_' {
+			separator = true
+			continue
+		}
+		if separator {
+			b.WriteByte('-')
+			separator = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// Slug returns the URL-safe form of name used in pretty URLs. Accents are stripped and
+// every run of characters other than ASCII letters and digits becomes a single dash, so
+// "io.github.example/Filesystem-Server" becomes "io-github-example-filesystem-server".
+func Slug(name string) string {
+	var b strings.Builder
+	separator := false
+	for _, r := range textnorm.Fold(name) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			separator = b.Len() > 0
+			continue
+		}
+		if separator {
+			b.WriteByte('-')
+			separator = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// IsSlug reports whether s could be a slug returned by Slug
+func IsSlug(s string) bool {
+	return s != "" && Slug(s) == s
+}
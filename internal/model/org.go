@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// ArchivedOrg marks an organization, the owner segment of its servers' repository URLs,
+// as archived. Its versions stay resolvable by ID but are hidden from listings, and no
+// new versions can be published under it.
+type ArchivedOrg struct {
+	// Org is stored lower case, as authors are matched case insensitively
+	Org        string    `json:"org" bson:"_id"`
+	Reason     string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	ArchivedAt time.Time `json:"archived_at" bson:"archived_at"`
+}
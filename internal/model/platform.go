@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Platform is an operating system and CPU architecture combination a package runs on.
+// An empty Arch means every architecture of the OS is supported.
+type Platform struct {
+	OS   string `json:"os" bson:"os"`
+	Arch string `json:"arch,omitempty" bson:"arch,omitempty"`
+}
+
+// knownOS and knownArch list the accepted platform values, using Go's GOOS/GOARCH naming
+var (
+	knownOS   = map[string]bool{"linux": true, "darwin": true, "windows": true}
+	knownArch = map[string]bool{"amd64": true, "arm64": true, "386": true, "arm": true}
+	// knownRuntimes lists the runtimes for which a minimum version may be declared
+	knownRuntimes = map[string]bool{"node": true, "python": true, "docker": true, "deno": true, "bun": true}
+	// minVersionPattern matches dotted numeric versions such as 18, 3.10 or 20.11.1
+	minVersionPattern = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+)
+
+// IsKnownOS reports whether os is an accepted platform operating system
+func IsKnownOS(os string) bool {
+	return knownOS[os]
+}
+
+// IsKnownArch reports whether arch is an accepted platform architecture
+func IsKnownArch(arch string) bool {
+	return knownArch[arch]
+}
+
+// ValidateCompatibility checks the platform and runtime requirements declared by the package
+func (p Package) ValidateCompatibility() error {
+	for _, platform := range p.Platforms {
+		if !knownOS[platform.OS] {
+			return fmt.Errorf("unsupported os %q", platform.OS)
+		}
+		if platform.Arch != "" && !knownArch[platform.Arch] {
+			return fmt.Errorf("unsupported arch %q", platform.Arch)
+		}
+	}
+	for runtime, version := range p.MinRuntimeVersions {
+		if !knownRuntimes[runtime] {
+			return fmt.Errorf("unsupported runtime %q", runtime)
+		}
+		if !minVersionPattern.MatchString(version) {
+			return fmt.Errorf("invalid minimum %s version %q", runtime, version)
+		}
+	}
+	return nil
+}
+
+// SupportsPlatform reports whether the package runs on the platform; packages that declare
+// no platforms are assumed to run everywhere. Empty fields of want match any value.
+func (p Package) SupportsPlatform(want Platform) bool {
+	if len(p.Platforms) == 0 {
+		return true
+	}
+	for _, platform := range p.Platforms {
+		if (want.OS == "" || platform.OS == want.OS) &&
+			(want.Arch == "" || platform.Arch == "" || platform.Arch == want.Arch) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsPlatform reports whether the server can be used on the platform: remote-only servers
+// run anywhere, otherwise at least one package must support it
+func (s *ServerDetail) SupportsPlatform(want Platform) bool {
+	if len(s.Packages) == 0 {
+		return true
+	}
+	for _, p := range s.Packages {
+		if p.SupportsPlatform(want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// PublishTokenPrefix starts every publish token, so they are recognized without a lookup
+// and found by secret scanners
+const PublishTokenPrefix = "mcpr_"
+
+// PublishToken is a registry-issued token that can only publish new versions of one
+// server, for embedding in that project's CI. Only the digest of the token is stored.
+type PublishToken struct {
+	ID          string    `json:"id" bson:"_id"`
+	ServerName  string    `json:"server_name" bson:"server_name"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	CreatedBy   string    `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	Digest      string    `json:"-" bson:"digest"`
+	// Token is the secret itself; it is only returned when the token is created
+	Token string `json:"token,omitempty" bson:"-"`
+}
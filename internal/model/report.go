@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// ReportReason is why a server was reported
+type ReportReason string
+
+const (
+	ReportSpam          ReportReason = "spam"
+	ReportMalware       ReportReason = "malware"
+	ReportImpersonation ReportReason = "impersonation"
+	ReportIllegal       ReportReason = "illegal"
+	ReportOther         ReportReason = "other"
+)
+
+// Valid reports whether r is one of the known reasons
+func (r ReportReason) Valid() bool {
+	switch r {
+	case ReportSpam, ReportMalware, ReportImpersonation, ReportIllegal, ReportOther:
+		return true
+	}
+	return false
+}
+
+// ReportStatus tracks a report through the moderation queue
+type ReportStatus string
+
+const (
+	// ReportOpen reports wait for a moderator
+	ReportOpen ReportStatus = "open"
+	// ReportReviewing reports are being looked into
+	ReportReviewing ReportStatus = "reviewing"
+	// ReportResolved reports led to action against the server
+	ReportResolved ReportStatus = "resolved"
+	// ReportDismissed reports needed no action
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Valid reports whether s is one of the known statuses
+func (s ReportStatus) Valid() bool {
+	switch s {
+	case ReportOpen, ReportReviewing, ReportResolved, ReportDismissed:
+		return true
+	}
+	return false
+}
+
+const (
+	// MaxReportDetailsBytes is the longest details text accepted with a report
+	MaxReportDetailsBytes = 4 << 10
+	// MaxReportContactBytes is the longest contact accepted with a report
+	MaxReportContactBytes = 256
+)
+
+// AbuseReport flags a server version as malicious, spam or otherwise abusive, for review
+// by the registry's moderators
+type AbuseReport struct {
+	ID         string       `json:"id" bson:"_id"`
+	ServerID   string       `json:"server_id" bson:"server_id"`
+	ServerName string       `json:"server_name" bson:"server_name"`
+	Reason     ReportReason `json:"reason" bson:"reason"`
+	Details    string       `json:"details,omitempty" bson:"details,omitempty"`
+	// Contact is how the reporter can be reached, if they chose to leave one
+	Contact string       `json:"contact,omitempty" bson:"contact,omitempty"`
+	Status  ReportStatus `json:"status" bson:"status"`
+	// Note is the moderator's record of the decision
+	Note      string    `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
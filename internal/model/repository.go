@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// UnmarshalJSON accepts both the structured repository object and the legacy flat
+// form where the repository was given as a bare URL string
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	var repoURL string
+	if err := json.Unmarshal(data, &repoURL); err == nil {
+		*r = Repository{URL: repoURL, Source: RepositorySourceFromURL(repoURL)}
+		return nil
+	}
+
+	// Decode through an alias type to avoid recursing into this method
+	type repository Repository
+	var decoded repository
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = Repository(decoded)
+	return nil
+}
+
+// RepositorySourceFromURL infers the hosting service of a repository from its URL
+func RepositorySourceFromURL(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch host {
+	case "github.com":
+		return "github"
+	case "gitlab.com":
+		return "gitlab"
+	case "bitbucket.org":
+		return "bitbucket"
+	}
+	return ""
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// RevokedToken records a bearer token that must no longer be accepted. Only the token's
+// sha256 digest is stored.
+type RevokedToken struct {
+	Digest    string    `json:"digest" bson:"_id"`
+	Login     string    `json:"login,omitempty" bson:"login,omitempty"`
+	RevokedAt time.Time `json:"revoked_at" bson:"revoked_at"`
+}
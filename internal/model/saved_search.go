@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// SavedSearch is a search query a user subscribed to. Whenever a newly published version
+// matches the query, a notification is sent to the webhook URL, the email address or both.
+type SavedSearch struct {
+	ID    string `json:"id" bson:"_id"`
+	Owner string `json:"owner" bson:"owner"`
+	// Query uses the same language as the q parameter of GET /v0/servers
+	Query      string    `json:"query" bson:"query"`
+	Match      string    `json:"match,omitempty" bson:"match,omitempty"`
+	WebhookURL string    `json:"webhook_url,omitempty" bson:"webhook_url,omitempty"`
+	Email      string    `json:"email,omitempty" bson:"email,omitempty"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	// Secret signs webhook deliveries; it is only returned when the search is created
+	Secret string `json:"secret,omitempty" bson:"secret"`
+	// EmailConfirmed is set once the owner confirms Email with the token mailed to it;
+	// nothing else is mailed to an unconfirmed address
+	EmailConfirmed bool `json:"email_confirmed,omitempty" bson:"email_confirmed,omitempty"`
+	// EmailTokenHash is the SHA-256 of the confirmation token mailed to Email
+	EmailTokenHash string `json:"-" bson:"email_token_hash,omitempty"`
+	// EmailToken is the confirmation token itself, only held until it is mailed
+	EmailToken string `json:"-" bson:"-"`
+}
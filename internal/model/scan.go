@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// ScanStatus summarizes the URL reputation scan of a published version
+type ScanStatus string
+
+const (
+	// ScanClean versions had no findings
+	ScanClean ScanStatus = "clean"
+	// ScanFlagged versions had findings and were published for moderators to review
+	ScanFlagged ScanStatus = "flagged"
+	// ScanIncomplete versions had no findings, but at least one scanner failed
+	ScanIncomplete ScanStatus = "incomplete"
+)
+
+// ScanFinding is a URL a scanner reports as malicious
+type ScanFinding struct {
+	Scanner string `json:"scanner" bson:"scanner"`
+	URL     string `json:"url" bson:"url"`
+	// Threat is the scanner's classification, such as MALWARE or SOCIAL_ENGINEERING
+	Threat string `json:"threat" bson:"threat"`
+}
+
+// ScanResult records the URL reputation scan run when a version was published
+type ScanResult struct {
+	Status   ScanStatus    `json:"status" bson:"status"`
+	Findings []ScanFinding `json:"findings,omitempty" bson:"findings,omitempty"`
+	// Errors lists the scanners that failed, with their error
+	Errors    []string  `json:"errors,omitempty" bson:"errors,omitempty"`
+	ScannedAt time.Time `json:"scanned_at" bson:"scanned_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Sitemap lists the servers whose detail pages public catalogs expose to search engines
+type Sitemap struct {
+	// Entries is ordered by slug
+	Entries     []SitemapEntry
+	GeneratedAt time.Time
+}
+
+// SitemapEntry is a server of the sitemap
+type SitemapEntry struct {
+	Slug string
+	// LastModified is the release date of the server's latest version
+	LastModified string
+}
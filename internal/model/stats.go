@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// RegistryStats summarizes the public contents of the registry for dashboards
+type RegistryStats struct {
+	// Servers is the number of distinct server names
+	Servers int `json:"servers"`
+	// ActiveServers is the number of servers whose latest version is not yanked
+	ActiveServers int `json:"active_servers"`
+	Versions      int `json:"versions"`
+	// YankedVersions is included in Versions
+	YankedVersions int `json:"yanked_versions"`
+	// PackageRegistries counts the active servers distributing a package on each registry
+	PackageRegistries map[string]int `json:"package_registries"`
+	// Weekly is ordered by week and omits weeks without releases
+	Weekly      []WeeklyStats `json:"weekly"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// WeeklyStats counts the releases of one week, which starts on Monday (UTC)
+type WeeklyStats struct {
+	Week       string `json:"week"`
+	NewServers int    `json:"new_servers"`
+	Versions   int    `json:"versions"`
+}
@@ -0,0 +1,52 @@
+package model
+
+// SyncAction is the change a declarative sync makes to one server
+type SyncAction string
+
+const (
+	// SyncCreate publishes the first version of a server
+	SyncCreate SyncAction = "create"
+	// SyncUpdate publishes a newer version of a server
+	SyncUpdate SyncAction = "update"
+	// SyncRestore unyanks the desired version
+	SyncRestore SyncAction = "restore"
+	// SyncDelete yanks every version of a server missing from the desired state
+	SyncDelete SyncAction = "delete"
+	// SyncUnchanged leaves a server as it is
+	SyncUnchanged SyncAction = "unchanged"
+	// SyncInvalid marks a desired server that cannot be synced
+	SyncInvalid SyncAction = "invalid"
+)
+
+// SyncStep is the planned, or applied, change to one server
+type SyncStep struct {
+	Action SyncAction `json:"action"`
+	Name   string     `json:"name"`
+	// Version is the desired version, or the latest version of a deleted server
+	Version string `json:"version,omitempty"`
+	// IDs are the versions published, restored or yanked
+	IDs   []string `json:"ids,omitempty"`
+	Error string   `json:"error,omitempty"`
+
+	// Server is the version to publish for creates and updates
+	Server *ServerDetail `json:"-"`
+}
+
+// SyncPlan lists the steps bringing the servers of an org to a desired state
+type SyncPlan struct {
+	Org     string             `json:"org"`
+	DryRun  bool               `json:"dry_run"`
+	Applied bool               `json:"applied"`
+	Steps   []SyncStep         `json:"steps"`
+	Summary map[SyncAction]int `json:"summary"`
+}
+
+// Valid reports whether every desired server can be synced
+func (p *SyncPlan) Valid() bool {
+	return p.Summary[SyncInvalid] == 0
+}
+
+// Deletes reports whether applying the plan deletes any server
+func (p *SyncPlan) Deletes() bool {
+	return p.Summary[SyncDelete] > 0
+}
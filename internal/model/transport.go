@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// TransportType identifies how a client connects to an MCP server
+type TransportType string
+
+const (
+	// TransportStdio launches the server as a local process speaking over stdin/stdout
+	TransportStdio TransportType = "stdio"
+	// TransportSSE connects to a remote server using server-sent events
+	TransportSSE TransportType = "sse"
+	// TransportStreamableHTTP connects to a remote server using streamable HTTP
+	TransportStreamableHTTP TransportType = "streamable-http"
+)
+
+// Transport describes how to launch (stdio) or connect to (sse, streamable-http) a server
+type Transport struct {
+	Type    TransportType     `json:"type" bson:"type"`
+	Command string            `json:"command,omitempty" bson:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" bson:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" bson:"env,omitempty"`
+	URL     string            `json:"url,omitempty" bson:"url,omitempty"`
+}
+
+// Validate checks that the transport carries the fields required by its type
+func (t Transport) Validate() error {
+	switch t.Type {
+	case TransportStdio:
+		if t.Command == "" {
+			return fmt.Errorf("stdio transport requires a command")
+		}
+		if t.URL != "" {
+			return fmt.Errorf("stdio transport must not set a url")
+		}
+	case TransportSSE, TransportStreamableHTTP:
+		if t.Command != "" || len(t.Args) > 0 {
+			return fmt.Errorf("%s transport must not set a command or args", t.Type)
+		}
+		u, err := url.Parse(t.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s transport requires an http(s) url", t.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported transport type %q", t.Type)
+	}
+	return nil
+}
+
+// SupportsTransport reports whether the server can be used over the given transport, considering
+// declared transports, remote endpoints and, for stdio, locally installable packages
+func (s *ServerDetail) SupportsTransport(transportType TransportType) bool {
+	for _, t := range s.Transports {
+		if t.Type == transportType {
+			return true
+		}
+	}
+	for _, r := range s.Remotes {
+		if TransportType(r.TransportType) == transportType {
+			return true
+		}
+	}
+	return transportType == TransportStdio && len(s.Packages) > 0
+}
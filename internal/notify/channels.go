@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// Chat services channels can post to
+const (
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+)
+
+// maxDescription bounds the description quoted in channel messages
+const maxDescription = 300
+
+// Channel is a chat channel receiving registry events through an incoming webhook
+type Channel struct {
+	Kind string
+	URL  string
+}
+
+// Channels returns the chat channels configured in cfg
+func Channels(cfg *config.Config) []Channel {
+	var channels []Channel
+	if cfg.NotifySlackWebhookURL != "" {
+		channels = append(channels, Channel{Kind: ChannelSlack, URL: cfg.NotifySlackWebhookURL})
+	}
+	if cfg.NotifyDiscordWebhookURL != "" {
+		channels = append(channels, Channel{Kind: ChannelDiscord, URL: cfg.NotifyDiscordWebhookURL})
+	}
+	return channels
+}
+
+// announce posts a message describing change to every channel. Only changes to public
+// versions are announced, and delivery failures are logged and not retried.
+func (n *Notifier) announce(ctx context.Context, change *model.Change) {
+	entry, err := n.db.GetByID(ctx, change.ID)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			log.Printf("Channel notifications for %s skipped: %v", change.ID, err)
+		}
+		return
+	}
+	if entry.Visibility.Effective() != model.VisibilityPublic {
+		return
+	}
+
+	for _, channel := range n.channels {
+		if err := n.post(ctx, channel, describeChange(channel.Kind, change, entry)); err != nil {
+			log.Printf("Posting %s of %s to %s failed: %v", change.Op, change.ID, channel.Kind, err)
+		}
+	}
+}
+
+// describeChange renders change as a message in the markup of the channel kind
+func describeChange(kind string, change *model.Change, entry *model.ServerDetail) string {
+	escape := strings.NewReplacer("*", "\\*", "_", "\\_", "`", "\\`", "~", "\\~", "|", "\\|").Replace
+	bold := "**%s** %s"
+	if kind == ChannelSlack {
+		escape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+		bold = "*%s* %s"
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, bold, escape(entry.Name), escape(entry.VersionDetail.Version))
+	switch change.Op {
+	case model.ChangeOpPublish:
+		msg.WriteString(" was published")
+		if entry.Description != "" {
+			description := entry.Description
+			if len([]rune(description)) > maxDescription {
+				description = string([]rune(description)[:maxDescription]) + "…"
+			}
+			msg.WriteString("\n> " + escape(headerSafe(description)))
+		}
+	case model.ChangeOpYank:
+		msg.WriteString(" was yanked")
+		if entry.VersionDetail.YankedReason != "" {
+			msg.WriteString(": " + escape(headerSafe(entry.VersionDetail.YankedReason)))
+		}
+	case model.ChangeOpUnyank:
+		msg.WriteString(" was restored")
+	default:
+		fmt.Fprintf(&msg, ": %s", change.Op)
+	}
+	fmt.Fprintf(&msg, "\nID: %s", entry.ID)
+	return msg.String()
+}
+
+// post sends text to channel in the payload its incoming webhook expects
+func (n *Notifier) post(ctx context.Context, channel Channel, text string) error {
+	var payload interface{}
+	switch channel.Kind {
+	case ChannelSlack:
+		payload = map[string]interface{}{"text": text}
+	case ChannelDiscord:
+		// Published text must not ping the channel
+		payload = map[string]interface{}{"content": text, "allowed_mentions": map[string][]string{"parse": {}}}
+	default:
+		return fmt.Errorf("unknown channel kind %q", channel.Kind)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Package notify delivers notifications for saved searches matching newly published versions
+// and announces registry events in chat channels
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/query"
+)
+
+const (
+	// stateKey is where the revision of the last processed change is stored
+	stateKey = database.SavedSearchRevisionKey
+	// pageSize is the number of changes read from the change log at a time
+	pageSize = 500
+	// EventSavedSearchMatch is the event type of webhook deliveries
+	EventSavedSearchMatch = "saved_search.match"
+	// SignatureHeader carries the HMAC-SHA256 of a webhook body keyed with the search's secret
+	SignatureHeader = "X-Registry-Signature"
+)
+
+// Event is the JSON body of a webhook delivery
+type Event struct {
+	Type          string              `json:"type"`
+	SavedSearchID string              `json:"saved_search_id"`
+	Query         string              `json:"query"`
+	Revision      int64               `json:"revision"`
+	Server        *model.ServerDetail `json:"server"`
+}
+
+// Notifier follows the change log and notifies the owners of saved searches matching
+// newly published versions. Publishes, yanks and unyanks are also posted to chat channels.
+// The operator's chat channels are posted to with client, and subscribers' webhooks with
+// webhooks, which only reaches public addresses.
+type Notifier struct {
+	db       database.Database
+	cfg      *config.Config
+	client   *http.Client
+	webhooks *http.Client
+	channels []Channel
+}
+
+// NewNotifier creates a notifier reading from db, delivering email through the SMTP
+// server configured in cfg and posting to the chat channels configured there
+func NewNotifier(db database.Database, cfg *config.Config) *Notifier {
+	return &Notifier{
+		db:       db,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		webhooks: newWebhookClient(cfg.IsDevelopment()),
+		channels: Channels(cfg),
+	}
+}
+
+// Sync notifies saved searches of every version published since the last sync and posts
+// every change to the chat channels. The first sync only records the current revision, so
+// existing versions do not trigger notifications.
+func (n *Notifier) Sync(ctx context.Context) error {
+	revision, err := n.loadRevision(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		changes, err := n.db.ListChanges(ctx, revision, time.Time{}, pageSize)
+		if errors.Is(err, database.ErrPruned) {
+			log.Printf("Changes after revision %d were pruned, skipping saved search notifications for them", revision)
+			return n.resetRevision(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		searches, err := n.db.ListSavedSearches(ctx, "")
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.Entity == model.ChangeEntityServer && change.Op == model.ChangeOpPublish && len(searches) > 0 {
+				n.notify(ctx, change, searches)
+			}
+			if change.Entity == model.ChangeEntityServer && len(n.channels) > 0 {
+				n.announce(ctx, change)
+			}
+			revision = change.Revision
+		}
+
+		if err := n.db.SaveState(ctx, stateKey, strconv.FormatInt(revision, 10)); err != nil {
+			return err
+		}
+		if len(changes) < pageSize {
+			return nil
+		}
+	}
+}
+
+// loadRevision returns the revision of the last processed change, starting at the head of
+// the change log on first run
+func (n *Notifier) loadRevision(ctx context.Context) (int64, error) {
+	stored, err := n.db.LoadState(ctx, stateKey)
+	if errors.Is(err, database.ErrNotFound) {
+		head, err := n.db.HeadRevision(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return head, n.db.SaveState(ctx, stateKey, strconv.FormatInt(head, 10))
+	}
+	if err != nil {
+		return 0, err
+	}
+	revision, err := strconv.ParseInt(stored, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stored revision %q: %w", stored, err)
+	}
+	return revision, nil
+}
+
+// resetRevision moves progress to the head of the change log
+func (n *Notifier) resetRevision(ctx context.Context) error {
+	head, err := n.db.HeadRevision(ctx)
+	if err != nil {
+		return err
+	}
+	return n.db.SaveState(ctx, stateKey, strconv.FormatInt(head, 10))
+}
+
+// notify delivers a notification to every saved search matching the published version.
+// Delivery failures are logged and not retried.
+func (n *Notifier) notify(ctx context.Context, change *model.Change, searches []*model.SavedSearch) {
+	entry, err := n.db.GetByID(ctx, change.ID)
+	if err != nil {
+		if !errors.Is(err, database.ErrNotFound) {
+			log.Printf("Saved search notifications for %s skipped: %v", change.ID, err)
+		}
+		return
+	}
+	if entry.VersionDetail.Yanked || entry.Visibility.Effective() != model.VisibilityPublic {
+		return
+	}
+
+	for _, search := range searches {
+		match := database.MatchMode(search.Match)
+		if match == "" {
+			match = database.MatchSubstring
+		}
+		filter, err := query.Parse(search.Query, query.Options{Match: match, FoldAccents: n.cfg.SearchFoldAccents})
+		if err != nil || !database.MatchesFilter(entry, filter) {
+			continue
+		}
+
+		if search.WebhookURL != "" {
+			if err := n.sendWebhook(ctx, search, change.Revision, entry); err != nil {
+				log.Printf("Webhook for saved search %s failed: %v", search.ID, err)
+			}
+		}
+		if search.Email != "" && search.EmailConfirmed {
+			if err := n.sendEmail(search, entry); err != nil {
+				log.Printf("Email for saved search %s failed: %v", search.ID, err)
+			}
+		}
+	}
+}
+
+// sendWebhook posts the event to the search's webhook URL, signed with its secret
+func (n *Notifier) sendWebhook(ctx context.Context, search *model.SavedSearch, revision int64, entry *model.ServerDetail) error {
+	body, err := json.Marshal(Event{
+		Type:          EventSavedSearchMatch,
+		SavedSearchID: search.ID,
+		Query:         search.Query,
+		Revision:      revision,
+		Server:        entry,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, search.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(search.Secret))
+	mac.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := n.webhooks.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendEmail mails a short summary of the matching version to the search's address
+func (n *Notifier) sendEmail(search *model.SavedSearch, entry *model.ServerDetail) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "A new version matching your saved search %q was published.\r\n\r\n", search.Query)
+	fmt.Fprintf(&body, "Name: %s\r\nVersion: %s\r\nID: %s\r\n", entry.Name, entry.VersionDetail.Version, entry.ID)
+	if entry.Description != "" {
+		fmt.Fprintf(&body, "\r\n%s\r\n", entry.Description)
+	}
+	subject := fmt.Sprintf("%s %s matches your saved search", entry.Name, entry.VersionDetail.Version)
+	return sendMail(n.cfg, search.Email, subject, body.String())
+}
+
+// SendConfirmation mails the confirmation token of a new saved search to its address. No
+// notification is mailed to the address until the token is confirmed.
+func SendConfirmation(cfg *config.Config, search *model.SavedSearch) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "A saved search for %q was created with this address by %s.\r\n\r\n", search.Query, search.Owner)
+	fmt.Fprintf(&body, "To receive its notifications, confirm the address with\r\n\r\n")
+	fmt.Fprintf(&body, "POST /v0/saved-searches/%s/confirm {\"token\": \"%s\"}\r\n\r\n", search.ID, search.EmailToken)
+	fmt.Fprintf(&body, "If you did not expect this, ignore this message; nothing else will be sent.\r\n")
+	return sendMail(cfg, search.Email, "Confirm your saved search notifications", body.String())
+}
+
+// sendMail sends a plain text message through the SMTP server configured in cfg
+func sendMail(cfg *config.Config, to, subject, body string) error {
+	if cfg.SMTPAddr == "" {
+		return errors.New("SMTP is not configured")
+	}
+
+	var smtpAuth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		host, _, err := net.SplitHostPort(cfg.SMTPAddr)
+		if err != nil {
+			return err
+		}
+		smtpAuth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.SMTPFrom)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerSafe(subject))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(body)
+
+	return smtp.SendMail(cfg.SMTPAddr, smtpAuth, cfg.SMTPFrom, []string{to}, []byte(msg.String()))
+}
+
+// headerSafe strips line breaks so published values cannot inject mail headers
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
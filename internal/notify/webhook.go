@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned when a webhook URL resolves to an address that is not
+// reachable from the public internet, such as loopback, private or cloud metadata addresses
+var ErrNonPublicAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which netip does not
+// classify as private
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddress reports whether addr is a unicast address on the public internet
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() && !sharedAddressSpace.Contains(addr)
+}
+
+// newWebhookClient returns the client delivering to subscriber-supplied webhook URLs. It
+// checks every address it connects to after DNS resolution, so a name cannot be pointed at
+// an internal host once validated, and it neither uses proxies nor follows redirects.
+// allowPrivate lifts the address check, for development.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return errors.New("webhook redirects are not followed")
+		},
+	}
+}
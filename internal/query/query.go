@@ -0,0 +1,220 @@
+// Package query parses the search language accepted by the q parameter of server listings.
+//
+// A query is a sequence of terms. A term is either a qualifier such as author:anthropic or
+// name:"my server", or free text, which searches server names; consecutive free words form
+// one phrase. Terms are combined with AND, and the keyword OR separates alternatives, so
+// "author:a name:sql OR author:b" matches servers by a named like sql, or any server by b.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// ErrInvalidQuery is wrapped by every parse error
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Qualifiers are the supported field qualifiers
+var Qualifiers = []string{"name", "author", "transport", "os", "arch", "version"}
+
+// qualifierPattern matches tokens that look like a qualifier, known or not
+var qualifierPattern = regexp.MustCompile(`^([a-z_]+):(.*)$`)
+
+// Options control how name terms are matched
+type Options struct {
+	Match       database.MatchMode
+	FoldAccents bool
+}
+
+// Parse converts q into a database filter. A query without OR yields a plain filter; with
+// OR the alternatives are listed under the "any" key.
+func Parse(q string, opts Options) (map[string]interface{}, error) {
+	tokens, err := tokenize(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []map[string]interface{}
+	group := newGroup(opts)
+	for _, tok := range tokens {
+		if tok.text == "OR" && !tok.quoted {
+			if group.empty() {
+				return nil, fmt.Errorf("%w: OR must separate two terms", ErrInvalidQuery)
+			}
+			groups = append(groups, group.filter())
+			group = newGroup(opts)
+			continue
+		}
+		if err := group.add(tok); err != nil {
+			return nil, err
+		}
+	}
+	if group.empty() {
+		if len(groups) > 0 {
+			return nil, fmt.Errorf("%w: OR must separate two terms", ErrInvalidQuery)
+		}
+		return map[string]interface{}{}, nil
+	}
+	groups = append(groups, group.filter())
+
+	if len(groups) == 1 {
+		return groups[0], nil
+	}
+	return map[string]interface{}{"any": groups}, nil
+}
+
+// Merge adds the conditions of extra to filter, nesting them under "all" if any key clashes
+func Merge(filter, extra map[string]interface{}) {
+	for key := range extra {
+		if _, clash := filter[key]; clash {
+			all, _ := filter["all"].([]map[string]interface{})
+			filter["all"] = append(all, extra)
+			return
+		}
+	}
+	for key, value := range extra {
+		filter[key] = value
+	}
+}
+
+// token is a word of the query; quoted tokens are never keywords
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits q on whitespace, keeping double-quoted sections together
+func tokenize(q string) ([]token, error) {
+	var (
+		tokens  []token
+		current strings.Builder
+		inQuote bool
+		quoted  bool
+		started bool
+	)
+	flush := func() {
+		if started {
+			tokens = append(tokens, token{text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted, started = false, false
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			quoted, started = true, true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+	flush()
+	return tokens, nil
+}
+
+// group collects the terms of one AND-group
+type group struct {
+	opts       Options
+	conditions map[string]interface{}
+	extra      []map[string]interface{}
+	phrase     []string
+	platform   model.Platform
+}
+
+func newGroup(opts Options) *group {
+	return &group{opts: opts, conditions: map[string]interface{}{}}
+}
+
+func (g *group) empty() bool {
+	return len(g.conditions) == 0 && len(g.extra) == 0 && len(g.phrase) == 0 && g.platform == (model.Platform{})
+}
+
+// add records a term; free text words accumulate into the current phrase
+func (g *group) add(tok token) error {
+	m := qualifierPattern.FindStringSubmatch(tok.text)
+	// URLs such as https://... are free text, not qualifiers
+	if m == nil || strings.HasPrefix(m[2], "//") {
+		g.phrase = append(g.phrase, tok.text)
+		return nil
+	}
+	g.endPhrase()
+
+	field, value := m[1], m[2]
+	if value == "" {
+		return fmt.Errorf("%w: %s: needs a value", ErrInvalidQuery, field)
+	}
+	switch field {
+	case "name":
+		g.set("search", g.search(value))
+	case "author":
+		g.set("author", value)
+	case "version":
+		g.set("version", value)
+	case "transport":
+		switch model.TransportType(value) {
+		case model.TransportStdio, model.TransportSSE, model.TransportStreamableHTTP:
+			g.set("transport", value)
+		default:
+			return fmt.Errorf("%w: unknown transport %q", ErrInvalidQuery, value)
+		}
+	case "os":
+		if !model.IsKnownOS(value) || g.platform.OS != "" {
+			return fmt.Errorf("%w: invalid os %q", ErrInvalidQuery, value)
+		}
+		g.platform.OS = value
+	case "arch":
+		if !model.IsKnownArch(value) || g.platform.Arch != "" {
+			return fmt.Errorf("%w: invalid arch %q", ErrInvalidQuery, value)
+		}
+		g.platform.Arch = value
+	default:
+		return fmt.Errorf("%w: unknown qualifier %q, expected one of %s",
+			ErrInvalidQuery, field, strings.Join(Qualifiers, ", "))
+	}
+	return nil
+}
+
+// endPhrase turns the free text collected so far into a name search
+func (g *group) endPhrase() {
+	if len(g.phrase) > 0 {
+		g.set("search", g.search(strings.Join(g.phrase, " ")))
+		g.phrase = nil
+	}
+}
+
+func (g *group) search(text string) database.Search {
+	return database.Search{Query: text, Match: g.opts.Match, FoldAccents: g.opts.FoldAccents}
+}
+
+// set adds a condition, keeping repeated fields as separate conditions that must all hold
+func (g *group) set(key string, value interface{}) {
+	if _, exists := g.conditions[key]; exists {
+		g.extra = append(g.extra, map[string]interface{}{key: value})
+		return
+	}
+	g.conditions[key] = value
+}
+
+// filter returns the group's conditions as a database filter
+func (g *group) filter() map[string]interface{} {
+	g.endPhrase()
+	if g.platform != (model.Platform{}) {
+		g.conditions["platform"] = g.platform
+	}
+	if len(g.extra) > 0 {
+		g.conditions["all"] = g.extra
+	}
+	return g.conditions
+}
@@ -0,0 +1,254 @@
+// Package replication keeps a passive registry instance in sync with a primary by following its change feed
+package replication
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+const (
+	// revisionHeader is the header carrying the primary's change log revision on exports
+	revisionHeader = "X-Registry-Revision"
+	// changesPageSize is the number of changes requested per page
+	changesPageSize = 500
+	// maxEntryBytes bounds a single exported entry read during bootstrap
+	maxEntryBytes = 4 << 20
+)
+
+// changesPage is a page of the primary's /v0/changes feed
+type changesPage struct {
+	Changes   []*model.Change `json:"changes"`
+	NextSince int64           `json:"next_since"`
+	HasMore   bool            `json:"has_more"`
+}
+
+// Follower replicates a primary registry into the local database
+type Follower struct {
+	source *url.URL
+	db     database.Database
+	policy database.ConflictPolicy
+	client *http.Client
+}
+
+// NewFollower creates a follower of the registry at sourceURL
+func NewFollower(sourceURL string, db database.Database, policy database.ConflictPolicy) (*Follower, error) {
+	source, err := url.Parse(strings.TrimSuffix(sourceURL, "/"))
+	if err != nil || (source.Scheme != "http" && source.Scheme != "https") || source.Host == "" {
+		return nil, fmt.Errorf("invalid replication source %q", sourceURL)
+	}
+
+	switch policy {
+	case database.ConflictSourceWins, database.ConflictLocalWins:
+	default:
+		return nil, fmt.Errorf("invalid conflict policy %q; supported: %s, %s",
+			policy, database.ConflictSourceWins, database.ConflictLocalWins)
+	}
+
+	return &Follower{
+		source: source,
+		db:     db,
+		policy: policy,
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// stateKey is where replication progress is stored; it includes the source so that
+// pointing a replica at a different primary starts over with a full bootstrap
+func (f *Follower) stateKey() string {
+	return "replication:" + f.source.String() + ":revision"
+}
+
+// Sync bootstraps from the primary's export on first run and then applies every change
+// recorded since the last sync
+func (f *Follower) Sync(ctx context.Context) error {
+	var revision int64
+	stored, err := f.db.LoadState(ctx, f.stateKey())
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		if revision, err = f.bootstrap(ctx); err != nil {
+			return fmt.Errorf("bootstrap: %w", err)
+		}
+		if err := f.db.SaveState(ctx, f.stateKey(), strconv.FormatInt(revision, 10)); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if revision, err = strconv.ParseInt(stored, 10, 64); err != nil {
+			return fmt.Errorf("invalid stored revision %q: %w", stored, err)
+		}
+	}
+
+	for {
+		page, err := f.fetchChanges(ctx, revision)
+		if errors.Is(err, errGone) {
+			log.Printf("Changes after revision %d were pruned on %s, bootstrapping again", revision, f.source)
+			if revision, err = f.bootstrap(ctx); err != nil {
+				return fmt.Errorf("bootstrap: %w", err)
+			}
+			if err := f.db.SaveState(ctx, f.stateKey(), strconv.FormatInt(revision, 10)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Several changes to one version are applied once, as the current state is fetched
+		applied := make(map[string]bool)
+		for _, change := range page.Changes {
+			if change.Entity != model.ChangeEntityServer || applied[change.ID] {
+				continue
+			}
+			applied[change.ID] = true
+			if err := f.replicateServer(ctx, change.ID); err != nil {
+				return fmt.Errorf("applying revision %d: %w", change.Revision, err)
+			}
+		}
+
+		if page.NextSince != revision {
+			revision = page.NextSince
+			if err := f.db.SaveState(ctx, f.stateKey(), strconv.FormatInt(revision, 10)); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore {
+			return nil
+		}
+	}
+}
+
+// bootstrap copies the primary's full export and returns the revision it reflects
+func (f *Follower) bootstrap(ctx context.Context) (int64, error) {
+	resp, err := f.get(ctx, "/v0/export", nil, "application/x-ndjson")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	revision, err := strconv.ParseInt(resp.Header.Get(revisionHeader), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("export response lacks a valid %s header", revisionHeader)
+	}
+
+	log.Printf("Bootstrapping replica from %s at revision %d", f.source, revision)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEntryBytes)
+	count := 0
+	for scanner.Scan() {
+		var entry model.ServerDetail
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return 0, fmt.Errorf("decoding export entry: %w", err)
+		}
+		if err := f.apply(ctx, &entry); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("reading export: %w", err)
+	}
+
+	log.Printf("Replica bootstrapped with %d entries", count)
+	return revision, nil
+}
+
+// fetchChanges reads one page of the primary's change feed
+func (f *Follower) fetchChanges(ctx context.Context, since int64) (*changesPage, error) {
+	query := url.Values{
+		"since": {strconv.FormatInt(since, 10)},
+		"limit": {strconv.Itoa(changesPageSize)},
+	}
+	resp, err := f.get(ctx, "/v0/changes", query, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page changesPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decoding changes: %w", err)
+	}
+	return &page, nil
+}
+
+// replicateServer fetches the current state of a version from the primary and applies it
+func (f *Follower) replicateServer(ctx context.Context, id string) error {
+	resp, err := f.get(ctx, "/v0/servers/"+url.PathEscape(id), nil, "application/json")
+	if errors.Is(err, errNotFound) {
+		log.Printf("Replication skipped %s: no longer available on the primary", id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var entry model.ServerDetail
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		return fmt.Errorf("decoding server %s: %w", id, err)
+	}
+	return f.apply(ctx, &entry)
+}
+
+// apply stores a replicated entry, logging rather than failing on conflicts kept by policy
+func (f *Follower) apply(ctx context.Context, entry *model.ServerDetail) error {
+	err := f.db.Replicate(ctx, entry, f.policy)
+	if errors.Is(err, database.ErrConflict) {
+		log.Printf("Replication kept local version: %v", err)
+		return nil
+	}
+	return err
+}
+
+var (
+	// errNotFound is returned by get for 404 responses
+	errNotFound = errors.New("not found on primary")
+	// errGone is returned by get for 410 responses, sent once the changes a replica still
+	// needs have been pruned from the primary's log
+	errGone = errors.New("gone from primary")
+)
+
+// get performs a GET request against the primary, returning an error for non-200 responses
+func (f *Follower) get(ctx context.Context, path string, query url.Values, accept string) (*http.Response, error) {
+	u := *f.source
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return nil, errNotFound
+		case http.StatusGone:
+			return nil, errGone
+		}
+		return nil, fmt.Errorf("GET %s returned %d: %s", u.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
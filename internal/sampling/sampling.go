@@ -0,0 +1,225 @@
+// Package sampling keeps a bounded, redacted sample of recent mutating request bodies so
+// malformed publisher payloads can be diagnosed without logging every request
+package sampling
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxBodyBytes is the largest prefix of a request body kept in a sample
+const MaxBodyBytes = 64 * 1024
+
+// redacted replaces secret values in sampled requests
+const redacted = "[REDACTED]"
+
+// Sample is a recorded request
+type Sample struct {
+	Time          time.Time         `json:"time"`
+	Method        string            `json:"method"`
+	Path          string            `json:"path"`
+	Query         string            `json:"query,omitempty"`
+	Headers       map[string]string `json:"headers"`
+	Body          string            `json:"body,omitempty"`
+	BodyBytes     int               `json:"body_bytes"`
+	BodyTruncated bool              `json:"body_truncated,omitempty"`
+	Status        int               `json:"status,omitempty"`
+}
+
+// Recorder samples requests into a fixed-size ring buffer
+type Recorder struct {
+	rate float64
+
+	mu      sync.Mutex
+	samples []Sample
+	next    int
+	full    bool
+}
+
+// NewRecorder creates a recorder keeping the latest size samples of rate percent of the
+// requests offered to it. A non-positive rate or size disables sampling.
+func NewRecorder(rate float64, size int) *Recorder {
+	if rate <= 0 || size <= 0 {
+		return &Recorder{}
+	}
+	return &Recorder{
+		rate:    min(rate, 100),
+		samples: make([]Sample, size),
+	}
+}
+
+// Enabled reports whether the recorder samples any requests
+func (rec *Recorder) Enabled() bool {
+	return rec != nil && rec.rate > 0
+}
+
+// Sampled reports whether the next request should be sampled
+func (rec *Recorder) Sampled() bool {
+	return rec.Enabled() && rand.Float64()*100 < rec.rate //nolint:gosec // Sampling needs no secure randomness
+}
+
+// Record stores a redacted copy of the request and its body, evicting the oldest sample
+// once the buffer is full. body holds at most MaxBodyBytes of the total size bytes.
+func (rec *Recorder) Record(r *http.Request, body []byte, size, status int) {
+	sample := Snapshot(r, body, size)
+	sample.Status = status
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.samples[rec.next] = sample
+	rec.next = (rec.next + 1) % len(rec.samples)
+	if rec.next == 0 {
+		rec.full = true
+	}
+}
+
+// Snapshot returns a redacted copy of the request without a status, as Record would store
+// it. body holds at most MaxBodyBytes of the total size bytes.
+func Snapshot(r *http.Request, body []byte, size int) Sample {
+	return Sample{
+		Time:          time.Now().UTC(),
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         redactQuery(r),
+		Headers:       redactHeaders(r.Header),
+		Body:          redactBody(r.Header.Get("Content-Type"), body),
+		BodyBytes:     size,
+		BodyTruncated: size > len(body),
+	}
+}
+
+// Samples returns the recorded samples, newest first
+func (rec *Recorder) Samples() []Sample {
+	if !rec.Enabled() {
+		return []Sample{}
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	count := rec.next
+	if rec.full {
+		count = len(rec.samples)
+	}
+	result := make([]Sample, 0, count)
+	for i := 1; i <= count; i++ {
+		result = append(result, rec.samples[(rec.next-i+len(rec.samples))%len(rec.samples)])
+	}
+	return result
+}
+
+// secretHeaders are never recorded verbatim
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// secretKey matches field and parameter names whose values must not be recorded
+var secretKey = regexp.MustCompile(`(?i)token|secret|password|passwd|authorization|api[_-]?key|private[_-]?key|credential`)
+
+// secretValue matches well-known token formats wherever they appear in a body
+var secretValue = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|Bearer\s+[A-Za-z0-9._~+/=-]+)`)
+
+// secretField matches "key": "value" pairs in bodies that are not valid JSON
+var secretField = regexp.MustCompile(`("[^"]*"\s*:\s*)"((?:[^"\\]|\\.)*)"`)
+
+// formField matches key=value pairs of form encoded bodies
+var formField = regexp.MustCompile(`(^|&)([^=&]*)=([^&]*)`)
+
+func redactHeaders(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if secretHeaders[name] || secretKey.MatchString(name) {
+			result[name] = redacted
+			continue
+		}
+		result[name] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+func redactQuery(r *http.Request) string {
+	return redactValues(r.URL.Query()).Encode()
+}
+
+func redactValues(values url.Values) url.Values {
+	for key := range values {
+		if secretKey.MatchString(key) {
+			values[key] = []string{redacted}
+		}
+	}
+	return values
+}
+
+// redactBody renders body for display, masking secret fields and token-shaped values.
+// Payloads that fail to parse, which are the interesting ones, are redacted textually.
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if !isText(contentType) {
+		return "[binary body omitted]"
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err == nil {
+		if out, err := json.Marshal(redactValue(value)); err == nil {
+			return secretValue.ReplaceAllString(string(out), redacted)
+		}
+	}
+
+	text := secretField.ReplaceAllStringFunc(string(body), func(field string) string {
+		parts := secretField.FindStringSubmatch(field)
+		if secretKey.MatchString(parts[1]) {
+			return parts[1] + `"` + redacted + `"`
+		}
+		return field
+	})
+	text = formField.ReplaceAllStringFunc(text, func(field string) string {
+		parts := formField.FindStringSubmatch(field)
+		if secretKey.MatchString(parts[2]) {
+			return parts[1] + parts[2] + "=" + redacted
+		}
+		return field
+	})
+	return secretValue.ReplaceAllString(text, redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if secretKey.MatchString(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func isText(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/x-www-form-urlencoded"
+}
@@ -0,0 +1,323 @@
+// Package sanitize removes active content from user supplied markdown and HTML
+package sanitize
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// droppedElements are removed together with their content. SVG and MathML are dropped
+// whole: their animation and foreign content rules can set attributes the allowlist never
+// sees.
+var droppedElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"applet":   true,
+	"noscript": true,
+	"noembed":  true,
+	"noframes": true,
+	"template": true,
+	"textarea": true,
+	"select":   true,
+	"title":    true,
+	"xmp":      true,
+	"svg":      true,
+	"math":     true,
+}
+
+// globalAttributes may appear on every allowed element
+var globalAttributes = map[string]bool{
+	"title": true,
+	"dir":   true,
+	"lang":  true,
+	"id":    true,
+	"align": true,
+}
+
+// allowedElements lists the elements kept in markdown together with the attributes each
+// may carry in addition to globalAttributes. Any other HTML element is removed while its
+// content is kept.
+var allowedElements = map[string]map[string]bool{
+	"a":          {"href": true, "name": true, "rel": true},
+	"abbr":       nil,
+	"b":          nil,
+	"bdi":        nil,
+	"bdo":        nil,
+	"blockquote": {"cite": true},
+	"br":         nil,
+	"caption":    nil,
+	"center":     nil,
+	"cite":       nil,
+	"code":       nil,
+	"col":        {"span": true, "width": true},
+	"colgroup":   {"span": true, "width": true},
+	"dd":         nil,
+	"del":        {"cite": true, "datetime": true},
+	"details":    {"open": true},
+	"dfn":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src": true, "alt": true, "width": true, "height": true},
+	"ins":        {"cite": true, "datetime": true},
+	"kbd":        nil,
+	"li":         {"value": true},
+	"mark":       nil,
+	"ol":         {"start": true, "type": true, "reversed": true},
+	"p":          nil,
+	"picture":    nil,
+	"pre":        nil,
+	"q":          {"cite": true},
+	"rp":         nil,
+	"rt":         nil,
+	"ruby":       nil,
+	"s":          nil,
+	"samp":       nil,
+	"small":      nil,
+	"source":     {"srcset": true, "media": true, "type": true, "width": true, "height": true},
+	"span":       nil,
+	"strike":     nil,
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan": true, "rowspan": true},
+	"tfoot":      nil,
+	"th":         {"colspan": true, "rowspan": true, "scope": true},
+	"thead":      nil,
+	"tr":         nil,
+	"tt":         nil,
+	"u":          nil,
+	"ul":         nil,
+	"var":        nil,
+	"wbr":        nil,
+}
+
+// urlAttributes hold URLs and must be relative or use one of linkSchemes
+var urlAttributes = map[string]bool{
+	"href": true,
+	"src":  true,
+	"cite": true,
+}
+
+// linkSchemes are the schemes markdown links and images may point to. Destinations
+// without a scheme are relative and always allowed.
+var linkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var (
+	// inlineDestination matches the destination of an inline link or image, allowing one
+	// level of balanced parentheses as CommonMark does
+	inlineDestination = regexp.MustCompile(`(\]\(\s*<?)((?:[^()\s<>]|\([^()\s<>]*\))*)`)
+	// referenceDestination matches the destination of a link reference definition
+	referenceDestination = regexp.MustCompile(`(?m)^( {0,3}\[[^\]\n]+\]:[ \t]*\n?[ \t]*<?)([^\s<>]+)`)
+	// autolink matches the raw text of an autolink, which the HTML tokenizer reads as a tag
+	autolink = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9+.\-]{1,31}:[^<>\s]*>$`)
+	// urlScheme matches the scheme of an absolute URL
+	urlScheme = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.\-]*):`)
+)
+
+// Markdown strips script capable HTML from markdown. Markdown syntax and allowed inline
+// HTML are preserved byte for byte; elements outside allowedElements are removed and
+// attributes outside their allowlist are dropped.
+// Link and image destinations with a scheme other than http, https or mailto are
+// replaced with "#", and such autolinks are dropped.
+func Markdown(s string) string {
+	var out bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(s))
+	skipping, depth := "", 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+
+		if skipping != "" {
+			depth = skipDepth(z, tt, skipping, depth)
+			if depth == 0 {
+				skipping = ""
+			}
+			continue
+		}
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			if tt == html.StartTagToken && autolink.Match(raw) {
+				if isAllowedLink(string(raw[1 : len(raw)-1])) {
+					out.Write(raw)
+				}
+				continue
+			}
+			// Token lowercases the tag name in the buffer raw points into
+			raw = bytes.Clone(raw)
+			token := z.Token()
+			allowed, ok := allowedElements[token.Data]
+			switch {
+			case droppedElements[token.Data]:
+				if tt == html.StartTagToken {
+					skipping, depth = token.Data, 1
+				}
+			case !ok:
+				// Generic parameters such as Vec<String> read as tags; an element HTML
+				// does not know, without attributes, renders nothing and is kept
+				if len(token.Attr) == 0 && atom.Lookup([]byte(token.Data)) == 0 {
+					out.Write(raw)
+				}
+			case tt == html.EndTagToken:
+				out.Write(raw)
+			default:
+				if cleaned, changed := cleanAttributes(token.Attr, allowed); changed {
+					token.Attr = cleaned
+					out.WriteString(token.String())
+				} else {
+					out.Write(raw)
+				}
+			}
+		case html.CommentToken, html.DoctypeToken:
+			// Comments can hide conditional markup and carry no value in documentation
+		case html.TextToken:
+			out.WriteString(cleanLinks(string(raw)))
+		default:
+			out.Write(raw)
+		}
+	}
+
+	return out.String()
+}
+
+// skipDepth tracks nesting of the dropped element name while its content is skipped and
+// returns the new depth; zero means the element has been closed
+func skipDepth(z *html.Tokenizer, tt html.TokenType, name string, depth int) int {
+	if tt != html.StartTagToken && tt != html.EndTagToken {
+		return depth
+	}
+	if tag, _ := z.TagName(); string(tag) != name {
+		return depth
+	}
+	if tt == html.StartTagToken {
+		return depth + 1
+	}
+	return depth - 1
+}
+
+// cleanLinks replaces the destinations of inline links, images and reference definitions
+// that use a scheme other than linkSchemes
+func cleanLinks(text string) string {
+	if !strings.Contains(text, "]") {
+		return text
+	}
+	replace := func(pattern *regexp.Regexp, text string) string {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			parts := pattern.FindStringSubmatch(match)
+			if isAllowedLink(parts[2]) {
+				return match
+			}
+			return parts[1] + "#"
+		})
+	}
+	return replace(referenceDestination, replace(inlineDestination, text))
+}
+
+// isAllowedLink reports whether a markdown link destination is relative or uses one of
+// linkSchemes. Entities and backslash escapes are resolved first, as markdown renderers do.
+func isAllowedLink(destination string) bool {
+	normalized := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '\\' {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(destination))
+	match := urlScheme.FindStringSubmatch(normalized)
+	return match == nil || linkSchemes[strings.ToLower(match[1])]
+}
+
+// Text strips every HTML tag from a plain text field such as a description, keeping the
+// text between tags. Text is otherwise preserved byte for byte, entities included.
+func Text(s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	var out bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(s))
+	skipping, depth := "", 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if skipping != "" {
+			depth = skipDepth(z, tt, skipping, depth)
+			if depth == 0 {
+				skipping = ""
+			}
+			continue
+		}
+		switch tt {
+		case html.TextToken:
+			out.Write(z.Raw())
+		case html.StartTagToken:
+			if name, _ := z.TagName(); droppedElements[string(name)] {
+				skipping, depth = string(name), 1
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// cleanAttributes keeps the global attributes and those in allowed, dropping URLs that are
+// neither relative nor use one of linkSchemes
+func cleanAttributes(attrs []html.Attribute, allowed map[string]bool) ([]html.Attribute, bool) {
+	cleaned := attrs[:0:0]
+	changed := false
+	for _, attr := range attrs {
+		key := strings.ToLower(attr.Key)
+		switch {
+		case attr.Namespace != "" || !globalAttributes[key] && !allowed[key],
+			urlAttributes[key] && !isAllowedLink(attr.Val),
+			key == "srcset" && !isAllowedSrcset(attr.Val):
+			changed = true
+			continue
+		}
+		cleaned = append(cleaned, attr)
+	}
+	return cleaned, changed
+}
+
+// isAllowedSrcset reports whether every image candidate of a srcset attribute is an
+// allowed link
+func isAllowedSrcset(srcset string) bool {
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 && !isAllowedLink(fields[0]) {
+			return false
+		}
+	}
+	return true
+}
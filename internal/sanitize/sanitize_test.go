@@ -0,0 +1,57 @@
+package sanitize
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain markdown", "# Title\n\n> quote & `a < b`\n", "# Title\n\n> quote & `a < b`\n"},
+		{"allowed html", `<p align="center"><img src="logo.png" alt="logo" width="64"></p>`, `<p align="center"><img src="logo.png" alt="logo" width="64"></p>`},
+		{"generic parameter", "`Vec<String>`", "`Vec<String>`"},
+		{"script", "a<script>alert(1)</script>b", "ab"},
+		{"event handler", `<img src="x.png" onerror="alert(1)">`, `<img src="x.png">`},
+		{"unknown attribute", `<p class="x" style="color:red">hi</p>`, `<p>hi</p>`},
+		{"disallowed element keeps content", `<form action="/x"><b>hi</b></form>`, `<b>hi</b>`},
+		{"javascript href", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"entity encoded href", `<a href="jav&#x61;script:alert(1)">x</a>`, `<a>x</a>`},
+		{"data svg href", `<a href="data:image/svg+xml;base64,PHN2Zz4=">x</a>`, `<a>x</a>`},
+		{"data image src", `<img src="data:image/png;base64,AAAA">`, `<img>`},
+		{"srcset", `<source srcset="a.png 1x, javascript:alert(1) 2x">`, `<source>`},
+		{"svg animate href", `<svg><a><animate attributeName="href" values="javascript:alert(1)"/><text>click</text></a></svg>`, ``},
+		{"svg set handler", `<svg><set attributeName="onmouseover" to="alert(1)"/>`, ``},
+		{"nested svg", `<svg><svg></svg><a xlink:href="javascript:alert(1)">x</a></svg>after`, `after`},
+		{"math", `<math><mtext><a href="javascript:alert(1)">x</a></mtext></math>`, ``},
+		{"namespaced attribute", `<a xlink:href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"bare known element", `<button>x</button>`, `x`},
+		{"markdown link", "[x](javascript:alert(1)) [y](https://example.com)", "[x](#) [y](https://example.com)"},
+		{"autolink", "<javascript:alert(1)> <https://example.com>", " <https://example.com>"},
+		{"comment", "a<!-- <script> -->b", "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(tt.in); got != tt.want {
+				t.Errorf("Markdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"a & b", "a & b"},
+		{"<b>bold</b> text", "bold text"},
+		{"a<script>alert(1)</script>b", "ab"},
+		{"a<svg><svg></svg><text>x</text></svg>b", "ab"},
+	}
+	for _, tt := range tests {
+		if got := Text(tt.in); got != tt.want {
+			t.Errorf("Text(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"registry/internal/model"
+)
+
+// safeBrowsingEndpoint is the Lookup API of Google Safe Browsing v4
+const safeBrowsingEndpoint = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// SafeBrowsing checks URLs with the Google Safe Browsing Lookup API
+type SafeBrowsing struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewSafeBrowsing returns a scanner using the Safe Browsing API key apiKey
+func NewSafeBrowsing(apiKey string) *SafeBrowsing {
+	return &SafeBrowsing{apiKey: apiKey, endpoint: safeBrowsingEndpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name identifies the scanner
+func (s *SafeBrowsing) Name() string {
+	return "safe_browsing"
+}
+
+// Scan looks up every URL in one request
+func (s *SafeBrowsing) Scan(ctx context.Context, urls []string) ([]model.ScanFinding, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	type threatEntry struct {
+		URL string `json:"url"`
+	}
+	entries := make([]threatEntry, len(urls))
+	for i, u := range urls {
+		entries[i] = threatEntry{URL: u}
+	}
+	request := map[string]interface{}{
+		"client": map[string]string{"clientId": "mcp-registry", "clientVersion": "1.0"},
+		"threatInfo": map[string]interface{}{
+			"threatTypes":      []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"},
+			"platformTypes":    []string{"ANY_PLATFORM"},
+			"threatEntryTypes": []string{"URL"},
+			"threatEntries":    entries,
+		},
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"?key="+url.QueryEscape(s.apiKey), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d from Safe Browsing", resp.StatusCode)
+	}
+
+	var response struct {
+		Matches []struct {
+			ThreatType string      `json:"threatType"`
+			Threat     threatEntry `json:"threat"`
+		} `json:"matches"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid Safe Browsing response: %w", err)
+	}
+	findings := make([]model.ScanFinding, 0, len(response.Matches))
+	for _, match := range response.Matches {
+		findings = append(findings, model.ScanFinding{Scanner: s.Name(), URL: match.Threat.URL, Threat: match.ThreatType})
+	}
+	return findings, nil
+}
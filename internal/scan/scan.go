@@ -0,0 +1,125 @@
+// Package scan checks the URLs of published servers against malware and phishing
+// reputation services
+package scan
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"registry/internal/config"
+	"registry/internal/model"
+)
+
+// Modes selected by MCP_REGISTRY_SCAN_MODE
+const (
+	// ModeFlag publishes versions with findings and queues them for moderators
+	ModeFlag = "flag"
+	// ModeBlock rejects versions with findings
+	ModeBlock = "block"
+)
+
+// Scanner checks URLs against a reputation service. Services other than the built-in ones
+// are added by implementing it and passing the scanner to the registry service.
+type Scanner interface {
+	// Name identifies the scanner in findings and errors
+	Name() string
+	// Scan returns the URLs the service considers malicious. URLs it does not know are
+	// not findings.
+	Scan(ctx context.Context, urls []string) ([]model.ScanFinding, error)
+}
+
+// Scanners returns the built-in scanners configured in cfg
+func Scanners(cfg *config.Config) []Scanner {
+	var scanners []Scanner
+	if cfg.ScanSafeBrowsingAPIKey != "" {
+		scanners = append(scanners, NewSafeBrowsing(cfg.ScanSafeBrowsingAPIKey))
+	}
+	if cfg.ScanVirusTotalAPIKey != "" {
+		scanners = append(scanners, NewVirusTotal(cfg.ScanVirusTotalAPIKey))
+	}
+	return scanners
+}
+
+// URLs returns the URLs of a version worth checking: its repository, remote and transport
+// endpoints, and the registry pages of its packages. Each URL is listed once.
+func URLs(serverDetail *model.ServerDetail) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(u string) {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return
+		}
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+
+	add(serverDetail.Repository.URL)
+	for _, remote := range serverDetail.Remotes {
+		add(remote.URL)
+	}
+	for _, transport := range serverDetail.Transports {
+		add(transport.URL)
+	}
+	for _, pkg := range serverDetail.Packages {
+		add(packageURL(pkg))
+	}
+	return urls
+}
+
+// packageURL returns the page of a package on its registry, or "" for registries
+// without a known page
+func packageURL(pkg model.Package) string {
+	switch pkg.RegistryName {
+	case "npm":
+		return "https://www.npmjs.com/package/" + pkg.Name
+	case "pypi":
+		return "https://pypi.org/project/" + pkg.Name + "/"
+	case "docker":
+		if !strings.Contains(pkg.Name, "/") {
+			return "https://hub.docker.com/_/" + pkg.Name
+		}
+		return "https://hub.docker.com/r/" + pkg.Name
+	}
+	return ""
+}
+
+// Run checks urls with every scanner in parallel and merges their results. A failing
+// scanner is recorded in the result's errors without failing the scan.
+func Run(ctx context.Context, scanners []Scanner, urls []string) *model.ScanResult {
+	type outcome struct {
+		findings []model.ScanFinding
+		err      error
+	}
+	outcomes := make([]outcome, len(scanners))
+	var wg sync.WaitGroup
+	for i, scanner := range scanners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcomes[i].findings, outcomes[i].err = scanner.Scan(ctx, urls)
+		}()
+	}
+	wg.Wait()
+
+	result := &model.ScanResult{Status: model.ScanClean, ScannedAt: time.Now().UTC()}
+	for i, o := range outcomes {
+		if o.err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", scanners[i].Name(), o.err))
+			continue
+		}
+		result.Findings = append(result.Findings, o.findings...)
+	}
+	switch {
+	case len(result.Findings) > 0:
+		result.Status = model.ScanFlagged
+	case len(result.Errors) > 0:
+		result.Status = model.ScanIncomplete
+	}
+	return result
+}
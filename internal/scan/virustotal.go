@@ -0,0 +1,88 @@
+package scan
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"registry/internal/model"
+)
+
+// virusTotalEndpoint is the URL reports endpoint of the VirusTotal v3 API
+const virusTotalEndpoint = "https://www.virustotal.com/api/v3/urls/"
+
+// VirusTotal checks URLs against the existing URL reports of VirusTotal. URLs are not
+// submitted for analysis, so URLs VirusTotal has never seen are not findings.
+type VirusTotal struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewVirusTotal returns a scanner using the VirusTotal API key apiKey
+func NewVirusTotal(apiKey string) *VirusTotal {
+	return &VirusTotal{apiKey: apiKey, endpoint: virusTotalEndpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name identifies the scanner
+func (v *VirusTotal) Name() string {
+	return "virustotal"
+}
+
+// Scan fetches the report of each URL. A URL is a finding when at least one engine of its
+// last analysis classified it as malicious.
+func (v *VirusTotal) Scan(ctx context.Context, urls []string) ([]model.ScanFinding, error) {
+	var findings []model.ScanFinding
+	for _, u := range urls {
+		malicious, err := v.maliciousVotes(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if malicious > 0 {
+			findings = append(findings, model.ScanFinding{
+				Scanner: v.Name(), URL: u, Threat: fmt.Sprintf("malicious according to %d engines", malicious),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// maliciousVotes returns how many engines classified u as malicious, or zero when
+// VirusTotal has no report for it
+func (v *VirusTotal) maliciousVotes(ctx context.Context, u string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.endpoint+base64.RawURLEncoding.EncodeToString([]byte(u)), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("x-apikey", v.apiKey)
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("status %d from VirusTotal", resp.StatusCode)
+	}
+
+	var report struct {
+		Data struct {
+			Attributes struct {
+				LastAnalysisStats struct {
+					Malicious int `json:"malicious"`
+				} `json:"last_analysis_stats"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return 0, fmt.Errorf("invalid VirusTotal response: %w", err)
+	}
+	return report.Data.Attributes.LastAnalysisStats.Malicious, nil
+}
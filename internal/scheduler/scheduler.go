@@ -0,0 +1,182 @@
+// Package scheduler runs the registry's background jobs on cron style schedules and
+// records the outcome of their last run
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"registry/internal/metrics"
+)
+
+// jobRuns counts job runs by outcome
+var jobRuns = metrics.NewCounterVec(
+	"mcp_registry_job_runs_total",
+	"Number of background job runs by job and outcome.",
+	"job", "status",
+)
+
+// Job is a background task run on a schedule
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Enabled is the configured on or off switch; disabled jobs are listed but never run
+	Enabled bool
+	// Condition is checked before each run, for example to run only on the elected leader.
+	// A nil Condition always allows the run.
+	Condition func() bool
+	// RunAtStart runs the job once as soon as the scheduler starts
+	RunAtStart bool
+	Run        func(ctx context.Context) error
+}
+
+// Outcome of a job run
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// RunStatus describes a job run
+type RunStatus struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobStatus describes a job and its last run
+type JobStatus struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Enabled  bool       `json:"enabled"`
+	Running  bool       `json:"running"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *RunStatus `json:"last_run,omitempty"`
+	Runs     int        `json:"runs"`
+	Failures int        `json:"failures"`
+}
+
+// Scheduler runs jobs, each in its own goroutine so a slow job never delays another.
+// Runs of the same job never overlap.
+type Scheduler struct {
+	jitter time.Duration
+
+	mu   sync.Mutex
+	jobs []*entry
+}
+
+type entry struct {
+	job    Job
+	status JobStatus
+}
+
+// New creates a scheduler delaying each run by a random duration of up to jitter, so
+// instances started together do not hit shared resources at the same moment
+func New(jitter time.Duration) *Scheduler {
+	return &Scheduler{jitter: jitter}
+}
+
+// Add registers a job; jobs must be added before Run is called
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &entry{
+		job:    job,
+		status: JobStatus{Name: job.Name, Schedule: job.Schedule.String(), Enabled: job.Enabled},
+	})
+}
+
+// Run starts the enabled jobs and returns once ctx is cancelled and every running job has returned
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	s.mu.Lock()
+	for _, e := range s.jobs {
+		if !e.job.Enabled {
+			continue
+		}
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	s.mu.Unlock()
+	wg.Wait()
+}
+
+// Jobs returns the status of every registered job by name
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]JobStatus, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		status := e.status
+		if status.LastRun != nil {
+			last := *status.LastRun
+			status.LastRun = &last
+		}
+		jobs = append(jobs, status)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	if e.job.RunAtStart {
+		s.run(ctx, e)
+	}
+	for {
+		next := e.job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Job %s has no future runs", e.job.Name)
+			return
+		}
+		if s.jitter > 0 {
+			next = next.Add(rand.N(s.jitter))
+		}
+		s.mu.Lock()
+		e.status.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.run(ctx, e)
+	}
+}
+
+// run runs a job once unless its condition prevents it
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	if e.job.Condition != nil && !e.job.Condition() {
+		return
+	}
+	started := time.Now()
+	s.mu.Lock()
+	e.status.Running = true
+	s.mu.Unlock()
+
+	err := e.job.Run(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.status.Running = false
+	e.status.Runs++
+	last := &RunStatus{StartedAt: started.UTC(), DurationMS: time.Since(started).Milliseconds(), Status: RunSucceeded}
+	if err != nil {
+		e.status.Failures++
+		last.Status, last.Error = RunFailed, err.Error()
+		if ctx.Err() == nil {
+			log.Printf("Job %s failed: %v", e.job.Name, err)
+		}
+	}
+	e.status.LastRun = last
+	jobRuns.Inc(e.job.Name, last.Status)
+}
@@ -0,0 +1,203 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// comparator is a single bound such as >=1.2.0
+type comparator struct {
+	op      string
+	version Version
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return cmp == 0
+}
+
+// Constraint is a version range: alternatives separated by ||, each a set of comparators
+// that must all hold
+type Constraint struct {
+	raw  string
+	sets [][]comparator
+}
+
+// ParseConstraint parses ranges such as ^1.2, ~1.2.3, 1.x, >=1.0 <2.0, 1.2 - 1.4,
+// =1.2.3 or * and combinations of them with || and commas
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return Constraint{}, fmt.Errorf("%w: empty constraint", ErrInvalidConstraint)
+	}
+	for _, alternative := range strings.Split(c.raw, "||") {
+		set, err := parseSet(alternative)
+		if err != nil {
+			return Constraint{}, err
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// String returns the constraint as written
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Exact returns the version the constraint pins when it is a single complete version, such
+// as =1.2.3 or 1.2.3
+func (c Constraint) Exact() (Version, bool) {
+	if len(c.sets) != 1 || len(c.sets[0]) != 1 || c.sets[0][0].op != "=" {
+		return Version{}, false
+	}
+	return c.sets[0][0].version, true
+}
+
+// Matches reports whether v satisfies the constraint. Prereleases only match alternatives
+// with a comparator on a prerelease of the same major, minor and patch, as in npm, so
+// ^1.2 never selects 1.3.0-beta.
+func (c Constraint) Matches(v Version) bool {
+	for _, set := range c.sets {
+		if matchesSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSet(set []comparator, v Version) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	if len(v.Prerelease) == 0 {
+		return true
+	}
+	for _, c := range set {
+		cv := c.version
+		if len(cv.Prerelease) > 0 && cv.Major == v.Major && cv.Minor == v.Minor && cv.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSet parses the comparators of one alternative
+func parseSet(s string) ([]comparator, error) {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: empty alternative", ErrInvalidConstraint)
+	}
+
+	// Hyphen ranges: 1.2 - 1.4 includes every 1.4.x
+	if len(fields) == 3 && fields[1] == "-" {
+		low, _, err := parsePartial(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConstraint, err)
+		}
+		high, parts, err := parsePartial(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConstraint, err)
+		}
+		set := []comparator{{">=", low}}
+		if parts == 3 {
+			return append(set, comparator{"<=", high}), nil
+		}
+		return append(set, comparator{"<", bump(high, parts)}), nil
+	}
+
+	var set []comparator
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		// Allow a space between an operator and its version, as in ">= 1.2"
+		if strings.Trim(field, "<>=~^") == "" && i+1 < len(fields) {
+			field += fields[i+1]
+			i++
+		}
+		comparators, err := parseComparator(field)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// parseComparator expands one term into the bounds it stands for
+func parseComparator(term string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	if term == "*" || term == "x" || term == "X" {
+		return []comparator{{">=", Version{}}}, nil
+	}
+	v, parts, err := parsePartial(strings.TrimPrefix(term, op))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConstraint, err)
+	}
+	if parts == 0 {
+		return []comparator{{">=", Version{}}}, nil
+	}
+
+	switch op {
+	case "^":
+		// Changes that do not modify the left-most non-zero part
+		var upper Version
+		switch {
+		case v.Major > 0 || parts == 1:
+			upper = Version{Major: v.Major + 1}
+		case v.Minor > 0 || parts == 2:
+			upper = Version{Minor: v.Minor + 1}
+		default:
+			upper = Version{Patch: v.Patch + 1}
+		}
+		return []comparator{{">=", v}, {"<", upper}}, nil
+	case "~":
+		// Patch changes, or minor changes when only the major is given
+		if parts == 1 {
+			return []comparator{{">=", v}, {"<", Version{Major: v.Major + 1}}}, nil
+		}
+		return []comparator{{">=", v}, {"<", Version{Major: v.Major, Minor: v.Minor + 1}}}, nil
+	case ">", "<=":
+		// >1.2 excludes every 1.2.x, <=1.2 includes them
+		if parts < 3 {
+			if op == ">" {
+				return []comparator{{">=", bump(v, parts)}}, nil
+			}
+			return []comparator{{"<", bump(v, parts)}}, nil
+		}
+		return []comparator{{op, v}}, nil
+	case ">=", "<":
+		return []comparator{{op, v}}, nil
+	}
+
+	// A bare or = version is exact when complete and a range over the missing parts otherwise
+	if parts == 3 {
+		return []comparator{{"=", v}}, nil
+	}
+	return []comparator{{">=", v}, {"<", bump(v, parts)}}, nil
+}
+
+// bump returns the lowest version above every version sharing the first parts numbers of v
+func bump(v Version, parts int) Version {
+	if parts == 1 {
+		return Version{Major: v.Major + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
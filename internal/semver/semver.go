@@ -0,0 +1,150 @@
+// Package semver parses semantic versions and the version range syntax used by npm and
+// Cargo, so installers can ask the registry for the best version matching a constraint
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned for versions that are not semantic versions
+var ErrInvalidVersion = errors.New("invalid semantic version")
+
+// ErrInvalidConstraint is returned for malformed version ranges
+var ErrInvalidConstraint = errors.New("invalid version constraint")
+
+// Version is a parsed semantic version. Build metadata is ignored.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          []string
+}
+
+// Parse parses a version such as 1.2.3, v1.2.3 or 1.2.3-rc.1+build. Missing minor and
+// patch numbers default to zero.
+func Parse(s string) (Version, error) {
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts < 3 && v.Prerelease != nil {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+	return v, nil
+}
+
+// parsePartial parses a possibly partial version, reporting how many numbers it has. A
+// part of x, X or * counts as missing.
+func parsePartial(s string) (Version, int, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	if s == "" {
+		return Version{}, 0, invalid
+	}
+
+	var v Version
+	numbers := strings.Split(s, ".")
+	if len(numbers) > 3 {
+		return Version{}, 0, invalid
+	}
+	parts := 0
+	for i, number := range numbers {
+		if number == "x" || number == "X" || number == "*" {
+			// Later parts must be wildcards too
+			for _, rest := range numbers[i:] {
+				if rest != "x" && rest != "X" && rest != "*" {
+					return Version{}, 0, invalid
+				}
+			}
+			break
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 0 || (len(number) > 1 && number[0] == '0') {
+			return Version{}, 0, invalid
+		}
+		switch i {
+		case 0:
+			v.Major = n
+		case 1:
+			v.Minor = n
+		case 2:
+			v.Patch = n
+		}
+		parts++
+	}
+
+	if hasPre {
+		if pre == "" {
+			return Version{}, 0, invalid
+		}
+		v.Prerelease = strings.Split(pre, ".")
+		for _, id := range v.Prerelease {
+			if id == "" {
+				return Version{}, 0, invalid
+			}
+		}
+	}
+	return v, parts, nil
+}
+
+// String formats v without build metadata
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or higher than other, following
+// semver precedence: a prerelease is lower than its release
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.Prerelease) - len(other.Prerelease))
+}
+
+// compareIdentifier orders prerelease identifiers: numeric ones numerically and below
+// alphanumeric ones, which are ordered lexically
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return sign(na - nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
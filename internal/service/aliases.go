@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/textnorm"
+)
+
+// AliasServer keeps name resolving to target after a server was renamed or moved to another
+// namespace. Aliases never chain: a target that is itself a former name is replaced by its
+// current name, and aliases pointing at name are repointed to target. Aliasing the current
+// name of a renamed server to its former name reverses the rename.
+func (s *registryServiceImpl) AliasServer(name, target string) (*model.ServerAlias, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	name, target = textnorm.NFC(name), textnorm.NFC(target)
+	slug := model.Slug(name)
+	if slug == "" || slug == model.Slug(target) {
+		return nil, fmt.Errorf("%w: an alias needs a name with a slug different from its target", database.ErrInvalidInput)
+	}
+
+	existing, err := s.db.GetAlias(ctx, model.Slug(target))
+	switch {
+	case err == nil && model.Slug(existing.Target) == slug:
+		if err := s.db.DeleteAlias(ctx, existing.Slug); err != nil {
+			return nil, err
+		}
+	case err == nil:
+		target = existing.Target
+	case !errors.Is(err, database.ErrNotFound):
+		return nil, err
+	}
+
+	count, err := s.db.Count(ctx, map[string]interface{}{"name": target})
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, database.ErrNotFound
+	}
+
+	alias := &model.ServerAlias{Slug: slug, Name: name, Target: target, CreatedAt: time.Now().UTC()}
+	if err := s.db.SetAlias(ctx, alias); err != nil {
+		return nil, err
+	}
+
+	aliases, err := s.db.ListAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range aliases {
+		if a.Target == name {
+			a.Target = target
+			if err := s.db.SetAlias(ctx, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return alias, nil
+}
+
+// RemoveAlias stops resolving the former server name
+func (s *registryServiceImpl) RemoveAlias(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.DeleteAlias(ctx, model.Slug(textnorm.NFC(name)))
+}
+
+// Aliases returns every alias by former name
+func (s *registryServiceImpl) Aliases() ([]*model.ServerAlias, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.ListAliases(ctx)
+}
+
+// ResolveAlias returns the alias of the former server name with the given slug
+func (s *registryServiceImpl) ResolveAlias(slug string) (*model.ServerAlias, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.GetAlias(ctx, slug)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"registry/internal/database"
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed, were signed with another key
+// or were issued for a listing with a different filter or order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorMACSize is the number of HMAC bytes kept in a cursor
+const cursorMACSize = 16
+
+// cursorCodec turns store positions into opaque signed cursors, so clients can't forge
+// positions or carry a cursor over to a listing with other filters
+type cursorCodec struct {
+	key []byte
+}
+
+// newCursorCodec signs cursors with key, or with a random key when key is empty
+func newCursorCodec(key []byte) (*cursorCodec, error) {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generating cursor key: %w", err)
+		}
+	}
+	return &cursorCodec{key: key}, nil
+}
+
+// cursorPayload is the signed content of a cursor
+type cursorPayload struct {
+	// Position is the sort key of the last entry of the previous page
+	Position database.Position `json:"p"`
+	// Scope binds the cursor to the listing's filter and order
+	Scope string `json:"s"`
+}
+
+// cursorScope identifies a listing. Filter maps print with sorted keys, so equal filters
+// give equal scopes.
+func cursorScope(filter map[string]interface{}, order database.SortOrder) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%v", order, filter)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encode returns the cursor resuming after position in the listing identified by scope
+func (c *cursorCodec) encode(position *database.Position, scope string) string {
+	payload, _ := json.Marshal(cursorPayload{Position: *position, Scope: scope})
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.sign(body))
+}
+
+// decode verifies a cursor and returns the position it holds
+func (c *cursorCodec) decode(cursor, scope string) (*database.Position, error) {
+	body, mac, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	got, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(got, c.sign(body)) {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Scope != scope {
+		return nil, ErrInvalidCursor
+	}
+	return &payload.Position, nil
+}
+
+// sign returns the truncated HMAC of a cursor body
+func (c *cursorCodec) sign(body string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)[:cursorMACSize]
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"registry/internal/database"
+)
+
+func TestCursorCodec(t *testing.T) {
+	codec, err := newCursorCodec([]byte("cursor-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newCursorCodec([]byte("other-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter := map[string]interface{}{"name": "io.github.acme", "version": "latest"}
+	scope := cursorScope(filter, database.SortByName)
+	position := &database.Position{ID: "a1b2", Name: "io.github.acme/server"}
+	cursor := codec.encode(position, scope)
+	body, mac, _ := strings.Cut(cursor, ".")
+
+	forgedBody := base64.RawURLEncoding.EncodeToString([]byte(`{"p":{"id":"zzzz"},"s":"` + scope + `"}`))
+	macBytes, _ := base64.RawURLEncoding.DecodeString(mac)
+
+	tests := []struct {
+		name   string
+		cursor string
+		scope  string
+	}{
+		{"empty", "", scope},
+		{"no signature", body, scope},
+		{"not base64", "!!!." + mac, scope},
+		{"forged position", forgedBody + "." + mac, scope},
+		{"truncated signature", body + "." + base64.RawURLEncoding.EncodeToString(macBytes[:cursorMACSize/2]), scope},
+		{"empty signature", body + ".", scope},
+		{"signed with another key", other.encode(position, scope), scope},
+		{"other filter", cursor, cursorScope(map[string]interface{}{"name": "io.github.other", "version": "latest"}, database.SortByName)},
+		{"unfiltered", cursor, cursorScope(map[string]interface{}{}, database.SortByName)},
+		{"other order", cursor, cursorScope(filter, database.SortByCreatedAt)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.decode(tt.cursor, tt.scope); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decode(%q) error = %v, want %v", tt.cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+
+	got, err := codec.decode(cursor, cursorScope(map[string]interface{}{"version": "latest", "name": "io.github.acme"}, database.SortByName))
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if *got != *position {
+		t.Errorf("decode() = %+v, want %+v", got, position)
+	}
+}
+
+func TestCursorCodecRandomKey(t *testing.T) {
+	first, err := newCursorCodec(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newCursorCodec(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope := cursorScope(nil, database.SortByID)
+	cursor := first.encode(&database.Position{ID: "a1b2"}, scope)
+	if _, err := first.decode(cursor, scope); err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	// Replicas without a shared secret reject each other's cursors
+	if _, err := second.decode(cursor, scope); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("decode() with another random key error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
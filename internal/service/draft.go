@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"registry/internal/database"
+	"registry/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// validateServer returns every problem that makes Publish reject serverDetail, apart from
+// conflicts with versions already in the registry
+func validateServer(serverDetail *model.ServerDetail) []model.ValidationIssue {
+	var issues []model.ValidationIssue
+	add := func(field, format string, args ...interface{}) {
+		issues = append(issues, model.ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if serverDetail.Name == "" {
+		add("name", "name is required")
+	}
+	if serverDetail.VersionDetail.Version == "" {
+		add("version_detail.version", "version is required")
+	}
+	if !serverDetail.Visibility.IsValid() {
+		add("visibility", "visibility must be public, unlisted or private")
+	}
+
+	for i, pkg := range serverDetail.Packages {
+		if err := pkg.ValidateCompatibility(); err != nil {
+			add(fmt.Sprintf("packages[%d]", i), "packages[%d]: %v", i, err)
+		}
+	}
+	for i, transport := range serverDetail.Transports {
+		if err := transport.Validate(); err != nil {
+			add(fmt.Sprintf("transports[%d]", i), "transports[%d]: %v", i, err)
+		}
+	}
+
+	if len(serverDetail.Readme) > model.MaxReadmeBytes {
+		add("readme", "readme exceeds %d bytes", model.MaxReadmeBytes)
+	}
+	if !utf8.ValidString(serverDetail.Readme) {
+		add("readme", "readme is not valid UTF-8")
+	}
+	if len(serverDetail.Changelog) > model.MaxChangelogBytes {
+		add("changelog", "changelog exceeds %d bytes", model.MaxChangelogBytes)
+	}
+	if !utf8.ValidString(serverDetail.Changelog) {
+		add("changelog", "changelog is not valid UTF-8")
+	}
+
+	return issues
+}
+
+// Validate reports every problem that would prevent serverDetail from being published,
+// including a version that already exists or is older than the server's latest version
+func (s *registryServiceImpl) Validate(serverDetail *model.ServerDetail) (*model.ValidationReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	issues := validateServer(serverDetail)
+
+	name, err := s.normalizeName(serverDetail.Name)
+	if err != nil {
+		return nil, err
+	}
+	version := serverDetail.VersionDetail.Version
+	if name != "" && version != "" {
+		var latest string
+		err := s.db.Iterate(ctx, map[string]interface{}{"name": name}, func(entry *model.ServerDetail) error {
+			if entry.VersionDetail.Version == version {
+				issues = append(issues, model.ValidationIssue{
+					Field:   "version_detail.version",
+					Message: fmt.Sprintf("version %s is already published", version),
+				})
+			}
+			if latest == "" || database.CompareSemanticVersions(entry.VersionDetail.Version, latest) > 0 {
+				latest = entry.VersionDetail.Version
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if latest != "" && latest != version && database.CompareSemanticVersions(version, latest) < 0 {
+			issues = append(issues, model.ValidationIssue{
+				Field:   "version_detail.version",
+				Message: fmt.Sprintf("version must be newer than the latest published version %s", latest),
+			})
+		}
+	}
+
+	if issues == nil {
+		issues = []model.ValidationIssue{}
+	}
+	return &model.ValidationReport{Valid: len(issues) == 0, Issues: issues}, nil
+}
+
+// CreateDraft saves serverDetail as a new draft. Drafts are stored as submitted; they are
+// only validated by Validate and when published.
+func (s *registryServiceImpl) CreateDraft(serverDetail *model.ServerDetail) (*model.Draft, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	name, err := s.normalizeName(serverDetail.Name)
+	if err != nil {
+		return nil, err
+	}
+	serverDetail.Name = name
+	now := time.Now().UTC()
+	draft := &model.Draft{ID: uuid.New().String(), Server: *serverDetail, CreatedAt: now, UpdatedAt: now}
+	if err := s.db.CreateDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// UpdateDraft replaces the content of a draft. The server name of a draft cannot change.
+func (s *registryServiceImpl) UpdateDraft(id string, serverDetail *model.ServerDetail) (*model.Draft, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	draft, err := s.db.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.normalizeName(serverDetail.Name)
+	if err != nil {
+		return nil, err
+	}
+	if name != draft.Server.Name {
+		return nil, fmt.Errorf("%w: the name of a draft cannot change", database.ErrInvalidInput)
+	}
+
+	draft.Server = *serverDetail
+	draft.Server.Name = name
+	draft.UpdatedAt = time.Now().UTC()
+	if err := s.db.UpdateDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// Draft retrieves a draft by ID
+func (s *registryServiceImpl) Draft(id string) (*model.Draft, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.GetDraft(ctx, id)
+}
+
+// Drafts returns the drafts for a server name, oldest first
+func (s *registryServiceImpl) Drafts(name string) ([]*model.Draft, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	name, err := s.normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.ListDrafts(ctx, name)
+}
+
+// DeleteDraft discards a draft
+func (s *registryServiceImpl) DeleteDraft(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.DeleteDraft(ctx, id)
+}
+
+// PublishDraft publishes a draft and discards it, returning the published version
+func (s *registryServiceImpl) PublishDraft(id string) (*model.ServerDetail, error) {
+	draft, err := s.Draft(id)
+	if err != nil {
+		return nil, err
+	}
+
+	serverDetail := draft.Server
+	serverDetail.ID = ""
+	if err := s.Publish(&serverDetail); err != nil {
+		return nil, err
+	}
+
+	// The version is live at this point, so a draft left behind is only reported
+	if err := s.DeleteDraft(id); err != nil {
+		log.Printf("Failed to delete published draft %s: %v", id, err)
+	}
+	return &serverDetail, nil
+}
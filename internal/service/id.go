@@ -0,0 +1,31 @@
+package service
+
+import "github.com/google/uuid"
+
+// IDFormat selects how the registry generates the IDs of published versions
+type IDFormat string
+
+const (
+	// IDFormatUUIDv4 generates random UUIDs
+	IDFormatUUIDv4 IDFormat = "uuidv4"
+	// IDFormatUUIDv7 generates UUIDs prefixed with their creation time, so IDs sort in
+	// publication order and index inserts stay local
+	IDFormatUUIDv7 IDFormat = "uuidv7"
+)
+
+// IsValid reports whether f is a supported ID format
+func (f IDFormat) IsValid() bool {
+	return f == IDFormatUUIDv4 || f == IDFormatUUIDv7
+}
+
+// newID generates an ID in format f, falling back to a random UUID for an unset format
+func (f IDFormat) newID() (string, error) {
+	if f == IDFormatUUIDv7 {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	}
+	return uuid.NewString(), nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"registry/internal/model"
+)
+
+// maxCachedListings bounds the first pages kept by the listing cache; when it is full the
+// cache is cleared rather than tracking which pages are least used
+const maxCachedListings = 256
+
+// CachePolicy configures the cache of listing first pages
+type CachePolicy struct {
+	// ListingTTL is how long a first page is served from memory; zero disables the cache
+	ListingTTL time.Duration
+}
+
+// cachedListing is a first page as List returned it
+type cachedListing struct {
+	entries    []model.Server
+	nextCursor string
+	expires    time.Time
+}
+
+// listingCache keeps the first pages of listings, which receive most of the traffic, so
+// they are served without a database round trip. Writes through the service clear it;
+// writes made elsewhere, such as by another replica, show once the TTL expires.
+type listingCache struct {
+	ttl time.Duration
+	// version changes on every invalidation, so caches built on listings can tell when
+	// they are stale
+	version atomic.Uint64
+
+	mu    sync.Mutex
+	pages map[string]cachedListing
+}
+
+func newListingCache(ttl time.Duration) *listingCache {
+	return &listingCache{ttl: ttl, pages: make(map[string]cachedListing)}
+}
+
+// listingKey identifies the first page of the listing with scope holding limit entries
+func listingKey(scope string, limit int) string {
+	return fmt.Sprintf("%s|%d", scope, limit)
+}
+
+// get returns a copy of the cached page for key, if it has not expired
+func (c *listingCache) get(key string) ([]model.Server, string, bool) {
+	if c.ttl <= 0 {
+		return nil, "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page, ok := c.pages[key]
+	if !ok || time.Now().After(page.expires) {
+		return nil, "", false
+	}
+	return append([]model.Server(nil), page.entries...), page.nextCursor, true
+}
+
+// put caches a copy of a first page under key, unless listings were invalidated since
+// version, when the page was read
+func (c *listingCache) put(key string, version uint64, entries []model.Server, nextCursor string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version.Load() != version {
+		return
+	}
+	if len(c.pages) >= maxCachedListings {
+		clear(c.pages)
+	}
+	c.pages[key] = cachedListing{
+		entries:    append([]model.Server(nil), entries...),
+		nextCursor: nextCursor,
+		expires:    time.Now().Add(c.ttl),
+	}
+}
+
+// invalidate drops every cached page
+func (c *listingCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.pages)
+	c.version.Add(1)
+}
+
+// InvalidateListings drops the cached first pages of listings
+func (s *registryServiceImpl) InvalidateListings() {
+	s.listings.invalidate()
+}
+
+// ListingsVersion returns a number that changes whenever listings are invalidated, by a
+// write through the service or by InvalidateListings
+func (s *registryServiceImpl) ListingsVersion() uint64 {
+	return s.listings.version.Load()
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/textnorm"
+)
+
+// ErrAmbiguousSlug is returned when a slug resolves to more than one server name
+var ErrAmbiguousSlug = errors.New("slug matches several servers")
+
+// normalizeName returns the name a version submitted under name is stored under. New names
+// are normalized by model.NormalizeName; a name already stored in another form, such as one
+// registered before names were normalized, is kept so its publishers can add versions.
+func (s *registryServiceImpl) normalizeName(name string) (string, error) {
+	name = textnorm.NFC(name)
+	normalized := model.NormalizeName(name)
+	if normalized == name {
+		return name, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	count, err := s.db.Count(ctx, map[string]interface{}{"name": name})
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return name, nil
+	}
+	return normalized, nil
+}
+
+// GetBySlug retrieves the highest version of the server whose name has the given slug that
+// is not yanked and for which keep returns true, as LatestVersionWhere does. It fails with
+// ErrAmbiguousSlug when names differing only in punctuation share the slug, counting every
+// version, and with database.ErrNotFound when no version qualifies.
+func (s *registryServiceImpl) GetBySlug(slug string, keep func(*model.ServerDetail) bool) (*model.ServerDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	var best *model.ServerDetail
+	names := make(map[string]bool)
+	err := s.db.Iterate(ctx, map[string]interface{}{"slug": slug}, func(entry *model.ServerDetail) error {
+		names[entry.Name] = true
+		if entry.VersionDetail.Yanked || !keep(entry) {
+			return nil
+		}
+		if best == nil || database.CompareSemanticVersions(entry.VersionDetail.Version, best.VersionDetail.Version) > 0 {
+			best = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 1 {
+		matches := make([]string, 0, len(names))
+		for name := range names {
+			matches = append(matches, name)
+		}
+		sort.Strings(matches)
+		return nil, fmt.Errorf("%w: %v", ErrAmbiguousSlug, matches)
+	}
+	if best == nil {
+		return nil, database.ErrNotFound
+	}
+	return best, nil
+}
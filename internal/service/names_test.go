@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+func TestGetBySlug(t *testing.T) {
+	registry, db := newTestRegistry(t)
+
+	publishTestVersion(t, db, "io.github.acme/yanked-top", "1.0.0", "")
+	yanked := publishTestVersion(t, db, "io.github.acme/yanked-top", "2.0.0", "")
+	publishTestVersion(t, db, "io.github.acme/private-top", "1.0.0", "")
+	publishTestVersion(t, db, "io.github.acme/private-top", "2.0.0", model.VisibilityPrivate)
+	allYanked := publishTestVersion(t, db, "io.github.acme/all-yanked", "1.0.0", "")
+	publishTestVersion(t, db, "a.b/c", "1.0.0", "")
+	publishTestVersion(t, db, "a-b/c", "1.0.0", "")
+	for _, id := range []string{yanked.ID, allYanked.ID} {
+		if err := registry.Yank(id, "broken"); err != nil {
+			t.Fatalf("Yank(%s): %v", id, err)
+		}
+	}
+
+	everyone := func(*model.ServerDetail) bool { return true }
+	public := func(version *model.ServerDetail) bool {
+		return version.Visibility.Effective() != model.VisibilityPrivate
+	}
+	tests := []struct {
+		name    string
+		slug    string
+		keep    func(*model.ServerDetail) bool
+		want    string
+		wantErr error
+	}{
+		{"yanked top version", "io-github-acme-yanked-top", everyone, "1.0.0", nil},
+		{"private top version hidden", "io-github-acme-private-top", public, "1.0.0", nil},
+		{"private top version visible", "io-github-acme-private-top", everyone, "2.0.0", nil},
+		{"every version yanked", "io-github-acme-all-yanked", everyone, "", database.ErrNotFound},
+		{"unknown slug", "io-github-acme-unknown", everyone, "", database.ErrNotFound},
+		{"ambiguous slug", "a-b-c", everyone, "", ErrAmbiguousSlug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.GetBySlug(tt.slug, tt.keep)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetBySlug(%s) error = %v, want %v", tt.slug, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBySlug(%s) error = %v", tt.slug, err)
+			}
+			if got.VersionDetail.Version != tt.want {
+				t.Errorf("GetBySlug(%s) = %s, want %s", tt.slug, got.VersionDetail.Version, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"registry/internal/model"
+)
+
+// ErrOrgArchived is returned when publishing under an archived organization
+var ErrOrgArchived = errors.New("organization is archived")
+
+// ArchiveOrg archives org, hiding its servers from listings and rejecting new publishes.
+// Archiving an archived organization replaces its reason.
+func (s *registryServiceImpl) ArchiveOrg(org, reason string) (*model.ArchivedOrg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	archived := &model.ArchivedOrg{Org: strings.ToLower(org), Reason: reason, ArchivedAt: time.Now().UTC()}
+	if err := s.db.ArchiveOrg(ctx, archived); err != nil {
+		return nil, err
+	}
+	s.listings.invalidate()
+	return archived, nil
+}
+
+// UnarchiveOrg restores an archived organization
+func (s *registryServiceImpl) UnarchiveOrg(org string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	defer s.listings.invalidate()
+	return s.db.UnarchiveOrg(ctx, strings.ToLower(org))
+}
+
+// ArchivedOrgs returns the archived organizations by name
+func (s *registryServiceImpl) ArchivedOrgs() ([]*model.ArchivedOrg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.ListArchivedOrgs(ctx)
+}
+
+// listable returns a copy of filter restricted to the versions listings show: public
+// versions of organizations that are not archived
+func (s *registryServiceImpl) listable(ctx context.Context, filter map[string]interface{}) (map[string]interface{}, error) {
+	listed := publicOnly(filter)
+	archived, err := s.db.ListArchivedOrgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) > 0 {
+		excluded := make([]string, len(archived))
+		for i, a := range archived {
+			excluded[i] = a.Org
+		}
+		listed["exclude_authors"] = excluded
+	}
+	return listed, nil
+}
+
+// checkArchived returns ErrOrgArchived when serverDetail is published under an archived
+// organization: from one of its repositories, or in its namespace. The repository URL is
+// supplied by the client, so the namespace, which publishing authorizes, is checked as well.
+func (s *registryServiceImpl) checkArchived(ctx context.Context, serverDetail *model.ServerDetail) error {
+	orgs := []string{
+		strings.ToLower(model.ExtractAuthorFromRepoURL(serverDetail.Repository.URL)),
+		namespaceOrg(serverDetail.Name),
+	}
+	archived, err := s.db.ListArchivedOrgs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range archived {
+		for _, org := range orgs {
+			if org != "" && a.Org == org {
+				return fmt.Errorf("%w: %s", ErrOrgArchived, org)
+			}
+		}
+	}
+	return nil
+}
+
+// namespaceOrg returns the organization owning the namespace of name, in lower case: the
+// GitHub owner of an io.github namespace, and the namespace itself otherwise
+func namespaceOrg(name string) string {
+	namespace, _, ok := strings.Cut(strings.ToLower(name), "/")
+	if !ok {
+		return ""
+	}
+	if owner, found := strings.CutPrefix(namespace, "io.github."); found {
+		return owner
+	}
+	return namespace
+}
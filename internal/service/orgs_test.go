@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"registry/internal/model"
+)
+
+func TestCheckArchived(t *testing.T) {
+	registry, _ := newTestRegistry(t)
+	for _, org := range []string{"Acme", "com.example"} {
+		if _, err := registry.ArchiveOrg(org, "sunset"); err != nil {
+			t.Fatalf("ArchiveOrg(%s): %v", org, err)
+		}
+	}
+	impl := registry.(*registryServiceImpl)
+
+	tests := []struct {
+		name    string
+		server  string
+		repoURL string
+		want    error
+	}{
+		{"repository of an archived org", "io.github.other/server", "https://github.com/acme/server", ErrOrgArchived},
+		{"github namespace of an archived org", "io.github.ACME/server", "https://github.com/other/server", ErrOrgArchived},
+		{"archived namespace", "com.example/server", "https://github.com/other/server", ErrOrgArchived},
+		{"active org", "io.github.other/server", "https://github.com/other/server", nil},
+		{"namespace prefixed by an archived org", "io.github.acme-labs/server", "https://github.com/acme-labs/server", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverDetail := &model.ServerDetail{Server: model.Server{
+				Name:       tt.server,
+				Repository: model.Repository{URL: tt.repoURL},
+			}}
+			if err := impl.checkArchived(context.Background(), serverDetail); !errors.Is(err, tt.want) {
+				t.Errorf("checkArchived(%s, %s) = %v, want %v", tt.server, tt.repoURL, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"registry/internal/model"
+)
+
+// DefaultQuotaRole applies to publishers without an assigned role
+const DefaultQuotaRole = "default"
+
+// Quota names reported in QuotaError
+const (
+	QuotaEntries        = "entries"
+	QuotaVersionsPerDay = "versions_per_day"
+	QuotaBytesPerDay    = "bytes_per_day"
+)
+
+// QuotaLimits bounds what one publisher may publish. Zero fields are unlimited.
+type QuotaLimits struct {
+	// Entries bounds the distinct server names of the publisher
+	Entries int
+	// VersionsPerDay bounds the versions published per UTC day
+	VersionsPerDay int
+	// BytesPerDay bounds the JSON size of the versions published per UTC day
+	BytesPerDay int64
+}
+
+// QuotaPolicy assigns publishers, the namespaces of server names, to roles and roles to
+// limits. The namespace is what publishing authorizes; the repository URL is supplied by the
+// client and proves nothing.
+type QuotaPolicy struct {
+	Roles map[string]QuotaLimits
+	// Members maps lower case publishers to their role
+	Members map[string]string
+}
+
+// NewQuotaPolicy builds a policy from per-role limits and publisher=role assignments
+func NewQuotaPolicy(entries, versionsPerDay map[string]int, bytesPerDay map[string]int64, members map[string]string) QuotaPolicy {
+	policy := QuotaPolicy{Roles: make(map[string]QuotaLimits), Members: make(map[string]string, len(members))}
+	for role, n := range entries {
+		limits := policy.Roles[role]
+		limits.Entries = n
+		policy.Roles[role] = limits
+	}
+	for role, n := range versionsPerDay {
+		limits := policy.Roles[role]
+		limits.VersionsPerDay = n
+		policy.Roles[role] = limits
+	}
+	for role, n := range bytesPerDay {
+		limits := policy.Roles[role]
+		limits.BytesPerDay = n
+		policy.Roles[role] = limits
+	}
+	for publisher, role := range members {
+		policy.Members[strings.ToLower(publisher)] = role
+	}
+	return policy
+}
+
+// limits returns the role of publisher and its limits
+func (p QuotaPolicy) limits(publisher string) (string, QuotaLimits) {
+	role, ok := p.Members[strings.ToLower(publisher)]
+	if !ok {
+		role = DefaultQuotaRole
+	}
+	return role, p.Roles[role]
+}
+
+// QuotaError reports a publish rejected because the publisher exhausted a quota
+type QuotaError struct {
+	Publisher string `json:"publisher"`
+	Role      string `json:"role"`
+	Quota     string `json:"quota"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota exceeded: %s of publisher %s (role %s) is limited to %d, %d used",
+		e.Quota, e.Publisher, e.Role, e.Limit, e.Used)
+}
+
+// Status is the HTTP status of the error: daily quotas reset and are 429, the entry quota
+// does not and is 403
+func (e *QuotaError) Status() int {
+	if e.Quota == QuotaEntries {
+		return http.StatusForbidden
+	}
+	return http.StatusTooManyRequests
+}
+
+// checkQuota returns a *QuotaError when publishing serverDetail would exceed a quota of its
+// publisher, the namespace of its name. Names without a namespace are not limited.
+func (s *registryServiceImpl) checkQuota(ctx context.Context, serverDetail *model.ServerDetail) error {
+	publisher, _, ok := strings.Cut(serverDetail.Name, "/")
+	if !ok || publisher == "" {
+		return nil
+	}
+	role, limits := s.quotas.limits(publisher)
+	if limits == (QuotaLimits{}) {
+		return nil
+	}
+
+	payload, err := json.Marshal(serverDetail)
+	if err != nil {
+		return err
+	}
+	today := time.Now().UTC().Format(time.DateOnly)
+	names := make(map[string]bool)
+	var versions, size int64
+	err = s.db.Iterate(ctx, map[string]interface{}{"namespace": publisher}, func(entry *model.ServerDetail) error {
+		names[entry.Name] = true
+		released, err := time.Parse(time.RFC3339, entry.VersionDetail.ReleaseDate)
+		if err != nil || released.UTC().Format(time.DateOnly) != today {
+			return nil
+		}
+		versions++
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		size += int64(len(data))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	exceeded := func(quota string, limit, used int64) error {
+		return &QuotaError{Publisher: publisher, Role: role, Quota: quota, Limit: limit, Used: used}
+	}
+	switch {
+	case limits.Entries > 0 && !names[serverDetail.Name] && len(names) >= limits.Entries:
+		return exceeded(QuotaEntries, int64(limits.Entries), int64(len(names)))
+	case limits.VersionsPerDay > 0 && versions >= int64(limits.VersionsPerDay):
+		return exceeded(QuotaVersionsPerDay, int64(limits.VersionsPerDay), versions)
+	case limits.BytesPerDay > 0 && size+int64(len(payload)) > limits.BytesPerDay:
+		return exceeded(QuotaBytesPerDay, limits.BytesPerDay, size)
+	}
+	return nil
+}
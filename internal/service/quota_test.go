@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+func TestCheckQuotaKeysOnNamespace(t *testing.T) {
+	db := database.NewMemoryDB(map[string]*model.Server{})
+	policy := NewQuotaPolicy(map[string]int{DefaultQuotaRole: 1}, nil, nil, nil)
+	registry, err := NewRegistryServiceWithDB(db, Timeouts{}, IDFormatUUIDv4, nil, policy, ScanPolicy{}, CachePolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	impl := registry.(*registryServiceImpl)
+
+	// Owned by io.github.acme, with a repository of github.com/acme
+	publishTestVersion(t, db, "io.github.acme/first", "1.0.0", "")
+
+	tests := []struct {
+		name      string
+		server    string
+		repoURL   string
+		publisher string
+	}{
+		{"new name in a full namespace", "io.github.acme/second", "https://github.com/acme/second", "io.github.acme"},
+		{"repository URL of another owner", "io.github.acme/second", "https://github.com/someone-else/second", "io.github.acme"},
+		{"repository URL of the full namespace's owner", "io.github.other/first", "https://github.com/acme/server", ""},
+		{"new version of an existing name", "io.github.acme/first", "https://github.com/acme/server", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverDetail := &model.ServerDetail{Server: model.Server{
+				Name:          tt.server,
+				Repository:    model.Repository{URL: tt.repoURL},
+				VersionDetail: model.VersionDetail{Version: "2.0.0"},
+			}}
+			err := impl.checkQuota(context.Background(), serverDetail)
+			var quotaErr *QuotaError
+			switch {
+			case tt.publisher == "" && err != nil:
+				t.Errorf("checkQuota(%s) = %v, want nil", tt.server, err)
+			case tt.publisher != "" && !errors.As(err, &quotaErr):
+				t.Errorf("checkQuota(%s) = %v, want a quota error", tt.server, err)
+			case tt.publisher != "" && quotaErr.Publisher != tt.publisher:
+				t.Errorf("checkQuota(%s) publisher = %s, want %s", tt.server, quotaErr.Publisher, tt.publisher)
+			}
+		})
+	}
+}
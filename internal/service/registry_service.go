@@ -2,29 +2,98 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"registry/internal/database"
 	"registry/internal/model"
+	"registry/internal/sanitize"
+	"registry/internal/semver"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// Timeouts bounds the database operations issued by the service
+type Timeouts struct {
+	// Operation bounds single reads and writes
+	Operation time.Duration
+	// Stream bounds streaming operations, which may walk the whole registry
+	Stream time.Duration
+}
+
+// DefaultTimeouts are used for any timeout left unset
+var DefaultTimeouts = Timeouts{
+	Operation: 5 * time.Second,
+	Stream:    5 * time.Minute,
+}
+
 // registryServiceImpl implements the RegistryService interface using our Database
 type registryServiceImpl struct {
-	db database.Database
+	db       database.Database
+	timeouts Timeouts
+	idFormat IDFormat
+	cursors  *cursorCodec
+	quotas   QuotaPolicy
+	scans    ScanPolicy
+	listings *listingCache
+
+	reindexMu sync.Mutex
+	reindex   ReindexStatus
+
+	statsMu sync.Mutex
+	stats   *model.RegistryStats
+
+	sitemapMu      sync.Mutex
+	sitemap        *model.Sitemap
+	sitemapVersion uint64
 }
 
-// NewRegistryServiceWithDB creates a new registry service with the provided database
+// NewRegistryServiceWithDB creates a new registry service with the provided database,
+// generating the IDs of published versions in idFormat and signing listing cursors with
+// cursorKey. An empty cursorKey is replaced by a random key, so cursors are only valid for
+// the lifetime of the process. Publishes are limited by quotas, and their URLs checked as
+// set by scans. The first pages of listings are cached as set by caches.
 //
 //nolint:ireturn // Factory function intentionally returns interface for dependency injection
-func NewRegistryServiceWithDB(db database.Database) RegistryService {
-	return &registryServiceImpl{
-		db: db,
+func NewRegistryServiceWithDB(
+	db database.Database, timeouts Timeouts, idFormat IDFormat, cursorKey []byte, quotas QuotaPolicy, scans ScanPolicy,
+	caches CachePolicy,
+) (RegistryService, error) {
+	if timeouts.Operation <= 0 {
+		timeouts.Operation = DefaultTimeouts.Operation
+	}
+	if timeouts.Stream <= 0 {
+		timeouts.Stream = DefaultTimeouts.Stream
+	}
+	cursors, err := newCursorCodec(cursorKey)
+	if err != nil {
+		return nil, err
 	}
+	return &registryServiceImpl{
+		db:       db,
+		timeouts: timeouts,
+		idFormat: idFormat,
+		cursors:  cursors,
+		quotas:   quotas,
+		scans:    scans,
+		listings: newListingCache(caches.ListingTTL),
+	}, nil
 }
 
-// List returns registry entries with cursor-based pagination
-func (s *registryServiceImpl) List(cursor string, limit int) ([]model.Server, string, error) {
+// List returns registry entries matching the filter with cursor-based pagination
+func (s *registryServiceImpl) List(
+	filter map[string]interface{},
+	cursor string,
+	limit int,
+	order database.SortOrder,
+) ([]model.Server, string, error) {
 	// Create a timeout context for the database operation
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
 	defer cancel()
 
 	// If limit is not set or negative, use a default limit
@@ -32,25 +101,62 @@ func (s *registryServiceImpl) List(cursor string, limit int) ([]model.Server, st
 		limit = 30
 	}
 
+	// Cursors are signed keyset positions, bound to the filter and order they were issued for
+	scope := cursorScope(filter, order)
+	var after *database.Position
+	if cursor != "" {
+		var err error
+		if after, err = s.cursors.decode(cursor, scope); err != nil {
+			return nil, "", err
+		}
+	} else if entries, next, ok := s.listings.get(listingKey(scope, limit)); ok {
+		return entries, next, nil
+	}
+	version := s.listings.version.Load()
+
+	listed, err := s.listable(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Use the database's List method with pagination
-	entries, nextCursor, err := s.db.List(ctx, nil, cursor, limit)
+	entries, next, err := s.db.List(ctx, listed, order, after, limit)
 	if err != nil {
 		return nil, "", err
 	}
+	nextCursor := ""
+	if next != nil {
+		nextCursor = s.cursors.encode(next, scope)
+	}
 
 	// Convert from []*model.Server to []model.Server
 	result := make([]model.Server, len(entries))
 	for i, entry := range entries {
 		result[i] = *entry
 	}
+	if cursor == "" {
+		s.listings.put(listingKey(scope, limit), version, result, nextCursor)
+	}
 
 	return result, nextCursor, nil
 }
 
+// Count returns the number of public versions List would return for filter across all pages
+func (s *registryServiceImpl) Count(filter map[string]interface{}) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	listed, err := s.listable(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return s.db.Count(ctx, listed)
+}
+
 // GetByID retrieves a specific server detail by its ID
 func (s *registryServiceImpl) GetByID(id string) (*model.ServerDetail, error) {
 	// Create a timeout context for the database operation
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
 	defer cancel()
 
 	// Use the database's GetByID method to retrieve the server detail
@@ -62,20 +168,547 @@ func (s *registryServiceImpl) GetByID(id string) (*model.ServerDetail, error) {
 	return serverDetail, nil
 }
 
+// GetVersion retrieves a specific version of the server identified by id, which may be
+// the ID of any of the server's versions
+func (s *registryServiceImpl) GetVersion(id, version string) (*model.ServerDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	serverDetail, err := s.db.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if serverDetail.VersionDetail.Version == version {
+		return serverDetail, nil
+	}
+
+	var match *model.ServerDetail
+	filter := map[string]interface{}{"name": serverDetail.Name, "version": version}
+	err = s.db.Iterate(ctx, filter, func(entry *model.ServerDetail) error {
+		match = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, database.ErrNotFound
+	}
+
+	return match, nil
+}
+
+// LatestVersion retrieves the latest version of the server identified by id, which may be
+// the ID of any of the server's versions. It returns database.ErrNotFound when every
+// version of the server is yanked.
+func (s *registryServiceImpl) LatestVersion(id string) (*model.ServerDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	serverDetail, err := s.db.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if serverDetail.VersionDetail.IsLatest {
+		return serverDetail, nil
+	}
+
+	var latest *model.ServerDetail
+	filter := map[string]interface{}{"name": serverDetail.Name, "is_latest": true}
+	err = s.db.Iterate(ctx, filter, func(entry *model.ServerDetail) error {
+		latest = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, database.ErrNotFound
+	}
+
+	return latest, nil
+}
+
+// LatestVersionWhere retrieves the highest version of the server identified by id that is
+// not yanked and for which keep returns true, such as the latest version a caller may see.
+// It returns database.ErrNotFound when no version qualifies.
+func (s *registryServiceImpl) LatestVersionWhere(id string, keep func(*model.ServerDetail) bool) (*model.ServerDetail, error) {
+	latest, err := s.LatestVersion(id)
+	if err != nil || keep(latest) {
+		return latest, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	var best *model.ServerDetail
+	err = s.db.Iterate(ctx, map[string]interface{}{"name": latest.Name}, func(entry *model.ServerDetail) error {
+		if entry.VersionDetail.Yanked || !keep(entry) {
+			return nil
+		}
+		if best == nil || database.CompareSemanticVersions(entry.VersionDetail.Version, best.VersionDetail.Version) > 0 {
+			best = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if best == nil {
+		return nil, database.ErrNotFound
+	}
+	return best, nil
+}
+
 // Publish adds a new server detail to the registry
 func (s *registryServiceImpl) Publish(serverDetail *model.ServerDetail) error {
+	if serverDetail == nil {
+		return database.ErrInvalidInput
+	}
+
+	// Spellings of a name differing in case, spacing or canonical form must not become
+	// distinct servers
+	name, err := s.normalizeName(serverDetail.Name)
+	if err != nil {
+		return err
+	}
+	serverDetail.Name = name
+
+	if issues := validateServer(serverDetail); len(issues) > 0 {
+		return fmt.Errorf("%w: %s", database.ErrInvalidInput, issues[0].Message)
+	}
+
+	if serverDetail.Visibility == model.VisibilityPublic {
+		serverDetail.Visibility = ""
+	}
+	sanitizeContent(serverDetail)
+
+	// Versions published with PublishWithID keep the ID chosen by the client
+	if serverDetail.ID == "" {
+		id, err := s.idFormat.newID()
+		if err != nil {
+			return fmt.Errorf("error generating ID: %w", err)
+		}
+		serverDetail.ID = id
+	}
+
+	// Scanners call external services, so they run before the database deadline starts
+	if err := s.scanVersion(serverDetail); err != nil {
+		return err
+	}
+
 	// Create a timeout context for the database operation
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
 	defer cancel()
 
+	if err := s.checkArchived(ctx, serverDetail); err != nil {
+		return err
+	}
+	if err := s.checkQuota(ctx, serverDetail); err != nil {
+		return err
+	}
+
+	if err := s.db.Publish(ctx, serverDetail); err != nil {
+		return err
+	}
+	s.listings.invalidate()
+	s.reportFindings(ctx, serverDetail)
+
+	return nil
+}
+
+// sanitizeContent strips active content from the text fields of a version, so catalog
+// frontends rendering them are safe from stored XSS. The published text is kept in Raw.
+func sanitizeContent(serverDetail *model.ServerDetail) {
+	raw := model.RawContent{}
+	if clean := sanitize.Text(serverDetail.Description); clean != serverDetail.Description {
+		raw.Description, serverDetail.Description = serverDetail.Description, clean
+	}
+	if clean := sanitize.Markdown(serverDetail.Readme); clean != serverDetail.Readme {
+		raw.Readme, serverDetail.Readme = serverDetail.Readme, clean
+	}
+	if clean := sanitize.Markdown(serverDetail.Changelog); clean != serverDetail.Changelog {
+		raw.Changelog, serverDetail.Changelog = serverDetail.Changelog, clean
+	}
+	serverDetail.Raw = nil
+	if raw != (model.RawContent{}) {
+		serverDetail.Raw = &raw
+	}
+}
+
+// PublishWithID publishes serverDetail under an ID chosen by the client. Repeating the
+// request is idempotent: when id already holds the same name and version, the stored
+// version is returned with created set to false. An id holding any other version fails
+// with ErrAlreadyExists.
+func (s *registryServiceImpl) PublishWithID(id string, serverDetail *model.ServerDetail) (*model.ServerDetail, bool, error) {
 	if serverDetail == nil {
-		return database.ErrInvalidInput
+		return nil, false, database.ErrInvalidInput
+	}
+	id, ok := model.CanonicalID(id)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: id must be a UUID", database.ErrInvalidInput)
+	}
+
+	existing, err := s.existingVersion(id, serverDetail)
+	if existing != nil || err != nil {
+		return existing, false, err
+	}
+
+	serverDetail.ID = id
+	err = s.Publish(serverDetail)
+	if errors.Is(err, database.ErrAlreadyExists) {
+		// A concurrent request with the same ID may have published the version first
+		if existing, getErr := s.existingVersion(id, serverDetail); existing != nil {
+			return existing, false, nil
+		} else if getErr != nil {
+			return nil, false, getErr
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return serverDetail, true, nil
+}
+
+// existingVersion returns the version stored under id when it matches the name and version
+// of serverDetail, nil when id is unused, and ErrAlreadyExists when id holds another version
+func (s *registryServiceImpl) existingVersion(id string, serverDetail *model.ServerDetail) (*model.ServerDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	existing, err := s.db.GetByID(ctx, id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.normalizeName(serverDetail.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Name != name || existing.VersionDetail.Version != serverDetail.VersionDetail.Version {
+		return nil, fmt.Errorf("%w: id %s is used by %s %s", database.ErrAlreadyExists, id, existing.Name, existing.VersionDetail.Version)
+	}
+	return existing, nil
+}
+
+// GetManifest retrieves the immutable manifest of a published version by its digest
+func (s *registryServiceImpl) GetManifest(digest string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.GetManifest(ctx, digest)
+}
+
+// maxYankReasonLength bounds the reason recorded when yanking a version
+const maxYankReasonLength = 500
+
+// Yank hides a version from listings and latest resolution while keeping it resolvable by ID
+func (s *registryServiceImpl) Yank(id, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	if len(reason) > maxYankReasonLength {
+		return fmt.Errorf("%w: reason exceeds %d bytes", database.ErrInvalidInput, maxYankReasonLength)
 	}
 
-	err := s.db.Publish(ctx, serverDetail)
+	defer s.listings.invalidate()
+	return s.db.SetYanked(ctx, id, true, reason)
+}
+
+// Unyank restores a previously yanked version
+func (s *registryServiceImpl) Unyank(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	defer s.listings.invalidate()
+	return s.db.SetYanked(ctx, id, false, "")
+}
+
+// StreamLatest calls fn for the latest version of every server matching filter,
+// as entries are read from the database
+func (s *registryServiceImpl) StreamLatest(filter map[string]interface{}, fn func(model.Server) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	defer cancel()
+
+	latest, err := s.listable(ctx, filter)
 	if err != nil {
 		return err
 	}
+	latest["is_latest"] = true
 
-	return nil
+	return s.db.Iterate(ctx, latest, func(entry *model.ServerDetail) error {
+		return fn(entry.Server)
+	})
+}
+
+// Export calls fn for every public server detail in the registry matching filter, including
+// previous versions. With active, yanked versions and the versions of archived organizations
+// are left out, as they are from listings.
+func (s *registryServiceImpl) Export(filter map[string]interface{}, active bool, fn func(*model.ServerDetail) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	defer cancel()
+
+	exported := publicOnly(filter)
+	if active {
+		var err error
+		if exported, err = s.listable(ctx, filter); err != nil {
+			return err
+		}
+		exported["yanked"] = false
+	}
+	return s.db.Iterate(ctx, exported, fn)
+}
+
+// publicOnly returns a copy of filter restricted to public versions. Unlisted and private
+// versions are only served by ID.
+func publicOnly(filter map[string]interface{}) map[string]interface{} {
+	public := map[string]interface{}{"visibility": model.VisibilityPublic}
+	for k, v := range filter {
+		public[k] = v
+	}
+	return public
+}
+
+// Changes returns up to limit change log entries after a revision or, when sinceTime is set, after a time
+func (s *registryServiceImpl) Changes(sinceRevision int64, sinceTime time.Time, limit int) ([]*model.Change, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.ListChanges(ctx, sinceRevision, sinceTime, limit)
+}
+
+// HeadRevision returns the revision of the most recent change
+func (s *registryServiceImpl) HeadRevision() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.HeadRevision(ctx)
+}
+
+// StoreStats reports diagnostics about the underlying database
+func (s *registryServiceImpl) StoreStats() (*database.StoreStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.Stats(ctx)
+}
+
+// CollectGarbage prunes stale operational records according to policy
+func (s *registryServiceImpl) CollectGarbage(policy database.RetentionPolicy) (*database.GCReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	defer cancel()
+
+	defer s.listings.invalidate()
+	return s.db.CollectGarbage(ctx, policy)
+}
+
+// AuthorProfile summarizes every server version published from the author's repositories
+func (s *registryServiceImpl) AuthorProfile(author string) (*model.AuthorProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	profile := &model.AuthorProfile{Author: author}
+	names := make(map[string]bool)
+	filter, err := s.listable(ctx, map[string]interface{}{"author": author})
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Iterate(ctx, filter, func(entry *model.ServerDetail) error {
+		names[entry.Name] = true
+		profile.VersionCount++
+
+		released := entry.VersionDetail.ReleaseDate
+		if profile.FirstPublished == "" || released < profile.FirstPublished {
+			profile.FirstPublished = released
+		}
+		if released > profile.LastPublished {
+			profile.LastPublished = released
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.VersionCount == 0 {
+		return nil, database.ErrNotFound
+	}
+	profile.ServerCount = len(names)
+
+	return profile, nil
+}
+
+// SaveSearch stores a new saved search, assigning its ID, creation time and webhook secret
+func (s *registryServiceImpl) SaveSearch(search *model.SavedSearch) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	search.ID = uuid.New().String()
+	search.CreatedAt = time.Now().UTC()
+	search.Secret = hex.EncodeToString(secret)
+	search.EmailConfirmed = false
+	if search.Email != "" {
+		// The address only receives notifications once its owner proves they read it
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			return err
+		}
+		search.EmailToken = hex.EncodeToString(token)
+		search.EmailTokenHash = hashEmailToken(search.EmailToken)
+	}
+
+	return s.db.CreateSavedSearch(ctx, search)
+}
+
+// SavedSearches returns the owner's saved searches without their webhook secrets
+func (s *registryServiceImpl) SavedSearches(owner string) ([]*model.SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	searches, err := s.db.ListSavedSearches(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, search := range searches {
+		search.Secret = ""
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch removes one of the owner's saved searches
+func (s *registryServiceImpl) DeleteSavedSearch(owner, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.DeleteSavedSearch(ctx, owner, id)
+}
+
+// ConfirmSavedSearchEmail confirms the email address of one of the owner's saved searches
+// with the token mailed to it
+func (s *registryServiceImpl) ConfirmSavedSearchEmail(owner, id, token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.ConfirmSavedSearchEmail(ctx, owner, id, hashEmailToken(token))
+}
+
+// hashEmailToken returns the stored form of an email confirmation token
+func hashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FeatureServer features the named server with the given ordering weight. Higher weights
+// are listed first.
+func (s *registryServiceImpl) FeatureServer(name string, weight int) (*model.FeaturedServer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	entries, _, err := s.db.List(ctx, map[string]interface{}{"name": name}, database.SortByID, nil, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, database.ErrNotFound
+	}
+
+	featured := &model.FeaturedServer{Name: name, Weight: weight, FeaturedAt: time.Now().UTC()}
+	if err := s.db.SetFeatured(ctx, featured); err != nil {
+		return nil, err
+	}
+	return featured, nil
+}
+
+// UnfeatureServer stops featuring the named server
+func (s *registryServiceImpl) UnfeatureServer(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.DeleteFeatured(ctx, name)
+}
+
+// FeaturedEntries returns the curation entries in listing order
+func (s *registryServiceImpl) FeaturedEntries() ([]*model.FeaturedServer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.ListFeatured(ctx)
+}
+
+// FeaturedServers returns the latest version of every featured server in curated order.
+// Servers without a latest version, for example because every version was yanked, are skipped.
+func (s *registryServiceImpl) FeaturedServers() ([]model.Server, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	featured, err := s.db.ListFeatured(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]model.Server, 0, len(featured))
+	for _, f := range featured {
+		filter, err := s.listable(ctx, map[string]interface{}{"name": f.Name, "is_latest": true})
+		if err != nil {
+			return nil, err
+		}
+		entries, _, err := s.db.List(ctx, filter, database.SortByID, nil, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) > 0 {
+			servers = append(servers, *entries[0])
+		}
+	}
+	return servers, nil
+}
+
+// ResolveVersions returns the versions of the server with the given version ID that
+// satisfy constraint, best first. Versions that are not semantic versions never match, and
+// yanked versions only match a constraint pinning them exactly, so existing pins keep
+// resolving while ranges move past them.
+func (s *registryServiceImpl) ResolveVersions(id string, constraint semver.Constraint) ([]*model.ServerDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	serverDetail, err := s.db.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		entry   *model.ServerDetail
+		version semver.Version
+	}
+	_, exact := constraint.Exact()
+	var candidates []candidate
+	err = s.db.Iterate(ctx, map[string]interface{}{"name": serverDetail.Name}, func(entry *model.ServerDetail) error {
+		if entry.VersionDetail.Yanked && !exact {
+			return nil
+		}
+		version, err := semver.Parse(entry.VersionDetail.Version)
+		if err != nil || !constraint.Matches(version) {
+			return nil
+		}
+		candidates = append(candidates, candidate{entry: entry, version: version})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].version.Compare(candidates[j].version) > 0
+	})
+	result := make([]*model.ServerDetail, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.entry
+	}
+	return result, nil
 }
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"registry/internal/database"
+)
+
+// Reindex job states
+const (
+	JobIdle      = "idle"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// reindexLease keeps instances sharing a database from rebuilding indexes concurrently
+const reindexLease = "reindex"
+
+// ReindexStatus reports the progress of the most recent index rebuild on this instance
+type ReindexStatus struct {
+	State        string     `json:"state"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	IndexesDone  int        `json:"indexes_done"`
+	IndexesTotal int        `json:"indexes_total"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// StartReindex rebuilds the search indexes in the background. It returns ErrConflict when
+// a rebuild is already running on this or another instance.
+func (s *registryServiceImpl) StartReindex() (ReindexStatus, error) {
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+
+	if s.reindex.State == JobRunning {
+		return s.reindex, fmt.Errorf("%w: reindex already running", database.ErrConflict)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	holder := database.NewLeaseHolder()
+	acquired, err := s.db.AcquireLease(ctx, reindexLease, holder, s.timeouts.Stream)
+	if err != nil {
+		cancel()
+		return s.reindex, err
+	}
+	if !acquired {
+		cancel()
+		return s.reindex, fmt.Errorf("%w: reindex already running on another instance", database.ErrConflict)
+	}
+
+	started := time.Now().UTC()
+	s.reindex = ReindexStatus{State: JobRunning, StartedAt: &started}
+
+	go func() {
+		defer cancel()
+
+		err := s.db.Reindex(ctx, func(done, total int) {
+			s.reindexMu.Lock()
+			s.reindex.IndexesDone, s.reindex.IndexesTotal = done, total
+			s.reindexMu.Unlock()
+		})
+
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+		defer releaseCancel()
+		if err := s.db.ReleaseLease(releaseCtx, reindexLease, holder); err != nil {
+			log.Printf("Failed to release reindex lease: %v", err)
+		}
+
+		s.reindexMu.Lock()
+		defer s.reindexMu.Unlock()
+		finished := time.Now().UTC()
+		s.reindex.FinishedAt = &finished
+		if err != nil {
+			log.Printf("Reindex failed: %v", err)
+			s.reindex.State = JobFailed
+			s.reindex.Error = err.Error()
+			return
+		}
+		log.Printf("Reindex completed in %s", finished.Sub(started).Round(time.Millisecond))
+		s.reindex.State = JobCompleted
+	}()
+
+	return s.reindex, nil
+}
+
+// ReindexStatus reports the progress of the most recent index rebuild
+func (s *registryServiceImpl) ReindexStatus() ReindexStatus {
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+
+	if s.reindex.State == "" {
+		return ReindexStatus{State: JobIdle}
+	}
+	return s.reindex
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// ReportServer files an abuse report against the version report.ServerID names. The
+// report enters the moderation queue as open.
+func (s *registryServiceImpl) ReportServer(report *model.AbuseReport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	report.Details = strings.TrimSpace(report.Details)
+	report.Contact = strings.TrimSpace(report.Contact)
+	switch {
+	case !report.Reason.Valid():
+		return fmt.Errorf("%w: reason must be one of spam, malware, impersonation, illegal or other", database.ErrInvalidInput)
+	case report.Reason == model.ReportOther && report.Details == "":
+		return fmt.Errorf("%w: details are required when the reason is other", database.ErrInvalidInput)
+	case len(report.Details) > model.MaxReportDetailsBytes:
+		return fmt.Errorf("%w: details must be at most %d bytes", database.ErrInvalidInput, model.MaxReportDetailsBytes)
+	case len(report.Contact) > model.MaxReportContactBytes:
+		return fmt.Errorf("%w: contact must be at most %d bytes", database.ErrInvalidInput, model.MaxReportContactBytes)
+	}
+
+	serverDetail, err := s.db.GetByID(ctx, report.ServerID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	report.ID = uuid.New().String()
+	report.ServerName = serverDetail.Name
+	report.Status = model.ReportOpen
+	report.Note = ""
+	report.CreatedAt, report.UpdatedAt = now, now
+	return s.db.CreateReport(ctx, report)
+}
+
+// Reports returns up to limit abuse reports, oldest first, optionally only those with the
+// given status
+func (s *registryServiceImpl) Reports(status model.ReportStatus, limit int) ([]*model.AbuseReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	if status != "" && !status.Valid() {
+		return nil, fmt.Errorf("%w: unknown report status %q", database.ErrInvalidInput, status)
+	}
+	return s.db.ListReports(ctx, status, limit)
+}
+
+// Report returns the abuse report with the given ID
+func (s *registryServiceImpl) Report(id string) (*model.AbuseReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.GetReport(ctx, id)
+}
+
+// UpdateReport moves an abuse report to status, recording the moderator's note. An empty
+// note keeps the current one.
+func (s *registryServiceImpl) UpdateReport(id string, status model.ReportStatus, note string) (*model.AbuseReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	if !status.Valid() {
+		return nil, fmt.Errorf("%w: status must be one of open, reviewing, resolved or dismissed", database.ErrInvalidInput)
+	}
+	report, err := s.db.GetReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	report.Status = status
+	if note != "" {
+		report.Note = note
+	}
+	report.UpdatedAt = time.Now().UTC()
+	if err := s.db.UpdateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
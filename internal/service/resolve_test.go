@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"registry/internal/semver"
+)
+
+func TestResolveVersionsYanked(t *testing.T) {
+	registry, db := newTestRegistry(t)
+
+	first := publishTestVersion(t, db, "io.github.acme/server", "1.0.0", "")
+	yanked := publishTestVersion(t, db, "io.github.acme/server", "1.1.0", "")
+	publishTestVersion(t, db, "io.github.acme/server", "1.2.0", "")
+	if err := registry.Yank(yanked.ID, "broken"); err != nil {
+		t.Fatalf("Yank(%s): %v", yanked.ID, err)
+	}
+
+	tests := []struct {
+		constraint string
+		want       []string
+	}{
+		{"=1.1.0", []string{"1.1.0"}},
+		{"1.1.0", []string{"1.1.0"}},
+		{"^1.0", []string{"1.2.0", "1.0.0"}},
+		{"1.1", nil},
+		{">=1.1.0 <=1.1.0", nil},
+		{"=1.1.0 || =1.0.0", []string{"1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := semver.ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%s): %v", tt.constraint, err)
+			}
+			got, err := registry.ResolveVersions(first.ID, constraint)
+			if err != nil {
+				t.Fatalf("ResolveVersions(%s): %v", tt.constraint, err)
+			}
+			var versions []string
+			for _, entry := range got {
+				versions = append(versions, entry.VersionDetail.Version)
+			}
+			if len(versions) != len(tt.want) {
+				t.Fatalf("ResolveVersions(%s) = %v, want %v", tt.constraint, versions, tt.want)
+			}
+			for i := range versions {
+				if versions[i] != tt.want[i] {
+					t.Errorf("ResolveVersions(%s) = %v, want %v", tt.constraint, versions, tt.want)
+					break
+				}
+			}
+			if len(got) == 1 && got[0].ID == yanked.ID && got[0].VersionDetail.YankedReason != "broken" {
+				t.Errorf("ResolveVersions(%s) yanked reason = %q, want %q", tt.constraint, got[0].VersionDetail.YankedReason, "broken")
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"registry/internal/model"
+	"registry/internal/scan"
+)
+
+// ErrBlockedByScan is returned when a URL of a version is flagged by a scanner and the
+// scan policy blocks such versions
+var ErrBlockedByScan = errors.New("version blocked by URL scan")
+
+// ScanPolicy selects the URL scanners run on publish and what happens to versions they flag
+type ScanPolicy struct {
+	Scanners []scan.Scanner
+	// Block rejects flagged versions; otherwise they are published and reported for review
+	Block bool
+	// Timeout bounds a scan, across all scanners
+	Timeout time.Duration
+}
+
+// scanVersion checks the URLs of serverDetail and records the result on it. Versions with
+// findings fail with ErrBlockedByScan when the policy blocks them. Scanner failures never
+// fail a publish.
+func (s *registryServiceImpl) scanVersion(serverDetail *model.ServerDetail) error {
+	serverDetail.Scan = nil
+	if len(s.scans.Scanners) == 0 {
+		return nil
+	}
+	urls := scan.URLs(serverDetail)
+	if len(urls) == 0 {
+		return nil
+	}
+
+	timeout := s.scans.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result := scan.Run(ctx, s.scans.Scanners, urls)
+	for _, scanErr := range result.Errors {
+		log.Printf("URL scan of %s %s incomplete: %s", serverDetail.Name, serverDetail.VersionDetail.Version, scanErr)
+	}
+	if result.Status == model.ScanFlagged && s.scans.Block {
+		finding := result.Findings[0]
+		return fmt.Errorf("%w: %s is reported as %s by %s", ErrBlockedByScan, finding.URL, finding.Threat, finding.Scanner)
+	}
+	serverDetail.Scan = result
+	return nil
+}
+
+// reportFindings queues a flagged version for moderators as an abuse report
+func (s *registryServiceImpl) reportFindings(ctx context.Context, serverDetail *model.ServerDetail) {
+	if serverDetail.Scan == nil || serverDetail.Scan.Status != model.ScanFlagged {
+		return
+	}
+	lines := make([]string, len(serverDetail.Scan.Findings))
+	for i, finding := range serverDetail.Scan.Findings {
+		lines[i] = fmt.Sprintf("%s: %s (%s)", finding.Scanner, finding.URL, finding.Threat)
+	}
+	details := "Flagged by URL scan on publish:\n" + strings.Join(lines, "\n")
+	if len(details) > model.MaxReportDetailsBytes {
+		details = details[:model.MaxReportDetailsBytes]
+	}
+
+	now := time.Now().UTC()
+	report := &model.AbuseReport{
+		ID:         uuid.New().String(),
+		ServerID:   serverDetail.ID,
+		ServerName: serverDetail.Name,
+		Reason:     model.ReportMalware,
+		Details:    details,
+		Contact:    "url-scan",
+		Status:     model.ReportOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.db.CreateReport(ctx, report); err != nil {
+		log.Printf("Failed to report URL scan findings for %s: %v", serverDetail.ID, err)
+	}
+}
@@ -1,10 +1,72 @@
 package service
 
-import "registry/internal/model"
+import (
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/semver"
+	"time"
+)
 
 // RegistryService defines the interface for registry operations
 type RegistryService interface {
-	List(cursor string, limit int) ([]model.Server, string, error)
+	List(filter map[string]interface{}, cursor string, limit int, order database.SortOrder) ([]model.Server, string, error)
+	Count(filter map[string]interface{}) (int, error)
+	InvalidateListings()
+	ListingsVersion() uint64
 	GetByID(id string) (*model.ServerDetail, error)
+	GetVersion(id, version string) (*model.ServerDetail, error)
+	LatestVersion(id string) (*model.ServerDetail, error)
+	LatestVersionWhere(id string, keep func(*model.ServerDetail) bool) (*model.ServerDetail, error)
+	GetBySlug(slug string, keep func(*model.ServerDetail) bool) (*model.ServerDetail, error)
+	ResolveVersions(id string, constraint semver.Constraint) ([]*model.ServerDetail, error)
+	GetManifest(digest string) ([]byte, error)
 	Publish(serverDetail *model.ServerDetail) error
+	PublishWithID(id string, serverDetail *model.ServerDetail) (*model.ServerDetail, bool, error)
+	Yank(id, reason string) error
+	Unyank(id string) error
+	StreamLatest(filter map[string]interface{}, fn func(model.Server) error) error
+	Export(filter map[string]interface{}, active bool, fn func(*model.ServerDetail) error) error
+	Changes(sinceRevision int64, sinceTime time.Time, limit int) ([]*model.Change, error)
+	HeadRevision() (int64, error)
+	StoreStats() (*database.StoreStats, error)
+	CollectGarbage(policy database.RetentionPolicy) (*database.GCReport, error)
+	StartReindex() (ReindexStatus, error)
+	ReindexStatus() ReindexStatus
+	AuthorProfile(author string) (*model.AuthorProfile, error)
+	Stats() (*model.RegistryStats, error)
+	Sitemap() (*model.Sitemap, error)
+	SaveSearch(search *model.SavedSearch) error
+	SavedSearches(owner string) ([]*model.SavedSearch, error)
+	DeleteSavedSearch(owner, id string) error
+	ConfirmSavedSearchEmail(owner, id, token string) error
+	Validate(serverDetail *model.ServerDetail) (*model.ValidationReport, error)
+	CreateDraft(serverDetail *model.ServerDetail) (*model.Draft, error)
+	UpdateDraft(id string, serverDetail *model.ServerDetail) (*model.Draft, error)
+	Draft(id string) (*model.Draft, error)
+	Drafts(name string) ([]*model.Draft, error)
+	DeleteDraft(id string) error
+	PublishDraft(id string) (*model.ServerDetail, error)
+	FeatureServer(name string, weight int) (*model.FeaturedServer, error)
+	UnfeatureServer(name string) error
+	FeaturedEntries() ([]*model.FeaturedServer, error)
+	FeaturedServers() ([]model.Server, error)
+	AliasServer(name, target string) (*model.ServerAlias, error)
+	RemoveAlias(name string) error
+	Aliases() ([]*model.ServerAlias, error)
+	ResolveAlias(slug string) (*model.ServerAlias, error)
+	ArchiveOrg(org, reason string) (*model.ArchivedOrg, error)
+	UnarchiveOrg(org string) error
+	ArchivedOrgs() ([]*model.ArchivedOrg, error)
+	RevokeToken(digest, login string) error
+	TokenRevoked(digest string) (bool, error)
+	CreatePublishToken(serverName, createdBy, description string) (*model.PublishToken, error)
+	PublishToken(secret string) (*model.PublishToken, error)
+	PublishTokens(serverName string) ([]*model.PublishToken, error)
+	DeletePublishToken(serverName, id string) error
+	ReportServer(report *model.AbuseReport) error
+	Reports(status model.ReportStatus, limit int) ([]*model.AbuseReport, error)
+	Report(id string) (*model.AbuseReport, error)
+	UpdateReport(id string, status model.ReportStatus, note string) (*model.AbuseReport, error)
+	PlanSync(org string, desired []*model.ServerDetail) (*model.SyncPlan, error)
+	ApplySync(plan *model.SyncPlan) error
 }
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"registry/internal/database"
+	"registry/internal/model"
+)
+
+// newTestRegistry returns a registry service over an empty memory database
+func newTestRegistry(t *testing.T) (RegistryService, *database.MemoryDB) {
+	t.Helper()
+	db := database.NewMemoryDB(map[string]*model.Server{})
+	registry, err := NewRegistryServiceWithDB(db, Timeouts{}, IDFormatUUIDv4, nil, QuotaPolicy{}, ScanPolicy{}, CachePolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry, db
+}
+
+// publishTestVersion publishes a version straight to db, bypassing validation
+func publishTestVersion(t *testing.T, db database.Database, name, version string, visibility model.Visibility) *model.ServerDetail {
+	t.Helper()
+	serverDetail := &model.ServerDetail{Server: model.Server{
+		Name:          name,
+		Repository:    model.Repository{URL: "https://github.com/acme/server"},
+		VersionDetail: model.VersionDetail{Version: version},
+		Visibility:    visibility,
+	}}
+	if err := db.Publish(context.Background(), serverDetail); err != nil {
+		t.Fatalf("publishing %s %s: %v", name, version, err)
+	}
+	return serverDetail
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"registry/internal/model"
+)
+
+// sitemapCacheTTL is how long the sitemap is reused while listings are unchanged, since
+// computing it walks every listed server
+const sitemapCacheTTL = time.Minute
+
+// Sitemap returns the servers listings show, each by the slug of its name, computed at most
+// sitemapCacheTTL ago and never before the last change to listings
+func (s *registryServiceImpl) Sitemap() (*model.Sitemap, error) {
+	s.sitemapMu.Lock()
+	defer s.sitemapMu.Unlock()
+
+	version := s.ListingsVersion()
+	if s.sitemap != nil && s.sitemapVersion == version && time.Since(s.sitemap.GeneratedAt) < sitemapCacheTTL {
+		return s.sitemap, nil
+	}
+	sitemap, err := s.computeSitemap()
+	if err != nil {
+		return nil, err
+	}
+	s.sitemap, s.sitemapVersion = sitemap, version
+	return sitemap, nil
+}
+
+// computeSitemap collects the latest listed version of every server whose slug leads to it
+func (s *registryServiceImpl) computeSitemap() (*model.Sitemap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	defer cancel()
+
+	filter, err := s.listable(ctx, map[string]interface{}{"is_latest": true, "yanked": false})
+	if err != nil {
+		return nil, err
+	}
+	lastModified := make(map[string]string)
+	err = s.db.Iterate(ctx, filter, func(entry *model.ServerDetail) error {
+		slug := model.Slug(entry.Name)
+		if slug == "" {
+			return nil
+		}
+		if modified, ok := lastModified[slug]; !ok || entry.VersionDetail.ReleaseDate > modified {
+			lastModified[slug] = entry.VersionDetail.ReleaseDate
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Names differing only in case or punctuation share a slug, and the detail page of such
+	// a slug fails with ErrAmbiguousSlug, so it is left out. GetBySlug counts every version,
+	// including hidden and yanked ones, and so does this pass.
+	names := make(map[string]map[string]bool, len(lastModified))
+	err = s.db.Iterate(ctx, map[string]interface{}{}, func(entry *model.ServerDetail) error {
+		slug := model.Slug(entry.Name)
+		if _, ok := lastModified[slug]; !ok {
+			return nil
+		}
+		if names[slug] == nil {
+			names[slug] = make(map[string]bool)
+		}
+		names[slug][entry.Name] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for slug, slugNames := range names {
+		if len(slugNames) > 1 {
+			delete(lastModified, slug)
+		}
+	}
+
+	sitemap := &model.Sitemap{Entries: make([]model.SitemapEntry, 0, len(lastModified))}
+	for slug, modified := range lastModified {
+		sitemap.Entries = append(sitemap.Entries, model.SitemapEntry{Slug: slug, LastModified: modified})
+	}
+	sort.Slice(sitemap.Entries, func(i, j int) bool { return sitemap.Entries[i].Slug < sitemap.Entries[j].Slug })
+	sitemap.GeneratedAt = time.Now().UTC()
+	return sitemap, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"registry/internal/model"
+)
+
+// statsCacheTTL is how long registry statistics are reused, since computing them walks
+// every public version
+const statsCacheTTL = time.Minute
+
+// Stats returns statistics over the public versions, computed at most statsCacheTTL ago
+func (s *registryServiceImpl) Stats() (*model.RegistryStats, error) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	if s.stats != nil && time.Since(s.stats.GeneratedAt) < statsCacheTTL {
+		return s.stats, nil
+	}
+	stats, err := s.computeStats()
+	if err != nil {
+		return nil, err
+	}
+	s.stats = stats
+	return stats, nil
+}
+
+// computeStats aggregates every public version in one pass
+func (s *registryServiceImpl) computeStats() (*model.RegistryStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	defer cancel()
+
+	stats := &model.RegistryStats{PackageRegistries: make(map[string]int)}
+	firstRelease := make(map[string]time.Time)
+	weeks := make(map[string]*model.WeeklyStats)
+	week := func(t time.Time) *model.WeeklyStats {
+		t = t.UTC().Truncate(24 * time.Hour)
+		// Weekday counts from Sunday; shift so weeks start on Monday
+		t = t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+		key := t.Format(time.DateOnly)
+		if weeks[key] == nil {
+			weeks[key] = &model.WeeklyStats{Week: key}
+		}
+		return weeks[key]
+	}
+
+	err := s.db.Iterate(ctx, publicOnly(nil), func(entry *model.ServerDetail) error {
+		stats.Versions++
+		if entry.VersionDetail.Yanked {
+			stats.YankedVersions++
+		}
+		if entry.VersionDetail.IsLatest {
+			stats.ActiveServers++
+			registries := make(map[string]bool)
+			for _, pkg := range entry.Packages {
+				if pkg.RegistryName != "" && !registries[pkg.RegistryName] {
+					registries[pkg.RegistryName] = true
+					stats.PackageRegistries[pkg.RegistryName]++
+				}
+			}
+		}
+
+		first, seen := firstRelease[entry.Name]
+		released, err := time.Parse(time.RFC3339, entry.VersionDetail.ReleaseDate)
+		if err != nil {
+			// Keep counting the server even when its release date can't be placed in a week
+			if !seen {
+				firstRelease[entry.Name] = time.Time{}
+			}
+			return nil
+		}
+		week(released).Versions++
+		if !seen || first.IsZero() || released.Before(first) {
+			firstRelease[entry.Name] = released
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Servers = len(firstRelease)
+	for _, first := range firstRelease {
+		if !first.IsZero() {
+			week(first).NewServers++
+		}
+	}
+	stats.Weekly = make([]model.WeeklyStats, 0, len(weeks))
+	for _, w := range weeks {
+		stats.Weekly = append(stats.Weekly, *w)
+	}
+	sort.Slice(stats.Weekly, func(i, j int) bool { return stats.Weekly[i].Week < stats.Weekly[j].Week })
+	stats.GeneratedAt = time.Now().UTC()
+	return stats, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"registry/internal/database"
+	"registry/internal/model"
+	"registry/internal/textnorm"
+)
+
+// SyncYankReason is recorded on versions yanked because their server left the desired state
+const SyncYankReason = "Removed by declarative sync"
+
+// syncVersions holds the stored versions of one server
+type syncVersions struct {
+	latest  *model.ServerDetail
+	byValue map[string]*model.ServerDetail
+	live    []string
+}
+
+func (v *syncVersions) add(entry *model.ServerDetail) {
+	if v.byValue == nil {
+		v.byValue = make(map[string]*model.ServerDetail)
+	}
+	v.byValue[entry.VersionDetail.Version] = entry
+	if !entry.VersionDetail.Yanked {
+		v.live = append(v.live, entry.ID)
+	}
+	if v.latest == nil || database.CompareSemanticVersions(entry.VersionDetail.Version, v.latest.VersionDetail.Version) > 0 {
+		v.latest = entry
+	}
+}
+
+// PlanSync computes the steps bringing the servers published from org's repositories to
+// desired. Servers missing from desired are deleted, that is, their versions are yanked.
+func (s *registryServiceImpl) PlanSync(org string, desired []*model.ServerDetail) (*model.SyncPlan, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Stream)
+	defer cancel()
+
+	stored := make(map[string]*syncVersions)
+	versionsOf := func(name string) *syncVersions {
+		if stored[name] == nil {
+			stored[name] = &syncVersions{}
+		}
+		return stored[name]
+	}
+	err := s.db.Iterate(ctx, map[string]interface{}{"author": org}, func(entry *model.ServerDetail) error {
+		versionsOf(entry.Name).add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]bool, len(stored))
+	for name := range stored {
+		owned[name] = true
+	}
+
+	plan := &model.SyncPlan{Org: org, Steps: []model.SyncStep{}, Summary: make(map[model.SyncAction]int)}
+	seen := make(map[string]bool, len(desired))
+	for _, serverDetail := range desired {
+		// Normalize as Publish does; names the org already stores keep their spelling
+		if name := textnorm.NFC(serverDetail.Name); owned[name] {
+			serverDetail.Name = name
+		} else {
+			serverDetail.Name = model.NormalizeName(name)
+		}
+		step := model.SyncStep{Name: serverDetail.Name, Version: serverDetail.VersionDetail.Version}
+		invalid := func(format string, args ...interface{}) {
+			step.Action = model.SyncInvalid
+			step.Error = fmt.Sprintf(format, args...)
+		}
+
+		switch issues := validateServer(serverDetail); {
+		case len(issues) > 0:
+			invalid("%s", issues[0].Message)
+		case seen[serverDetail.Name]:
+			invalid("server %s is listed more than once", serverDetail.Name)
+		case !strings.EqualFold(model.ExtractAuthorFromRepoURL(serverDetail.Repository.URL), org):
+			invalid("repository of %s does not belong to %s", serverDetail.Name, org)
+		}
+		seen[serverDetail.Name] = true
+
+		if step.Action == "" && !owned[serverDetail.Name] {
+			// The name may already be used by servers of another org
+			err := s.db.Iterate(ctx, map[string]interface{}{"name": serverDetail.Name}, func(entry *model.ServerDetail) error {
+				versionsOf(entry.Name).add(entry)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if versionsOf(serverDetail.Name).latest != nil {
+				invalid("server %s is published from another org", serverDetail.Name)
+			}
+		}
+
+		if step.Action == "" {
+			versions := versionsOf(serverDetail.Name)
+			existing := versions.byValue[step.Version]
+			switch {
+			case versions.latest == nil:
+				step.Action = model.SyncCreate
+			case existing != nil && existing.VersionDetail.Yanked:
+				step.Action = model.SyncRestore
+				step.IDs = []string{existing.ID}
+			case existing != nil:
+				step.Action = model.SyncUnchanged
+			case database.CompareSemanticVersions(step.Version, versions.latest.VersionDetail.Version) > 0:
+				step.Action = model.SyncUpdate
+			default:
+				invalid("version must be newer than the latest published version %s", versions.latest.VersionDetail.Version)
+			}
+			if step.Action == model.SyncCreate || step.Action == model.SyncUpdate {
+				step.Server = serverDetail
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	var removed []string
+	for name := range owned {
+		if !seen[name] && len(stored[name].live) > 0 {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		plan.Steps = append(plan.Steps, model.SyncStep{
+			Action:  model.SyncDelete,
+			Name:    name,
+			Version: stored[name].latest.VersionDetail.Version,
+			IDs:     stored[name].live,
+		})
+	}
+
+	for _, step := range plan.Steps {
+		plan.Summary[step.Action]++
+	}
+	return plan, nil
+}
+
+// ApplySync carries out the steps of a valid plan in order. Failed steps record their error
+// and do not stop the remaining steps.
+func (s *registryServiceImpl) ApplySync(plan *model.SyncPlan) error {
+	if !plan.Valid() {
+		return fmt.Errorf("%w: plan has invalid servers", database.ErrInvalidInput)
+	}
+
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		var err error
+		switch step.Action {
+		case model.SyncCreate, model.SyncUpdate:
+			serverDetail := *step.Server
+			serverDetail.ID = ""
+			if err = s.Publish(&serverDetail); err == nil {
+				step.IDs = []string{serverDetail.ID}
+			}
+		case model.SyncRestore:
+			err = s.Unyank(step.IDs[0])
+		case model.SyncDelete:
+			for _, id := range step.IDs {
+				if err = s.Yank(id, SyncYankReason); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			step.Error = err.Error()
+		}
+	}
+	plan.Applied = true
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+
+	"registry/internal/auth"
+	"registry/internal/model"
+)
+
+// RevokeToken records that the token with the given digest, issued to login, must no
+// longer be accepted
+func (s *registryServiceImpl) RevokeToken(digest, login string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.RevokeToken(ctx, &model.RevokedToken{Digest: digest, Login: login, RevokedAt: time.Now().UTC()})
+}
+
+// TokenRevoked reports whether the token with the given digest was revoked
+func (s *registryServiceImpl) TokenRevoked(digest string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.IsTokenRevoked(ctx, digest)
+}
+
+// CreatePublishToken mints a token that can only publish versions of the named server. The
+// returned token holds the secret, which is not stored and cannot be retrieved again.
+func (s *registryServiceImpl) CreatePublishToken(serverName, createdBy, description string) (*model.PublishToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	token := &model.PublishToken{
+		ID:          uuid.New().String(),
+		ServerName:  serverName,
+		Description: description,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+		Token:       model.PublishTokenPrefix + hex.EncodeToString(secret),
+	}
+	token.Digest = auth.TokenDigest(token.Token)
+
+	if err := s.db.CreatePublishToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// PublishToken returns the publish token with the given secret. Revoked tokens are
+// reported as not found.
+func (s *registryServiceImpl) PublishToken(secret string) (*model.PublishToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	digest := auth.TokenDigest(secret)
+	token, err := s.db.GetPublishToken(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.db.IsTokenRevoked(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, auth.ErrTokenRevoked
+	}
+	return token, nil
+}
+
+// PublishTokens returns the publish tokens of the named server, oldest first
+func (s *registryServiceImpl) PublishTokens(serverName string) ([]*model.PublishToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.ListPublishTokens(ctx, serverName)
+}
+
+// DeletePublishToken removes one of the publish tokens of the named server
+func (s *registryServiceImpl) DeletePublishToken(serverName, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeouts.Operation)
+	defer cancel()
+
+	return s.db.DeletePublishToken(ctx, serverName, id)
+}
@@ -0,0 +1,104 @@
+// Package signing signs registry responses so mirrors can verify them with the registry's public key
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Algorithm is the signature algorithm advertised with the public key
+const Algorithm = "ed25519"
+
+// Signer holds the registry signing key
+type Signer struct {
+	private ed25519.PrivateKey
+	keyID   string
+}
+
+// NewSigner creates a signer from a base64 encoded 32 byte Ed25519 seed
+func NewSigner(encodedSeed string) (*Signer, error) {
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedSeed))
+	if err != nil {
+		return nil, fmt.Errorf("decoding signing key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a %d byte Ed25519 seed, got %d bytes", ed25519.SeedSize, len(seed))
+	}
+
+	private := ed25519.NewKeyFromSeed(seed)
+	return &Signer{private: private, keyID: KeyID(private.Public().(ed25519.PublicKey))}, nil
+}
+
+// KeyID derives the identifier of a public key
+func KeyID(public ed25519.PublicKey) string {
+	sum := sha256.Sum256(public)
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateKey returns a new random seed in the encoding accepted by NewSigner
+func GenerateKey() (string, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(seed), nil
+}
+
+// KeyID identifies the key, derived from the public key
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the public half of the signing key
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.private.Public().(ed25519.PublicKey)
+}
+
+// SignDigest signs a SHA-256 digest of the signed content
+func (s *Signer) SignDigest(digest []byte) []byte {
+	return ed25519.Sign(s.private, digest)
+}
+
+// SignatureValue formats a detached signature over a SHA-256 digest for the
+// Registry-Signature header or trailer
+func (s *Signer) SignatureValue(digest []byte) string {
+	return fmt.Sprintf(`keyid="%s", alg="%s", digest="sha-256=%s", sig="%s"`,
+		s.keyID, Algorithm,
+		base64.StdEncoding.EncodeToString(digest),
+		base64.StdEncoding.EncodeToString(s.SignDigest(digest)))
+}
+
+// PublicKeyDocument is served at the well-known public key URL
+type PublicKeyDocument struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// PublicKeyHandler returns a handler serving the public key; it responds 404 when signing is disabled
+func PublicKeyHandler(signer *Signer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if signer == nil {
+			http.Error(w, "Response signing is not enabled", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		if err := json.NewEncoder(w).Encode(PublicKeyDocument{
+			KeyID:     signer.KeyID(),
+			Algorithm: Algorithm,
+			PublicKey: base64.StdEncoding.EncodeToString(signer.PublicKey()),
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
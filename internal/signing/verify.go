@@ -0,0 +1,84 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUntrusted is returned for signatures that are missing, malformed, made with a key that
+// is not trusted or that do not match the signed content
+var ErrUntrusted = errors.New("signature not trusted")
+
+// Verifier checks Registry-Signature values against a set of trusted public keys
+type Verifier struct {
+	keys map[string]ed25519.PublicKey
+}
+
+// NewVerifier creates a verifier trusting the given base64 encoded Ed25519 public keys, in
+// the encoding served at the well-known public key URL
+func NewVerifier(encodedKeys []string) (*Verifier, error) {
+	v := &Verifier{keys: make(map[string]ed25519.PublicKey)}
+	for _, encoded := range encodedKeys {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decoding public key: %w", err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("public key must be %d bytes, got %d bytes", ed25519.PublicKeySize, len(key))
+		}
+		v.keys[KeyID(key)] = key
+	}
+	return v, nil
+}
+
+// Len returns the number of trusted keys
+func (v *Verifier) Len() int {
+	return len(v.keys)
+}
+
+// Verify checks that value, a Registry-Signature value, signs the SHA-256 digest of the
+// content with a trusted key
+func (v *Verifier) Verify(value string, digest []byte) error {
+	params := parseSignatureValue(value)
+	if params["alg"] != Algorithm {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrUntrusted, params["alg"])
+	}
+	key, ok := v.keys[params["keyid"]]
+	if !ok {
+		return fmt.Errorf("%w: unknown key %q", ErrUntrusted, params["keyid"])
+	}
+	encodedDigest, ok := strings.CutPrefix(params["digest"], "sha-256=")
+	if !ok {
+		return fmt.Errorf("%w: unsupported digest %q", ErrUntrusted, params["digest"])
+	}
+	signed, err := base64.StdEncoding.DecodeString(encodedDigest)
+	if err != nil || !bytes.Equal(signed, digest) {
+		return fmt.Errorf("%w: digest does not match the content", ErrUntrusted)
+	}
+	sig, err := base64.StdEncoding.DecodeString(params["sig"])
+	if err != nil || !ed25519.Verify(key, digest, sig) {
+		return fmt.Errorf("%w: invalid signature by key %q", ErrUntrusted, params["keyid"])
+	}
+	return nil
+}
+
+// parseSignatureValue splits a Registry-Signature value into its parameters
+func parseSignatureValue(value string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		name, quoted, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(quoted), `"`)
+	}
+	return params
+}
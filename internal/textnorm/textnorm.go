@@ -0,0 +1,27 @@
+// Package textnorm normalizes text so that searches treat canonically equivalent strings,
+// and optionally accented and unaccented letters, as equal
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NFC returns s in Unicode normalization form C
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// Fold returns s lowercased and stripped of combining marks, so that "Café" and "cafe"
+// fold to the same string
+func Fold(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		folded = NFC(s)
+	}
+	return strings.ToLower(folded)
+}
@@ -0,0 +1,225 @@
+// Package usage accounts requests and transferred bytes per tenant and API key per day, so
+// operators of shared registries can attribute load to their users
+package usage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Anonymous is the key of requests without accepted credentials
+const Anonymous = "anonymous"
+
+// OtherTenants is the tenant of requests from tenants beyond the daily limit
+const OtherTenants = "other"
+
+// Admin is the key of requests carrying the admin token
+const Admin = "admin"
+
+// Row is the usage of one key of one tenant on one day
+type Row struct {
+	Day      string `json:"day"`
+	Tenant   string `json:"tenant,omitempty"`
+	Key      string `json:"key"`
+	Requests int64  `json:"requests"`
+	BytesIn  int64  `json:"bytes_in"`
+	BytesOut int64  `json:"bytes_out"`
+}
+
+// bucket identifies a row
+type bucket struct {
+	day    string
+	tenant string
+	key    string
+}
+
+// Ledger holds the daily usage of this process for a number of days
+type Ledger struct {
+	retention  int
+	maxTenants int
+
+	mu      sync.Mutex
+	rows    map[bucket]*Row
+	tenants map[string]map[string]bool
+	last    string
+}
+
+// NewLedger creates a ledger keeping the usage of the latest retention days. A
+// non-positive retention disables accounting. At most maxTenants tenants are told apart
+// per day, and later ones are recorded as OtherTenants; a non-positive maxTenants lifts
+// the limit.
+func NewLedger(retention, maxTenants int) *Ledger {
+	if retention <= 0 {
+		return &Ledger{}
+	}
+	return &Ledger{
+		retention:  retention,
+		maxTenants: maxTenants,
+		rows:       make(map[bucket]*Row),
+		tenants:    make(map[string]map[string]bool),
+	}
+}
+
+// Enabled reports whether the ledger accounts any usage
+func (l *Ledger) Enabled() bool {
+	return l != nil && l.retention > 0
+}
+
+// Record adds one request of key for tenant, received at at, to the ledger
+func (l *Ledger) Record(at time.Time, tenant, key string, bytesIn, bytesOut int64) {
+	if !l.Enabled() {
+		return
+	}
+	day := at.UTC().Format(time.DateOnly)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if day != l.last {
+		l.last = day
+		l.prune(at)
+	}
+
+	if tenant != "" && l.maxTenants > 0 {
+		seen := l.tenants[day]
+		if seen == nil {
+			seen = make(map[string]bool)
+			l.tenants[day] = seen
+		}
+		if !seen[tenant] {
+			if len(seen) < l.maxTenants {
+				seen[tenant] = true
+			} else {
+				tenant = OtherTenants
+			}
+		}
+	}
+
+	b := bucket{day: day, tenant: tenant, key: key}
+	row, ok := l.rows[b]
+	if !ok {
+		row = &Row{Day: day, Tenant: tenant, Key: key}
+		l.rows[b] = row
+	}
+	row.Requests++
+	row.BytesIn += bytesIn
+	row.BytesOut += bytesOut
+}
+
+// prune drops the days that fell out of the retention window. Callers hold l.mu.
+func (l *Ledger) prune(now time.Time) {
+	oldest := now.UTC().AddDate(0, 0, 1-l.retention).Format(time.DateOnly)
+	for b := range l.rows {
+		if b.day < oldest {
+			delete(l.rows, b)
+		}
+	}
+	for day := range l.tenants {
+		if day < oldest {
+			delete(l.tenants, day)
+		}
+	}
+}
+
+// Filter selects rows of a report. Empty fields match everything; From and To are
+// inclusive days in YYYY-MM-DD form.
+type Filter struct {
+	From   string
+	To     string
+	Tenant string
+	Key    string
+}
+
+// Report returns the rows matching filter ordered by day, tenant and key
+func (l *Ledger) Report(filter Filter) []Row {
+	if !l.Enabled() {
+		return []Row{}
+	}
+
+	l.mu.Lock()
+	result := make([]Row, 0, len(l.rows))
+	for b, row := range l.rows {
+		if (filter.From != "" && b.day < filter.From) || (filter.To != "" && b.day > filter.To) ||
+			(filter.Tenant != "" && b.tenant != filter.Tenant) || (filter.Key != "" && b.key != filter.Key) {
+			continue
+		}
+		result = append(result, *row)
+	}
+	l.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Day != result[j].Day {
+			return result[i].Day < result[j].Day
+		}
+		if result[i].Tenant != result[j].Tenant {
+			return result[i].Tenant < result[j].Tenant
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// principalKey is the context key of the flag set by Authenticated
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx in which Authenticated sets the returned flag
+func WithPrincipal(ctx context.Context) (context.Context, *bool) {
+	authenticated := new(bool)
+	return context.WithValue(ctx, principalKey{}, authenticated), authenticated
+}
+
+// Authenticated records that the credential of the request with ctx was accepted, so its
+// usage is attributed to the credential rather than to Anonymous. Without WithPrincipal
+// it does nothing.
+func Authenticated(ctx context.Context) {
+	if authenticated, ok := ctx.Value(principalKey{}).(*bool); ok {
+		*authenticated = true
+	}
+}
+
+// KeyOf identifies the credential of r without keeping it: a fingerprint of its bearer
+// token, or Anonymous when it carries none. Callers only use it for credentials that were
+// accepted, so made up tokens cannot add keys to the ledger.
+func KeyOf(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return Anonymous
+	}
+	token := header
+	if len(header) > 7 && strings.ToUpper(header[:7]) == "BEARER " {
+		token = header[7:]
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "key-" + hex.EncodeToString(sum[:8])
+}
+
+// WriteCSV writes rows as CSV with a header line
+func WriteCSV(w io.Writer, rows []Row) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"day", "tenant", "key", "requests", "bytes_in", "bytes_out"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Day,
+			row.Tenant,
+			row.Key,
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatInt(row.BytesIn, 10),
+			strconv.FormatInt(row.BytesOut, 10),
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
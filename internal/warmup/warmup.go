@@ -0,0 +1,271 @@
+// Package warmup keeps the busiest server listings cached, replaying the unfiltered first
+// page and the most requested queries after startup and after every write, so the first
+// requests after a deploy or a publish do not all miss the cache
+package warmup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stateKey is the state store key holding the most requested queries, so a restarted
+// instance warms the queries its predecessor saw
+const stateKey = "warmup:queries"
+
+// maxTrackedQueries bounds the distinct queries counted between warm-ups
+const maxTrackedQueries = 1000
+
+// Registry is the part of the registry service the warmer drives
+type Registry interface {
+	HeadRevision() (int64, error)
+	InvalidateListings()
+}
+
+// StateStore is the key-value state store of the database
+type StateStore interface {
+	LoadState(ctx context.Context, key string) (string, error)
+	SaveState(ctx context.Context, key, value string) error
+}
+
+// Warmer counts the queries of first-page listings and replays the most requested ones
+type Warmer struct {
+	registry Registry
+	store    StateStore
+	notFound error
+	top      int
+	refresh  time.Duration
+
+	mu      sync.Mutex
+	handler http.Handler
+	paths   []string
+	counts  map[string]int
+
+	// warmMu serializes warm-ups and guards the fields below
+	warmMu   sync.Mutex
+	loaded   bool
+	saved    string
+	warmed   time.Time
+	revision int64
+}
+
+// New returns a warmer replaying the unfiltered first page and the top most requested
+// queries. Listings are replayed when the registry changes, and at least every refresh so
+// pages expiring from the cache are fetched again. notFound is the error store returns for
+// missing keys.
+func New(registry Registry, store StateStore, notFound error, top int, refresh time.Duration) *Warmer {
+	return &Warmer{
+		registry: registry,
+		store:    store,
+		notFound: notFound,
+		top:      top,
+		refresh:  refresh,
+		counts:   make(map[string]int),
+	}
+}
+
+// SetHandler sets the handler requests are replayed through, and the listing paths whose
+// unfiltered first page is always replayed
+func (w *Warmer) SetHandler(handler http.Handler, paths ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handler, w.paths = handler, paths
+}
+
+type replayKey struct{}
+
+// IsReplay reports whether ctx belongs to a request replayed by a warmer, which must not be
+// recorded again
+func IsReplay(ctx context.Context) bool {
+	return ctx.Value(replayKey{}) != nil
+}
+
+// Record counts a first-page listing request for u. Requests are counted by path as well
+// as query, so replays fill the same cached pages as the requests they stand for.
+func (w *Warmer) Record(u *url.URL) {
+	query := u.Query()
+	if query.Get("cursor") != "" {
+		return
+	}
+	key := u.Path + "?" + canonicalQuery(query)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.counts[key]; !ok && len(w.counts) >= maxTrackedQueries {
+		// Halve every count to make room, forgetting queries seen only once
+		for k, n := range w.counts {
+			if n /= 2; n == 0 {
+				delete(w.counts, k)
+			} else {
+				w.counts[k] = n
+			}
+		}
+		if len(w.counts) >= maxTrackedQueries {
+			return
+		}
+	}
+	w.counts[key]++
+}
+
+// Warm replays the unfiltered first page and the most requested queries if the registry
+// changed or the refresh period passed since the last warm-up. Cached listings are dropped
+// first when the registry changed, since the change may have been made by another instance.
+func (w *Warmer) Warm(ctx context.Context) error {
+	w.warmMu.Lock()
+	defer w.warmMu.Unlock()
+
+	w.mu.Lock()
+	handler, paths := w.handler, w.paths
+	w.mu.Unlock()
+	if handler == nil {
+		return nil
+	}
+
+	if !w.loaded {
+		if err := w.load(ctx); err != nil {
+			return err
+		}
+		w.loaded = true
+	}
+
+	revision, err := w.registry.HeadRevision()
+	if err != nil {
+		return fmt.Errorf("error reading head revision: %w", err)
+	}
+	changed := w.warmed.IsZero() || revision != w.revision
+	if !changed && time.Since(w.warmed) < w.refresh {
+		return nil
+	}
+	if changed {
+		w.registry.InvalidateListings()
+	}
+
+	queries := w.topQueries()
+	replayCtx := context.WithValue(ctx, replayKey{}, true)
+	for _, target := range replays(paths, queries) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(replayCtx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		handler.ServeHTTP(&discardWriter{header: make(http.Header)}, req)
+	}
+	w.warmed, w.revision = time.Now(), revision
+
+	return w.save(ctx, queries)
+}
+
+// load seeds the counts with the queries saved by a previous run, in their saved order
+func (w *Warmer) load(ctx context.Context) error {
+	value, err := w.store.LoadState(ctx, stateKey)
+	if errors.Is(err, w.notFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error loading warm-up queries: %w", err)
+	}
+	var queries []string
+	if err := json.Unmarshal([]byte(value), &queries); err != nil {
+		return fmt.Errorf("error decoding warm-up queries: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, query := range queries {
+		w.counts[query] += len(queries) - i
+	}
+	w.saved = value
+	return nil
+}
+
+// save records queries for the next run when they changed
+func (w *Warmer) save(ctx context.Context, queries []string) error {
+	data, err := json.Marshal(queries)
+	if err != nil {
+		return err
+	}
+	if string(data) == w.saved {
+		return nil
+	}
+	if err := w.store.SaveState(ctx, stateKey, string(data)); err != nil {
+		return fmt.Errorf("error saving warm-up queries: %w", err)
+	}
+	w.saved = string(data)
+	return nil
+}
+
+// topQueries returns the most requested queries, most requested first
+func (w *Warmer) topQueries() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	queries := make([]string, 0, len(w.counts))
+	for query := range w.counts {
+		queries = append(queries, query)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if w.counts[queries[i]] != w.counts[queries[j]] {
+			return w.counts[queries[i]] > w.counts[queries[j]]
+		}
+		return queries[i] < queries[j]
+	})
+	if len(queries) > w.top {
+		queries = queries[:w.top]
+	}
+	return queries
+}
+
+// replays returns the requests to replay: the unfiltered first page of paths and of every
+// other listing path among queries, then the queries themselves
+func replays(paths, queries []string) []string {
+	seen := make(map[string]bool)
+	var targets []string
+	add := func(target string) {
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	for _, path := range paths {
+		add(path + "?")
+	}
+	for _, query := range queries {
+		path, _, _ := strings.Cut(query, "?")
+		add(path + "?")
+	}
+	for _, query := range queries {
+		add(query)
+	}
+	return targets
+}
+
+// canonicalQuery returns query without its cursor, encoded with sorted keys so equivalent
+// requests count as one
+func canonicalQuery(query url.Values) string {
+	canonical := make(url.Values, len(query))
+	for key, values := range query {
+		if key != "cursor" {
+			canonical[key] = values
+		}
+	}
+	return canonical.Encode()
+}
+
+// discardWriter drops replayed responses; only the cache fill matters
+type discardWriter struct {
+	header http.Header
+}
+
+func (d *discardWriter) Header() http.Header { return d.header }
+
+func (d *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func (d *discardWriter) WriteHeader(int) {}
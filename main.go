@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -12,11 +13,29 @@ import (
 	"time"
 
 	"registry/internal/api"
+	"registry/internal/api/router"
 	"registry/internal/auth"
+	"registry/internal/backup"
 	"registry/internal/config"
+	"registry/internal/daemon"
 	"registry/internal/database"
+	"registry/internal/enrichment"
+	"registry/internal/flags"
+	"registry/internal/gc"
+	"registry/internal/health"
+	"registry/internal/importer"
+	"registry/internal/leader"
+	"registry/internal/liveness"
+	"registry/internal/mcpserver"
+	"registry/internal/media"
 	"registry/internal/model"
+	"registry/internal/notify"
+	"registry/internal/replication"
+	"registry/internal/scan"
+	"registry/internal/scheduler"
 	"registry/internal/service"
+	"registry/internal/signing"
+	"registry/internal/warmup"
 )
 
 // Version info for the MCP Registry application
@@ -32,10 +51,42 @@ var (
 )
 
 func main() {
+	// Subcommands come before any flags
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-bundle" {
+		if err := runImportBundle(os.Args[2:]); err != nil {
+			log.Fatalf("Bundle import failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		if err := runBench(os.Args[2:]); err != nil {
+			log.Fatalf("Benchmark failed: %v", err)
+		}
+		return
+	}
+
 	// Parse command line flags
 	showVersion := flag.Bool("version", false, "Display version information")
+	generateSigningKey := flag.Bool("generate-signing-key", false, "Print a new response signing key and exit")
+	mcpStdio := flag.Bool("mcp-stdio", false, "Serve the registry as an MCP server over stdin and stdout instead of HTTP")
+	windowsService := flag.Bool("windows-service", false, "Run under the Windows service control manager")
 	flag.Parse()
 
+	if *generateSigningKey {
+		key, err := signing.GenerateKey()
+		if err != nil {
+			log.Fatalf("Failed to generate signing key: %v", err)
+		}
+		fmt.Println(key)
+		return
+	}
+
 	// Show version information if requested
 	if *showVersion {
 		log.Printf("MCP Registry v%s\n", Version)
@@ -46,35 +97,55 @@ func main() {
 
 	log.Printf("Starting MCP Registry Application v%s (commit: %s)", Version, GitCommit)
 
+	// Stop requests arrive as signals, or from the Windows service control manager
+	quit := make(chan os.Signal, 1)
+	if *windowsService {
+		if err := daemon.RunWindowsService("mcp-registry", quit); err != nil {
+			log.Printf("Failed to start Windows service: %v", err)
+			return
+		}
+		defer daemon.Stopped()
+	}
+
 	var (
 		registryService service.RegistryService
 		db              database.Database
-		err             error
 	)
 
 	// Initialize configuration
 	cfg := config.NewConfig()
 
+	nameUniqueness := database.NameUniqueness(cfg.NameUniqueness)
+	if !nameUniqueness.IsValid() {
+		log.Printf("Invalid name uniqueness: %s; supported policies: %s, %s", cfg.NameUniqueness, database.NamesExact, database.NamesCaseInsensitive)
+		return
+	}
+
 	// Initialize services based on environment
 	switch cfg.DatabaseType {
 	case config.DatabaseTypeMemory:
-		db = database.NewMemoryDB(map[string]*model.Server{})
-		registryService = service.NewRegistryServiceWithDB(db)
+		memoryDB := database.NewMemoryDB(map[string]*model.Server{})
+		memoryDB.SetNameUniqueness(nameUniqueness)
+		db = memoryDB
 	case config.DatabaseTypeMongoDB:
 		// Use MongoDB for real registry service in production/other environments
-		// Create a context with timeout for MongoDB connection
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		// Connect to MongoDB
-		db, err = database.NewMongoDB(ctx, cfg.DatabaseURL, cfg.DatabaseName, cfg.CollectionName)
+		// Connect to MongoDB, retrying while it starts up alongside the registry
+		mongoDB, err := connectMongoDB(cfg.DatabaseURL, cfg.DatabaseName, cfg.CollectionName, cfg.DatabaseConnectTimeout)
 		if err != nil {
 			log.Printf("Failed to connect to MongoDB: %v", err)
 			return
 		}
+		db = mongoDB
+
+		// Retry reads failing during elections, then ping periodically and reconnect
+		// after longer outages
+		mongoDB.SetReadRetryPolicy(database.RetryPolicy{
+			Retries: cfg.DatabaseReadRetries,
+			Backoff: cfg.DatabaseReadRetryBackoff,
+		})
+		mongoDB.SetNameUniqueness(nameUniqueness)
+		mongoDB.StartHealthMonitor(cfg.HealthCheckInterval)
 
-		// Create registry service with MongoDB
-		registryService = service.NewRegistryServiceWithDB(db)
 		log.Printf("MongoDB database name: %s", cfg.DatabaseName)
 		log.Printf("MongoDB collection name: %s", cfg.CollectionName)
 
@@ -91,24 +162,243 @@ func main() {
 		return
 	}
 
+	// Write bursts of publishes in batches
+	if cfg.PublishCoalesceWindow > 0 {
+		db = database.NewCoalescingDB(db, cfg.PublishCoalesceWindow, cfg.PublishCoalesceMaxBatch)
+		log.Printf("Coalescing publishes arriving within %s", cfg.PublishCoalesceWindow)
+	}
+
+	// While migrating to a new database, mirror every write to it
+	if cfg.DualWriteURL != "" {
+		name := cfg.DualWriteDatabaseName
+		if name == "" {
+			name = cfg.DatabaseName
+		}
+		secondary, err := connectMongoDB(cfg.DualWriteURL, name, cfg.CollectionName, cfg.DatabaseConnectTimeout)
+		if err != nil {
+			log.Printf("Failed to connect to the dual write database: %v", err)
+			return
+		}
+		db = database.NewDualWriteDB(db, secondary, cfg.DatabaseTimeout)
+		log.Printf("Mirroring writes to database %s", name)
+	}
+
+	// Record per-operation durations for the /metrics endpoint
+	db = database.NewInstrumentedDB(db, string(cfg.DatabaseType), cfg.SlowQueryThreshold)
+
+	// Record how the previous run of this instance ended, for /v0/health?verbose=true
+	instance, _ := os.Hostname()
+	startCtx, startCancel := context.WithTimeout(context.Background(), cfg.DatabaseTimeout)
+	if err := health.Default.TrackStart(startCtx, db, instance, database.ErrNotFound); err != nil {
+		log.Printf("Failed to record process start: %v", err)
+	}
+	startCancel()
+
+	idFormat := service.IDFormat(cfg.IDFormat)
+	if !idFormat.IsValid() {
+		log.Printf("Invalid ID format: %s; supported formats: %s, %s", cfg.IDFormat, service.IDFormatUUIDv4, service.IDFormatUUIDv7)
+		return
+	}
+
+	if cfg.ScanMode != scan.ModeFlag && cfg.ScanMode != scan.ModeBlock {
+		log.Printf("Invalid scan mode: %s; supported modes: %s, %s", cfg.ScanMode, scan.ModeFlag, scan.ModeBlock)
+		return
+	}
+
+	// Replicas behind one load balancer must share the secret to accept each other's cursors
+	if cfg.CursorSecret == "" {
+		log.Println("No cursor secret configured; listing cursors are only valid until restart")
+	}
+
+	// Create registry service with the configured database
+	var err error
+	registryService, err = service.NewRegistryServiceWithDB(db, service.Timeouts{
+		Operation: cfg.DatabaseTimeout,
+		Stream:    cfg.StreamTimeout,
+	}, idFormat, []byte(cfg.CursorSecret), service.NewQuotaPolicy(
+		cfg.QuotaMaxEntries, cfg.QuotaMaxVersionsPerDay, cfg.QuotaMaxBytesPerDay, cfg.QuotaRoles,
+	), service.ScanPolicy{
+		Scanners: scan.Scanners(cfg),
+		Block:    cfg.ScanMode == scan.ModeBlock,
+		Timeout:  cfg.ScanTimeout,
+	}, service.CachePolicy{ListingTTL: cfg.ListCacheTTL})
+	if err != nil {
+		log.Printf("Failed to create registry service: %v", err)
+		return
+	}
+
 	// Import seed data if requested (works for both memory and MongoDB)
 	if cfg.SeedImport {
 		log.Println("Importing data...")
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		if err := db.ImportSeed(ctx, cfg.SeedFilePath); err != nil {
+		if _, err := importer.ImportOnce(ctx, db, importer.Options{
+			Path:            cfg.SeedFilePath,
+			Format:          importer.Format(cfg.SeedFormat),
+			Policy:          database.ImportPolicy(cfg.SeedConflictPolicy),
+			SHA256:          cfg.SeedSHA256,
+			DownloadTimeout: cfg.SeedDownloadTimeout,
+		}); err != nil {
 			log.Printf("Failed to import seed file: %v", err)
 		} else {
 			log.Println("Data import completed successfully")
 		}
 	}
 
+	// Agents can also run the registry as a local MCP server speaking on stdin and stdout
+	if *mcpStdio {
+		log.Println("Serving MCP over stdio")
+		if err := mcpserver.New(registryService, cfg).ServeStdio(context.Background(), os.Stdin, os.Stdout); err != nil {
+			log.Printf("MCP stdio server failed: %v", err)
+		}
+		if err := health.Default.TrackStop(context.Background(), db, instance, "stdin closed"); err != nil {
+			log.Printf("Failed to record process stop: %v", err)
+		}
+		return
+	}
+
 	// Initialize authentication services
-	authService := auth.NewAuthService(cfg)
+	authService, err := auth.NewAuthService(cfg, router.ServerRouteNames())
+	if err != nil {
+		log.Printf("Invalid authentication configuration: %v", err)
+		return
+	}
+	authService = auth.WithRevocations(authService, registryService)
+
+	// Resolve feature flags from defaults, environment and configuration
+	featureFlags, err := flags.New(cfg)
+	if err != nil {
+		log.Printf("Invalid feature flag configuration: %v", err)
+		return
+	}
+
+	// Background workers stop when the process shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// With several replicas sharing a database, only the elected leader runs background jobs
+	isLeader := func() bool { return true }
+	electorDone := make(chan struct{})
+	if cfg.LeaderElection {
+		elector := leader.NewElector(db, cfg.LeaderLeaseTTL)
+		isLeader = elector.IsLeader
+		go func() {
+			defer close(electorDone)
+			elector.Run(workerCtx)
+		}()
+	} else {
+		close(electorDone)
+	}
+
+	// Background jobs run on their configured schedules, on the elected leader only.
+	// Jobs without a schedule fall back to their interval setting.
+	type jobSpec struct {
+		job      scheduler.Job
+		schedule string
+		interval time.Duration
+	}
+	enricher := enrichment.NewEnricher(cfg.GithubToken, cfg.EnrichmentTTL)
+	specs := []jobSpec{
+		{scheduler.Job{
+			// Enrich entries with GitHub repository metadata while the flag is on
+			Name: "enrichment", Enabled: cfg.EnrichmentEnabled, RunAtStart: true,
+			Condition: func() bool { return isLeader() && featureFlags.Enabled(flags.Enrichment) },
+			Run: func(ctx context.Context) error {
+				return enricher.Refresh(ctx, func() ([]string, error) { return enrichment.RepositoryURLs(registryService.StreamLatest) })
+			},
+		}, cfg.EnrichmentSchedule, cfg.EnrichmentInterval},
+		{scheduler.Job{
+			// Prune expired leases, old changes and orphaned manifests
+			Name: "gc", Enabled: cfg.GCEnabled, Condition: isLeader,
+			Run: func(context.Context) error { return gc.Collect(registryService, gc.Policy(cfg)) },
+		}, cfg.GCSchedule, cfg.GCInterval},
+		{scheduler.Job{
+			// Notify saved searches and chat channels of registry events
+			Name: "notifications", Enabled: cfg.SavedSearchEnabled, Condition: isLeader,
+			Run: notify.NewNotifier(db, cfg).Sync,
+		}, cfg.SavedSearchSchedule, cfg.SavedSearchInterval},
+		{scheduler.Job{
+			Name: "backup", Enabled: cfg.BackupEnabled, Condition: isLeader,
+			Run: func(context.Context) error { return backup.Write(registryService, cfg.BackupDir, cfg.BackupRetain) },
+		}, cfg.BackupSchedule, 0},
+		{scheduler.Job{
+			Name: "liveness", Enabled: cfg.LivenessEnabled, Condition: isLeader,
+			Run: func(ctx context.Context) error {
+				return liveness.NewChecker(cfg.LivenessTimeout).Check(ctx, registryService)
+			},
+		}, cfg.LivenessSchedule, 0},
+	}
+
+	// Every instance keeps its own listing cache warm; the job is a no-op until the listing
+	// handler is registered and a warm-up is due
+	warmer := warmup.New(registryService, db, database.ErrNotFound, cfg.CacheWarmQueries, cfg.ListCacheTTL)
+	specs = append(specs, jobSpec{scheduler.Job{
+		Name: "cache-warm", Enabled: cfg.ListCacheTTL > 0, Run: warmer.Warm,
+	}, "", cfg.CacheWarmInterval})
+
+	// Replicas follow the primary's change feed and reject local writes
+	if cfg.IsReplica() {
+		follower, err := replication.NewFollower(cfg.ReplicationSource, db, database.ConflictPolicy(cfg.ReplicationConflictPolicy))
+		if err != nil {
+			log.Printf("Invalid replication configuration: %v", err)
+			return
+		}
+		log.Printf("Replicating from %s", cfg.ReplicationSource)
+		specs = append(specs, jobSpec{scheduler.Job{
+			Name: "replication", Enabled: true, RunAtStart: true, Condition: isLeader, Run: follower.Sync,
+		}, cfg.ReplicationSchedule, cfg.ReplicationInterval})
+	}
+
+	jobs := scheduler.New(cfg.SchedulerJitter)
+	for _, spec := range specs {
+		switch {
+		case spec.schedule != "":
+			schedule, err := scheduler.Parse(spec.schedule)
+			if err != nil {
+				log.Printf("Invalid configuration for job %s: %v", spec.job.Name, err)
+				return
+			}
+			spec.job.Schedule = schedule
+		case spec.interval > 0:
+			spec.job.Schedule = scheduler.Every(spec.interval)
+		default:
+			// An interval of zero disables the job
+			spec.job.Schedule, spec.job.Enabled = scheduler.Every(0), false
+		}
+		jobs.Add(spec.job)
+	}
+	go jobs.Run(workerCtx)
+
+	// Uploaded icons live on disk or in S3
+	icons, err := media.NewStore(cfg)
+	if err != nil {
+		log.Printf("Failed to initialize media storage: %v", err)
+		return
+	}
+
+	// Sign listing and export responses when a signing key is configured
+	var signer *signing.Signer
+	if cfg.SigningKey != "" {
+		signer, err = signing.NewSigner(cfg.SigningKey)
+		if err != nil {
+			log.Printf("Invalid signing key: %v", err)
+			return
+		}
+		log.Printf("Signing responses with key %s", signer.KeyID())
+	}
 
 	// Initialize HTTP server
-	server := api.NewServer(cfg, registryService, authService)
+	server := api.NewServer(cfg, registryService, authService, featureFlags, enricher, icons, signer, jobs, warmer)
+
+	// Warm the busiest listings now rather than on the job's first tick
+	if cfg.ListCacheTTL > 0 {
+		go func() {
+			if err := warmer.Warm(workerCtx); err != nil {
+				log.Printf("Failed to warm listing cache: %v", err)
+			}
+		}()
+	}
 
 	// Start server in a goroutine so it doesn't block signal handling
 	go func() {
@@ -119,14 +409,13 @@ func main() {
 	}()
 
 	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	sig := <-quit
 	log.Println("Shutting down server...")
+	daemon.Stopping()
 
-	// Create context with timeout for shutdown
-	sctx, scancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Create context with timeout for shutdown, on top of the readiness drain delay
+	sctx, scancel := context.WithTimeout(context.Background(), cfg.ShutdownDelay+10*time.Second)
 	defer scancel()
 
 	// Gracefully shutdown the server
@@ -134,5 +423,41 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop background jobs and hand leadership over before exiting
+	stopWorkers()
+	<-electorDone
+
+	if err := health.Default.TrackStop(sctx, db, instance, sig.String()); err != nil {
+		log.Printf("Failed to record process stop: %v", err)
+	}
+
 	log.Println("Server exiting")
 }
+
+// connectMongoDB connects to MongoDB, retrying with exponential backoff until
+// connectTimeout elapses so the registry can start before the database is ready
+func connectMongoDB(uri, databaseName, collectionName string, connectTimeout time.Duration) (*database.MongoDB, error) {
+	const (
+		attemptTimeout = 10 * time.Second
+		maxBackoff     = 30 * time.Second
+	)
+
+	deadline := time.Now().Add(connectTimeout)
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), attemptTimeout)
+		db, err := database.NewMongoDB(ctx, uri, databaseName, collectionName)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		log.Printf("MongoDB not ready (attempt %d), retrying in %s: %v", attempt, backoff, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
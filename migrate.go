@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"registry/internal/config"
+	"registry/internal/database"
+	"registry/internal/migrate"
+)
+
+// runMigrate implements the migrate subcommand, which copies every record from one database
+// to another and then verifies the copy
+func runMigrate(args []string) error {
+	cfg := config.NewConfig()
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", "", "Connection URL of the database to copy from")
+	to := fs.String("to", "", "Connection URL of the database to copy to")
+	fromName := fs.String("from-database", cfg.DatabaseName, "Name of the database to copy from")
+	toName := fs.String("to-database", cfg.DatabaseName, "Name of the database to copy to")
+	verifyOnly := fs.Bool("verify-only", false, "Only verify that the target holds every record of the source")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return errors.New("both -from and -to are required")
+	}
+	if *from == *to && *fromName == *toName {
+		return errors.New("the source and target databases are the same")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	source, err := openMigrationDB(*from, *fromName, cfg)
+	if err != nil {
+		return fmt.Errorf("error opening source: %w", err)
+	}
+	defer source.Close()
+	target, err := openMigrationDB(*to, *toName, cfg)
+	if err != nil {
+		return fmt.Errorf("error opening target: %w", err)
+	}
+	defer target.Close()
+
+	if !*verifyOnly {
+		report, err := migrate.Copy(ctx, source, target)
+		if err != nil {
+			return err
+		}
+		log.Printf("Copied %d versions, %d saved searches, %d drafts, %d publish tokens, %d abuse reports, "+
+			"%d featured servers, %d aliases, %d archived organizations, %d revoked tokens and %d state values",
+			report.Versions, report.SavedSearches, report.Drafts, report.PublishTokens, report.Reports,
+			report.Featured, report.Aliases, report.ArchivedOrgs, report.RevokedTokens, report.State)
+	}
+
+	verification, err := migrate.Verify(ctx, source, target)
+	if err != nil {
+		return fmt.Errorf("error verifying: %w", err)
+	}
+	for _, mismatch := range verification.Mismatches {
+		log.Printf("Mismatch: %s", mismatch)
+	}
+	if verification.Truncated {
+		log.Printf("Further mismatches were not listed")
+	}
+	if !verification.OK() {
+		return fmt.Errorf("%d of %d records differ", len(verification.Mismatches), verification.Checked)
+	}
+	log.Printf("Verified %d records", verification.Checked)
+	return nil
+}
+
+// openMigrationDB connects to a database named by its connection URL. MongoDB is the only
+// persistent backend, so only MongoDB URLs are accepted.
+func openMigrationDB(uri, name string, cfg *config.Config) (database.Database, error) {
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		// Only the scheme is shown, as the URL may hold credentials
+		scheme, _, _ := strings.Cut(uri, "://")
+		return nil, fmt.Errorf("unsupported backend %q: expected a mongodb:// or mongodb+srv:// URL", scheme)
+	}
+	return connectMongoDB(uri, name, cfg.CollectionName, cfg.DatabaseConnectTimeout)
+}